@@ -115,7 +115,12 @@ export type CodegenGenerator =
   | "golang-server"
   | "golang-client"
   | "typescript-client"
-  | "dart-client";
+  | "dart-client"
+  | "openapi"
+  | "playground"
+  | "docs"
+  | "jsonschema"
+  | "asyncapi";
 
 export interface CmdCodegenOptions {
   /** The generator to use */
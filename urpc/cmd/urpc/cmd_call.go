@@ -0,0 +1,250 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/uforg/uforpc/urpc/internal/client"
+	"github.com/uforg/uforpc/urpc/internal/codegen"
+	"github.com/uforg/uforpc/urpc/internal/schema"
+)
+
+// clientArgs are the arguments shared by the call and subscribe commands.
+type clientArgs struct {
+	ConfigPath     string   `arg:"--config" default:"./uforpc.toml" help:"The config file path"`
+	URL            string   `arg:"--url" help:"The base URL of the server, overrides the one from the config file"`
+	Data           string   `arg:"-d,--data" default:"{}" help:"The JSON input, use @file to read it from a file or - to read it from stdin"`
+	Headers        []string `arg:"-H,--header,separate" help:"Header in the \"Key: Value\" format, can be repeated"`
+	Profile        string   `arg:"--profile" help:"The config profile to use, see [profile.<name>] in the config file"`
+	ClientProfile  string   `arg:"--client-profile" help:"Deprecated: use --profile with a [profile.<name>.client] table instead"`
+	SkipValidation bool     `arg:"--skip-validation" help:"Send the input without validating it against the schema"`
+	Compact        bool     `arg:"--compact" help:"Print the responses on a single line"`
+}
+
+type cmdCallArgs struct {
+	Proc    string        `arg:"positional,required" help:"The name of the procedure to call"`
+	Timeout time.Duration `arg:"--timeout" default:"30s" help:"The maximum duration of the call"`
+	clientArgs
+}
+
+type cmdSubscribeArgs struct {
+	Stream string `arg:"positional,required" help:"The name of the stream to subscribe to"`
+	clientArgs
+}
+
+func cmdCall(args *cmdCallArgs) {
+	loaded, c, input := prepareClient(args.clientArgs)
+
+	if _, ok := loaded.Schema.GetProcNodesMap()[args.Proc]; !ok {
+		if _, isStream := loaded.Schema.GetStreamNodesMap()[args.Proc]; isStream {
+			log.Fatalf("UFO RPC: %s is a stream, use urpc subscribe instead", args.Proc)
+		}
+		log.Fatalf("UFO RPC: procedure %s not found in the schema", args.Proc)
+	}
+	if !args.SkipValidation {
+		validateClientInput(loaded.Schema, loaded.Schema.GetProcNodesMap()[args.Proc].Input, input)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), args.Timeout)
+	defer cancel()
+
+	res, err := c.Call(ctx, args.Proc, input)
+	if err != nil {
+		log.Fatalf("UFO RPC: failed to call %s: %s", args.Proc, err)
+	}
+
+	printResponse(res, args.Compact)
+	if !res.Ok {
+		os.Exit(1)
+	}
+}
+
+func cmdSubscribe(args *cmdSubscribeArgs) {
+	loaded, c, input := prepareClient(args.clientArgs)
+
+	if _, ok := loaded.Schema.GetStreamNodesMap()[args.Stream]; !ok {
+		if _, isProc := loaded.Schema.GetProcNodesMap()[args.Stream]; isProc {
+			log.Fatalf("UFO RPC: %s is a procedure, use urpc call instead", args.Stream)
+		}
+		log.Fatalf("UFO RPC: stream %s not found in the schema", args.Stream)
+	}
+	if !args.SkipValidation {
+		validateClientInput(loaded.Schema, loaded.Schema.GetStreamNodesMap()[args.Stream].Input, input)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	failed := false
+	err := c.Subscribe(ctx, args.Stream, input, func(res client.Response) error {
+		printResponse(res, args.Compact)
+		if !res.Ok {
+			failed = true
+		}
+		return nil
+	})
+	if err != nil {
+		log.Fatalf("UFO RPC: failed to subscribe to %s: %s", args.Stream, err)
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// prepareClient loads the schema, resolves the endpoint and reads the
+// input, exiting on any error.
+func prepareClient(args clientArgs) (loadedSchema, *client.Client, []byte) {
+	loaded, clientProfile, err := loadClientConfig(args)
+	if err != nil {
+		log.Fatalf("UFO RPC: %s", err)
+	}
+
+	endpoint, err := resolveEndpoint(loaded, clientProfile, args)
+	if err != nil {
+		log.Fatalf("UFO RPC: %s", err)
+	}
+
+	input, err := readClientInput(args.Data)
+	if err != nil {
+		log.Fatalf("UFO RPC: %s", err)
+	}
+
+	return loaded, client.New(endpoint), input
+}
+
+// loadClientConfig loads the schema with the selected config profile and
+// returns the deprecated [client.profiles.<name>] profile to apply.
+//
+// Before config profiles existed --profile selected a client profile, so a
+// client profile with the same name is still applied, after a warning,
+// both when it is the only profile with that name and over the config
+// profile.
+func loadClientConfig(args clientArgs) (loadedSchema, string, error) {
+	loaded, err := loadSchemaFromConfig(args.ConfigPath, args.Profile)
+	var profileErr *codegen.UndefinedProfileError
+	if errors.As(err, &profileErr) {
+		fallback, fallbackErr := loadSchemaFromConfig(args.ConfigPath, "")
+		if fallbackErr == nil && fallback.Config.Client != nil && fallback.Config.Client.HasProfile(args.Profile) {
+			loaded, err = fallback, nil
+		}
+	}
+	if err != nil {
+		return loadedSchema{}, "", err
+	}
+
+	clientConfig := client.Config{}
+	if loaded.Config.Client != nil {
+		clientConfig = *loaded.Config.Client
+	}
+
+	if args.ClientProfile != "" {
+		log.Printf("UFO RPC: warning: --client-profile and [client.profiles] are deprecated, move the profile to [profile.%s.client] and use --profile %s", args.ClientProfile, args.ClientProfile)
+		return loaded, args.ClientProfile, nil
+	}
+	if args.Profile != "" && clientConfig.HasProfile(args.Profile) {
+		log.Printf("UFO RPC: warning: [client.profiles.%s] is deprecated, move it to [profile.%s.client]", args.Profile, args.Profile)
+		return loaded, args.Profile, nil
+	}
+	return loaded, "", nil
+}
+
+// resolveEndpoint applies, in order, the playground defaults, the client
+// config, the selected client profile and the command line overrides.
+func resolveEndpoint(loaded loadedSchema, clientProfile string, args clientArgs) (client.Endpoint, error) {
+	defaults := client.Endpoint{}
+	if playgroundConfig := loaded.Config.FirstPlayground(); playgroundConfig != nil {
+		defaults.BaseURL = playgroundConfig.DefaultBaseURL
+		for _, header := range playgroundConfig.DefaultHeaders {
+			defaults.Headers = append(defaults.Headers, client.Header{Key: header.Key, Value: header.Value})
+		}
+	}
+
+	clientConfig := client.Config{}
+	if loaded.Config.Client != nil {
+		clientConfig = *loaded.Config.Client
+	}
+
+	endpoint, err := clientConfig.Resolve(defaults, clientProfile)
+	if err != nil {
+		return client.Endpoint{}, err
+	}
+
+	if args.URL != "" {
+		endpoint.BaseURL = args.URL
+	}
+	for _, raw := range args.Headers {
+		header, err := client.ParseHeader(raw)
+		if err != nil {
+			return client.Endpoint{}, err
+		}
+		endpoint.Headers = client.MergeHeaders(endpoint.Headers, []client.Header{header})
+	}
+
+	if endpoint.BaseURL == "" {
+		return client.Endpoint{}, fmt.Errorf("the base URL is not configured, use --url or set base_url in the [client] section of the config file")
+	}
+
+	return endpoint, nil
+}
+
+// readClientInput returns the input from the --data argument.
+func readClientInput(data string) ([]byte, error) {
+	switch {
+	case data == "-":
+		input, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read input from stdin: %w", err)
+		}
+		return input, nil
+	case strings.HasPrefix(data, "@"):
+		input, err := os.ReadFile(data[1:])
+		if err != nil {
+			return nil, fmt.Errorf("failed to read input file: %w", err)
+		}
+		return input, nil
+	default:
+		return []byte(data), nil
+	}
+}
+
+// validateClientInput checks the input against the schema fields, exiting
+// if it is invalid.
+func validateClientInput(sch schema.Schema, fields []schema.FieldDefinition, input []byte) {
+	value, err := schema.DecodeValue(input)
+	if err != nil {
+		log.Fatalf("UFO RPC: invalid input: the input is not valid JSON: %s", err)
+	}
+	if err := schema.NewValueValidator(sch).Validate(fields, value); err != nil {
+		log.Fatalf("UFO RPC: invalid input: %s", err)
+	}
+}
+
+// printResponse prints the response envelope to stdout.
+func printResponse(res client.Response, compact bool) {
+	raw := bytes.TrimSpace(res.Raw)
+
+	var out bytes.Buffer
+	var err error
+	if compact {
+		err = json.Compact(&out, raw)
+	} else {
+		err = json.Indent(&out, raw, "", "  ")
+	}
+	if err != nil {
+		out.Reset()
+		out.Write(raw)
+	}
+	out.WriteByte('\n')
+	_, _ = os.Stdout.Write(out.Bytes())
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/uforg/uforpc/urpc/internal/codegen"
+	"github.com/uforg/uforpc/urpc/internal/urpc/analyzer"
+	"github.com/uforg/uforpc/urpc/internal/urpc/ast"
+	"github.com/uforg/uforpc/urpc/internal/urpc/docstore"
+	"github.com/uforg/uforpc/urpc/internal/urpc/linter"
+	"github.com/uforg/uforpc/urpc/internal/urpc/report"
+	"github.com/uforg/uforpc/urpc/internal/util/filepathutil"
+)
+
+type cmdCheckArgs struct {
+	Patterns   []string `arg:"positional" help:"The schema file patterns to check, supports recursive globs (e.g. './rpc/**/*.urpc')"`
+	Format     string   `arg:"-f,--format" default:"human" help:"The output format: human, json, sarif or github"`
+	ConfigPath string   `arg:"--config" default:"./uforpc.toml" help:"The config file with the lint rules, the default rules are used when it does not exist"`
+	Profile    string   `arg:"--profile" help:"The config profile to use, see [profile.<name>] in the config file"`
+	NoLint     bool     `arg:"--no-lint" help:"Only report the analyzer diagnostics, without running the linter"`
+	Strict     bool     `arg:"--strict" help:"Exit with a non-zero code also when warnings are found"`
+}
+
+func cmdCheck(args *cmdCheckArgs) {
+	startTime := time.Now()
+
+	if len(args.Patterns) == 0 {
+		log.Fatalf("UFO RPC: no schema patterns provided, e.g. urpc check './rpc/**/*.urpc'")
+	}
+
+	format, err := report.ParseFormat(args.Format)
+	if err != nil {
+		log.Fatalf("UFO RPC: %s", err)
+	}
+
+	files, err := collectSchemaFiles(args.Patterns, nil)
+	if err != nil {
+		log.Fatalf("UFO RPC: %s", err)
+	}
+	if len(files) == 0 {
+		log.Fatalf("UFO RPC: no schema files found")
+	}
+
+	lintConfig, err := loadCheckLintConfig(args)
+	if err != nil {
+		log.Fatalf("UFO RPC: %s", err)
+	}
+	rep, err := checkSchemas(files, lintConfig)
+	if err != nil {
+		log.Fatalf("UFO RPC: %s", err)
+	}
+
+	writeCheckReport(rep, format, args.Strict, func(errorsCount, warningsCount, infosCount int) {
+		log.Printf(
+			"UFO RPC: checked %d schemas in %s, %d errors, %d warnings, %d infos",
+			len(files), time.Since(startTime), errorsCount, warningsCount, infosCount,
+		)
+	})
+}
+
+// checkSchemas analyzes the schema files and lints the valid ones with the
+// given config, the linter is not run when it is nil. It returns the report
+// with the diagnostics of all the files.
+func checkSchemas(files []string, lintConfig *linter.Config) (report.Report, error) {
+	an, err := analyzer.NewAnalyzer(docstore.NewDocstore())
+	if err != nil {
+		return report.Report{}, fmt.Errorf("failed to create URPC analyzer: %w", err)
+	}
+
+	rep := report.Report{Sources: map[string]string{}}
+	seen := map[string]bool{}
+	for _, file := range files {
+		absPath, err := filepathutil.NormalizeFromWD(file)
+		if err != nil {
+			return report.Report{}, fmt.Errorf("failed to normalize schema path: %w", err)
+		}
+
+		astSchema, diagnostics, err := an.Analyze(absPath)
+		if err != nil && len(diagnostics) == 0 {
+			return report.Report{}, fmt.Errorf("failed to analyze %s: %w", file, err)
+		}
+
+		// The linter can report misleading findings on invalid schemas
+		if err == nil && lintConfig != nil {
+			diagnostics = append(diagnostics, linter.Lint(astSchema, *lintConfig)...)
+		}
+
+		for _, diag := range diagnostics {
+			diag.Pos = relativeToWD(diag.Pos)
+			diag.EndPos = relativeToWD(diag.EndPos)
+
+			// The same external docstring can be referenced from many schemas
+			key := fmt.Sprintf("%s|%s|%s", diag.Pos, diag.EndPos, diag.Message)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			if _, ok := rep.Sources[diag.Pos.Filename]; !ok {
+				if content, err := os.ReadFile(diag.Pos.Filename); err == nil {
+					rep.Sources[diag.Pos.Filename] = string(content)
+				}
+			}
+			rep.Diagnostics = append(rep.Diagnostics, diag)
+		}
+	}
+
+	return rep, nil
+}
+
+// writeCheckReport writes the report to stdout, calls summary with the
+// counts of the diagnostics and exits with a non-zero code if there are
+// errors, or also warnings when strict is true.
+func writeCheckReport(rep report.Report, format report.Format, strict bool, summary func(errorsCount, warningsCount, infosCount int)) {
+	if err := rep.Write(os.Stdout, format); err != nil {
+		log.Fatalf("UFO RPC: failed to write report: %s", err)
+	}
+
+	errorsCount, warningsCount, infosCount := rep.Counts()
+	summary(errorsCount, warningsCount, infosCount)
+
+	if errorsCount > 0 || (strict && warningsCount > 0) {
+		os.Exit(1)
+	}
+}
+
+// loadCheckLintConfig returns the lint config of the config file, or the
+// default one when the file does not exist.
+func loadCheckLintConfig(args *cmdCheckArgs) (*linter.Config, error) {
+	if args.NoLint {
+		return nil, nil
+	}
+
+	if _, err := os.Stat(args.ConfigPath); errors.Is(err, os.ErrNotExist) {
+		if args.Profile != "" {
+			return nil, fmt.Errorf("config file %s not found, it is required to use a profile", args.ConfigPath)
+		}
+		return &linter.Config{}, nil
+	}
+
+	config, err := codegen.LoadConfig(args.ConfigPath, args.Profile)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config file: %w", err)
+	}
+	return &config.Lint, nil
+}
+
+// relativeToWD makes the file name of a position relative to the working
+// directory when the file is inside it, so reports are portable.
+func relativeToWD(pos ast.Position) ast.Position {
+	if pos.Filename == "" || !filepath.IsAbs(pos.Filename) {
+		return pos
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return pos
+	}
+
+	rel, err := filepath.Rel(wd, pos.Filename)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return pos
+	}
+
+	pos.Filename = rel
+	return pos
+}
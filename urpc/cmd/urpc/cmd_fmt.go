@@ -1,48 +1,200 @@
 package main
 
 import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
 	"log"
 	"os"
-	"path/filepath"
+	"slices"
+	"strings"
 	"time"
 
+	"github.com/pmezard/go-difflib/difflib"
 	"github.com/uforg/uforpc/urpc/internal/urpc/formatter"
 )
 
+// stdinPattern is the pattern used to read from stdin and write to stdout.
+const stdinPattern = "-"
+
 type cmdFmtArgs struct {
-	Pattern string `arg:"positional" help:"The file pattern to format (support globs e.g. './rpc/**/*.urpc')"`
-	Verbose bool   `arg:"-v,--verbose" help:"Verbose output prints all formatted files"`
+	Patterns   []string `arg:"positional" help:"The file patterns to format, supports recursive globs (e.g. './rpc/**/*.urpc') and '-' to read from stdin and write to stdout"`
+	Ignore     []string `arg:"--ignore,separate" help:"Pattern of files to skip, can be repeated (e.g. --ignore 'rpc/generated/**')"`
+	IgnorePath string   `arg:"--ignore-path" default:".urpcignore" help:"File with patterns of files to skip, one per line"`
+	Check      bool     `arg:"--check" help:"Do not write files, exit with code 1 if any file is not formatted"`
+	Diff       bool     `arg:"--diff" help:"Do not write files, print a unified diff of the changes"`
+	Verbose    bool     `arg:"-v,--verbose" help:"Verbose output prints all formatted files"`
 }
 
 func cmdFmt(args *cmdFmtArgs) {
-	var matches []string
-	var err error
 	startTime := time.Now()
 
-	matches, err = filepath.Glob(args.Pattern)
-	if err != nil {
-		log.Fatalf("UFO RPC: failed to glob pattern: %s", err)
+	if len(args.Patterns) == 0 {
+		log.Fatalf("UFO RPC: no file patterns provided, e.g. urpc fmt './rpc/**/*.urpc'")
 	}
 
-	for _, match := range matches {
-		fileBytes, err := os.ReadFile(match)
-		if err != nil {
-			log.Fatalf("UFO RPC: failed to read file: %s", err)
+	if slices.Contains(args.Patterns, stdinPattern) {
+		if len(args.Patterns) > 1 {
+			log.Fatalf("UFO RPC: '%s' cannot be combined with other patterns", stdinPattern)
 		}
+		os.Exit(cmdFmtStdin(args))
+	}
+
+	ignorePatterns, err := readIgnoreFile(args.IgnorePath)
+	if err != nil {
+		log.Fatalf("UFO RPC: failed to read ignore file: %s", err)
+	}
+	ignorePatterns = append(ignorePatterns, args.Ignore...)
 
-		formatted, err := formatter.Format(match, string(fileBytes))
+	files, err := collectSchemaFiles(args.Patterns, ignorePatterns)
+	if err != nil {
+		log.Fatalf("UFO RPC: %s", err)
+	}
+
+	failed, changed := 0, 0
+	for _, file := range files {
+		fileChanged, err := formatFile(file, args)
 		if err != nil {
-			log.Fatalf("UFO RPC: failed to format file: %s", err)
+			log.Printf("UFO RPC: %s", err)
+			failed++
+			continue
+		}
+		if fileChanged {
+			changed++
 		}
+	}
+
+	switch {
+	case args.Check:
+		log.Printf("UFO RPC: checked %d files in %s, %d not formatted, %d failed", len(files), time.Since(startTime), changed, failed)
+	case args.Diff:
+		log.Printf("UFO RPC: diffed %d files in %s, %d not formatted, %d failed", len(files), time.Since(startTime), changed, failed)
+	default:
+		log.Printf("UFO RPC: formatted %d files in %s, %d changed, %d failed", len(files), time.Since(startTime), changed, failed)
+	}
 
-		if err := os.WriteFile(match, []byte(formatted), 0644); err != nil {
-			log.Fatalf("UFO RPC: failed to write file: %s", err)
+	if failed > 0 || (args.Check && changed > 0) {
+		os.Exit(1)
+	}
+}
+
+// cmdFmtStdin formats the schema read from stdin and writes it to stdout.
+//
+// Returns the exit code.
+func cmdFmtStdin(args *cmdFmtArgs) int {
+	input, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		log.Printf("UFO RPC: failed to read stdin: %s", err)
+		return 1
+	}
+
+	formatted, err := formatter.Format("<stdin>", string(input))
+	if err != nil {
+		log.Printf("UFO RPC: <stdin>: %s", err)
+		return 1
+	}
+
+	changed := formatted != string(input)
+	switch {
+	case args.Diff:
+		if changed {
+			fmt.Print(unifiedDiff("<stdin>", string(input), formatted))
 		}
+	case args.Check:
+		// Nothing is printed, only the exit code is relevant
+	default:
+		fmt.Print(formatted)
+	}
+
+	if args.Check && changed {
+		return 1
+	}
+	return 0
+}
+
+// formatFile formats a single file according to the args.
+//
+// Returns true if the file content is not formatted.
+func formatFile(file string, args *cmdFmtArgs) (bool, error) {
+	fileBytes, err := os.ReadFile(file)
+	if err != nil {
+		return false, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	formatted, err := formatter.Format(file, string(fileBytes))
+	if err != nil {
+		return false, fmt.Errorf("failed to format file: %w", err)
+	}
+
+	changed := formatted != string(fileBytes)
+	if !changed {
+		return false, nil
+	}
 
+	if args.Diff {
+		fmt.Print(unifiedDiff(file, string(fileBytes), formatted))
+	}
+
+	if args.Check || args.Diff {
 		if args.Verbose {
-			log.Println("UFO RPC: formatted", match)
+			log.Println("UFO RPC: not formatted", file)
+		}
+		return true, nil
+	}
+
+	if err := os.WriteFile(file, []byte(formatted), 0644); err != nil {
+		return true, fmt.Errorf("failed to write file: %w", err)
+	}
+
+	if args.Verbose {
+		log.Println("UFO RPC: formatted", file)
+	}
+
+	return true, nil
+}
+
+// readIgnoreFile reads the patterns of an ignore file. Empty lines and lines
+// starting with # are skipped. A missing ignore file has no patterns.
+func readIgnoreFile(path string) ([]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	patterns := []string{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
 		}
+		patterns = append(patterns, line)
 	}
 
-	log.Printf("UFO RPC: formatted %d files in %s", len(matches), time.Since(startTime))
+	return patterns, scanner.Err()
+}
+
+// unifiedDiff returns the unified diff between the original and the
+// formatted content of a file.
+func unifiedDiff(file string, original string, formatted string) string {
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(original),
+		B:        difflib.SplitLines(formatted),
+		FromFile: file + ".orig",
+		ToFile:   file,
+		Context:  3,
+	})
+	if err != nil {
+		return fmt.Sprintf("failed to diff %s: %s\n", file, err)
+	}
+	return diff
 }
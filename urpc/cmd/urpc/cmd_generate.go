@@ -1,26 +1,178 @@
 package main
 
 import (
+	"context"
+	"crypto/sha256"
+	"fmt"
 	"log"
+	"maps"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
 	"time"
 
 	"github.com/uforg/uforpc/urpc/internal/codegen"
 )
 
 type cmdGenerateArgs struct {
-	ConfigPath string `arg:"positional" help:"The config file path (default: ./uforpc.toml)"`
+	ConfigPath    string        `arg:"positional" help:"The config file path (default: ./uforpc.toml)"`
+	Watch         bool          `arg:"-w,--watch" help:"Watch the config, the schema and its external docstrings and generate again on changes"`
+	WatchInterval time.Duration `arg:"--watch-interval" default:"500ms" help:"How often to check for changes in watch mode"`
+	Check         bool          `arg:"--check" help:"Check that the generated files are up to date without writing them, exits with a non-zero code if they are not"`
+	Jobs          int           `arg:"-j,--jobs" help:"Maximum number of targets generated concurrently (default: number of CPUs)"`
+	Profile       string        `arg:"--profile" help:"The config profile to use, see [profile.<name>] in the config file"`
 }
 
 func cmdGenerate(args *cmdGenerateArgs) {
-	startTime := time.Now()
-
 	if args.ConfigPath == "" {
 		args.ConfigPath = "./uforpc.toml"
 	}
 
-	if err := codegen.Run(args.ConfigPath); err != nil {
+	if args.Watch && args.Check {
+		log.Fatalf("UFO RPC: --watch and --check cannot be used together")
+	}
+
+	if args.Watch {
+		cmdGenerateWatch(args)
+		return
+	}
+
+	if args.Check {
+		cmdGenerateCheck(args)
+		return
+	}
+
+	startTime := time.Now()
+
+	report, err := codegen.RunWithOptions(args.ConfigPath, codegen.Options{Jobs: args.Jobs, Profile: args.Profile})
+	logTargetTimings(report)
+	if err != nil {
 		log.Fatalf("UFO RPC: failed to run code generator: %s", err)
 	}
 
 	log.Printf("UFO RPC: code generation finished in %s", time.Since(startTime))
 }
+
+// logTargetTimings prints the time taken by every generated target.
+func logTargetTimings(report codegen.Report) {
+	for _, target := range report.Targets {
+		if target.Err != nil {
+			log.Printf("UFO RPC:   %s failed after %s", target.Name, target.Duration.Round(time.Microsecond))
+			continue
+		}
+		files := "files"
+		if target.Files == 1 {
+			files = "file"
+		}
+		log.Printf("UFO RPC:   %s generated %d %s in %s", target.Name, target.Files, files, target.Duration.Round(time.Microsecond))
+	}
+}
+
+// cmdGenerateCheck generates the code in memory and exits with a non-zero
+// code if any generated file on disk is stale or missing.
+func cmdGenerateCheck(args *cmdGenerateArgs) {
+	result, err := codegen.Check(args.ConfigPath, codegen.Options{Jobs: args.Jobs, Profile: args.Profile})
+	if err != nil {
+		log.Fatalf("UFO RPC: failed to run code generator: %s", err)
+	}
+
+	if len(result.Stale) == 0 {
+		log.Printf("UFO RPC: the %d generated files are up to date", result.Checked)
+		return
+	}
+
+	wd, _ := os.Getwd()
+	for _, file := range result.Stale {
+		path := file.Path
+		if rel, err := filepath.Rel(wd, path); err == nil {
+			path = rel
+		}
+		fmt.Printf("%s: %s\n", path, file.Reason)
+	}
+	log.Fatalf("UFO RPC: %d generated files are out of date, run `urpc generate` to update them", len(result.Stale))
+}
+
+// cmdGenerateWatch runs the code generator every time a watched file
+// changes until the process is interrupted. Errors are printed and the
+// watcher keeps running.
+func cmdGenerateWatch(args *cmdGenerateArgs) {
+	if args.WatchInterval <= 0 {
+		log.Fatalf("UFO RPC: the watch interval must be greater than zero")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	files, snapshot := generateAndSnapshot(args)
+	log.Printf("UFO RPC: watching %d files for changes, press Ctrl+C to stop", len(files))
+
+	ticker := time.NewTicker(args.WatchInterval)
+	defer ticker.Stop()
+
+	// Changes are debounced, the generation runs once the watched files
+	// stop changing for a whole interval
+	pending := false
+	for {
+		select {
+		case <-ctx.Done():
+			log.Printf("UFO RPC: stopped watching")
+			return
+		case <-ticker.C:
+		}
+
+		current := takeSnapshot(files)
+		if !maps.Equal(current, snapshot) {
+			snapshot = current
+			pending = true
+			continue
+		}
+
+		if pending {
+			pending = false
+			previousCount := len(files)
+			files, snapshot = generateAndSnapshot(args)
+			if len(files) != previousCount {
+				log.Printf("UFO RPC: watching %d files for changes", len(files))
+			}
+		}
+	}
+}
+
+// generateAndSnapshot runs the code generator printing the result.
+//
+// Returns the files to watch and their snapshot taken before generating,
+// so changes made during the generation are not missed.
+func generateAndSnapshot(args *cmdGenerateArgs) ([]string, map[string]string) {
+	files, err := codegen.WatchedFiles(args.ConfigPath, args.Profile)
+	if err != nil {
+		log.Printf("UFO RPC: failed to resolve watched files: %s", err)
+	}
+	snapshot := takeSnapshot(files)
+
+	startTime := time.Now()
+	report, err := codegen.RunWithOptions(args.ConfigPath, codegen.Options{Jobs: args.Jobs, Profile: args.Profile})
+	logTargetTimings(report)
+	if err != nil {
+		log.Printf("UFO RPC: failed to run code generator: %s", err)
+	} else {
+		log.Printf("UFO RPC: code generation finished in %s", time.Since(startTime))
+	}
+
+	return files, snapshot
+}
+
+// takeSnapshot returns the content hash of every file, missing or unreadable
+// files have an empty hash.
+func takeSnapshot(files []string) map[string]string {
+	snapshot := make(map[string]string, len(files))
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			snapshot[file] = ""
+			continue
+		}
+		snapshot[file] = fmt.Sprintf("%x", sha256.Sum256(content))
+	}
+	return snapshot
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"log"
+	"os"
+
+	"github.com/uforg/uforpc/urpc/internal/importer"
+	importopenapi "github.com/uforg/uforpc/urpc/internal/importer/openapi"
+	importproto "github.com/uforg/uforpc/urpc/internal/importer/proto"
+)
+
+type cmdImportArgs struct {
+	OpenAPI *cmdImportOpenAPIArgs `arg:"subcommand:openapi" help:"Convert an OpenAPI 3 document in YAML or JSON format to a URPC schema"`
+	Proto   *cmdImportProtoArgs   `arg:"subcommand:proto" help:"Convert a Protocol Buffers (proto3) file to a URPC schema"`
+}
+
+// importOutputArgs are the arguments shared by all the import subcommands.
+type importOutputArgs struct {
+	Output string `arg:"-o,--output" help:"The file to write the URPC schema to, it is printed to stdout if not specified"`
+	Force  bool   `arg:"--force" help:"Overwrite the output file if it already exists"`
+}
+
+type cmdImportOpenAPIArgs struct {
+	Path string `arg:"positional,required" help:"The OpenAPI document to convert, use '-' to read from stdin"`
+	importOutputArgs
+}
+
+type cmdImportProtoArgs struct {
+	Path string `arg:"positional,required" help:"The proto file to convert, use '-' to read from stdin"`
+	importOutputArgs
+}
+
+func cmdImport(args *cmdImportArgs) {
+	if args.OpenAPI != nil {
+		content := readImportInput(args.OpenAPI.Path)
+		result, err := importopenapi.Import(content)
+		if err != nil {
+			log.Fatalf("UFO RPC: failed to import OpenAPI document: %s", err)
+		}
+		writeImportResult(result, args.OpenAPI.importOutputArgs)
+		return
+	}
+
+	if args.Proto != nil {
+		content := readImportInput(args.Proto.Path)
+		result, err := importproto.Import(content)
+		if err != nil {
+			log.Fatalf("UFO RPC: failed to import proto file: %s", err)
+		}
+		writeImportResult(result, args.Proto.importOutputArgs)
+		return
+	}
+
+	log.Fatalf("UFO RPC: no import format specified, e.g. urpc import openapi spec.yaml")
+}
+
+func readImportInput(path string) []byte {
+	if path == stdinPattern {
+		content, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			log.Fatalf("UFO RPC: failed to read stdin: %s", err)
+		}
+		return content
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		log.Fatalf("UFO RPC: failed to read file: %s", err)
+	}
+	return content
+}
+
+// writeImportResult reports the warnings of the import and writes the
+// formatted schema to the output file or stdout.
+func writeImportResult(result importer.Result, args importOutputArgs) {
+	if args.Output != "" && !args.Force {
+		if _, err := os.Stat(args.Output); err == nil {
+			log.Fatalf("UFO RPC: %s already exists, use --force to overwrite it", args.Output)
+		} else if !errors.Is(err, os.ErrNotExist) {
+			log.Fatalf("UFO RPC: failed to check output file: %s", err)
+		}
+	}
+
+	for _, warning := range result.Warnings {
+		log.Printf("UFO RPC: warning: %s", warning)
+	}
+
+	formatted := result.Format()
+	if args.Output == "" {
+		os.Stdout.WriteString(formatted)
+		return
+	}
+
+	if err := os.WriteFile(args.Output, []byte(formatted), 0644); err != nil {
+		log.Fatalf("UFO RPC: failed to write output file: %s", err)
+	}
+	log.Printf("UFO RPC: schema written to %s with %d warnings", args.Output, len(result.Warnings))
+}
@@ -8,6 +8,8 @@ import (
 	"path/filepath"
 	"strings"
 	"time"
+
+	"github.com/uforg/uforpc/urpc/internal/scaffold"
 )
 
 //go:embed cmd_init_schema.urpc
@@ -17,10 +19,20 @@ var initSchema []byte
 var initConfig []byte
 
 type cmdInitArgs struct {
-	Path string `arg:"positional" help:"The directory path where URPC schema and config files will be created. Defaults to the current directory."`
+	Path          string   `arg:"positional" help:"The directory path where URPC schema and config files will be created. Defaults to the current directory."`
+	Template      string   `arg:"-t,--template" help:"Create a project from a built-in template, a template of the user templates directory or a template directory path"`
+	ListTemplates bool     `arg:"--list-templates" help:"List the available project templates"`
+	Name          string   `arg:"--name" help:"The project name used by the template (default: the directory name)"`
+	Module        string   `arg:"--module" help:"The Go module path used by the template (default: example.com/<name>)"`
+	Vars          []string `arg:"--var,separate" help:"Set a template variable as KEY=VALUE, can be repeated"`
 }
 
 func cmdInit(args *cmdInitArgs) {
+	if args.ListTemplates {
+		listInitTemplates()
+		return
+	}
+
 	if args.Path == "" {
 		args.Path = "."
 	}
@@ -30,6 +42,11 @@ func cmdInit(args *cmdInitArgs) {
 		log.Fatalf("UFO RPC: path must be a directory, not a file: %s", args.Path)
 	}
 
+	if args.Template != "" {
+		initFromTemplate(args)
+		return
+	}
+
 	// Create directory if it doesn't exist
 	if err := os.MkdirAll(args.Path, 0755); err != nil {
 		log.Fatalf("UFO RPC: failed to create directory: %s", err)
@@ -51,6 +68,101 @@ func cmdInit(args *cmdInitArgs) {
 	fmt.Printf("UFO RPC: files initialized:\n- %s\n- %s\n", schemaPath, configPath)
 }
 
+// initFromTemplate renders the template selected with --template in the
+// project directory.
+func initFromTemplate(args *cmdInitArgs) {
+	tmpl, err := resolveInitTemplate(args.Template)
+	if err != nil {
+		log.Fatalf("UFO RPC: %s", err)
+	}
+
+	absPath, err := filepath.Abs(args.Path)
+	if err != nil {
+		log.Fatalf("UFO RPC: failed to normalize project path: %s", err)
+	}
+	if args.Name == "" {
+		args.Name = filepath.Base(absPath)
+	}
+
+	vars := scaffold.DefaultVars(args.Name)
+	if args.Module != "" {
+		vars["ModulePath"] = args.Module
+	}
+	for _, v := range args.Vars {
+		key, value, ok := strings.Cut(v, "=")
+		if !ok || key == "" {
+			log.Fatalf("UFO RPC: invalid --var %q, expected KEY=VALUE", v)
+		}
+		vars[key] = value
+	}
+
+	files, err := tmpl.Render(vars)
+	if err != nil {
+		log.Fatalf("UFO RPC: %s", err)
+	}
+
+	written, err := scaffold.WriteFiles(args.Path, files)
+	if err != nil {
+		log.Fatalf("UFO RPC: %s", err)
+	}
+
+	fmt.Printf("UFO RPC: project initialized from the %s template:\n", tmpl.Name)
+	for _, filePath := range written {
+		fmt.Printf("- %s\n", filePath)
+	}
+}
+
+// userTemplatesDir returns the directory where the users can add their own
+// templates, e.g. ~/.config/urpc/templates on Linux.
+func userTemplatesDir() string {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(configDir, "urpc", "templates")
+}
+
+// resolveInitTemplate returns the template for the --template value. Paths
+// are used as template directories, names are looked up first in the user
+// templates directory and then in the built-in templates.
+func resolveInitTemplate(name string) (scaffold.Template, error) {
+	if strings.ContainsAny(name, `/\`) || strings.HasPrefix(name, ".") {
+		return scaffold.FromDir(name)
+	}
+
+	if dir := userTemplatesDir(); dir != "" {
+		if info, err := os.Stat(filepath.Join(dir, name)); err == nil && info.IsDir() {
+			return scaffold.FromDir(filepath.Join(dir, name))
+		}
+	}
+
+	if tmpl, ok := scaffold.Builtin(name); ok {
+		return tmpl, nil
+	}
+
+	return scaffold.Template{}, fmt.Errorf("unknown template %q, available templates: %s", name, strings.Join(scaffold.BuiltinNames(), ", "))
+}
+
+func listInitTemplates() {
+	for _, tmpl := range scaffold.Builtins() {
+		fmt.Printf("%-12s %s\n", tmpl.Name, tmpl.Description)
+	}
+
+	dir := userTemplatesDir()
+	if dir == "" {
+		return
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			fmt.Printf("%-12s %s\n", entry.Name(), "User template from "+filepath.Join(dir, entry.Name()))
+		}
+	}
+}
+
 // generateUniqueFilenames generates unique filenames for the schema and config files
 //
 // Returns:
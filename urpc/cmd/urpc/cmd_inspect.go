@@ -0,0 +1,181 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"github.com/uforg/uforpc/urpc/internal/urpc/analyzer"
+	"github.com/uforg/uforpc/urpc/internal/urpc/ast"
+	"github.com/uforg/uforpc/urpc/internal/urpc/docstore"
+	"github.com/uforg/uforpc/urpc/internal/urpc/inspect"
+	"github.com/uforg/uforpc/urpc/internal/util/filepathutil"
+)
+
+type cmdInspectArgs struct {
+	Graph  *cmdInspectGraphArgs  `arg:"subcommand:graph" help:"Print the dependency graph between types, procedures and streams"`
+	Usages *cmdInspectUsagesArgs `arg:"subcommand:usages" help:"List the declarations that depend directly or transitively on a type"`
+	Stats  *cmdInspectStatsArgs  `arg:"subcommand:stats" help:"Print counts, the maximum nesting depth and the largest payloads"`
+}
+
+// inspectSchemaArgs are the arguments shared by all the inspect subcommands.
+type inspectSchemaArgs struct {
+	ConfigPath string `arg:"--config" default:"./uforpc.toml" help:"The config file path"`
+	Profile    string `arg:"--profile" help:"The config profile to use, see [profile.<name>] in the config file"`
+	SchemaPath string `arg:"--schema" help:"The URPC schema to inspect, overrides the schema of the config file"`
+}
+
+type cmdInspectGraphArgs struct {
+	Format string `arg:"--format" default:"dot" help:"The output format: dot or mermaid"`
+	inspectSchemaArgs
+}
+
+type cmdInspectUsagesArgs struct {
+	Type   string `arg:"positional,required" help:"The name of the type"`
+	Format string `arg:"--format" default:"text" help:"The output format: text or json"`
+	inspectSchemaArgs
+}
+
+type cmdInspectStatsArgs struct {
+	Format string `arg:"--format" default:"text" help:"The output format: text or json"`
+	Top    int    `arg:"--top" default:"10" help:"The number of largest payloads to show"`
+	inspectSchemaArgs
+}
+
+func cmdInspect(args *cmdInspectArgs) {
+	if args.Graph != nil {
+		g := inspect.NewGraph(loadInspectSchema(args.Graph.inspectSchemaArgs))
+		switch args.Graph.Format {
+		case "dot":
+			fmt.Print(g.DOT())
+		case "mermaid":
+			fmt.Print(g.Mermaid())
+		default:
+			log.Fatalf("UFO RPC: unsupported graph format %q, use dot or mermaid", args.Graph.Format)
+		}
+		return
+	}
+
+	if args.Usages != nil {
+		requireInspectFormat(args.Usages.Format)
+		g := inspect.NewGraph(loadInspectSchema(args.Usages.inspectSchemaArgs))
+		usages, err := g.Usages(args.Usages.Type)
+		if err != nil {
+			log.Fatalf("UFO RPC: %s", err)
+		}
+		if args.Usages.Format == "json" {
+			printInspectJSON(usages)
+			return
+		}
+		printUsages(args.Usages.Type, usages)
+		return
+	}
+
+	if args.Stats != nil {
+		requireInspectFormat(args.Stats.Format)
+		if args.Stats.Top < 0 {
+			log.Fatalf("UFO RPC: --top must not be negative")
+		}
+		stats := inspect.ComputeStats(loadInspectSchema(args.Stats.inspectSchemaArgs))
+		if len(stats.Payloads) > args.Stats.Top {
+			stats.Payloads = stats.Payloads[:args.Stats.Top]
+		}
+		if args.Stats.Format == "json" {
+			printInspectJSON(stats)
+			return
+		}
+		printStats(stats)
+		return
+	}
+
+	log.Fatalf("UFO RPC: no inspect subcommand specified, e.g. urpc inspect stats")
+}
+
+// loadInspectSchema analyzes the schema passed with --schema or the one
+// referenced by the config file.
+func loadInspectSchema(args inspectSchemaArgs) *ast.Schema {
+	if args.SchemaPath == "" {
+		loaded, err := loadSchemaFromConfig(args.ConfigPath, args.Profile)
+		if err != nil {
+			log.Fatalf("UFO RPC: %s", err)
+		}
+		return loaded.AST
+	}
+
+	absSchemaPath, err := filepathutil.NormalizeFromWD(args.SchemaPath)
+	if err != nil {
+		log.Fatalf("UFO RPC: failed to normalize schema path: %s", err)
+	}
+
+	an, err := analyzer.NewAnalyzer(docstore.NewDocstore())
+	if err != nil {
+		log.Fatalf("UFO RPC: failed to create URPC analyzer: %s", err)
+	}
+
+	astSchema, diagnostics, err := an.Analyze(absSchemaPath)
+	if err != nil {
+		diagnosticErrs := make([]error, len(diagnostics))
+		for i, diagnostic := range diagnostics {
+			diagnosticErrs[i] = diagnostic
+		}
+		log.Fatalf("UFO RPC: invalid schema: %s", errors.Join(diagnosticErrs...))
+	}
+	return astSchema
+}
+
+func requireInspectFormat(format string) {
+	if format != "text" && format != "json" {
+		log.Fatalf("UFO RPC: unsupported format %q, use text or json", format)
+	}
+}
+
+func printInspectJSON(value any) {
+	jsonBytes, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		log.Fatalf("UFO RPC: failed to encode JSON: %s", err)
+	}
+	fmt.Println(string(jsonBytes))
+}
+
+func printUsages(typeName string, usages []inspect.Usage) {
+	if len(usages) == 0 {
+		fmt.Printf("type %s is not used by any declaration\n", typeName)
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KIND\tNAME\tDEPTH\tPATH")
+	for _, usage := range usages {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\n", usage.Node.Kind, usage.Node.Name, usage.Depth, usage.PathString(typeName))
+	}
+	w.Flush()
+}
+
+func printStats(stats inspect.Stats) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Types:\t%d\n", stats.Types)
+	fmt.Fprintf(w, "Procedures:\t%d\n", stats.Procs)
+	fmt.Fprintf(w, "Streams:\t%d\n", stats.Streams)
+	fmt.Fprintf(w, "Fields:\t%d (%d optional, %d arrays, %d inline objects)\n", stats.Fields, stats.OptionalFields, stats.ArrayFields, stats.InlineObjects)
+	fmt.Fprintf(w, "Deprecated:\t%d\n", stats.Deprecated)
+	if stats.MaxDepth.Name != "" {
+		fmt.Fprintf(w, "Max depth:\t%d (%s: %s)\n", stats.MaxDepth.Depth, stats.MaxDepth.Name, stats.MaxDepth.DeepestPath)
+	}
+	w.Flush()
+
+	if len(stats.Payloads) == 0 {
+		return
+	}
+
+	fmt.Println()
+	fmt.Println("Largest payloads:")
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  NAME\tFIELDS\tDEPTH")
+	for _, payload := range stats.Payloads {
+		fmt.Fprintf(w, "  %s\t%d\t%d\n", payload.Name, payload.Fields, payload.Depth)
+	}
+	w.Flush()
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+
+	"github.com/uforg/uforpc/urpc/internal/codegen"
+	"github.com/uforg/uforpc/urpc/internal/urpc/linter"
+	"github.com/uforg/uforpc/urpc/internal/urpc/report"
+	"github.com/uforg/uforpc/urpc/internal/util/filepathutil"
+)
+
+type cmdLintArgs struct {
+	ConfigPath string `arg:"positional" help:"The config file path (default: ./uforpc.toml)"`
+	Format     string `arg:"-f,--format" default:"human" help:"The output format: human, json, sarif or github"`
+	Profile    string `arg:"--profile" help:"The config profile to use, see [profile.<name>] in the config file"`
+	Strict     bool   `arg:"--strict" help:"Exit with a non-zero code also when warnings are found"`
+	ListRules  bool   `arg:"--list-rules" help:"List all available lint rules and their default levels"`
+}
+
+// cmdLint checks the schema of the config file like urpc check does, with
+// the lint rules of the same config file.
+func cmdLint(args *cmdLintArgs) {
+	if args.ListRules {
+		for _, rule := range linter.Rules() {
+			fmt.Printf("%-22s %-8s %s\n", rule.Name, rule.DefaultLevel, rule.Description)
+		}
+		return
+	}
+
+	if args.ConfigPath == "" {
+		args.ConfigPath = "./uforpc.toml"
+	}
+
+	format, err := report.ParseFormat(args.Format)
+	if err != nil {
+		log.Fatalf("UFO RPC: %s", err)
+	}
+
+	config, err := codegen.LoadConfig(args.ConfigPath, args.Profile)
+	if err != nil {
+		log.Fatalf("UFO RPC: invalid config file: %s", err)
+	}
+
+	absConfigPath, err := filepathutil.NormalizeFromWD(args.ConfigPath)
+	if err != nil {
+		log.Fatalf("UFO RPC: failed to normalize config path: %s", err)
+	}
+	absSchemaPath := filepath.Join(filepath.Dir(absConfigPath), config.Schema)
+
+	rep, err := checkSchemas([]string{absSchemaPath}, &config.Lint)
+	if err != nil {
+		log.Fatalf("UFO RPC: %s", err)
+	}
+
+	writeCheckReport(rep, format, args.Strict, func(errorsCount, warningsCount, infosCount int) {
+		log.Printf("UFO RPC: %d errors, %d warnings, %d infos", errorsCount, warningsCount, infosCount)
+	})
+}
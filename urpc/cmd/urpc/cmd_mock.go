@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/uforg/uforpc/urpc/internal/mock"
+)
+
+type cmdMockArgs struct {
+	ConfigPath   string  `arg:"--config" default:"./uforpc.toml" help:"The config file path"`
+	Profile      string  `arg:"--profile" help:"The config profile to use, see [profile.<name>] in the config file"`
+	Host         string  `arg:"--host" default:"localhost" help:"The host to listen on, use 0.0.0.0 to listen on all interfaces"`
+	Port         int     `arg:"-p,--port" default:"8080" help:"The port to listen on"`
+	FixturesPath string  `arg:"--fixtures" help:"TOML or JSON file overriding responses, latency, errors and stream rates"`
+	Seed         *uint64 `arg:"--seed" help:"Seed for reproducible outputs and injected errors, overrides the fixtures seed"`
+}
+
+func cmdMock(args *cmdMockArgs) {
+	loaded, err := loadSchemaFromConfig(args.ConfigPath, args.Profile)
+	if err != nil {
+		log.Fatalf("UFO RPC: %s", err)
+	}
+
+	fixtures := mock.Fixtures{}
+	if args.FixturesPath != "" {
+		fixtures, err = mock.LoadFixtures(args.FixturesPath)
+		if err != nil {
+			log.Fatalf("UFO RPC: %s", err)
+		}
+	}
+	if args.Seed != nil {
+		fixtures.Seed = args.Seed
+	}
+
+	mockServer, err := mock.NewServer(loaded.Schema, fixtures)
+	if err != nil {
+		log.Fatalf("UFO RPC: %s", err)
+	}
+
+	addr := net.JoinHostPort(args.Host, strconv.Itoa(args.Port))
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           logRequests(mockServer),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+
+	log.Printf(
+		"UFO RPC: mock server for %d procedures and %d streams listening on http://%s",
+		len(loaded.Schema.GetProcNodes()), len(loaded.Schema.GetStreamNodes()), addr,
+	)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Fatalf("UFO RPC: failed to run mock server: %s", err)
+	}
+	log.Printf("UFO RPC: mock server stopped")
+}
+
+// logRequests logs the operation, the duration and the status of every
+// request.
+func logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		startTime := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		log.Printf("UFO RPC: %s %s %d in %s", r.Method, r.URL.Path, rec.status, time.Since(startTime))
+	})
+}
+
+// statusRecorder is an http.ResponseWriter that records the status code,
+// it keeps the http.Flusher support needed by the streams.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+// WriteHeader implements the http.ResponseWriter interface.
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Flush implements the http.Flusher interface.
+func (r *statusRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/uforg/uforpc/urpc/internal/codegen"
+	"github.com/uforg/uforpc/urpc/internal/codegen/openapi"
+	"github.com/uforg/uforpc/urpc/internal/codegen/playground"
+)
+
+type cmdPlaygroundArgs struct {
+	ConfigPath    string        `arg:"--config" default:"./uforpc.toml" help:"The config file path"`
+	Profile       string        `arg:"--profile" help:"The config profile to use, see [profile.<name>] in the config file"`
+	Host          string        `arg:"--host" default:"localhost" help:"The host to listen on, use 0.0.0.0 to listen on all interfaces"`
+	Port          int           `arg:"-p,--port" default:"8090" help:"The port to listen on"`
+	Target        string        `arg:"--target" help:"The base URL the requests are proxied to, defaults to the playground default_base_url or the client base_url from the config file"`
+	WatchInterval time.Duration `arg:"--watch-interval" default:"500ms" help:"How often to check the schema for changes"`
+}
+
+func cmdPlayground(args *cmdPlaygroundArgs) {
+	if args.WatchInterval <= 0 {
+		log.Fatalf("UFO RPC: the watch interval must be greater than zero")
+	}
+
+	files, snapshot := watchedFilesSnapshot(args.ConfigPath, args.Profile)
+	loaded, err := loadSchemaFromConfig(args.ConfigPath, args.Profile)
+	if err != nil {
+		log.Fatalf("UFO RPC: %s", err)
+	}
+
+	target := args.Target
+	if target == "" {
+		target = playgroundTarget(loaded)
+	}
+
+	server, err := playground.NewServer(target)
+	if err != nil {
+		log.Fatalf("UFO RPC: %s", err)
+	}
+	if err := updatePlayground(server, loaded); err != nil {
+		log.Fatalf("UFO RPC: %s", err)
+	}
+
+	addr := net.JoinHostPort(args.Host, strconv.Itoa(args.Port))
+	httpServer := &http.Server{
+		Addr:              addr,
+		Handler:           server,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = httpServer.Shutdown(shutdownCtx)
+	}()
+
+	go watchPlayground(ctx, args, server, files, snapshot)
+
+	if server.Target() != "" {
+		log.Printf("UFO RPC: proxying requests to %s", server.Target())
+	} else {
+		log.Printf("UFO RPC: no base URL is configured, the playground will send the requests directly")
+	}
+	log.Printf("UFO RPC: playground listening on http://%s", addr)
+	if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Fatalf("UFO RPC: failed to run playground server: %s", err)
+	}
+	log.Printf("UFO RPC: playground server stopped")
+}
+
+// watchPlayground reloads the playground every time a watched file changes
+// until the context is canceled. Errors are printed and the previous
+// playground keeps being served.
+func watchPlayground(ctx context.Context, args *cmdPlaygroundArgs, server *playground.Server, files []string, snapshot map[string]string) {
+	ticker := time.NewTicker(args.WatchInterval)
+	defer ticker.Stop()
+
+	// Changes are debounced, the reload runs once the watched files stop
+	// changing for a whole interval
+	pending := false
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		current := takeSnapshot(files)
+		if !maps.Equal(current, snapshot) {
+			snapshot = current
+			pending = true
+			continue
+		}
+		if !pending {
+			continue
+		}
+		pending = false
+
+		files, snapshot = watchedFilesSnapshot(args.ConfigPath, args.Profile)
+		loaded, err := loadSchemaFromConfig(args.ConfigPath, args.Profile)
+		if err != nil {
+			log.Printf("UFO RPC: failed to reload playground: %s", err)
+			continue
+		}
+		if err := updatePlayground(server, loaded); err != nil {
+			log.Printf("UFO RPC: failed to reload playground: %s", err)
+			continue
+		}
+		log.Printf("UFO RPC: playground reloaded")
+	}
+}
+
+// watchedFilesSnapshot returns the files the playground depends on and
+// their snapshot.
+func watchedFilesSnapshot(configPath string, profile string) ([]string, map[string]string) {
+	files, err := codegen.WatchedFiles(configPath, profile)
+	if err != nil {
+		log.Printf("UFO RPC: failed to resolve watched files: %s", err)
+	}
+	return files, takeSnapshot(files)
+}
+
+// playgroundTarget returns the base URL the requests are proxied to from
+// the config file.
+func playgroundTarget(loaded loadedSchema) string {
+	if playgroundConfig := loaded.Config.FirstPlayground(); playgroundConfig != nil && playgroundConfig.DefaultBaseURL != "" {
+		return playgroundConfig.DefaultBaseURL
+	}
+	if loaded.Config.Client != nil {
+		return loaded.Config.Client.BaseURL
+	}
+	return ""
+}
+
+// updatePlayground generates the playground files in memory and serves
+// them.
+func updatePlayground(server *playground.Server, loaded loadedSchema) error {
+	config := playground.Config{}
+	if playgroundConfig := loaded.Config.FirstPlayground(); playgroundConfig != nil {
+		config = *playgroundConfig
+	}
+
+	files, err := playground.Files(loaded.AST, config)
+	if err != nil {
+		return fmt.Errorf("failed to generate playground: %w", err)
+	}
+
+	openAPIConfig := loaded.Config.OpenAPIMetadata()
+	if openAPIConfig.BaseURL == "" {
+		openAPIConfig.BaseURL = server.Target()
+	}
+	openAPISpec, err := openapi.Generate(loaded.Schema, openAPIConfig)
+	if err != nil {
+		return fmt.Errorf("failed to generate openapi.yaml code: %w", err)
+	}
+	files["openapi.yaml"] = []byte(openAPISpec)
+
+	server.Update(files, config.DefaultHeaders)
+	return nil
+}
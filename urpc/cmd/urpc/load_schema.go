@@ -0,0 +1,67 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/uforg/uforpc/urpc/internal/codegen"
+	"github.com/uforg/uforpc/urpc/internal/schema"
+	"github.com/uforg/uforpc/urpc/internal/transpile"
+	"github.com/uforg/uforpc/urpc/internal/urpc/analyzer"
+	"github.com/uforg/uforpc/urpc/internal/urpc/ast"
+	"github.com/uforg/uforpc/urpc/internal/urpc/docstore"
+	"github.com/uforg/uforpc/urpc/internal/util/filepathutil"
+)
+
+// loadedSchema is a schema loaded from the config file of a project.
+type loadedSchema struct {
+	Config        codegen.Config
+	AbsConfigPath string
+	AbsSchemaPath string
+	AST           *ast.Schema
+	Schema        schema.Schema
+}
+
+// loadSchemaFromConfig reads the config file with the given profile,
+// analyzes the schema it references and transpiles it to its JSON
+// representation.
+func loadSchemaFromConfig(configPath string, profile string) (loadedSchema, error) {
+	config, err := codegen.LoadConfig(configPath, profile)
+	if err != nil {
+		return loadedSchema{}, fmt.Errorf("invalid config file: %w", err)
+	}
+
+	absConfigPath, err := filepathutil.NormalizeFromWD(configPath)
+	if err != nil {
+		return loadedSchema{}, fmt.Errorf("failed to normalize config path: %w", err)
+	}
+	absSchemaPath := filepath.Join(filepath.Dir(absConfigPath), config.Schema)
+
+	an, err := analyzer.NewAnalyzer(docstore.NewDocstore())
+	if err != nil {
+		return loadedSchema{}, fmt.Errorf("failed to create URPC analyzer: %w", err)
+	}
+
+	astSchema, diagnostics, err := an.Analyze(absSchemaPath)
+	if err != nil {
+		diagnosticErrs := make([]error, len(diagnostics))
+		for i, diagnostic := range diagnostics {
+			diagnosticErrs[i] = diagnostic
+		}
+		return loadedSchema{}, fmt.Errorf("invalid schema: %w", errors.Join(diagnosticErrs...))
+	}
+
+	jsonSchema, err := transpile.ToJSON(*astSchema)
+	if err != nil {
+		return loadedSchema{}, fmt.Errorf("failed to transpile schema to its JSON representation: %w", err)
+	}
+
+	return loadedSchema{
+		Config:        config,
+		AbsConfigPath: absConfigPath,
+		AbsSchemaPath: absSchemaPath,
+		AST:           astSchema,
+		Schema:        jsonSchema,
+	}, nil
+}
@@ -10,12 +10,20 @@ import (
 )
 
 type allArgs struct {
-	Init      *cmdInitArgs      `arg:"subcommand:init" help:"Initialize a new URPC schema in the specified path"`
-	Fmt       *cmdFmtArgs       `arg:"subcommand:fmt" help:"Format the URPC schema in the specified path"`
-	Transpile *cmdTranspileArgs `arg:"subcommand:transpile" help:"Transpile a URPC schema to JSON and vice versa, the result will be printed to stdout"`
-	Generate  *cmdGenerateArgs  `arg:"subcommand:generate" help:"Generate code from the URPC schema"`
-	LSP       *cmdLSPArgs       `arg:"subcommand:lsp" help:"Start the UFO RPC Language Server"`
-	Version   *struct{}         `arg:"subcommand:version" help:"Show urpc version information"`
+	Init       *cmdInitArgs       `arg:"subcommand:init" help:"Initialize a new URPC schema in the specified path"`
+	Fmt        *cmdFmtArgs        `arg:"subcommand:fmt" help:"Format the URPC schema in the specified path"`
+	Transpile  *cmdTranspileArgs  `arg:"subcommand:transpile" help:"Transpile a URPC schema to JSON and vice versa, the result will be printed to stdout"`
+	Generate   *cmdGenerateArgs   `arg:"subcommand:generate" help:"Generate code from the URPC schema"`
+	Lint       *cmdLintArgs       `arg:"subcommand:lint" help:"Lint the URPC schema using the rules configured in uforpc.toml"`
+	Check      *cmdCheckArgs      `arg:"subcommand:check" help:"Check that the URPC schemas are valid and lint them without generating code"`
+	Mock       *cmdMockArgs       `arg:"subcommand:mock" help:"Start a mock server that implements the URPC schema with synthesized responses"`
+	Call       *cmdCallArgs       `arg:"subcommand:call" help:"Call a procedure of a running server, the input is validated against the URPC schema"`
+	Subscribe  *cmdSubscribeArgs  `arg:"subcommand:subscribe" help:"Subscribe to a stream of a running server and print every event"`
+	Playground *cmdPlaygroundArgs `arg:"subcommand:playground" help:"Serve the playground for the URPC schema with a same-origin proxy to the server"`
+	Import     *cmdImportArgs     `arg:"subcommand:import" help:"Convert an API definition in another format to a URPC schema"`
+	Inspect    *cmdInspectArgs    `arg:"subcommand:inspect" help:"Inspect the dependencies and the shape of the URPC schema"`
+	LSP        *cmdLSPArgs        `arg:"subcommand:lsp" help:"Start the UFO RPC Language Server"`
+	Version    *struct{}          `arg:"subcommand:version" help:"Show urpc version information"`
 }
 
 func printVersion() {
@@ -79,6 +87,46 @@ func main() {
 		return
 	}
 
+	if args.Lint != nil {
+		cmdLint(args.Lint)
+		return
+	}
+
+	if args.Check != nil {
+		cmdCheck(args.Check)
+		return
+	}
+
+	if args.Mock != nil {
+		cmdMock(args.Mock)
+		return
+	}
+
+	if args.Call != nil {
+		cmdCall(args.Call)
+		return
+	}
+
+	if args.Subscribe != nil {
+		cmdSubscribe(args.Subscribe)
+		return
+	}
+
+	if args.Playground != nil {
+		cmdPlayground(args.Playground)
+		return
+	}
+
+	if args.Import != nil {
+		cmdImport(args.Import)
+		return
+	}
+
+	if args.Inspect != nil {
+		cmdInspect(args.Inspect)
+		return
+	}
+
 	// If no subcommand was specified, show version by default
 	printVersion()
 }
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/uforg/uforpc/urpc/internal/util/filepathutil"
+)
+
+// collectSchemaFiles expands the patterns and removes the ignored files,
+// it is used by the commands that take schema file patterns.
+//
+// Returns the sorted list of unique files.
+func collectSchemaFiles(patterns []string, ignorePatterns []string) ([]string, error) {
+	files := []string{}
+	for _, pattern := range patterns {
+		matches, err := filepathutil.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+		if len(matches) == 0 {
+			log.Printf("UFO RPC: pattern %q did not match any file", pattern)
+		}
+
+		for _, match := range matches {
+			ignored, err := isIgnored(match, ignorePatterns)
+			if err != nil {
+				return nil, err
+			}
+			if !ignored {
+				files = append(files, filepath.Clean(match))
+			}
+		}
+	}
+
+	slices.Sort(files)
+	return slices.Compact(files), nil
+}
+
+// isIgnored returns true if the file matches any of the ignore patterns.
+//
+// Patterns without a slash match the name of the file or of any of its
+// parent directories (e.g. "generated"), other patterns match the path of
+// the file or of any of its parent directories (e.g. "rpc/**/old").
+func isIgnored(file string, ignorePatterns []string) (bool, error) {
+	path := filepath.ToSlash(filepath.Clean(file))
+	segments := strings.Split(path, "/")
+
+	for _, pattern := range ignorePatterns {
+		pattern = strings.TrimSuffix(filepath.ToSlash(pattern), "/")
+		pattern = strings.TrimPrefix(pattern, "./")
+
+		if !strings.Contains(pattern, "/") {
+			for _, segment := range segments {
+				matched, err := filepath.Match(pattern, segment)
+				if err != nil {
+					return false, fmt.Errorf("invalid ignore pattern %q: %w", pattern, err)
+				}
+				if matched {
+					return true, nil
+				}
+			}
+			continue
+		}
+
+		for i := range segments {
+			matched, err := filepathutil.Match(pattern, strings.Join(segments[:i+1], "/"))
+			if err != nil {
+				return false, fmt.Errorf("invalid ignore pattern %q: %w", pattern, err)
+			}
+			if matched {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
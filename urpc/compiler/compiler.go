@@ -0,0 +1,125 @@
+package compiler
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io/fs"
+	"path"
+
+	"github.com/uforg/uforpc/urpc/internal/schema"
+	"github.com/uforg/uforpc/urpc/internal/transpile"
+	"github.com/uforg/uforpc/urpc/internal/urpc/analyzer"
+	"github.com/uforg/uforpc/urpc/internal/urpc/ast"
+	"github.com/uforg/uforpc/urpc/internal/urpc/docstore"
+	"github.com/uforg/uforpc/urpc/internal/urpc/formatter"
+	"github.com/uforg/uforpc/urpc/internal/urpc/parser"
+	"github.com/uforg/uforpc/urpc/internal/version"
+)
+
+// Version is the version of urpc this package belongs to.
+const Version = version.Version
+
+// The abstract syntax tree of a schema.
+type (
+	AST      = ast.Schema
+	Position = ast.Position
+)
+
+// The JSON Schema intermediate representation of a schema.
+type (
+	Schema               = schema.Schema
+	Node                 = schema.Node
+	NodeDoc              = schema.NodeDoc
+	NodeType             = schema.NodeType
+	NodeProc             = schema.NodeProc
+	NodeStream           = schema.NodeStream
+	FieldDefinition      = schema.FieldDefinition
+	InlineTypeDefinition = schema.InlineTypeDefinition
+)
+
+// Diagnostic is a problem found while analyzing a schema, it implements the
+// error interface.
+type Diagnostic = analyzer.Diagnostic
+
+// Severity is how serious a Diagnostic is.
+type Severity = analyzer.Severity
+
+// Severities of the diagnostics.
+const (
+	SeverityError   = analyzer.SeverityError
+	SeverityWarning = analyzer.SeverityWarning
+	SeverityInfo    = analyzer.SeverityInfo
+)
+
+// Parse parses the source of a single schema file without analyzing it, the
+// filename is only used in the positions of the AST and the errors.
+func Parse(filename string, content string) (*AST, error) {
+	return parser.ParserInstance.ParseString(filename, content)
+}
+
+// Analyze parses and analyzes the schema at the given path of the disk.
+//
+// The diagnostics include the syntax, docstring and semantic problems of the
+// schema, and the error is the first of them. The AST is returned even when
+// there are diagnostics, it is only nil when the file cannot be read.
+func Analyze(path string) (*AST, []Diagnostic, error) {
+	a, err := analyzer.NewAnalyzer(docstore.NewDocstore())
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create analyzer: %w", err)
+	}
+	return a.Analyze(path)
+}
+
+// AnalyzeFS is like Analyze but reads the schema and the files it references
+// from fsys, the paths are slash separated and relative to its root.
+func AnalyzeFS(fsys fs.FS, path string) (*AST, []Diagnostic, error) {
+	a, err := analyzer.NewAnalyzer(fsFileProvider{fsys: fsys})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create analyzer: %w", err)
+	}
+	return a.Analyze(path)
+}
+
+// Transpile converts an analyzed AST to the intermediate representation.
+func Transpile(astSchema *AST) (Schema, error) {
+	return transpile.ToJSON(*astSchema)
+}
+
+// TranspileToURPC converts the intermediate representation back to an AST,
+// which can be formatted with FormatAST to get the source of the schema.
+func TranspileToURPC(sch Schema) (*AST, error) {
+	astSchema, err := transpile.ToURPC(sch)
+	if err != nil {
+		return nil, err
+	}
+	return &astSchema, nil
+}
+
+// Format returns the formatted source of a schema file, the filename is only
+// used in the errors.
+func Format(filename string, content string) (string, error) {
+	return formatter.Format(filename, content)
+}
+
+// FormatAST returns the formatted source of an AST.
+func FormatAST(astSchema *AST) string {
+	return formatter.FormatSchema(astSchema)
+}
+
+// fsFileProvider implements analyzer.FileProvider reading files from an
+// fs.FS, relative paths are resolved from the directory of the file that
+// references them.
+type fsFileProvider struct {
+	fsys fs.FS
+}
+
+func (p fsFileProvider) GetFileAndHash(relativeTo string, name string) (string, string, error) {
+	if relativeTo != "" && !path.IsAbs(name) {
+		name = path.Join(path.Dir(relativeTo), name)
+	}
+	content, err := fs.ReadFile(p.fsys, path.Clean(name))
+	if err != nil {
+		return "", "", err
+	}
+	return string(content), fmt.Sprintf("%x", sha256.Sum256(content)), nil
+}
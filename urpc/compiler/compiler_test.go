@@ -0,0 +1,107 @@
+package compiler
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testSchema = "version 1\n\n\"\"\" docs/user.md \"\"\"\ntype User {\n  id: string\n}\n\nproc GetUser {\n  input {\n    id: string\n  }\n  output {\n    user: User\n  }\n}\n"
+
+func TestAnalyze(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "docs"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "schema.urpc"), []byte(testSchema), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "docs", "user.md"), []byte("A user."), 0644))
+
+	astSchema, diagnostics, err := Analyze(filepath.Join(dir, "schema.urpc"))
+	require.NoError(t, err)
+	require.Empty(t, diagnostics)
+
+	sch, err := Transpile(astSchema)
+	require.NoError(t, err)
+	require.Equal(t, "A user.", *sch.GetTypeNodes()[0].Doc)
+
+	_, diagnostics, err = Analyze(filepath.Join(dir, "missing.urpc"))
+	require.Error(t, err)
+	require.Len(t, diagnostics, 1)
+	require.Equal(t, SeverityError, diagnostics[0].Severity)
+}
+
+func TestAnalyzeFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"api/schema.urpc":  {Data: []byte(testSchema)},
+		"api/docs/user.md": {Data: []byte("A user.")},
+	}
+
+	astSchema, diagnostics, err := AnalyzeFS(fsys, "api/schema.urpc")
+	require.NoError(t, err)
+	require.Empty(t, diagnostics)
+
+	sch, err := Transpile(astSchema)
+	require.NoError(t, err)
+	require.Equal(t, "A user.", *sch.GetTypeNodes()[0].Doc)
+
+	delete(fsys, "api/docs/user.md")
+	_, diagnostics, err = AnalyzeFS(fsys, "api/schema.urpc")
+	require.Error(t, err)
+	require.Len(t, diagnostics, 1)
+	require.Contains(t, diagnostics[0].Message, "docs/user.md")
+}
+
+func TestTranspileRoundTrip(t *testing.T) {
+	astSchema, err := Parse("schema.urpc", "version 1\ntype User{id:string}")
+	require.NoError(t, err)
+
+	sch, err := Transpile(astSchema)
+	require.NoError(t, err)
+
+	back, err := TranspileToURPC(sch)
+	require.NoError(t, err)
+	require.Equal(t, "version 1\n\ntype User {\n  id: string\n}\n", FormatAST(back))
+}
+
+func TestGenerate(t *testing.T) {
+	fsys := fstest.MapFS{
+		"schema.urpc":  {Data: []byte(testSchema)},
+		"docs/user.md": {Data: []byte("A user.")},
+	}
+	astSchema, _, err := AnalyzeFS(fsys, "schema.urpc")
+	require.NoError(t, err)
+
+	files, err := Generate(astSchema, GolangServer(GolangConfig{OutputFile: "gen/server.go", PackageName: "gen"}))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	require.Equal(t, "gen/server.go", files[0].Path)
+	require.Contains(t, string(files[0].Content), "package gen\n")
+	require.Contains(t, string(files[0].Content), "Generated by urpc v"+Version)
+
+	files, err = Generate(astSchema, DartClient(DartConfig{OutputDir: "dart", PackageName: "api"}))
+	require.NoError(t, err)
+	require.Greater(t, len(files), 1)
+	for _, file := range files {
+		require.True(t, strings.HasPrefix(file.Path, "dart/"), file.Path)
+	}
+
+	files, err = Generate(astSchema, AsyncAPI(AsyncAPIConfig{OutputFile: "asyncapi.yaml"}))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	require.Contains(t, string(files[0].Content), "asyncapi: 3.0.0\n")
+
+	files, err = Generate(astSchema, JSONSchema(JSONSchemaConfig{OutputDir: "schemas"}))
+	require.NoError(t, err)
+	require.Equal(t, "schemas/GetUserInput.json", files[0].Path)
+
+	_, err = Generate(astSchema, GolangClient(GolangConfig{OutputFile: "client.go"}))
+	require.EqualError(t, err, `failed to run golang-client code generator: invalid config: "package_name" is required`)
+
+	_, err = Generate(astSchema, Template(TemplateConfig{OutputFile: "out.txt"}, "{{ .Nope }}"))
+	require.ErrorContains(t, err, "failed to run template code generator: failed to render template: template: template:1:3:")
+
+	_, err = Generate(astSchema, Generator{})
+	require.EqualError(t, err, "the generator must be created with one of the generator functions")
+}
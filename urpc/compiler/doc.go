@@ -0,0 +1,47 @@
+// Package compiler is the Go API of the UFO RPC compiler, it exposes the
+// same pipeline used by the urpc command so other tools can embed it.
+//
+// A schema goes through the following steps:
+//
+//   - Parse turns the source of a single file into an AST.
+//   - Analyze and AnalyzeFS parse a schema and the files it references,
+//     resolve the external docstrings and check its semantics, reporting
+//     every problem as a Diagnostic.
+//   - Transpile converts an analyzed AST to the JSON Schema intermediate
+//     representation used by the code generators and plugins.
+//   - Format returns the canonical formatting of a schema.
+//   - Generate runs a code generator and returns the generated files
+//     without writing them to disk.
+//
+// A minimal program that generates a Go client:
+//
+//	astSchema, diagnostics, err := compiler.Analyze("./schema.urpc")
+//	if err != nil {
+//		for _, diagnostic := range diagnostics {
+//			fmt.Println(diagnostic)
+//		}
+//		return
+//	}
+//	files, err := compiler.Generate(astSchema, compiler.GolangClient(compiler.GolangConfig{
+//		OutputFile:  "client.go",
+//		PackageName: "client",
+//	}))
+//
+// # Compatibility
+//
+// This package follows semantic versioning together with the urpc releases,
+// the exported identifiers of a major version are never removed or changed
+// in a backwards incompatible way. Some precisions:
+//
+//   - The AST, the intermediate representation and the generator configs
+//     are aliases of the types used by urpc itself. New fields can be added
+//     to them in minor versions, so they should be created using keyed
+//     struct literals.
+//   - The intermediate representation is versioned by Schema.Version, which
+//     only changes when its format changes in a backwards incompatible way.
+//   - The generated code is not part of the API, it can change in any
+//     release. Generated files carry a header with the urpc version that
+//     produced them.
+//   - The packages under internal are implementation details without any
+//     compatibility guarantees and cannot be imported.
+package compiler
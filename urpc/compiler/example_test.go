@@ -0,0 +1,97 @@
+package compiler_test
+
+import (
+	"fmt"
+	"strings"
+	"testing/fstest"
+
+	"github.com/uforg/uforpc/urpc/compiler"
+)
+
+func ExampleParse() {
+	astSchema, err := compiler.Parse("schema.urpc", "version 1\n\ntype User {\n  id: string\n}\n")
+	if err != nil {
+		panic(err)
+	}
+
+	for _, typeDecl := range astSchema.GetTypes() {
+		fmt.Println(typeDecl.Name)
+	}
+	// Output: User
+}
+
+func ExampleAnalyzeFS() {
+	fsys := fstest.MapFS{
+		"api/schema.urpc": {Data: []byte("version 1\n\nproc GetUser {\n  input {\n    id: string\n  }\n  output {\n    user: User\n  }\n}\n")},
+	}
+
+	_, diagnostics, err := compiler.AnalyzeFS(fsys, "api/schema.urpc")
+	if err != nil {
+		for _, diagnostic := range diagnostics {
+			fmt.Printf("%s %s\n", diagnostic.Severity, diagnostic)
+		}
+	}
+	// Output: error api/schema.urpc:8:11: type "User" referenced at output of procedure "GetUser" is not declared
+}
+
+func ExampleTranspile() {
+	astSchema, err := compiler.Parse("schema.urpc", "version 1\n\n\"\"\" A user of the app \"\"\"\ntype User {\n  id: string\n  tags?: string[]\n}\n")
+	if err != nil {
+		panic(err)
+	}
+
+	sch, err := compiler.Transpile(astSchema)
+	if err != nil {
+		panic(err)
+	}
+
+	for _, typeNode := range sch.GetTypeNodes() {
+		fmt.Printf("%s: %s\n", typeNode.Name, strings.TrimSpace(*typeNode.Doc))
+		for _, field := range typeNode.Fields {
+			fmt.Printf("  %s %s array=%t optional=%t\n", field.Name, *field.TypeName, field.IsArray, field.Optional)
+		}
+	}
+	// Output:
+	// User: A user of the app
+	//   id string array=false optional=false
+	//   tags string array=true optional=true
+}
+
+func ExampleFormat() {
+	formatted, err := compiler.Format("schema.urpc", "version 1\ntype User{id:string}")
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Print(formatted)
+	// Output:
+	// version 1
+	//
+	// type User {
+	//   id: string
+	// }
+}
+
+func ExampleGenerate() {
+	astSchema, err := compiler.Parse("schema.urpc", "version 1\n\ntype User {\n  id: string\n}\n\ntype Post {\n  title: string\n}\n")
+	if err != nil {
+		panic(err)
+	}
+
+	generator := compiler.Template(compiler.TemplateConfig{
+		OutputFile: "sql/{{ snake .Node.Name }}.sql",
+		Each:       "type",
+	}, "CREATE TABLE {{ snake .Node.Name }}s ();\n")
+
+	files, err := compiler.Generate(astSchema, generator)
+	if err != nil {
+		panic(err)
+	}
+
+	for _, file := range files {
+		fmt.Printf("%s: %s", file.Path, file.Content)
+	}
+	// Output:
+	// sql/post.sql: CREATE TABLE posts ();
+	// sql/user.sql: CREATE TABLE users ();
+}
@@ -0,0 +1,134 @@
+package compiler
+
+import (
+	"errors"
+
+	"github.com/uforg/uforpc/urpc/internal/codegen"
+	"github.com/uforg/uforpc/urpc/internal/codegen/asyncapi"
+	"github.com/uforg/uforpc/urpc/internal/codegen/dart"
+	"github.com/uforg/uforpc/urpc/internal/codegen/docs"
+	"github.com/uforg/uforpc/urpc/internal/codegen/golang"
+	"github.com/uforg/uforpc/urpc/internal/codegen/jsonschema"
+	"github.com/uforg/uforpc/urpc/internal/codegen/openapi"
+	"github.com/uforg/uforpc/urpc/internal/codegen/playground"
+	"github.com/uforg/uforpc/urpc/internal/codegen/template"
+	"github.com/uforg/uforpc/urpc/internal/codegen/typescript"
+)
+
+// The configs of the code generators, they are the same as the entries of
+// uforpc.toml. The output paths are used as the paths of the generated
+// files.
+type (
+	GolangConfig     = golang.Config
+	TypescriptConfig = typescript.Config
+	DartConfig       = dart.Config
+	OpenAPIConfig    = openapi.Config
+	PlaygroundConfig = playground.Config
+	AsyncAPIConfig   = asyncapi.Config
+	DocsConfig       = docs.Config
+	JSONSchemaConfig = jsonschema.Config
+	TemplateConfig   = template.Config
+)
+
+// Generator is a code generator together with its config, it is created
+// with one of the functions named after the generators.
+type Generator struct {
+	name   string
+	config any
+}
+
+// Name returns the name of the generator as used in uforpc.toml.
+func (g Generator) Name() string {
+	return g.name
+}
+
+// GolangServer returns the generator of the Go server, the IncludeServer and
+// IncludeClient fields of the config are ignored.
+func GolangServer(config GolangConfig) Generator {
+	return Generator{name: "golang-server", config: config}
+}
+
+// GolangClient returns the generator of the Go client, the IncludeServer and
+// IncludeClient fields of the config are ignored.
+func GolangClient(config GolangConfig) Generator {
+	return Generator{name: "golang-client", config: config}
+}
+
+// TypescriptClient returns the generator of the TypeScript client, the
+// IncludeServer and IncludeClient fields of the config are ignored.
+func TypescriptClient(config TypescriptConfig) Generator {
+	return Generator{name: "typescript-client", config: config}
+}
+
+// DartClient returns the generator of the Dart client package.
+func DartClient(config DartConfig) Generator {
+	return Generator{name: "dart-client", config: config}
+}
+
+// OpenAPI returns the generator of the OpenAPI specification.
+func OpenAPI(config OpenAPIConfig) Generator {
+	return Generator{name: "openapi", config: config}
+}
+
+// Playground returns the generator of the web playground.
+func Playground(config PlaygroundConfig) Generator {
+	return Generator{name: "playground", config: config}
+}
+
+// AsyncAPI returns the generator of the AsyncAPI 3.0 document of the
+// streams.
+func AsyncAPI(config AsyncAPIConfig) Generator {
+	return Generator{name: "asyncapi", config: config}
+}
+
+// Docs returns the generator of the documentation site.
+func Docs(config DocsConfig) Generator {
+	return Generator{name: "docs", config: config}
+}
+
+// JSONSchema returns the generator of the JSON Schema (draft 2020-12)
+// documents of the types and of the inputs and outputs of the procedures and
+// streams.
+func JSONSchema(config JSONSchemaConfig) Generator {
+	return Generator{name: "jsonschema", config: config}
+}
+
+// Template returns the generator that renders a text/template. The text is
+// given instead of read from the Template path of the config, which is only
+// used to name the template in the errors.
+func Template(config TemplateConfig, text string) Generator {
+	return Generator{name: "template", config: codegen.TemplateSource{Config: config, Text: text}}
+}
+
+// File is a generated file.
+type File struct {
+	// Path is the slash separated path of the file, relative to the
+	// directory the output paths of the config are relative to.
+	Path    string
+	Content []byte
+}
+
+// Generate validates the config of the generator and runs it for an analyzed
+// AST, returning the generated files sorted by path. Nothing is written to
+// disk.
+func Generate(astSchema *AST, generator Generator) ([]File, error) {
+	if generator.name == "" {
+		return nil, errors.New("the generator must be created with one of the generator functions")
+	}
+
+	jsonSchema, err := Transpile(astSchema)
+	if err != nil {
+		return nil, err
+	}
+
+	generated, err := codegen.GenerateInMemory(generator.name, generator.config, astSchema, jsonSchema)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]File, len(generated))
+	for i, file := range generated {
+		files[i] = File{Path: file.Name, Content: file.Content}
+	}
+	return files, nil
+}
@@ -12,6 +12,7 @@ require (
 	github.com/alexflint/go-arg v1.5.1
 	github.com/goccy/go-yaml v1.18.0
 	github.com/orsinium-labs/enum v1.4.0
+	github.com/pmezard/go-difflib v1.0.0
 	github.com/santhosh-tekuri/jsonschema/v6 v6.0.1
 	github.com/stretchr/testify v1.10.0
 	github.com/uforg/ufogenkit v0.1.0
@@ -21,7 +22,6 @@ require (
 require (
 	github.com/alexflint/go-scalar v1.2.0 // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
-	github.com/pmezard/go-difflib v1.0.0 // indirect
 	golang.org/x/mod v0.24.0 // indirect
 	golang.org/x/sync v0.13.0 // indirect
 	golang.org/x/text v0.14.0 // indirect
@@ -0,0 +1,195 @@
+// Package client calls the procedures and subscribes to the streams of a
+// UFO RPC server without generated code, it is used by the urpc call and
+// urpc subscribe commands.
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// maxErrorBodySize is the maximum number of bytes of an unexpected
+// response body included in errors.
+const maxErrorBodySize = 1024
+
+// Response is the UFO RPC response envelope of a procedure or of a stream
+// event.
+type Response struct {
+	Ok bool `json:"ok"`
+	// Raw is the envelope as received, used to print it without losing
+	// any field or number precision.
+	Raw json.RawMessage `json:"-"`
+}
+
+// Client sends requests to an endpoint.
+type Client struct {
+	HTTPClient *http.Client
+	Endpoint   Endpoint
+}
+
+// New creates a client for the endpoint using http.DefaultClient.
+func New(endpoint Endpoint) *Client {
+	return &Client{HTTPClient: http.DefaultClient, Endpoint: endpoint}
+}
+
+// Call calls a procedure with the JSON input and returns its response.
+//
+// A response with ok set to false is not an error, errors are only returned
+// when the request fails or the response is not a UFO RPC envelope.
+func (c *Client) Call(ctx context.Context, procName string, input []byte) (Response, error) {
+	res, err := c.post(ctx, procName, input, "application/json")
+	if err != nil {
+		return Response{}, err
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return Response{}, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	return parseResponse(res, body)
+}
+
+// Subscribe subscribes to a stream with the JSON input and calls onEvent
+// for every event until the server closes the stream, the context is
+// canceled or onEvent returns an error.
+//
+// Returns nil when the stream is closed by the server or the context is
+// canceled.
+func (c *Client) Subscribe(ctx context.Context, streamName string, input []byte, onEvent func(Response) error) error {
+	res, err := c.post(ctx, streamName, input, "text/event-stream")
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return err
+	}
+	defer res.Body.Close()
+
+	if !strings.HasPrefix(res.Header.Get("Content-Type"), "text/event-stream") {
+		// Servers answer some errors (e.g. invalid operation name) with a
+		// regular JSON response
+		body, err := io.ReadAll(res.Body)
+		if err != nil {
+			return fmt.Errorf("failed to read response: %w", err)
+		}
+		response, err := parseResponse(res, body)
+		if err != nil {
+			return err
+		}
+		return onEvent(response)
+	}
+
+	err = readEvents(res.Body, func(data []byte) error {
+		var response Response
+		if err := json.Unmarshal(data, &response); err != nil {
+			return fmt.Errorf("invalid event %q: %w", truncate(data), err)
+		}
+		response.Raw = data
+		return onEvent(response)
+	})
+	if err != nil && ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
+
+// post sends the input to the operation URL.
+func (c *Client) post(ctx context.Context, operationName string, input []byte, accept string) (*http.Response, error) {
+	if c.Endpoint.BaseURL == "" {
+		return nil, fmt.Errorf("the base URL is empty")
+	}
+	url := strings.TrimRight(c.Endpoint.BaseURL, "/") + "/" + operationName
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(input))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", accept)
+	for _, header := range c.Endpoint.Headers {
+		req.Header.Set(header.Key, header.Value)
+	}
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	return res, nil
+}
+
+// parseResponse parses the body of a JSON response.
+func parseResponse(res *http.Response, body []byte) (Response, error) {
+	var response Response
+	if err := json.Unmarshal(body, &response); err != nil {
+		return Response{}, fmt.Errorf("unexpected response with status %s: %s", res.Status, truncate(body))
+	}
+	response.Raw = body
+	return response, nil
+}
+
+// readEvents reads Server-Sent Events and calls onData with the data of
+// each event, multiple data lines are joined with a newline. Comments and
+// other fields are ignored.
+func readEvents(r io.Reader, onData func([]byte) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	var data bytes.Buffer
+	hasData := false
+	dispatch := func() error {
+		if !hasData {
+			return nil
+		}
+		event := bytes.Clone(data.Bytes())
+		data.Reset()
+		hasData = false
+		return onData(event)
+	}
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			if err := dispatch(); err != nil {
+				return err
+			}
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		if field != "data" {
+			continue
+		}
+		if hasData {
+			data.WriteByte('\n')
+		}
+		data.WriteString(strings.TrimPrefix(value, " "))
+		hasData = true
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read events: %w", err)
+	}
+
+	// The last event is dispatched even without the trailing blank line
+	return dispatch()
+}
+
+// truncate returns the body as a string limited to maxErrorBodySize bytes.
+func truncate(body []byte) string {
+	if len(body) > maxErrorBodySize {
+		return string(body[:maxErrorBodySize]) + "..."
+	}
+	return string(body)
+}
@@ -0,0 +1,135 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCall(t *testing.T) {
+	var gotPath, gotBody, gotAuth, gotContentType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		gotPath, gotBody = r.URL.Path, string(body)
+		gotAuth, gotContentType = r.Header.Get("Authorization"), r.Header.Get("Content-Type")
+
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasSuffix(r.URL.Path, "/Fail") {
+			fmt.Fprint(w, `{"ok":false,"error":{"message":"boom"}}`)
+			return
+		}
+		if strings.HasSuffix(r.URL.Path, "/Broken") {
+			w.WriteHeader(http.StatusBadGateway)
+			fmt.Fprint(w, `bad gateway`)
+			return
+		}
+		fmt.Fprint(w, `{"ok":true,"output":{"id":12345678901234567890}}`)
+	}))
+	defer server.Close()
+
+	c := New(Endpoint{
+		BaseURL: server.URL + "/api/urpc/",
+		Headers: []Header{{Key: "Authorization", Value: "Bearer token"}},
+	})
+
+	t.Run("Ok response", func(t *testing.T) {
+		res, err := c.Call(context.Background(), "GetUser", []byte(`{"id":"1"}`))
+		require.NoError(t, err)
+		require.True(t, res.Ok)
+		require.Equal(t, `{"ok":true,"output":{"id":12345678901234567890}}`, string(res.Raw))
+
+		require.Equal(t, "/api/urpc/GetUser", gotPath)
+		require.Equal(t, `{"id":"1"}`, gotBody)
+		require.Equal(t, "Bearer token", gotAuth)
+		require.Equal(t, "application/json", gotContentType)
+	})
+
+	t.Run("Failed response is not an error", func(t *testing.T) {
+		res, err := c.Call(context.Background(), "Fail", []byte(`{}`))
+		require.NoError(t, err)
+		require.False(t, res.Ok)
+		require.Equal(t, `{"ok":false,"error":{"message":"boom"}}`, string(res.Raw))
+	})
+
+	t.Run("Unexpected response", func(t *testing.T) {
+		_, err := c.Call(context.Background(), "Broken", []byte(`{}`))
+		require.EqualError(t, err, "unexpected response with status 502 Bad Gateway: bad gateway")
+	})
+
+	t.Run("Empty base URL", func(t *testing.T) {
+		_, err := New(Endpoint{}).Call(context.Background(), "GetUser", []byte(`{}`))
+		require.EqualError(t, err, "the base URL is empty")
+	})
+}
+
+func TestSubscribe(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/Missing") {
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"ok":false,"error":{"message":"Invalid operation name"}}`)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, ": comment\n\n")
+		fmt.Fprint(w, "data: {\"ok\":true,\"output\":{\"n\":1}}\n\n")
+		fmt.Fprint(w, "event: message\ndata: {\"ok\":true,\ndata: \"output\":{\"n\":2}}\n\n")
+		fmt.Fprint(w, "data: {\"ok\":false,\"error\":{\"message\":\"boom\"}}")
+	}))
+	defer server.Close()
+
+	c := New(Endpoint{BaseURL: server.URL})
+
+	t.Run("Reads all the events", func(t *testing.T) {
+		events := []string{}
+		err := c.Subscribe(context.Background(), "Ticks", []byte(`{}`), func(res Response) error {
+			events = append(events, fmt.Sprintf("%t %s", res.Ok, res.Raw))
+			return nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{
+			`true {"ok":true,"output":{"n":1}}`,
+			"true {\"ok\":true,\n\"output\":{\"n\":2}}",
+			`false {"ok":false,"error":{"message":"boom"}}`,
+		}, events)
+	})
+
+	t.Run("Stops when the callback fails", func(t *testing.T) {
+		count := 0
+		err := c.Subscribe(context.Background(), "Ticks", []byte(`{}`), func(res Response) error {
+			count++
+			return fmt.Errorf("stop")
+		})
+		require.EqualError(t, err, "stop")
+		require.Equal(t, 1, count)
+	})
+
+	t.Run("JSON responses are a single event", func(t *testing.T) {
+		events := []Response{}
+		err := c.Subscribe(context.Background(), "Missing", []byte(`{}`), func(res Response) error {
+			events = append(events, res)
+			return nil
+		})
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		require.False(t, events[0].Ok)
+	})
+}
+
+func TestReadEvents(t *testing.T) {
+	input := "id: 1\ndata:no-space\n\n\n\ndata: a\ndata: b\n\n"
+
+	events := []string{}
+	err := readEvents(strings.NewReader(input), func(data []byte) error {
+		events = append(events, string(data))
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"no-space", "a\nb"}, events)
+}
@@ -0,0 +1,153 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// Header is an HTTP header sent with every request.
+type Header struct {
+	Key   string `toml:"key"`
+	Value string `toml:"value"`
+}
+
+// Config is the configuration for the command line client.
+//
+// It is loaded from the [client] table of the uforpc.toml file:
+//
+//	[client]
+//	base_url = "http://localhost:8080/api/v1/urpc"
+//	headers = [{ key = "Authorization", value = "Bearer dev-token" }]
+//
+//	[profile.staging.client]
+//	base_url = "https://staging.example.com/api/v1/urpc"
+//	headers = [{ key = "Authorization", value = "Bearer staging-token" }]
+//
+// The [client.profiles.<name>] tables are deprecated in favor of the
+// [profile.<name>.client] tables of the config profiles.
+type Config struct {
+	// BaseURL is the URL the operation names are appended to.
+	BaseURL string `toml:"base_url"`
+	// Headers are sent with every request.
+	Headers []Header `toml:"headers"`
+	// Profiles override the base URL and the headers when selected.
+	//
+	// Deprecated: use the [profile.<name>.client] tables instead.
+	Profiles map[string]Profile `toml:"profiles"`
+}
+
+// Profile is a named set of overrides of the client config.
+type Profile struct {
+	// BaseURL replaces the base URL of the client config when set.
+	BaseURL string `toml:"base_url"`
+	// Headers are added to the headers of the client config, replacing
+	// the ones with the same key.
+	Headers []Header `toml:"headers"`
+}
+
+// Endpoint is where and with which headers the requests are sent.
+type Endpoint struct {
+	BaseURL string
+	Headers []Header
+}
+
+// Validate checks that all the headers have a key.
+func (c Config) Validate() error {
+	for i, header := range c.Headers {
+		if header.Key == "" {
+			return fmt.Errorf(`headers[%d]: "key" is required`, i)
+		}
+	}
+
+	for _, name := range c.ProfileNames() {
+		for i, header := range c.Profiles[name].Headers {
+			if header.Key == "" {
+				return fmt.Errorf(`profiles.%s.headers[%d]: "key" is required`, name, i)
+			}
+		}
+	}
+
+	return nil
+}
+
+// ProfileNames returns the sorted names of the profiles.
+func (c Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// HasProfile returns true if the client profile is defined.
+func (c Config) HasProfile(name string) bool {
+	_, ok := c.Profiles[name]
+	return ok
+}
+
+// Resolve returns the endpoint after applying the client config and the
+// given profile, an empty profile name selects no profile, over the
+// defaults (e.g. the playground defaults).
+//
+// Headers are merged by their case insensitive key, the last one wins.
+func (c Config) Resolve(defaults Endpoint, profileName string) (Endpoint, error) {
+	endpoint := Endpoint{
+		BaseURL: defaults.BaseURL,
+		Headers: MergeHeaders(defaults.Headers, c.Headers),
+	}
+	if c.BaseURL != "" {
+		endpoint.BaseURL = c.BaseURL
+	}
+
+	if profileName == "" {
+		return endpoint, nil
+	}
+
+	profile, ok := c.Profiles[profileName]
+	if !ok {
+		available := "no client profiles are configured"
+		if names := c.ProfileNames(); len(names) > 0 {
+			available = "available client profiles: " + strings.Join(names, ", ")
+		}
+		return Endpoint{}, fmt.Errorf("client profile %q not found, %s", profileName, available)
+	}
+
+	if profile.BaseURL != "" {
+		endpoint.BaseURL = profile.BaseURL
+	}
+	endpoint.Headers = MergeHeaders(endpoint.Headers, profile.Headers)
+
+	return endpoint, nil
+}
+
+// MergeHeaders returns the headers of base with the overrides applied,
+// headers with the same case insensitive key are replaced in place and new
+// ones are appended.
+func MergeHeaders(base []Header, overrides []Header) []Header {
+	merged := slices.Clone(base)
+	for _, override := range overrides {
+		key := http.CanonicalHeaderKey(override.Key)
+		index := slices.IndexFunc(merged, func(h Header) bool {
+			return http.CanonicalHeaderKey(h.Key) == key
+		})
+		if index >= 0 {
+			merged[index] = override
+		} else {
+			merged = append(merged, override)
+		}
+	}
+	return merged
+}
+
+// ParseHeader parses a header in the "Key: Value" format used by curl.
+func ParseHeader(raw string) (Header, error) {
+	key, value, found := strings.Cut(raw, ":")
+	key = strings.TrimSpace(key)
+	if !found || key == "" {
+		return Header{}, fmt.Errorf("invalid header %q, the format must be \"Key: Value\"", raw)
+	}
+	return Header{Key: key, Value: strings.TrimSpace(value)}, nil
+}
@@ -0,0 +1,98 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigResolve(t *testing.T) {
+	config := Config{
+		BaseURL: "http://localhost:8080/urpc",
+		Headers: []Header{{Key: "authorization", Value: "Bearer dev"}},
+		Profiles: map[string]Profile{
+			"staging": {
+				BaseURL: "https://staging.example.com/urpc",
+				Headers: []Header{{Key: "Authorization", Value: "Bearer staging"}, {Key: "X-Env", Value: "staging"}},
+			},
+			"debug": {
+				Headers: []Header{{Key: "X-Debug", Value: "1"}},
+			},
+		},
+	}
+	defaults := Endpoint{
+		BaseURL: "http://playground.local/urpc",
+		Headers: []Header{{Key: "Authorization", Value: "Bearer playground"}, {Key: "X-Client", Value: "playground"}},
+	}
+
+	t.Run("Without profile", func(t *testing.T) {
+		endpoint, err := config.Resolve(defaults, "")
+		require.NoError(t, err)
+		require.Equal(t, Endpoint{
+			BaseURL: "http://localhost:8080/urpc",
+			Headers: []Header{{Key: "authorization", Value: "Bearer dev"}, {Key: "X-Client", Value: "playground"}},
+		}, endpoint)
+	})
+
+	t.Run("With profile", func(t *testing.T) {
+		endpoint, err := config.Resolve(defaults, "staging")
+		require.NoError(t, err)
+		require.Equal(t, Endpoint{
+			BaseURL: "https://staging.example.com/urpc",
+			Headers: []Header{
+				{Key: "Authorization", Value: "Bearer staging"},
+				{Key: "X-Client", Value: "playground"},
+				{Key: "X-Env", Value: "staging"},
+			},
+		}, endpoint)
+	})
+
+	t.Run("Profile without base URL", func(t *testing.T) {
+		endpoint, err := config.Resolve(defaults, "debug")
+		require.NoError(t, err)
+		require.Equal(t, "http://localhost:8080/urpc", endpoint.BaseURL)
+		require.Len(t, endpoint.Headers, 3)
+	})
+
+	t.Run("Only defaults", func(t *testing.T) {
+		endpoint, err := Config{}.Resolve(defaults, "")
+		require.NoError(t, err)
+		require.Equal(t, defaults, endpoint)
+	})
+
+	t.Run("Unknown profile", func(t *testing.T) {
+		require.True(t, config.HasProfile("staging"))
+		require.False(t, config.HasProfile("prod"))
+
+		_, err := config.Resolve(defaults, "prod")
+		require.EqualError(t, err, `client profile "prod" not found, available client profiles: debug, staging`)
+
+		_, err = Config{}.Resolve(defaults, "prod")
+		require.EqualError(t, err, `client profile "prod" not found, no client profiles are configured`)
+	})
+}
+
+func TestConfigValidate(t *testing.T) {
+	require.NoError(t, Config{Headers: []Header{{Key: "A", Value: ""}}}.Validate())
+	require.EqualError(t, Config{Headers: []Header{{Value: "x"}}}.Validate(), `headers[0]: "key" is required`)
+	require.EqualError(t,
+		Config{Profiles: map[string]Profile{"a": {Headers: []Header{{Key: "A"}, {Value: "x"}}}}}.Validate(),
+		`profiles.a.headers[1]: "key" is required`,
+	)
+}
+
+func TestParseHeader(t *testing.T) {
+	header, err := ParseHeader("Authorization:  Bearer a:b ")
+	require.NoError(t, err)
+	require.Equal(t, Header{Key: "Authorization", Value: "Bearer a:b"}, header)
+
+	header, err = ParseHeader("X-Empty:")
+	require.NoError(t, err)
+	require.Equal(t, Header{Key: "X-Empty", Value: ""}, header)
+
+	_, err = ParseHeader("Authorization")
+	require.EqualError(t, err, `invalid header "Authorization", the format must be "Key: Value"`)
+
+	_, err = ParseHeader(": value")
+	require.Error(t, err)
+}
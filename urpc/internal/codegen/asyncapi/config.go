@@ -0,0 +1,40 @@
+package asyncapi
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Config is the configuration for the AsyncAPI generator.
+type Config struct {
+	// OutputFile is the file to output the generated document to.
+	OutputFile string `toml:"output_file"`
+	// Title is the title of the AsyncAPI document.
+	Title string `toml:"title"`
+	// Description is the description of the AsyncAPI document.
+	Description string `toml:"description"`
+	// Version is the version of the AsyncAPI document.
+	Version string `toml:"version"`
+	// BaseURL is the URL the streams are served from, it is used as the
+	// server of the AsyncAPI document.
+	BaseURL string `toml:"base_url"`
+}
+
+func (c Config) Validate() error {
+	if c.OutputFile == "" {
+		return fmt.Errorf(`"output_file" is required`)
+	}
+	if !strings.HasSuffix(c.OutputFile, ".json") &&
+		!strings.HasSuffix(c.OutputFile, ".yaml") &&
+		!strings.HasSuffix(c.OutputFile, ".yml") {
+		return fmt.Errorf(`"output_file" must end with ".json", ".yaml" or ".yml"`)
+	}
+	if c.BaseURL != "" {
+		u, err := url.Parse(c.BaseURL)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf(`"base_url" must be an absolute URL, e.g. "https://example.com/api/v1/urpc"`)
+		}
+	}
+	return nil
+}
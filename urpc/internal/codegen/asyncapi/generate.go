@@ -0,0 +1,232 @@
+package asyncapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/uforg/uforpc/urpc/internal/codegen/openapi"
+	"github.com/uforg/uforpc/urpc/internal/schema"
+	"github.com/uforg/uforpc/urpc/internal/util/strutil"
+)
+
+// httpBindingVersion is the version of the AsyncAPI HTTP bindings.
+const httpBindingVersion = "0.3.0"
+
+// Generate takes a schema and a config and generates the AsyncAPI 3.0
+// document of the streams.
+//
+// Every stream is a channel with a send operation: the client sends the
+// input as the body of a POST request and receives the events as the reply,
+// a text/event-stream response where every event is the output or the error
+// envelope. The component schemas of the types are the same as the OpenAPI
+// ones.
+func Generate(sch schema.Schema, config Config) (string, error) {
+	if config.Title == "" {
+		config.Title = "UFO RPC API"
+	}
+	if config.Version == "" {
+		config.Version = "1.0.0"
+	}
+
+	spec := Spec{
+		AsyncAPI: "3.0.0",
+		Info: Info{
+			Title:       config.Title,
+			Version:     config.Version,
+			Description: config.Description,
+		},
+		DefaultContentType: "application/json",
+		Channels:           map[string]any{},
+		Operations:         map[string]any{},
+		Components: Components{
+			Schemas:  openapi.GenerateTypeSchemas(sch),
+			Messages: map[string]any{},
+			SecuritySchemes: map[string]any{
+				"AuthToken": map[string]any{
+					"type":        "httpApiKey",
+					"in":          "header",
+					"name":        "Authorization",
+					"description": "The full value of the Authorization header, its format is determined by the server's implementation.",
+				},
+			},
+		},
+	}
+
+	if config.BaseURL != "" {
+		server, err := generateServer(config.BaseURL)
+		if err != nil {
+			return "", err
+		}
+		spec.Servers = map[string]any{"default": server}
+	}
+
+	for _, streamNode := range sch.GetStreamNodes() {
+		generateStream(&spec, streamNode)
+	}
+
+	code, err := encodeSpec(spec, config)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate spec file: %w", err)
+	}
+
+	return code, nil
+}
+
+func generateServer(baseURL string) (map[string]any, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base url: %w", err)
+	}
+
+	server := map[string]any{
+		"host":     u.Host,
+		"protocol": u.Scheme,
+	}
+	if pathname := strings.TrimSuffix(u.Path, "/"); pathname != "" {
+		server["pathname"] = pathname
+	}
+	return server, nil
+}
+
+func generateStream(spec *Spec, streamNode *schema.NodeStream) {
+	name := streamNode.Name
+	inputName := fmt.Sprintf("%sInput", name)
+	outputName := fmt.Sprintf("%sOutput", name)
+	errorName := fmt.Sprintf("%sError", name)
+
+	desc := ""
+	if streamNode.Doc != nil {
+		desc = strings.TrimSpace(strutil.NormalizeIndent(*streamNode.Doc))
+	}
+	if streamNode.Deprecated != nil {
+		desc += "\n\nDeprecated: "
+		if *streamNode.Deprecated == "" {
+			desc += "This stream is deprecated and should not be used in new code."
+		} else {
+			desc += *streamNode.Deprecated
+		}
+	}
+	desc = strings.TrimSpace(desc)
+
+	inputProperties, inputRequiredFields := openapi.GenerateProperties(streamNode.Input)
+	spec.Components.Messages[inputName] = map[string]any{
+		"name":        inputName,
+		"title":       "Input of the " + name + " stream",
+		"summary":     "Sent as the JSON body of the POST request that subscribes to the stream.",
+		"contentType": "application/json",
+		"payload":     objectSchema(inputProperties, inputRequiredFields),
+	}
+
+	outputProperties, outputRequiredFields := openapi.GenerateProperties(streamNode.Output)
+	spec.Components.Messages[outputName] = map[string]any{
+		"name":     outputName,
+		"title":    "Event of the " + name + " stream",
+		"summary":  "Sent as the data of a server sent event for every output of the stream.",
+		"payload":  objectSchema(map[string]any{"ok": map[string]any{"type": "boolean", "const": true}, "output": objectSchema(outputProperties, outputRequiredFields)}, []string{"ok", "output"}),
+		"bindings": eventStreamBindings(),
+	}
+	spec.Components.Messages[errorName] = map[string]any{
+		"name":     errorName,
+		"title":    "Error of the " + name + " stream",
+		"summary":  "Sent as the data of a server sent event when the stream fails, the stream ends after it.",
+		"payload":  objectSchema(map[string]any{"ok": map[string]any{"type": "boolean", "const": false}, "error": openapi.ErrorSchema()}, []string{"ok", "error"}),
+		"bindings": eventStreamBindings(),
+	}
+
+	channel := map[string]any{
+		"address": "/" + name,
+		"title":   name,
+		"messages": map[string]any{
+			inputName:  messageRef(inputName),
+			outputName: messageRef(outputName),
+			errorName:  messageRef(errorName),
+		},
+	}
+	if desc != "" {
+		channel["description"] = desc
+	}
+	spec.Channels[name] = channel
+
+	channelRef := map[string]any{"$ref": "#/channels/" + name}
+	spec.Operations[name] = map[string]any{
+		"action":   "send",
+		"channel":  channelRef,
+		"title":    "Subscribe to " + name,
+		"summary":  "Subscribes to the " + name + " stream with a POST request, the events are sent as server sent events (SSE).",
+		"security": []any{map[string]any{"$ref": "#/components/securitySchemes/AuthToken"}},
+		"messages": []any{channelMessageRef(name, inputName)},
+		"reply": map[string]any{
+			"channel":  channelRef,
+			"messages": []any{channelMessageRef(name, outputName), channelMessageRef(name, errorName)},
+		},
+		"bindings": map[string]any{
+			"http": map[string]any{
+				"method":         "POST",
+				"bindingVersion": httpBindingVersion,
+			},
+		},
+	}
+}
+
+func objectSchema(properties map[string]any, required []string) map[string]any {
+	object := map[string]any{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		object["required"] = required
+	}
+	return object
+}
+
+// eventStreamBindings returns the bindings of the messages sent as server
+// sent events in the response of the subscription.
+func eventStreamBindings() map[string]any {
+	return map[string]any{
+		"http": map[string]any{
+			"headers": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"Content-Type": map[string]any{
+						"type":  "string",
+						"const": "text/event-stream",
+					},
+				},
+			},
+			"bindingVersion": httpBindingVersion,
+		},
+	}
+}
+
+func messageRef(name string) map[string]any {
+	return map[string]any{"$ref": "#/components/messages/" + name}
+}
+
+func channelMessageRef(channel string, name string) map[string]any {
+	return map[string]any{"$ref": "#/channels/" + channel + "/messages/" + name}
+}
+
+func encodeSpec(spec Spec, config Config) (string, error) {
+	isYAML := strings.HasSuffix(config.OutputFile, ".yaml") || strings.HasSuffix(config.OutputFile, ".yml")
+	var buf bytes.Buffer
+
+	if isYAML {
+		enc := yaml.NewEncoder(&buf)
+		if err := enc.Encode(spec); err != nil {
+			return "", fmt.Errorf("failed to encode yaml spec: %w", err)
+		}
+		return buf.String(), nil
+	}
+
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(spec); err != nil {
+		return "", fmt.Errorf("failed to encode json spec: %w", err)
+	}
+	return buf.String(), nil
+}
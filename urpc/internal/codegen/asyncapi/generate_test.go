@@ -0,0 +1,163 @@
+package asyncapi
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/goccy/go-yaml"
+	"github.com/stretchr/testify/require"
+	"github.com/uforg/uforpc/urpc/internal/schema"
+)
+
+func testSchema(t *testing.T) schema.Schema {
+	t.Helper()
+
+	sch, err := schema.ParseSchema(`{
+		"version": 1,
+		"nodes": [
+			{
+				"kind": "type",
+				"name": "User",
+				"doc": "A user.",
+				"fields": [{"name": "id", "typeName": "string", "isArray": false, "optional": false}]
+			},
+			{
+				"kind": "proc",
+				"name": "GetUser",
+				"input": [],
+				"output": []
+			},
+			{
+				"kind": "stream",
+				"name": "WatchUsers",
+				"doc": "  Emits the users as they change.  ",
+				"input": [{"name": "teamId", "typeName": "string", "isArray": false, "optional": false}],
+				"output": [{"name": "user", "typeName": "User", "isArray": false, "optional": false}]
+			},
+			{
+				"kind": "stream",
+				"name": "WatchLegacy",
+				"deprecated": "",
+				"input": [],
+				"output": []
+			}
+		]
+	}`)
+	require.NoError(t, err)
+	return sch
+}
+
+// resolve returns the value of a local reference like "#/a/b", following
+// the references it points to.
+func resolve(doc map[string]any, ref string) (any, bool) {
+	var current any = doc
+	for _, key := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+		object, ok := current.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if current, ok = object[key]; !ok {
+			return nil, false
+		}
+	}
+	if object, ok := current.(map[string]any); ok && len(object) == 1 {
+		if next, ok := object["$ref"].(string); ok {
+			return resolve(doc, next)
+		}
+	}
+	return current, true
+}
+
+// collectRefs returns every "$ref" of the value.
+func collectRefs(value any) []string {
+	refs := []string{}
+	switch v := value.(type) {
+	case map[string]any:
+		for key, child := range v {
+			if ref, ok := child.(string); ok && key == "$ref" {
+				refs = append(refs, ref)
+				continue
+			}
+			refs = append(refs, collectRefs(child)...)
+		}
+	case []any:
+		for _, child := range v {
+			refs = append(refs, collectRefs(child)...)
+		}
+	}
+	return refs
+}
+
+func TestGenerate(t *testing.T) {
+	code, err := Generate(testSchema(t), Config{OutputFile: "asyncapi.json", BaseURL: "https://example.com/api/v1/urpc/"})
+	require.NoError(t, err)
+
+	doc := map[string]any{}
+	require.NoError(t, json.Unmarshal([]byte(code), &doc))
+	require.Equal(t, "3.0.0", doc["asyncapi"])
+	require.Equal(t, map[string]any{"title": "UFO RPC API", "version": "1.0.0"}, doc["info"])
+	require.Equal(t, map[string]any{"default": map[string]any{"host": "example.com", "protocol": "https", "pathname": "/api/v1/urpc"}}, doc["servers"])
+
+	// Only the streams are channels
+	channels := doc["channels"].(map[string]any)
+	require.Len(t, channels, 2)
+	channel := channels["WatchUsers"].(map[string]any)
+	require.Equal(t, "/WatchUsers", channel["address"])
+	require.Equal(t, "Emits the users as they change.", channel["description"])
+	require.Equal(t, "Deprecated: This stream is deprecated and should not be used in new code.", channels["WatchLegacy"].(map[string]any)["description"])
+
+	operation := doc["operations"].(map[string]any)["WatchUsers"].(map[string]any)
+	require.Equal(t, "send", operation["action"])
+	require.Equal(t, map[string]any{"method": "POST", "bindingVersion": httpBindingVersion}, operation["bindings"].(map[string]any)["http"])
+
+	input, ok := resolve(doc, operation["messages"].([]any)[0].(map[string]any)["$ref"].(string))
+	require.True(t, ok)
+	require.Equal(t, map[string]any{
+		"type":       "object",
+		"properties": map[string]any{"teamId": map[string]any{"type": "string"}},
+		"required":   []any{"teamId"},
+	}, input.(map[string]any)["payload"])
+
+	reply := operation["reply"].(map[string]any)["messages"].([]any)
+	require.Len(t, reply, 2)
+	output, ok := resolve(doc, reply[0].(map[string]any)["$ref"].(string))
+	require.True(t, ok)
+	outputPayload := output.(map[string]any)["payload"].(map[string]any)
+	require.Equal(t, []any{"ok", "output"}, outputPayload["required"])
+	require.Equal(t, map[string]any{"$ref": "#/components/schemas/User"}, outputPayload["properties"].(map[string]any)["output"].(map[string]any)["properties"].(map[string]any)["user"].(map[string]any)["allOf"].([]any)[0])
+	contentType := output.(map[string]any)["bindings"].(map[string]any)["http"].(map[string]any)["headers"].(map[string]any)["properties"].(map[string]any)["Content-Type"]
+	require.Equal(t, map[string]any{"type": "string", "const": "text/event-stream"}, contentType)
+
+	errorMessage, ok := resolve(doc, reply[1].(map[string]any)["$ref"].(string))
+	require.True(t, ok)
+	errorPayload := errorMessage.(map[string]any)["payload"].(map[string]any)
+	require.Equal(t, map[string]any{"type": "boolean", "const": false}, errorPayload["properties"].(map[string]any)["ok"])
+	require.Equal(t, []any{"message"}, errorPayload["properties"].(map[string]any)["error"].(map[string]any)["required"])
+
+	// Every reference points to an object of the document
+	refs := collectRefs(doc)
+	require.NotEmpty(t, refs)
+	for _, ref := range refs {
+		_, ok := resolve(doc, ref)
+		require.True(t, ok, "unresolved reference %s", ref)
+	}
+}
+
+func TestGenerateYAML(t *testing.T) {
+	code, err := Generate(testSchema(t), Config{OutputFile: "asyncapi.yaml", Title: "Events"})
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(code, "asyncapi: 3.0.0\n"), code)
+
+	doc := map[string]any{}
+	require.NoError(t, yaml.Unmarshal([]byte(code), &doc))
+	require.Equal(t, "Events", doc["info"].(map[string]any)["title"])
+	require.NotContains(t, doc, "servers")
+}
+
+func TestConfigValidate(t *testing.T) {
+	require.NoError(t, Config{OutputFile: "asyncapi.yaml", BaseURL: "http://localhost:8080/urpc"}.Validate())
+	require.EqualError(t, Config{}.Validate(), `"output_file" is required`)
+	require.EqualError(t, Config{OutputFile: "asyncapi.txt"}.Validate(), `"output_file" must end with ".json", ".yaml" or ".yml"`)
+	require.EqualError(t, Config{OutputFile: "asyncapi.yaml", BaseURL: "/urpc"}.Validate(), `"base_url" must be an absolute URL, e.g. "https://example.com/api/v1/urpc"`)
+}
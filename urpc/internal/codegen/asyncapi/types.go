@@ -0,0 +1,23 @@
+package asyncapi
+
+type Spec struct {
+	AsyncAPI           string         `json:"asyncapi"`
+	Info               Info           `json:"info"`
+	DefaultContentType string         `json:"defaultContentType"`
+	Servers            map[string]any `json:"servers,omitempty"`
+	Channels           map[string]any `json:"channels"`
+	Operations         map[string]any `json:"operations"`
+	Components         Components     `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitzero"`
+}
+
+type Components struct {
+	Schemas         map[string]any `json:"schemas,omitempty"`
+	Messages        map[string]any `json:"messages,omitempty"`
+	SecuritySchemes map[string]any `json:"securitySchemes,omitempty"`
+}
@@ -4,50 +4,170 @@ import (
 	"fmt"
 
 	"github.com/BurntSushi/toml"
+	"github.com/uforg/uforpc/urpc/internal/client"
+	"github.com/uforg/uforpc/urpc/internal/codegen/asyncapi"
 	"github.com/uforg/uforpc/urpc/internal/codegen/dart"
+	"github.com/uforg/uforpc/urpc/internal/codegen/docs"
 	"github.com/uforg/uforpc/urpc/internal/codegen/golang"
+	"github.com/uforg/uforpc/urpc/internal/codegen/jsonschema"
 	"github.com/uforg/uforpc/urpc/internal/codegen/openapi"
 	"github.com/uforg/uforpc/urpc/internal/codegen/playground"
+	"github.com/uforg/uforpc/urpc/internal/codegen/template"
 	"github.com/uforg/uforpc/urpc/internal/codegen/typescript"
+	"github.com/uforg/uforpc/urpc/internal/urpc/linter"
 )
 
 // Config is the configuration for the code generator.
+//
+// Every generator can be configured more than once using arrays of tables,
+// e.g. [[golang-client]], see Targets.
 type Config struct {
 	Version int    `toml:"version"`
 	Schema  string `toml:"schema"`
 
-	OpenAPI    openapi.Config     `toml:"openapi"`
-	Playground *playground.Config `toml:"playground"`
+	Lint linter.Config `toml:"lint"`
+
+	// Client is used by the urpc call and urpc subscribe commands
+	Client *client.Config `toml:"client"`
+
+	OpenAPI    Targets[openapi.Config]    `toml:"openapi"`
+	Playground Targets[playground.Config] `toml:"playground"`
+	AsyncAPI   Targets[asyncapi.Config]   `toml:"asyncapi"`
 
 	// New split generators
-	GolangServer     *golang.Config     `toml:"golang-server"`
-	GolangClient     *golang.Config     `toml:"golang-client"`
-	TypescriptClient *typescript.Config `toml:"typescript-client"`
-	DartClient       *dart.Config       `toml:"dart-client"`
+	GolangServer     Targets[golang.Config]     `toml:"golang-server"`
+	GolangClient     Targets[golang.Config]     `toml:"golang-client"`
+	TypescriptClient Targets[typescript.Config] `toml:"typescript-client"`
+	DartClient       Targets[dart.Config]       `toml:"dart-client"`
+
+	Docs Targets[docs.Config] `toml:"docs"`
+
+	JSONSchema Targets[jsonschema.Config] `toml:"jsonschema"`
+
+	// Template are the user defined text/template generators
+	Template Targets[template.Config] `toml:"template"`
+
+	// Plugin are the external generators
+	Plugin Targets[PluginConfig] `toml:"plugin"`
+
+	// files are the config files read by LoadConfig
+	files []string
+}
+
+// SourceFiles returns the absolute paths of the config files read by
+// LoadConfig, the loaded file first and then the files it extends.
+func (c *Config) SourceFiles() []string {
+	return c.files
+}
+
+// PluginConfig is the configuration of an external generator, see the
+// plugin package for the protocol.
+type PluginConfig struct {
+	// Command is the executable of the plugin, it is looked up in the PATH
+	// if it does not contain a path separator. Relative paths are relative
+	// to the config file.
+	Command string `toml:"command"`
+	// Args are the arguments passed to the command.
+	Args []string `toml:"args"`
+	// OutputDir is the directory where the files returned by the plugin are
+	// written.
+	OutputDir string `toml:"output_dir"`
+	// Options are passed to the plugin as they are.
+	Options map[string]any `toml:"options"`
+}
+
+// Validate returns an error if the command or the output directory of the
+// plugin are missing.
+func (c PluginConfig) Validate() error {
+	if c.Command == "" {
+		return fmt.Errorf(`"command" is required`)
+	}
+	if c.OutputDir == "" {
+		return fmt.Errorf(`"output_dir" is required`)
+	}
+	return nil
+}
+
+// configKeyError is a validation error of a key of the config, it is used
+// by LoadConfig to show where the values of the key come from.
+type configKeyError struct {
+	key string
+	err error
+}
+
+func (e *configKeyError) Error() string {
+	return e.err.Error()
+}
+
+func (e *configKeyError) Unwrap() error {
+	return e.err
 }
 
 func (c *Config) HasOpenAPI() bool {
-	return c.OpenAPI.OutputFile != ""
+	for _, cfg := range c.OpenAPI {
+		if cfg.OutputFile != "" {
+			return true
+		}
+	}
+	return false
 }
 
 func (c *Config) HasPlayground() bool {
-	return c.Playground != nil
+	return len(c.Playground) > 0
+}
+
+func (c *Config) HasAsyncAPI() bool {
+	return len(c.AsyncAPI) > 0
 }
 
 func (c *Config) HasGolangServer() bool {
-	return c.GolangServer != nil
+	return len(c.GolangServer) > 0
 }
 
 func (c *Config) HasGolangClient() bool {
-	return c.GolangClient != nil
+	return len(c.GolangClient) > 0
 }
 
 func (c *Config) HasTypescriptClient() bool {
-	return c.TypescriptClient != nil
+	return len(c.TypescriptClient) > 0
 }
 
 func (c *Config) HasDartClient() bool {
-	return c.DartClient != nil
+	return len(c.DartClient) > 0
+}
+
+func (c *Config) HasDocs() bool {
+	return len(c.Docs) > 0
+}
+
+func (c *Config) HasJSONSchema() bool {
+	return len(c.JSONSchema) > 0
+}
+
+func (c *Config) HasTemplate() bool {
+	return len(c.Template) > 0
+}
+
+func (c *Config) HasPlugin() bool {
+	return len(c.Plugin) > 0
+}
+
+// OpenAPIMetadata returns the first [openapi] entry, its metadata is used
+// by the other generators (e.g., playground).
+func (c *Config) OpenAPIMetadata() openapi.Config {
+	if len(c.OpenAPI) == 0 {
+		return openapi.Config{}
+	}
+	return c.OpenAPI[0]
+}
+
+// FirstPlayground returns the first [playground] entry, used by the urpc
+// playground, call and subscribe commands, or nil if there is none.
+func (c *Config) FirstPlayground() *playground.Config {
+	if len(c.Playground) == 0 {
+		return nil
+	}
+	return &c.Playground[0]
 }
 
 func (c *Config) Unmarshal(data []byte) error {
@@ -59,51 +179,72 @@ func (c *Config) Unmarshal(data []byte) error {
 
 func (c *Config) Validate() error {
 	if c.Version == 0 {
-		return fmt.Errorf(`"version" is required`)
+		return &configKeyError{key: "version", err: fmt.Errorf(`"version" is required`)}
 	}
 
 	if c.Version != 1 {
-		return fmt.Errorf("unsupported version: %d", c.Version)
+		return &configKeyError{key: "version", err: fmt.Errorf("unsupported version: %d", c.Version)}
 	}
 
 	if c.Schema == "" {
-		return fmt.Errorf(`"schema" is required`)
+		return &configKeyError{key: "schema", err: fmt.Errorf(`"schema" is required`)}
 	}
 
-	if err := c.OpenAPI.Validate(); err != nil {
-		return fmt.Errorf("openapi config is invalid: %w", err)
+	if err := c.Lint.Validate(); err != nil {
+		return &configKeyError{key: "lint", err: fmt.Errorf("lint config is invalid: %w", err)}
 	}
 
-	if c.Playground != nil {
-		if err := c.Playground.Validate(); err != nil {
-			return fmt.Errorf("playground config is invalid: %w", err)
+	if c.Client != nil {
+		if err := c.Client.Validate(); err != nil {
+			return &configKeyError{key: "client", err: fmt.Errorf("client config is invalid: %w", err)}
 		}
 	}
 
-	if c.GolangServer != nil {
-		if err := c.GolangServer.Validate(); err != nil {
-			return fmt.Errorf("golang-server config is invalid: %w", err)
-		}
+	if err := validateTargets("openapi", c.OpenAPI); err != nil {
+		return err
 	}
-
-	if c.GolangClient != nil {
-		if err := c.GolangClient.Validate(); err != nil {
-			return fmt.Errorf("golang-client config is invalid: %w", err)
-		}
+	if err := validateTargets("playground", c.Playground); err != nil {
+		return err
 	}
-
-	if c.TypescriptClient != nil {
-		if err := c.TypescriptClient.Validate(); err != nil {
-			return fmt.Errorf("typescript-client config is invalid: %w", err)
-		}
+	if err := validateTargets("asyncapi", c.AsyncAPI); err != nil {
+		return err
+	}
+	if err := validateTargets("golang-server", c.GolangServer); err != nil {
+		return err
+	}
+	if err := validateTargets("golang-client", c.GolangClient); err != nil {
+		return err
+	}
+	if err := validateTargets("typescript-client", c.TypescriptClient); err != nil {
+		return err
+	}
+	if err := validateTargets("dart-client", c.DartClient); err != nil {
+		return err
+	}
+	if err := validateTargets("docs", c.Docs); err != nil {
+		return err
+	}
+	if err := validateTargets("jsonschema", c.JSONSchema); err != nil {
+		return err
+	}
+	if err := validateTargets("template", c.Template); err != nil {
+		return err
+	}
+	if err := validateTargets("plugin", c.Plugin); err != nil {
+		return err
 	}
 
-	if c.DartClient != nil {
-		if err := c.DartClient.Validate(); err != nil {
-			return fmt.Errorf("dart-client config is invalid: %w", err)
+	return nil
+}
+
+// validateTargets validates every entry of a generator.
+func validateTargets[T interface{ Validate() error }](name string, targets Targets[T]) error {
+	for i, target := range targets {
+		if err := target.Validate(); err != nil {
+			target := targetName(name, i, len(targets))
+			return &configKeyError{key: target, err: fmt.Errorf("%s config is invalid: %w", target, err)}
 		}
 	}
-
 	return nil
 }
 
@@ -0,0 +1,445 @@
+package codegen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/uforg/uforpc/urpc/internal/util/filepathutil"
+)
+
+// LoadConfig reads, resolves and validates a config file.
+//
+// A config file can extend another one and define profiles:
+//
+//	extends = "../base.toml"
+//
+//	[profile.staging.playground]
+//	default_base_url = "${STAGING_URL:-https://staging.example.com}"
+//
+// The extended file is loaded first and the values of the config file are
+// merged over it: tables are merged key by key and any other value is
+// replaced. Then the selected profile, if not empty, is merged over the
+// result. Finally ${VAR}, ${VAR:-default} (used when VAR is unset or empty)
+// and ${VAR-default} (used when VAR is unset) in string values are replaced
+// with environment variables, "$${" is a literal "${".
+//
+// Paths in the config are always relative to the directory of configPath,
+// also the ones defined in extended files.
+//
+// Validation errors include the source of the values of the invalid
+// section, i.e. the file, the profile and the environment variables they
+// come from. On error the returned config only has the source files set,
+// so they can still be watched for changes.
+func LoadConfig(configPath string, profile string) (Config, error) {
+	absConfigPath, err := filepathutil.NormalizeFromWD(configPath)
+	if err != nil {
+		return Config{}, fmt.Errorf("failed to normalize config path: %w", err)
+	}
+
+	loader := &configLoader{rootDir: filepath.Dir(absConfigPath)}
+	failed := func(err error) (Config, error) {
+		return Config{files: loader.files}, err
+	}
+
+	tree, err := loader.load(absConfigPath)
+	if err != nil {
+		return failed(err)
+	}
+
+	profiles, _ := tree["profile"].(map[string]any)
+	delete(tree, "profile")
+	if profile != "" {
+		overlay, ok := profiles[profile].(map[string]any)
+		if !ok {
+			return failed(&UndefinedProfileError{Profile: profile, Available: slices.Sorted(maps.Keys(profiles))})
+		}
+		tree = mergeConfigValues(tree, overlay).(map[string]any)
+	}
+
+	if err := interpolateConfigValues(tree, ""); err != nil {
+		return failed(err)
+	}
+
+	sources := map[string]sourcedValue{}
+	plain := unwrapConfigValues(tree, "", sources).(map[string]any)
+
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(plain); err != nil {
+		return failed(fmt.Errorf("failed to encode resolved config: %w", err))
+	}
+
+	config := Config{files: loader.files}
+	if err := config.Unmarshal(buf.Bytes()); err != nil {
+		// The line numbers of the decoding errors refer to the resolved
+		// config, so the key and its sources are shown instead
+		if match := decodeErrRegex.FindStringSubmatch(err.Error()); match != nil {
+			return failed(fmt.Errorf("invalid value of %q: %s%s", match[1], match[2], describeSources(sources, match[1])))
+		}
+		return failed(err)
+	}
+
+	if err := config.Validate(); err != nil {
+		var keyErr *configKeyError
+		if errors.As(err, &keyErr) {
+			return failed(fmt.Errorf("%w%s", err, describeSources(sources, keyErr.key)))
+		}
+		return failed(err)
+	}
+
+	return config, nil
+}
+
+// UndefinedProfileError is returned by LoadConfig when the selected profile
+// is not defined in the config.
+type UndefinedProfileError struct {
+	// Profile is the selected profile.
+	Profile string
+	// Available are the sorted names of the profiles defined in the config.
+	Available []string
+}
+
+func (e *UndefinedProfileError) Error() string {
+	if len(e.Available) == 0 {
+		return fmt.Sprintf("profile %q is not defined, the config has no profiles", e.Profile)
+	}
+	return fmt.Sprintf("profile %q is not defined, available profiles: %s", e.Profile, strings.Join(e.Available, ", "))
+}
+
+// decodeErrRegex matches the key and the message of the TOML decoding
+// errors, e.g. `toml: line 2 (last key "version"): incompatible types`.
+var decodeErrRegex = regexp.MustCompile(`\(last key "([^"]+)"\): (.*)$`)
+
+// sourcedValue is a value of the config together with where it comes from,
+// e.g. `../base.toml` or `uforpc.toml [profile.staging]`.
+type sourcedValue struct {
+	value  any
+	source string
+}
+
+// configLoader loads a config file and the files it extends.
+type configLoader struct {
+	// rootDir is the directory of the loaded config file, the sources are
+	// shown relative to it.
+	rootDir string
+	// files are the absolute paths of the loaded files.
+	files []string
+}
+
+// load decodes the config file and merges it over the files it extends,
+// recursively. The values are wrapped with their source and an error is
+// returned if a file extends itself, directly or not.
+func (l *configLoader) load(absPath string) (map[string]any, error) {
+	if slices.Contains(l.files, absPath) {
+		return nil, fmt.Errorf("config file %s extends itself", l.display(absPath))
+	}
+	l.files = append(l.files, absPath)
+
+	configBytes, err := os.ReadFile(absPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s config file: %s", l.display(absPath), err)
+	}
+
+	raw := map[string]any{}
+	if err := toml.Unmarshal(configBytes, &raw); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal TOML config %s: %w", l.display(absPath), err)
+	}
+
+	extends, hasExtends := raw["extends"]
+	delete(raw, "extends")
+	tree := wrapConfigValues(raw, l.display(absPath), nil).(map[string]any)
+	if !hasExtends {
+		return tree, nil
+	}
+
+	extendsPath, ok := extends.(string)
+	if !ok || extendsPath == "" {
+		return nil, fmt.Errorf(`"extends" of %s must be the path of a config file`, l.display(absPath))
+	}
+	base, err := l.load(filepath.Join(filepath.Dir(absPath), extendsPath))
+	if err != nil {
+		return nil, err
+	}
+	return mergeConfigValues(base, tree).(map[string]any), nil
+}
+
+// display returns the path shown to the user for a config file.
+func (l *configLoader) display(absPath string) string {
+	if rel, err := filepath.Rel(l.rootDir, absPath); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return absPath
+}
+
+// wrapConfigValues replaces every value of the decoded TOML that is not a
+// table or an array of tables with a sourcedValue.
+func wrapConfigValues(value any, source string, path []string) any {
+	switch v := value.(type) {
+	case map[string]any:
+		wrapped := make(map[string]any, len(v))
+		for key, item := range v {
+			wrapped[key] = wrapConfigValues(item, source, append(slices.Clip(path), key))
+		}
+		return wrapped
+	case []map[string]any:
+		wrapped := make([]any, len(v))
+		for i, item := range v {
+			wrapped[i] = wrapConfigValues(item, source, path)
+		}
+		return wrapped
+	case []any:
+		if len(v) > 0 && !slices.ContainsFunc(v, func(item any) bool { _, ok := item.(map[string]any); return !ok }) {
+			wrapped := make([]any, len(v))
+			for i, item := range v {
+				wrapped[i] = wrapConfigValues(item, source, path)
+			}
+			return wrapped
+		}
+	}
+
+	if len(path) >= 2 && path[0] == "profile" {
+		source = fmt.Sprintf("%s [profile.%s]", source, path[1])
+	}
+	return sourcedValue{value: value, source: source}
+}
+
+// mergeConfigValues merges the overlay over the base. Tables are merged
+// key by key, a table merged over an array with a single table is merged
+// into that table, and any other value is replaced.
+func mergeConfigValues(base any, overlay any) any {
+	overlayTable, ok := overlay.(map[string]any)
+	if !ok {
+		return overlay
+	}
+
+	switch b := base.(type) {
+	case map[string]any:
+		merged := maps.Clone(b)
+		for key, value := range overlayTable {
+			if current, exists := merged[key]; exists {
+				merged[key] = mergeConfigValues(current, value)
+			} else {
+				merged[key] = value
+			}
+		}
+		return merged
+	case []any:
+		if len(b) == 1 {
+			if _, isTable := b[0].(map[string]any); isTable {
+				return []any{mergeConfigValues(b[0], overlayTable)}
+			}
+		}
+	}
+
+	return overlay
+}
+
+// interpolateConfigValues replaces the environment variables of all the
+// string values.
+func interpolateConfigValues(value any, path string) error {
+	switch v := value.(type) {
+	case map[string]any:
+		for _, key := range slices.Sorted(maps.Keys(v)) {
+			item := v[key]
+			if sourced, ok := item.(sourcedValue); ok {
+				interpolated, err := interpolateSourcedValue(sourced, joinConfigPath(path, key))
+				if err != nil {
+					return err
+				}
+				v[key] = interpolated
+				continue
+			}
+			if err := interpolateConfigValues(item, joinConfigPath(path, key)); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for i, item := range v {
+			if err := interpolateConfigValues(item, indexConfigPath(path, i, len(v))); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// interpolateSourcedValue replaces the environment variables of a string
+// value, or of the strings of an array, see interpolateEnv. ${VAR},
+// ${VAR:-default} and ${VAR-default} are accepted and "$${" escapes a
+// literal "${". The notes about the used variables are appended to the
+// source of the value.
+func interpolateSourcedValue(sourced sourcedValue, path string) (sourcedValue, error) {
+	notes := []string{}
+	interpolate := func(s string) (string, error) {
+		result, usedNotes, err := interpolateEnv(s)
+		if err != nil {
+			return "", fmt.Errorf("invalid value of %q (from %s): %w", path, sourced.source, err)
+		}
+		notes = append(notes, usedNotes...)
+		return result, nil
+	}
+
+	switch v := sourced.value.(type) {
+	case string:
+		result, err := interpolate(v)
+		if err != nil {
+			return sourced, err
+		}
+		sourced.value = result
+	case []any:
+		items := slices.Clone(v)
+		for i, item := range items {
+			if s, ok := item.(string); ok {
+				result, err := interpolate(s)
+				if err != nil {
+					return sourced, err
+				}
+				items[i] = result
+			}
+		}
+		sourced.value = items
+	}
+
+	if len(notes) > 0 {
+		sourced.source += ", " + strings.Join(notes, ", ")
+	}
+	return sourced, nil
+}
+
+// interpolateEnv replaces the environment variables of the string, it
+// returns a note for every replaced variable explaining where its value
+// comes from.
+func interpolateEnv(s string) (string, []string, error) {
+	var result strings.Builder
+	notes := []string{}
+
+	for {
+		start := strings.Index(s, "${")
+		if start < 0 {
+			result.WriteString(s)
+			return result.String(), notes, nil
+		}
+		if start > 0 && s[start-1] == '$' {
+			result.WriteString(s[:start-1] + "${")
+			s = s[start+2:]
+			continue
+		}
+
+		end := strings.IndexByte(s[start:], '}')
+		if end < 0 {
+			return "", nil, fmt.Errorf("unclosed %q", s[start:])
+		}
+		expr := s[start+2 : start+end]
+		result.WriteString(s[:start])
+		s = s[start+end+1:]
+
+		name, defaultValue, hasDefault := expr, "", false
+		useDefaultIfEmpty := false
+		if i := strings.Index(expr, ":-"); i >= 0 {
+			name, defaultValue, hasDefault, useDefaultIfEmpty = expr[:i], expr[i+2:], true, true
+		} else if i := strings.IndexByte(expr, '-'); i >= 0 {
+			name, defaultValue, hasDefault = expr[:i], expr[i+1:], true
+		}
+		if !isEnvVarName(name) {
+			return "", nil, fmt.Errorf("invalid environment variable name %q", name)
+		}
+
+		value, isSet := os.LookupEnv(name)
+		switch {
+		case isSet && (value != "" || !useDefaultIfEmpty):
+			result.WriteString(value)
+			notes = append(notes, fmt.Sprintf("${%s} from the environment", name))
+		case hasDefault:
+			result.WriteString(defaultValue)
+			notes = append(notes, fmt.Sprintf("${%s} not set, using its default", name))
+		default:
+			return "", nil, fmt.Errorf("environment variable %s is not set and has no default, use ${%s:-default} to set one", name, name)
+		}
+	}
+}
+
+// isEnvVarName returns true if the name is a valid environment variable
+// name, letters, digits and underscores not starting with a digit.
+func isEnvVarName(name string) bool {
+	if name == "" || ('0' <= name[0] && name[0] <= '9') {
+		return false
+	}
+	for _, r := range name {
+		if !(r == '_' || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || ('0' <= r && r <= '9')) {
+			return false
+		}
+	}
+	return true
+}
+
+// unwrapConfigValues returns the plain TOML values and records the
+// sourced values by their path, e.g. "golang-client[1].output_file".
+func unwrapConfigValues(value any, path string, sources map[string]sourcedValue) any {
+	switch v := value.(type) {
+	case sourcedValue:
+		sources[path] = v
+		return v.value
+	case map[string]any:
+		plain := make(map[string]any, len(v))
+		for key, item := range v {
+			plain[key] = unwrapConfigValues(item, joinConfigPath(path, key), sources)
+		}
+		return plain
+	case []any:
+		tables := make([]map[string]any, len(v))
+		for i, item := range v {
+			tables[i] = unwrapConfigValues(item, indexConfigPath(path, i, len(v)), sources).(map[string]any)
+		}
+		return tables
+	}
+	return value
+}
+
+// joinConfigPath returns the path of a key of the table at the path, e.g.
+// "playground.default_base_url".
+func joinConfigPath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// indexConfigPath returns the path of an entry of an array of tables, the
+// index is omitted if there is a single entry like in the errors of the
+// generators, see targetName.
+func indexConfigPath(path string, index int, count int) string {
+	if count <= 1 {
+		return path
+	}
+	return fmt.Sprintf("%s[%d]", path, index)
+}
+
+// describeSources returns the lines describing the source of the values
+// at the path or inside it.
+func describeSources(sources map[string]sourcedValue, path string) string {
+	var b strings.Builder
+	for _, key := range slices.Sorted(maps.Keys(sources)) {
+		if key != path && !strings.HasPrefix(key, path+".") && !strings.HasPrefix(key, path+"[") {
+			continue
+		}
+		sourced := sources[key]
+		fmt.Fprintf(&b, "\n  %s = %s (from %s)", key, formatConfigValue(sourced.value), sourced.source)
+	}
+	return b.String()
+}
+
+// formatConfigValue returns a value as shown in the errors, strings are
+// quoted like in TOML.
+func formatConfigValue(value any) string {
+	if s, ok := value.(string); ok {
+		return fmt.Sprintf("%q", s)
+	}
+	return fmt.Sprintf("%v", value)
+}
@@ -0,0 +1,248 @@
+package codegen
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfigFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	return dir
+}
+
+func TestLoadConfigExtendsAndProfiles(t *testing.T) {
+	dir := writeConfigFiles(t, map[string]string{
+		"base.toml": `
+version = 1
+schema = "./schema.urpc"
+
+[playground]
+output_dir = "./playground"
+default_base_url = "http://localhost:8080"
+default_headers = [{ key = "X-Env", value = "dev" }]
+
+[golang-server]
+output_file = "./server/server.go"
+package_name = "server"
+
+[profile.prod.playground]
+default_base_url = "https://api.example.com"
+`,
+		"api/uforpc.toml": `
+extends = "../base.toml"
+schema = "./api.urpc"
+
+[golang-server]
+package_name = "api"
+
+[profile.staging.playground]
+default_base_url = "https://staging.example.com"
+default_headers = [{ key = "X-Env", value = "staging" }]
+`,
+	})
+	configPath := filepath.Join(dir, "api", "uforpc.toml")
+
+	config, err := LoadConfig(configPath, "")
+	require.NoError(t, err)
+	require.Equal(t, "./api.urpc", config.Schema)
+	require.Equal(t, "./server/server.go", config.GolangServer[0].OutputFile)
+	require.Equal(t, "api", config.GolangServer[0].PackageName)
+	require.Equal(t, "http://localhost:8080", config.Playground[0].DefaultBaseURL)
+	require.Equal(t, []string{configPath, filepath.Join(dir, "base.toml")}, config.SourceFiles())
+
+	config, err = LoadConfig(configPath, "staging")
+	require.NoError(t, err)
+	require.Equal(t, "https://staging.example.com", config.Playground[0].DefaultBaseURL)
+	require.Equal(t, "staging", config.Playground[0].DefaultHeaders[0].Value)
+	require.Equal(t, "./playground", config.Playground[0].OutputDir)
+
+	config, err = LoadConfig(configPath, "prod")
+	require.NoError(t, err)
+	require.Equal(t, "https://api.example.com", config.Playground[0].DefaultBaseURL)
+	require.Equal(t, "dev", config.Playground[0].DefaultHeaders[0].Value)
+
+	_, err = LoadConfig(configPath, "qa")
+	require.EqualError(t, err, `profile "qa" is not defined, available profiles: prod, staging`)
+	var profileErr *UndefinedProfileError
+	require.ErrorAs(t, err, &profileErr)
+	require.Equal(t, "qa", profileErr.Profile)
+}
+
+func TestLoadConfigProfileClient(t *testing.T) {
+	dir := writeConfigFiles(t, map[string]string{
+		"uforpc.toml": `
+version = 1
+schema = "./schema.urpc"
+
+[client]
+base_url = "http://localhost:8080"
+headers = [{ key = "Authorization", value = "Bearer dev" }]
+
+[profile.staging.client]
+base_url = "https://staging.example.com"
+`,
+	})
+	configPath := filepath.Join(dir, "uforpc.toml")
+
+	config, err := LoadConfig(configPath, "")
+	require.NoError(t, err)
+	require.Equal(t, "http://localhost:8080", config.Client.BaseURL)
+
+	config, err = LoadConfig(configPath, "staging")
+	require.NoError(t, err)
+	require.Equal(t, "https://staging.example.com", config.Client.BaseURL)
+	require.Equal(t, "Bearer dev", config.Client.Headers[0].Value)
+}
+
+func TestLoadConfigProfileOverArrayOfTables(t *testing.T) {
+	dir := writeConfigFiles(t, map[string]string{
+		"uforpc.toml": `
+version = 1
+schema = "./schema.urpc"
+
+[[playground]]
+output_dir = "./playground"
+
+[profile.prod.playground]
+default_base_url = "https://api.example.com"
+
+[[profile.prod.golang-client]]
+output_file = "./a/client.go"
+package_name = "a"
+
+[[profile.prod.golang-client]]
+output_file = "./b/client.go"
+package_name = "b"
+`,
+	})
+
+	config, err := LoadConfig(filepath.Join(dir, "uforpc.toml"), "prod")
+	require.NoError(t, err)
+	require.Len(t, config.Playground, 1)
+	require.Equal(t, "./playground", config.Playground[0].OutputDir)
+	require.Equal(t, "https://api.example.com", config.Playground[0].DefaultBaseURL)
+	require.Len(t, config.GolangClient, 2)
+}
+
+func TestLoadConfigEnvInterpolation(t *testing.T) {
+	t.Setenv("URPC_TEST_URL", "https://env.example.com")
+	t.Setenv("URPC_TEST_EMPTY", "")
+
+	dir := writeConfigFiles(t, map[string]string{
+		"uforpc.toml": `
+version = 1
+schema = "./schema.urpc"
+
+[playground]
+output_dir = "./${URPC_TEST_UNSET:-playground}"
+default_base_url = "${URPC_TEST_URL}/api"
+default_headers = [{ key = "Authorization", value = "Bearer ${URPC_TEST_EMPTY:-dev}${URPC_TEST_EMPTY-x}" }]
+
+[openapi]
+output_file = "./openapi.yaml"
+title = "Price in $${USD}"
+`,
+	})
+
+	config, err := LoadConfig(filepath.Join(dir, "uforpc.toml"), "")
+	require.NoError(t, err)
+	require.Equal(t, "./playground", config.Playground[0].OutputDir)
+	require.Equal(t, "https://env.example.com/api", config.Playground[0].DefaultBaseURL)
+	require.Equal(t, "Bearer dev", config.Playground[0].DefaultHeaders[0].Value)
+	require.Equal(t, "Price in ${USD}", config.OpenAPI[0].Title)
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	t.Setenv("URPC_TEST_PACKAGE", "")
+
+	tests := []struct {
+		name     string
+		files    map[string]string
+		profile  string
+		expected string
+	}{
+		{
+			name: "missing environment variable",
+			files: map[string]string{"uforpc.toml": `
+version = 1
+schema = "${URPC_TEST_UNSET}"
+`},
+			expected: `invalid value of "schema" (from uforpc.toml): environment variable URPC_TEST_UNSET is not set and has no default, use ${URPC_TEST_UNSET:-default} to set one`,
+		},
+		{
+			name: "invalid environment variable",
+			files: map[string]string{"uforpc.toml": `
+version = 1
+schema = "${NOT VALID}"
+`},
+			expected: `invalid value of "schema" (from uforpc.toml): invalid environment variable name "NOT VALID"`,
+		},
+		{
+			name: "extends cycle",
+			files: map[string]string{
+				"uforpc.toml": `extends = "./base.toml"`,
+				"base.toml":   `extends = "./uforpc.toml"`,
+			},
+			expected: "config file uforpc.toml extends itself",
+		},
+		{
+			name:     "missing extended file",
+			files:    map[string]string{"uforpc.toml": `extends = "./missing.toml"`},
+			expected: "failed to read missing.toml config file: open ",
+		},
+		{
+			name:     "undefined profile",
+			files:    map[string]string{"uforpc.toml": "version = 1\nschema = \"./schema.urpc\"\n"},
+			profile:  "staging",
+			expected: `profile "staging" is not defined, the config has no profiles`,
+		},
+		{
+			name: "validation error with sources",
+			files: map[string]string{
+				"base.toml": `
+version = 1
+schema = "./schema.urpc"
+
+[golang-server]
+output_file = "./server/server.go"
+`,
+				"uforpc.toml": `
+extends = "./base.toml"
+
+[profile.prod.golang-server]
+package_name = "${URPC_TEST_PACKAGE-server}"
+`,
+			},
+			profile: "prod",
+			expected: `golang-server config is invalid: "package_name" is required
+  golang-server.output_file = "./server/server.go" (from base.toml)
+  golang-server.package_name = "" (from uforpc.toml [profile.prod], ${URPC_TEST_PACKAGE} from the environment)`,
+		},
+		{
+			name: "type error with sources",
+			files: map[string]string{"uforpc.toml": `
+version = "1"
+schema = "./schema.urpc"
+`},
+			expected: "invalid value of \"version\": incompatible types: TOML value has type string; destination has type integer\n  version = \"1\" (from uforpc.toml)",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := writeConfigFiles(t, test.files)
+			_, err := LoadConfig(filepath.Join(dir, "uforpc.toml"), test.profile)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), test.expected)
+		})
+	}
+}
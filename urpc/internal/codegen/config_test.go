@@ -0,0 +1,314 @@
+package codegen
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/uforg/uforpc/urpc/internal/codegen/golang"
+	"github.com/uforg/uforpc/urpc/internal/codegen/jsonschema"
+	"github.com/uforg/uforpc/urpc/internal/codegen/playground"
+	"github.com/uforg/uforpc/urpc/internal/codegen/typescript"
+)
+
+func TestConfigSingleTable(t *testing.T) {
+	config := Config{}
+	err := config.UnmarshalAndValidate([]byte(`
+version = 1
+schema = "./schema.urpc"
+
+[openapi]
+title = "API"
+
+[golang-client]
+output_file = "./client.go"
+package_name = "client"
+`))
+	require.NoError(t, err)
+
+	require.Equal(t, Targets[golang.Config]{{OutputFile: "./client.go", PackageName: "client"}}, config.GolangClient)
+	require.True(t, config.HasGolangClient())
+	require.False(t, config.HasGolangServer())
+	require.False(t, config.HasOpenAPI())
+	require.Equal(t, "API", config.OpenAPIMetadata().Title)
+	require.Nil(t, config.FirstPlayground())
+}
+
+func TestConfigArrayOfTables(t *testing.T) {
+	config := Config{}
+	err := config.UnmarshalAndValidate([]byte(`
+version = 1
+schema = "./schema.urpc"
+docs = [{ output_dir = "./docs" }, { output_dir = "./docs-md", format = "markdown" }]
+
+[[typescript-client]]
+output_file = "./web/client.ts"
+
+[[typescript-client]]
+output_file = "./admin/client.ts"
+
+[[playground]]
+output_dir = "./playground"
+default_headers = [{ key = "X-Foo", value = "bar" }]
+`))
+	require.NoError(t, err)
+
+	require.Equal(t, Targets[typescript.Config]{
+		{OutputFile: "./web/client.ts"},
+		{OutputFile: "./admin/client.ts"},
+	}, config.TypescriptClient)
+	require.Equal(t, "X-Foo", config.FirstPlayground().DefaultHeaders[0].Key)
+	require.Len(t, config.Docs, 2)
+	require.Equal(t, "markdown", config.Docs[1].Format)
+}
+
+func TestConfigValidationReportsEntry(t *testing.T) {
+	config := Config{}
+	err := config.UnmarshalAndValidate([]byte(`
+version = 1
+schema = "./schema.urpc"
+
+[[golang-client]]
+output_file = "./a/client.go"
+package_name = "a"
+
+[[golang-client]]
+output_file = "./b/client.go"
+`))
+	require.EqualError(t, err, `golang-client[1] config is invalid: "package_name" is required`)
+
+	config = Config{}
+	err = config.UnmarshalAndValidate([]byte(`
+version = 1
+schema = "./schema.urpc"
+
+[golang-client]
+output_file = "./a/client.ts"
+package_name = "a"
+`))
+	require.EqualError(t, err, `golang-client config is invalid: "output_file" must end with ".go"`)
+
+	config = Config{}
+	err = config.Unmarshal([]byte(`
+version = 1
+schema = "./schema.urpc"
+golang-client = "./client.go"
+`))
+	require.ErrorContains(t, err, "expected a table or an array of tables")
+}
+
+func TestCheckOutputPaths(t *testing.T) {
+	config := Config{
+		GolangServer: Targets[golang.Config]{{OutputFile: "./gen/api.go"}},
+		GolangClient: Targets[golang.Config]{{OutputFile: "./a/client.go"}, {OutputFile: "./b/client.go"}},
+	}
+	require.NoError(t, checkOutputPaths("/project", config))
+
+	config.GolangClient[1].OutputFile = "a/../a/client.go"
+	require.EqualError(t, checkOutputPaths("/project", config), "golang-client[0] and golang-client[1] write to the same output path a/../a/client.go")
+
+	config.GolangClient = Targets[golang.Config]{{OutputFile: "gen/api.go"}}
+	require.EqualError(t, checkOutputPaths("/project", config), "golang-server and golang-client write to the same output path gen/api.go")
+
+	config = Config{
+		Playground:   Targets[playground.Config]{{OutputDir: "./web/playground"}},
+		GolangServer: Targets[golang.Config]{{OutputFile: "./web/server.go"}},
+		JSONSchema:   Targets[jsonschema.Config]{{OutputDir: "./schemas"}, {OutputFile: "./schemas.json"}},
+	}
+	require.NoError(t, checkOutputPaths("/project", config))
+
+	config.GolangServer[0].OutputFile = "./web/playground/server.go"
+	require.EqualError(t, checkOutputPaths("/project", config), "golang-server writes to web/playground/server.go inside the output directory ./web/playground of playground, which removes the stale files in it")
+
+	config.GolangServer[0].OutputFile = "./server.go"
+	config.JSONSchema[1].OutputFile = "./schemas/nested/all.json"
+	require.EqualError(t, checkOutputPaths("/project", config), "jsonschema[1] writes to schemas/nested/all.json inside the output directory ./schemas of jsonschema[0], which removes the stale files in it")
+
+	// The root is never cleaned
+	config.JSONSchema = Targets[jsonschema.Config]{{OutputDir: "."}}
+	require.NoError(t, checkOutputPaths("/project", config))
+}
+
+func TestRunMultipleTargets(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "schema.urpc"), []byte("version 1\n\ntype User {\n  id: string\n}\n"), 0644))
+
+	configPath := filepath.Join(dir, "uforpc.toml")
+	require.NoError(t, os.WriteFile(configPath, []byte(`
+version = 1
+schema = "./schema.urpc"
+
+[[golang-client]]
+output_file = "./a/client.go"
+package_name = "a"
+
+[[golang-client]]
+output_file = "./b/client.go"
+package_name = "b"
+`), 0644))
+	require.NoError(t, Run(configPath))
+
+	for _, pkg := range []string{"a", "b"} {
+		content, err := os.ReadFile(filepath.Join(dir, pkg, "client.go"))
+		require.NoError(t, err)
+		require.Contains(t, string(content), "package "+pkg+"\n")
+	}
+
+	require.NoError(t, os.WriteFile(configPath, []byte(`
+version = 1
+schema = "./schema.urpc"
+
+[[golang-client]]
+output_file = "./a/client.go"
+package_name = "a"
+
+[golang-server]
+output_file = "./a/client.go"
+package_name = "a"
+`), 0644))
+	require.EqualError(t, Run(configPath), "golang-server and golang-client write to the same output path ./a/client.go")
+}
+
+func TestRunTemplate(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "schema.urpc"), []byte("version 1\n\ntype User {\n  id: string\n}\n\ntype Post {\n  title: string\n}\n"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "templates"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "templates", "table.sql.tmpl"), []byte("CREATE TABLE {{ snake .Node.Name }}s ();\n"), 0644))
+
+	configPath := filepath.Join(dir, "uforpc.toml")
+	require.NoError(t, os.WriteFile(configPath, []byte(`
+version = 1
+schema = "./schema.urpc"
+
+[[template]]
+template = "./templates/table.sql.tmpl"
+output_file = "./sql/{{ snake .Node.Name }}.sql"
+each = "type"
+`), 0644))
+	require.NoError(t, Run(configPath))
+
+	content, err := os.ReadFile(filepath.Join(dir, "sql", "post.sql"))
+	require.NoError(t, err)
+	require.Equal(t, "CREATE TABLE posts ();\n", string(content))
+
+	files, err := WatchedFiles(configPath, "")
+	require.NoError(t, err)
+	require.Contains(t, files, filepath.Join(dir, "templates", "table.sql.tmpl"))
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "templates", "table.sql.tmpl"), []byte("\n{{ .Node.Nope }}"), 0644))
+	err = Run(configPath)
+	require.ErrorContains(t, err, "failed to run template code generator: failed to render template: failed to render type User: template: table.sql.tmpl:2:8:")
+
+	// The rendered paths of a target can collide with the files of another
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "templates", "table.sql.tmpl"), []byte("CREATE TABLE {{ snake .Node.Name }}s ();\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "templates", "schema.sql.tmpl"), []byte("-- schema\n"), 0644))
+	require.NoError(t, os.WriteFile(configPath, []byte(`
+version = 1
+schema = "./schema.urpc"
+
+[[template]]
+template = "./templates/table.sql.tmpl"
+output_file = "./sql/{{ snake .Node.Name }}.sql"
+each = "type"
+
+[[template]]
+template = "./templates/schema.sql.tmpl"
+output_file = "./sql/user.sql"
+`), 0644))
+	require.EqualError(t, Run(configPath), "template[0] and template[1] code generators both write to sql/user.sql")
+}
+
+func TestCheck(t *testing.T) {
+	dir := t.TempDir()
+	schemaPath := filepath.Join(dir, "schema.urpc")
+	require.NoError(t, os.WriteFile(schemaPath, []byte("version 1\n\ntype User {\n  id: string\n}\n"), 0644))
+
+	configPath := filepath.Join(dir, "uforpc.toml")
+	require.NoError(t, os.WriteFile(configPath, []byte(`
+version = 1
+schema = "./schema.urpc"
+
+[golang-client]
+output_file = "./client/client.go"
+package_name = "client"
+
+[typescript-client]
+output_file = "./ts/client.ts"
+`), 0644))
+
+	result, err := Check(configPath, Options{})
+	require.NoError(t, err)
+	require.Equal(t, CheckResult{Checked: 2, Stale: []StaleFile{
+		{Path: filepath.Join(dir, "client", "client.go"), Reason: "missing"},
+		{Path: filepath.Join(dir, "ts", "client.ts"), Reason: "missing"},
+	}}, result)
+	_, err = os.Stat(filepath.Join(dir, "client"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	require.NoError(t, Run(configPath))
+	result, err = Check(configPath, Options{})
+	require.NoError(t, err)
+	require.Equal(t, CheckResult{Checked: 2}, result)
+
+	content, err := os.ReadFile(filepath.Join(dir, "client", "client.go"))
+	require.NoError(t, err)
+	require.Regexp(t, `^// Code generated by UFO RPC. DO NOT EDIT.\n// Generated by urpc v\S+ from schema sha256:[0-9a-f]{16}\n`, string(content))
+
+	require.NoError(t, os.WriteFile(schemaPath, []byte("version 1\n\ntype User {\n  id: string\n  name: string\n}\n"), 0644))
+	result, err = Check(configPath, Options{})
+	require.NoError(t, err)
+	require.Len(t, result.Stale, 2)
+	require.Contains(t, result.Stale[0].Reason, "generated from schema sha256:")
+}
+
+func TestRunJSONSchema(t *testing.T) {
+	dir := t.TempDir()
+	schemaPath := filepath.Join(dir, "schema.urpc")
+	require.NoError(t, os.WriteFile(schemaPath, []byte("version 1\n\ntype User {\n  id: string\n}\n\ntype Post {\n  title: string\n}\n"), 0644))
+
+	configPath := filepath.Join(dir, "uforpc.toml")
+	require.NoError(t, os.WriteFile(configPath, []byte(`
+version = 1
+schema = "./schema.urpc"
+
+[[jsonschema]]
+output_file = "./schema.json"
+
+[[jsonschema]]
+output_dir = "./schemas"
+`), 0644))
+	require.NoError(t, Run(configPath))
+
+	content, err := os.ReadFile(filepath.Join(dir, "schema.json"))
+	require.NoError(t, err)
+	require.Regexp(t, `^\{\n  "\$comment": "Generated by urpc v\S+ from schema sha256:[0-9a-f]{16}",\n  "\$schema": "https://json-schema.org/draft/2020-12/schema",\n  "\$defs": \{\n    "Post": \{`, string(content))
+	require.FileExists(t, filepath.Join(dir, "schemas", "Post.json"))
+
+	// Files in the output directory not written by urpc are never removed
+	foreign := map[string]string{
+		"Comment.json":       `{"$schema": "https://json-schema.org/draft/2020-12/schema", "title": "Comment"}`,
+		"README.md":          "# Schemas\n",
+		"nested/Legacy.json": `{"$comment": "Generated by urpc v0.0.1 from schema sha256:0000000000000000"}`,
+		"notes.json":         "{}",
+	}
+	for name, content := range foreign {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, "schemas", name)), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "schemas", name), []byte(content), 0644))
+	}
+
+	require.NoError(t, os.WriteFile(schemaPath, []byte("version 1\n\ntype User {\n  id: string\n}\n"), 0644))
+	result, err := Check(configPath, Options{})
+	require.NoError(t, err)
+	require.Contains(t, result.Stale, StaleFile{Path: filepath.Join(dir, "schemas", "Post.json"), Reason: "not generated anymore"})
+	require.NotContains(t, result.Stale, StaleFile{Path: filepath.Join(dir, "schemas", "Comment.json"), Reason: "not generated anymore"})
+
+	require.NoError(t, Run(configPath))
+	require.FileExists(t, filepath.Join(dir, "schemas", "User.json"))
+	require.NoFileExists(t, filepath.Join(dir, "schemas", "Post.json"))
+	for name, content := range foreign {
+		current, err := os.ReadFile(filepath.Join(dir, "schemas", name))
+		require.NoError(t, err, name)
+		require.Equal(t, content, string(current), name)
+	}
+}
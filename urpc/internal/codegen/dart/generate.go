@@ -62,11 +62,11 @@ func Generate(sch schema.Schema, config Config) (Output, error) {
 		Content: libClientContent,
 	}
 
-	// 2) Generate pubspec.yaml
-	pubspecRawPiece = strings.ReplaceAll(pubspecRawPiece, "{{ package_name }}", config.PackageName)
+	// 2) Generate pubspec.yaml, the embedded piece is not modified because
+	// the generator can run concurrently for several targets
 	pubspec := OutputFile{
 		Path:    "pubspec.yaml",
-		Content: pubspecRawPiece,
+		Content: strings.ReplaceAll(pubspecRawPiece, "{{ package_name }}", config.PackageName),
 	}
 
 	// 3) Generate pubspec.lock
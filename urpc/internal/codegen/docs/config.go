@@ -0,0 +1,35 @@
+package docs
+
+import (
+	"fmt"
+)
+
+const (
+	// FormatHTML generates a static HTML site with search.
+	FormatHTML = "html"
+	// FormatMarkdown generates Markdown files only.
+	FormatMarkdown = "markdown"
+)
+
+// Config is the configuration for the documentation generator.
+type Config struct {
+	// OutputDir is the directory to output the generated documentation to.
+	OutputDir string `toml:"output_dir"`
+	// Format is the format of the generated documentation, "html" (default)
+	// or "markdown".
+	Format string `toml:"format"`
+	// Title is the title of the documentation, defaults to "UFO RPC API".
+	Title string `toml:"title"`
+	// BaseURL is the base URL shown in the request examples.
+	BaseURL string `toml:"base_url"`
+}
+
+func (c Config) Validate() error {
+	if c.OutputDir == "" {
+		return fmt.Errorf(`"output_dir" is required`)
+	}
+	if c.Format != "" && c.Format != FormatHTML && c.Format != FormatMarkdown {
+		return fmt.Errorf(`"format" must be %q or %q`, FormatHTML, FormatMarkdown)
+	}
+	return nil
+}
@@ -0,0 +1,325 @@
+package docs
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/uforg/uforpc/urpc/internal/schema"
+)
+
+// OutputFile represents a single generated file.
+type OutputFile struct {
+	Path    string `json:"path"`
+	Content string `json:"content"`
+}
+
+// Output represents the generated documentation files.
+type Output struct {
+	Files []OutputFile `json:"files"`
+}
+
+// Generate takes a schema and a config and generates the documentation
+// files for the schema.
+func Generate(sch schema.Schema, config Config) (Output, error) {
+	if config.Title == "" {
+		config.Title = "UFO RPC API"
+	}
+
+	s := newSite(sch, config)
+
+	switch config.Format {
+	case "", FormatHTML:
+		return generateHTML(s)
+	case FormatMarkdown:
+		return generateMarkdown(s), nil
+	default:
+		return Output{}, fmt.Errorf("unsupported format %q", config.Format)
+	}
+}
+
+const (
+	pageKindIndex    = "index"
+	pageKindEnvelope = "envelope"
+	pageKindDoc      = "doc"
+	pageKindType     = "type"
+	pageKindProc     = "proc"
+	pageKindStream   = "stream"
+)
+
+// site is the documentation of a schema independent of the output format.
+type site struct {
+	Config    Config
+	Index     *page
+	Envelope  *page
+	Docs      []*page
+	Types     []*page
+	Procs     []*page
+	Streams   []*page
+	typePaths map[string]string
+}
+
+// page is a single page of the documentation.
+type page struct {
+	Kind string
+	// Path is the slash separated path of the page without extension.
+	Path       string
+	Title      string
+	Doc        string
+	Deprecated *string
+	Sections   []fieldSection
+	// UsedBy are the pages of the types, procedures and streams that
+	// reference the type of the page.
+	UsedBy []*page
+}
+
+// fieldSection is a table of fields, e.g. the input of a procedure.
+type fieldSection struct {
+	Title string
+	Rows  []fieldRow
+}
+
+// fieldRow is a field of a fieldSection, fields of inline objects are
+// flattened with a dotted path.
+type fieldRow struct {
+	Path     string
+	Type     typeRef
+	Optional bool
+	Doc      string
+}
+
+// typeRef is the type of a field.
+type typeRef struct {
+	Name string
+	// Path is the path of the page of the type, empty for primitive and
+	// inline types.
+	Path    string
+	IsArray bool
+}
+
+// String returns the type as written in the schema.
+func (t typeRef) String() string {
+	if t.IsArray {
+		return t.Name + "[]"
+	}
+	return t.Name
+}
+
+// allPages returns all the pages in navigation order.
+func (s *site) allPages() []*page {
+	pages := []*page{s.Index, s.Envelope}
+	pages = append(pages, s.Docs...)
+	pages = append(pages, s.Procs...)
+	pages = append(pages, s.Streams...)
+	pages = append(pages, s.Types...)
+	return pages
+}
+
+func newSite(sch schema.Schema, config Config) *site {
+	s := &site{
+		Config:    config,
+		Index:     &page{Kind: pageKindIndex, Path: "index", Title: config.Title},
+		Envelope:  &page{Kind: pageKindEnvelope, Path: "envelope", Title: "Requests and responses"},
+		typePaths: map[string]string{},
+	}
+
+	for _, node := range sch.GetTypeNodes() {
+		s.typePaths[node.Name] = "types/" + node.Name
+	}
+
+	usedSlugs := map[string]bool{}
+	for i, node := range sch.GetDocNodes() {
+		title := markdownTitle(node.Content)
+		slug := slugify(title)
+		if slug == "" {
+			slug = fmt.Sprintf("doc-%d", i+1)
+		}
+		for base, n := slug, 2; usedSlugs[slug]; n++ {
+			slug = fmt.Sprintf("%s-%d", base, n)
+		}
+		usedSlugs[slug] = true
+
+		s.Docs = append(s.Docs, &page{
+			Kind:  pageKindDoc,
+			Path:  "docs/" + slug,
+			Title: title,
+			Doc:   node.Content,
+		})
+	}
+
+	typePages := map[string]*page{}
+	for _, node := range sch.GetTypeNodes() {
+		p := &page{
+			Kind:       pageKindType,
+			Path:       s.typePaths[node.Name],
+			Title:      node.Name,
+			Doc:        deref(node.Doc),
+			Deprecated: node.Deprecated,
+			Sections:   []fieldSection{{Title: "Fields", Rows: s.fieldRows(node.Fields, "")}},
+		}
+		typePages[node.Name] = p
+		s.Types = append(s.Types, p)
+	}
+
+	for _, node := range sch.GetProcNodes() {
+		s.Procs = append(s.Procs, &page{
+			Kind:       pageKindProc,
+			Path:       "procedures/" + node.Name,
+			Title:      node.Name,
+			Doc:        deref(node.Doc),
+			Deprecated: node.Deprecated,
+			Sections: []fieldSection{
+				{Title: "Input", Rows: s.fieldRows(node.Input, "")},
+				{Title: "Output", Rows: s.fieldRows(node.Output, "")},
+			},
+		})
+	}
+
+	for _, node := range sch.GetStreamNodes() {
+		s.Streams = append(s.Streams, &page{
+			Kind:       pageKindStream,
+			Path:       "streams/" + node.Name,
+			Title:      node.Name,
+			Doc:        deref(node.Doc),
+			Deprecated: node.Deprecated,
+			Sections: []fieldSection{
+				{Title: "Input", Rows: s.fieldRows(node.Input, "")},
+				{Title: "Output", Rows: s.fieldRows(node.Output, "")},
+			},
+		})
+	}
+
+	// Reverse references for the "Used by" lists of the type pages
+	for _, p := range slices.Concat(s.Procs, s.Streams, s.Types) {
+		seen := map[string]bool{}
+		for _, section := range p.Sections {
+			for _, row := range section.Rows {
+				target, ok := typePages[row.Type.Name]
+				if !ok || row.Type.Path == "" || seen[row.Type.Name] || target == p {
+					continue
+				}
+				seen[row.Type.Name] = true
+				target.UsedBy = append(target.UsedBy, p)
+			}
+		}
+	}
+
+	return s
+}
+
+// fieldRows flattens the fields, the fields of inline objects follow their
+// parent with a dotted path.
+func (s *site) fieldRows(fields []schema.FieldDefinition, prefix string) []fieldRow {
+	rows := []fieldRow{}
+	for _, field := range fields {
+		path := field.Name
+		if prefix != "" {
+			path = prefix + "." + field.Name
+		}
+
+		ref := typeRef{Name: "object", IsArray: field.IsArray}
+		if field.IsNamed() {
+			ref.Name = *field.TypeName
+			ref.Path = s.typePaths[ref.Name]
+		}
+
+		rows = append(rows, fieldRow{
+			Path:     path,
+			Type:     ref,
+			Optional: field.Optional,
+			Doc:      deref(field.Doc),
+		})
+
+		if field.IsInline() {
+			childPrefix := path
+			if field.IsArray {
+				childPrefix += "[]"
+			}
+			rows = append(rows, s.fieldRows(field.TypeInline.Fields, childPrefix)...)
+		}
+	}
+	return rows
+}
+
+// relativeLink returns the link from the page at path from to the page at
+// path to with the given extension.
+func relativeLink(from string, to string, ext string) string {
+	return strings.Repeat("../", strings.Count(from, "/")) + to + ext
+}
+
+// rootPrefix returns the relative path from the page to the root of the
+// site.
+func rootPrefix(from string) string {
+	return strings.Repeat("../", strings.Count(from, "/"))
+}
+
+// markdownTitle returns the first level 1 heading of the markdown, or
+// "Untitled: <first line>" if there is none, like the playground does.
+func markdownTitle(markdown string) string {
+	firstLine := ""
+	for line := range strings.SplitSeq(markdown, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if firstLine == "" {
+			firstLine = line
+		}
+		if line == "#" || strings.HasPrefix(line, "# ") {
+			return strings.TrimSpace(strings.TrimPrefix(line, "#"))
+		}
+	}
+	return "Untitled: " + firstLine
+}
+
+var (
+	slugInvalidChars = regexp.MustCompile(`[^a-z0-9\s-]`)
+	slugSpaces       = regexp.MustCompile(`\s+`)
+	slugDashes       = regexp.MustCompile(`--+`)
+)
+
+// slugify converts a title to a URL friendly slug, like the playground
+// does.
+func slugify(str string) string {
+	str = strings.TrimSpace(strings.ToLower(str))
+	str = slugInvalidChars.ReplaceAllString(str, "")
+	str = slugSpaces.ReplaceAllString(str, "-")
+	str = slugDashes.ReplaceAllString(str, "-")
+	return strings.Trim(str, "-")
+}
+
+// firstParagraph returns the first paragraph of the markdown that is not a
+// heading, used as a summary.
+func firstParagraph(markdown string) string {
+	lines := []string{}
+	inCode := false
+	for line := range strings.SplitSeq(markdown, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inCode = !inCode
+			continue
+		}
+		if inCode || strings.HasPrefix(trimmed, "#") {
+			if len(lines) > 0 {
+				break
+			}
+			continue
+		}
+		if trimmed == "" {
+			if len(lines) > 0 {
+				break
+			}
+			continue
+		}
+		lines = append(lines, trimmed)
+	}
+	return strings.Join(lines, " ")
+}
+
+func deref(str *string) string {
+	if str == nil {
+		return ""
+	}
+	return *str
+}
@@ -0,0 +1,173 @@
+package docs
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"html"
+	"html/template"
+	"regexp"
+	"strings"
+)
+
+//go:embed pieces/layout.html
+var layoutRawPiece string
+
+//go:embed pieces/style.css
+var styleRawPiece string
+
+//go:embed pieces/search.js
+var searchRawPiece string
+
+var layoutTemplate = template.Must(template.New("layout").Parse(layoutRawPiece))
+
+// layoutData is the data of the layout template.
+type layoutData struct {
+	SiteTitle string
+	PageTitle string
+	// Root is the relative path from the page to the root of the site.
+	Root    string
+	Nav     []navSection
+	Content template.HTML
+}
+
+type navSection struct {
+	Title string
+	Items []navItem
+}
+
+type navItem struct {
+	Title      string
+	Href       string
+	Active     bool
+	Deprecated bool
+}
+
+// searchEntry is an entry of the search index.
+type searchEntry struct {
+	Title   string   `json:"title"`
+	Kind    string   `json:"kind"`
+	URL     string   `json:"url"`
+	Summary string   `json:"summary"`
+	Fields  []string `json:"fields"`
+}
+
+// generateHTML renders every page of the site as an HTML file together
+// with the assets and the search index.
+func generateHTML(s *site) (Output, error) {
+	output := Output{}
+
+	for _, p := range s.allPages() {
+		content, err := renderHTMLPage(s, p)
+		if err != nil {
+			return Output{}, fmt.Errorf("failed to render page %s: %w", p.Path, err)
+		}
+		output.Files = append(output.Files, OutputFile{Path: p.Path + ".html", Content: content})
+	}
+
+	searchIndex, err := json.Marshal(buildSearchIndex(s))
+	if err != nil {
+		return Output{}, fmt.Errorf("failed to marshal search index: %w", err)
+	}
+
+	output.Files = append(output.Files,
+		OutputFile{Path: "assets/style.css", Content: styleRawPiece},
+		OutputFile{Path: "assets/search.js", Content: searchRawPiece},
+		// The index is also loaded as a script because browsers block
+		// fetch requests to the file system
+		OutputFile{Path: "search-index.js", Content: "window.URPC_SEARCH_INDEX = " + string(searchIndex) + ";\n"},
+		OutputFile{Path: "search-index.json", Content: string(searchIndex) + "\n"},
+	)
+
+	return output, nil
+}
+
+// renderHTMLPage renders a page inside the layout.
+func renderHTMLPage(s *site, p *page) (string, error) {
+	content := &strings.Builder{}
+	if p.Kind == pageKindDoc {
+		content.WriteString(markdownToHTML(p.Doc))
+	} else {
+		fmt.Fprintf(content, "<h1>%s</h1>\n", template.HTMLEscapeString(p.Title))
+		if p.Deprecated != nil {
+			fmt.Fprintf(content, "<div class=\"deprecated\" role=\"note\"><strong>Deprecated:</strong> %s</div>\n", renderInline(deprecationMessage(p)))
+		}
+		content.WriteString(markdownToHTML(renderMarkdownBody(s, p, ".html")))
+	}
+
+	navItems := func(pages []*page) []navItem {
+		items := make([]navItem, 0, len(pages))
+		for _, target := range pages {
+			items = append(items, navItem{
+				Title:      target.Title,
+				Href:       relativeLink(p.Path, target.Path, ".html"),
+				Active:     target == p,
+				Deprecated: target.Deprecated != nil,
+			})
+		}
+		return items
+	}
+
+	nav := []navSection{{Title: "Overview", Items: navItems([]*page{s.Index, s.Envelope})}}
+	for _, section := range []navSection{
+		{Title: "Documentation", Items: navItems(s.Docs)},
+		{Title: "Procedures", Items: navItems(s.Procs)},
+		{Title: "Streams", Items: navItems(s.Streams)},
+		{Title: "Types", Items: navItems(s.Types)},
+	} {
+		if len(section.Items) > 0 {
+			nav = append(nav, section)
+		}
+	}
+
+	b := &strings.Builder{}
+	err := layoutTemplate.Execute(b, layoutData{
+		SiteTitle: s.Config.Title,
+		PageTitle: p.Title,
+		Root:      rootPrefix(p.Path),
+		Nav:       nav,
+		Content:   template.HTML(content.String()),
+	})
+	if err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// buildSearchIndex returns an entry for every page except the index.
+func buildSearchIndex(s *site) []searchEntry {
+	entries := []searchEntry{}
+	for _, p := range s.allPages() {
+		if p.Kind == pageKindIndex {
+			continue
+		}
+
+		summary := markdownToText(firstParagraph(p.Doc))
+		if p.Kind == pageKindEnvelope {
+			summary = "Request format, response envelope, errors and streams."
+		}
+
+		fields := []string{}
+		for _, section := range p.Sections {
+			for _, row := range section.Rows {
+				fields = append(fields, row.Path)
+			}
+		}
+
+		entries = append(entries, searchEntry{
+			Title:   p.Title,
+			Kind:    p.Kind,
+			URL:     p.Path + ".html",
+			Summary: summary,
+			Fields:  fields,
+		})
+	}
+	return entries
+}
+
+var htmlTagRegex = regexp.MustCompile(`<[^>]*>`)
+
+// markdownToText returns the plain text of inline markdown.
+func markdownToText(markdown string) string {
+	return html.UnescapeString(htmlTagRegex.ReplaceAllString(renderInline(markdown), ""))
+}
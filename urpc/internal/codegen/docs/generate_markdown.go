@@ -0,0 +1,225 @@
+package docs
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+)
+
+//go:embed pieces/envelope.md
+var envelopeRawPiece string
+
+// generateMarkdown renders every page of the site as a Markdown file.
+func generateMarkdown(s *site) Output {
+	output := Output{}
+	for _, p := range s.allPages() {
+		output.Files = append(output.Files, OutputFile{
+			Path:    p.Path + ".md",
+			Content: renderMarkdownPage(s, p, ".md"),
+		})
+	}
+	return output
+}
+
+// renderMarkdownPage renders a page as Markdown, links to other pages use
+// the given extension.
+func renderMarkdownPage(s *site, p *page, ext string) string {
+	if p.Kind == pageKindDoc {
+		return strings.TrimSpace(p.Doc) + "\n"
+	}
+
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "# %s\n\n", p.Title)
+	if p.Deprecated != nil {
+		fmt.Fprintf(b, "> **Deprecated:** %s\n\n", deprecationMessage(p))
+	}
+	b.WriteString(renderMarkdownBody(s, p, ext))
+	return b.String()
+}
+
+// renderMarkdownBody renders the content of a page after its title and
+// deprecation banner as Markdown.
+func renderMarkdownBody(s *site, p *page, ext string) string {
+	b := &strings.Builder{}
+	link := func(to *page) string {
+		return fmt.Sprintf("[%s](%s)", to.Title, relativeLink(p.Path, to.Path, ext))
+	}
+
+	switch p.Kind {
+	case pageKindDoc:
+		return strings.TrimSpace(p.Doc) + "\n"
+
+	case pageKindIndex:
+		fmt.Fprintf(b, "All requests and responses follow the same format, see %s.\n\n", link(s.Envelope))
+		writeMarkdownIndexList(b, "Documentation", s.Docs, link)
+		writeMarkdownIndexList(b, "Procedures", s.Procs, link)
+		writeMarkdownIndexList(b, "Streams", s.Streams, link)
+		writeMarkdownIndexList(b, "Types", s.Types, link)
+		return strings.TrimSpace(b.String()) + "\n"
+
+	case pageKindEnvelope:
+		return envelopeMarkdown(s.Config.BaseURL)
+	}
+
+	if doc := strings.TrimSpace(p.Doc); doc != "" {
+		b.WriteString(shiftHeadings(doc, 1))
+		b.WriteString("\n\n")
+	}
+
+	if p.Kind == pageKindProc || p.Kind == pageKindStream {
+		b.WriteString("## Request\n\n")
+		b.WriteString("```http\n")
+		b.WriteString(requestLine(s.Config.BaseURL, p))
+		b.WriteString("\n```\n\n")
+		fmt.Fprintf(b, "%s See %s.\n\n", requestDescription(p), link(s.Envelope))
+	}
+
+	for _, section := range p.Sections {
+		fmt.Fprintf(b, "## %s\n\n", section.Title)
+		if len(section.Rows) == 0 {
+			b.WriteString("No fields.\n\n")
+			continue
+		}
+
+		b.WriteString("| Field | Type | Required | Description |\n")
+		b.WriteString("| --- | --- | --- | --- |\n")
+		for _, row := range section.Rows {
+			typeCell := fmt.Sprintf("`%s`", row.Type)
+			if row.Type.Path != "" {
+				typeCell = fmt.Sprintf("[`%s`](%s)", row.Type, relativeLink(p.Path, row.Type.Path, ext))
+			}
+			required := "Yes"
+			if row.Optional {
+				required = "No"
+			}
+			fmt.Fprintf(b, "| `%s` | %s | %s | %s |\n", row.Path, typeCell, required, markdownTableCell(row.Doc))
+		}
+		b.WriteString("\n")
+	}
+
+	if len(p.UsedBy) > 0 {
+		b.WriteString("## Used by\n\n")
+		for _, usedBy := range p.UsedBy {
+			fmt.Fprintf(b, "- %s (%s)\n", link(usedBy), pageKindLabel(usedBy.Kind))
+		}
+		b.WriteString("\n")
+	}
+
+	return strings.TrimSpace(b.String()) + "\n"
+}
+
+// writeMarkdownIndexList writes a section of the index page with a link to
+// each page and its summary.
+func writeMarkdownIndexList(b *strings.Builder, title string, pages []*page, link func(*page) string) {
+	if len(pages) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "## %s\n\n", title)
+	for _, p := range pages {
+		fmt.Fprintf(b, "- %s", link(p))
+		if p.Deprecated != nil {
+			b.WriteString(" (deprecated)")
+		}
+		if summary := pageSummary(p); summary != "" {
+			fmt.Fprintf(b, ": %s", summary)
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+}
+
+// markdownTableCell escapes the text to be used inside a table cell.
+func markdownTableCell(text string) string {
+	text = strings.TrimSpace(text)
+	text = strings.ReplaceAll(text, "|", `\|`)
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	return strings.ReplaceAll(text, "\n", "<br>")
+}
+
+// shiftHeadings increases the level of the ATX headings outside code
+// blocks, so docstrings headings are nested inside the page heading.
+func shiftHeadings(markdown string, by int) string {
+	lines := strings.Split(markdown, "\n")
+	inCode := false
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inCode = !inCode
+			continue
+		}
+		if inCode || !strings.HasPrefix(line, "#") {
+			continue
+		}
+		level := len(line) - len(strings.TrimLeft(line, "#"))
+		if level > 6 || (len(line) > level && line[level] != ' ') {
+			continue
+		}
+		lines[i] = strings.Repeat("#", min(level+by, 6)) + line[level:]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// pageSummary returns the first paragraph of the documentation of the page.
+func pageSummary(p *page) string {
+	if p.Kind == pageKindDoc {
+		return ""
+	}
+	return firstParagraph(p.Doc)
+}
+
+// pageKindLabel returns the human readable kind of a page.
+func pageKindLabel(kind string) string {
+	switch kind {
+	case pageKindType:
+		return "type"
+	case pageKindProc:
+		return "procedure"
+	case pageKindStream:
+		return "stream"
+	default:
+		return "page"
+	}
+}
+
+// deprecationMessage returns the deprecation message of a page, or a
+// generic one if the schema has no message.
+func deprecationMessage(p *page) string {
+	if p.Deprecated != nil && strings.TrimSpace(*p.Deprecated) != "" {
+		return strings.TrimSpace(*p.Deprecated)
+	}
+	return fmt.Sprintf("This %s is deprecated and may be removed in a future version.", pageKindLabel(p.Kind))
+}
+
+// requestLine returns the HTTP request line and headers of a procedure or
+// stream.
+func requestLine(baseURL string, p *page) string {
+	line := fmt.Sprintf("POST %s/%s\nContent-Type: application/json", displayBaseURL(baseURL), p.Title)
+	if p.Kind == pageKindStream {
+		line += "\nAccept: text/event-stream"
+	}
+	return line
+}
+
+// requestDescription describes how the response of a procedure or stream
+// is delivered.
+func requestDescription(p *page) string {
+	if p.Kind == pageKindStream {
+		return "The input is sent as a JSON object in the request body. The server answers with Server-Sent Events, the data of each event is a response envelope with the output."
+	}
+	return "The input is sent as a JSON object in the request body. The server answers with a response envelope with the output."
+}
+
+// envelopeMarkdown documents the request and response format shared by all
+// procedures and streams.
+func envelopeMarkdown(baseURL string) string {
+	return strings.ReplaceAll(envelopeRawPiece, "{{ base_url }}", displayBaseURL(baseURL))
+}
+
+// displayBaseURL returns the base URL without the trailing slash, or a
+// placeholder if it is not configured.
+func displayBaseURL(baseURL string) string {
+	if baseURL == "" {
+		return "<base-url>"
+	}
+	return strings.TrimRight(baseURL, "/")
+}
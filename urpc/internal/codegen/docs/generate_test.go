@@ -0,0 +1,228 @@
+package docs
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/uforg/uforpc/urpc/internal/schema"
+)
+
+func testSchema(t *testing.T) schema.Schema {
+	t.Helper()
+
+	sch, err := schema.ParseSchema(`{
+		"version": 1,
+		"nodes": [
+			{"kind": "doc", "content": "# Getting started\n\nWelcome to the **API**."},
+			{"kind": "doc", "content": "# Getting started\n\nAgain."},
+			{
+				"kind": "type",
+				"name": "Address",
+				"doc": "Address of a user.\n\n# Format\n\nFree form.",
+				"fields": [
+					{"name": "street", "typeName": "string", "isArray": false, "optional": false},
+					{"name": "zip", "doc": "Postal | code\nof the address", "typeName": "int", "isArray": false, "optional": true}
+				]
+			},
+			{
+				"kind": "type",
+				"name": "User",
+				"deprecated": "",
+				"fields": [
+					{"name": "id", "typeName": "string", "isArray": false, "optional": false},
+					{"name": "addresses", "typeName": "Address", "isArray": true, "optional": false},
+					{
+						"name": "meta",
+						"typeInline": {"fields": [{"name": "source", "typeName": "string", "isArray": false, "optional": false}]},
+						"isArray": true,
+						"optional": true
+					}
+				]
+			},
+			{
+				"kind": "proc",
+				"name": "GetUser",
+				"doc": "Returns a user.",
+				"deprecated": "Use ` + "`GetUserV2`" + ` instead.",
+				"input": [{"name": "id", "typeName": "string", "isArray": false, "optional": false}],
+				"output": [{"name": "user", "typeName": "User", "isArray": false, "optional": false}]
+			},
+			{
+				"kind": "stream",
+				"name": "Ticks",
+				"input": [],
+				"output": [{"name": "value", "typeName": "int", "isArray": false, "optional": false}]
+			}
+		]
+	}`)
+	require.NoError(t, err)
+	return sch
+}
+
+func outputFiles(output Output) map[string]string {
+	files := map[string]string{}
+	for _, file := range output.Files {
+		files[file.Path] = file.Content
+	}
+	return files
+}
+
+func TestGenerateMarkdown(t *testing.T) {
+	output, err := Generate(testSchema(t), Config{OutputDir: "docs", Format: FormatMarkdown, BaseURL: "https://api.example.com/urpc/"})
+	require.NoError(t, err)
+	files := outputFiles(output)
+
+	paths := []string{}
+	for _, file := range output.Files {
+		paths = append(paths, file.Path)
+	}
+	require.Equal(t, []string{
+		"index.md",
+		"envelope.md",
+		"docs/getting-started.md",
+		"docs/getting-started-2.md",
+		"procedures/GetUser.md",
+		"streams/Ticks.md",
+		"types/Address.md",
+		"types/User.md",
+	}, paths)
+
+	t.Run("Index", func(t *testing.T) {
+		require.Equal(t, strings.Join([]string{
+			"# UFO RPC API",
+			"",
+			"All requests and responses follow the same format, see [Requests and responses](envelope.md).",
+			"",
+			"## Documentation",
+			"",
+			"- [Getting started](docs/getting-started.md)",
+			"- [Getting started](docs/getting-started-2.md)",
+			"",
+			"## Procedures",
+			"",
+			"- [GetUser](procedures/GetUser.md) (deprecated): Returns a user.",
+			"",
+			"## Streams",
+			"",
+			"- [Ticks](streams/Ticks.md)",
+			"",
+			"## Types",
+			"",
+			"- [Address](types/Address.md): Address of a user.",
+			"- [User](types/User.md) (deprecated)",
+			"",
+		}, "\n"), files["index.md"])
+	})
+
+	t.Run("Type", func(t *testing.T) {
+		require.Equal(t, strings.Join([]string{
+			"# Address",
+			"",
+			"Address of a user.",
+			"",
+			"## Format",
+			"",
+			"Free form.",
+			"",
+			"## Fields",
+			"",
+			"| Field | Type | Required | Description |",
+			"| --- | --- | --- | --- |",
+			"| `street` | `string` | Yes |  |",
+			"| `zip` | `int` | No | Postal \\| code<br>of the address |",
+			"",
+			"## Used by",
+			"",
+			"- [User](../types/User.md) (type)",
+			"",
+		}, "\n"), files["types/Address.md"])
+
+		require.Contains(t, files["types/User.md"], "> **Deprecated:** This type is deprecated and may be removed in a future version.")
+		require.Contains(t, files["types/User.md"], "| `addresses` | [`Address[]`](../types/Address.md) | Yes |  |")
+		require.Contains(t, files["types/User.md"], "| `meta` | `object[]` | No |  |\n| `meta[].source` | `string` | Yes |  |")
+	})
+
+	t.Run("Procedure", func(t *testing.T) {
+		content := files["procedures/GetUser.md"]
+		require.Contains(t, content, "# GetUser\n\n> **Deprecated:** Use `GetUserV2` instead.\n\nReturns a user.")
+		require.Contains(t, content, "```http\nPOST https://api.example.com/urpc/GetUser\nContent-Type: application/json\n```")
+		require.Contains(t, content, "See [Requests and responses](../envelope.md).")
+		require.Contains(t, content, "| `user` | [`User`](../types/User.md) | Yes |  |")
+	})
+
+	t.Run("Stream", func(t *testing.T) {
+		content := files["streams/Ticks.md"]
+		require.Contains(t, content, "Accept: text/event-stream")
+		require.Contains(t, content, "## Input\n\nNo fields.")
+	})
+
+	t.Run("Doc and envelope", func(t *testing.T) {
+		require.Equal(t, "# Getting started\n\nWelcome to the **API**.\n", files["docs/getting-started.md"])
+		require.Contains(t, files["envelope.md"], "POST https://api.example.com/urpc/<Name>")
+		require.Contains(t, files["envelope.md"], `"code": "MISSING_REQUIRED_FIELD"`)
+	})
+}
+
+func TestGenerateHTML(t *testing.T) {
+	output, err := Generate(testSchema(t), Config{OutputDir: "docs", Title: "Users <API>"})
+	require.NoError(t, err)
+	files := outputFiles(output)
+
+	require.Contains(t, files, "assets/style.css")
+	require.Contains(t, files, "assets/search.js")
+	require.Contains(t, files, "search-index.js")
+
+	t.Run("Layout", func(t *testing.T) {
+		content := files["procedures/GetUser.html"]
+		require.Contains(t, content, "<title>GetUser - Users &lt;API&gt;</title>")
+		require.Contains(t, content, `<link rel="stylesheet" href="../assets/style.css">`)
+		require.Contains(t, content, `<body data-root="../">`)
+		require.Contains(t, content, `<li><a href="../procedures/GetUser.html" class="active">GetUser</a> <span class="badge">deprecated</span></li>`)
+		require.Contains(t, content, `<script src="../search-index.js"></script>`)
+
+		require.Contains(t, files["index.html"], "<title>Users &lt;API&gt;</title>")
+		require.Contains(t, files["index.html"], `<link rel="stylesheet" href="assets/style.css">`)
+	})
+
+	t.Run("Content", func(t *testing.T) {
+		content := files["procedures/GetUser.html"]
+		require.Contains(t, content, "<h1>GetUser</h1>\n<div class=\"deprecated\" role=\"note\"><strong>Deprecated:</strong> Use <code>GetUserV2</code> instead.</div>")
+		require.Contains(t, content, `<td><a href="../types/User.html"><code>User</code></a></td>`)
+		require.Contains(t, content, `<a href="../envelope.html">Requests and responses</a>`)
+		require.Contains(t, content, "POST &lt;base-url&gt;/GetUser")
+
+		require.Contains(t, files["types/Address.html"], "<td>Postal | code<br>of the address</td>")
+		require.Contains(t, files["docs/getting-started.html"], "<h1 id=\"getting-started\">Getting started</h1>\n<p>Welcome to the <strong>API</strong>.</p>")
+	})
+
+	t.Run("Search index", func(t *testing.T) {
+		var entries []searchEntry
+		require.NoError(t, json.Unmarshal([]byte(files["search-index.json"]), &entries))
+		require.Len(t, entries, 7)
+		require.Contains(t, entries, searchEntry{
+			Title:   "Getting started",
+			Kind:    "doc",
+			URL:     "docs/getting-started.html",
+			Summary: "Welcome to the API.",
+			Fields:  []string{},
+		})
+		require.Contains(t, entries, searchEntry{
+			Title:   "User",
+			Kind:    "type",
+			URL:     "types/User.html",
+			Summary: "",
+			Fields:  []string{"id", "addresses", "meta", "meta[].source"},
+		})
+
+		require.True(t, strings.HasPrefix(files["search-index.js"], "window.URPC_SEARCH_INDEX = ["))
+	})
+}
+
+func TestConfigValidate(t *testing.T) {
+	require.NoError(t, Config{OutputDir: "docs"}.Validate())
+	require.NoError(t, Config{OutputDir: "docs", Format: FormatMarkdown}.Validate())
+	require.EqualError(t, Config{}.Validate(), `"output_dir" is required`)
+	require.EqualError(t, Config{OutputDir: "docs", Format: "pdf"}.Validate(), `"format" must be "html" or "markdown"`)
+}
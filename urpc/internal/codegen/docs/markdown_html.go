@@ -0,0 +1,431 @@
+package docs
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+)
+
+// markdownToHTML renders the subset of Markdown used in docstrings to HTML:
+// headings, paragraphs, fenced code blocks, lists, blockquotes, tables,
+// horizontal rules, emphasis, inline code, links and images.
+//
+// Raw HTML is escaped, except for <br> which is used to break lines inside
+// table cells.
+func markdownToHTML(markdown string) string {
+	markdown = strings.ReplaceAll(markdown, "\r\n", "\n")
+	lines := strings.Split(markdown, "\n")
+
+	b := &strings.Builder{}
+	renderBlocks(b, lines)
+	return b.String()
+}
+
+var (
+	headingRegex        = regexp.MustCompile(`^ {0,3}(#{1,6})(?:\s+(.*?))?\s*#*\s*$`)
+	hrRegex             = regexp.MustCompile(`^ {0,3}((\*\s*){3,}|(-\s*){3,}|(_\s*){3,})$`)
+	fenceRegex          = regexp.MustCompile("^( {0,3})(`{3,}|~{3,})\\s*([^`\\s]*)")
+	listItemRegex       = regexp.MustCompile(`^( *)([-*+]|\d{1,9}[.)])( +|$)`)
+	tableSeparatorRegex = regexp.MustCompile(`^\s*\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?\s*$`)
+)
+
+// renderBlocks renders the block level elements of the lines.
+func renderBlocks(b *strings.Builder, lines []string) {
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case trimmed == "":
+			i++
+
+		case fenceRegex.MatchString(line):
+			i = renderFence(b, lines, i)
+
+		case headingRegex.MatchString(line):
+			match := headingRegex.FindStringSubmatch(line)
+			level := len(match[1])
+			text := strings.TrimSpace(match[2])
+			fmt.Fprintf(b, "<h%d id=\"%s\">%s</h%d>\n", level, html.EscapeString(slugify(text)), renderInline(text), level)
+			i++
+
+		case hrRegex.MatchString(line):
+			b.WriteString("<hr>\n")
+			i++
+
+		case strings.HasPrefix(trimmed, ">"):
+			quoted := []string{}
+			for i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), ">") {
+				content := strings.TrimPrefix(strings.TrimSpace(lines[i]), ">")
+				quoted = append(quoted, strings.TrimPrefix(content, " "))
+				i++
+			}
+			b.WriteString("<blockquote>\n")
+			renderBlocks(b, quoted)
+			b.WriteString("</blockquote>\n")
+
+		case listItemRegex.MatchString(line):
+			i = renderList(b, lines, i)
+
+		case i+1 < len(lines) && strings.Contains(line, "|") && tableSeparatorRegex.MatchString(lines[i+1]):
+			i = renderTable(b, lines, i)
+
+		default:
+			paragraph := []string{}
+			for i < len(lines) && strings.TrimSpace(lines[i]) != "" && (len(paragraph) == 0 || !startsBlock(lines[i])) {
+				paragraph = append(paragraph, strings.TrimSpace(lines[i]))
+				i++
+			}
+			fmt.Fprintf(b, "<p>%s</p>\n", renderInline(strings.Join(paragraph, "\n")))
+		}
+	}
+}
+
+// startsBlock returns true if the line interrupts a paragraph.
+func startsBlock(line string) bool {
+	return fenceRegex.MatchString(line) ||
+		headingRegex.MatchString(line) ||
+		hrRegex.MatchString(line) ||
+		strings.HasPrefix(strings.TrimSpace(line), ">") ||
+		listItemRegex.MatchString(line)
+}
+
+// renderFence renders the fenced code block starting at lines[start] and
+// returns the index of the line after it.
+func renderFence(b *strings.Builder, lines []string, start int) int {
+	match := fenceRegex.FindStringSubmatch(lines[start])
+	indent, fence, lang := len(match[1]), match[2], match[3]
+
+	code := []string{}
+	i := start + 1
+	for ; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" {
+			i++
+			break
+		}
+		line := lines[i]
+		for j := 0; j < indent && strings.HasPrefix(line, " "); j++ {
+			line = line[1:]
+		}
+		code = append(code, line)
+	}
+
+	if lang != "" {
+		fmt.Fprintf(b, "<pre><code class=\"language-%s\">", html.EscapeString(lang))
+	} else {
+		b.WriteString("<pre><code>")
+	}
+	b.WriteString(html.EscapeString(strings.Join(code, "\n")))
+	b.WriteString("</code></pre>\n")
+	return i
+}
+
+// renderList renders the list starting at lines[start] and returns the
+// index of the line after it. Lines indented under an item belong to it,
+// so nested lists are rendered recursively.
+func renderList(b *strings.Builder, lines []string, start int) int {
+	first := listItemRegex.FindStringSubmatch(lines[start])
+	baseIndent := len(first[1])
+	ordered := first[2] != "-" && first[2] != "*" && first[2] != "+"
+
+	if ordered {
+		b.WriteString("<ol>\n")
+	} else {
+		b.WriteString("<ul>\n")
+	}
+
+	i := start
+	for i < len(lines) {
+		match := listItemRegex.FindStringSubmatch(lines[i])
+		if match == nil || len(match[1]) != baseIndent {
+			break
+		}
+		itemOrdered := match[2] != "-" && match[2] != "*" && match[2] != "+"
+		if itemOrdered != ordered {
+			break
+		}
+
+		contentIndent := len(match[0])
+		item := []string{lines[i][len(match[0]):]}
+		i++
+
+		for i < len(lines) {
+			line := lines[i]
+			if strings.TrimSpace(line) == "" {
+				// A blank line only continues the item if an indented line
+				// follows it
+				if i+1 < len(lines) && indentation(lines[i+1]) > baseIndent && strings.TrimSpace(lines[i+1]) != "" {
+					item = append(item, "")
+					i++
+					continue
+				}
+				break
+			}
+			if indentation(line) <= baseIndent && startsBlock(line) {
+				break
+			}
+			item = append(item, dedent(line, contentIndent))
+			i++
+		}
+
+		inner := &strings.Builder{}
+		renderBlocks(inner, item)
+		content := strings.TrimSpace(inner.String())
+		// Tight items are rendered without the paragraph
+		if strings.HasPrefix(content, "<p>") && strings.Count(content, "<p>") == 1 {
+			content = strings.Replace(content, "<p>", "", 1)
+			content = strings.Replace(content, "</p>", "", 1)
+		}
+		fmt.Fprintf(b, "<li>%s</li>\n", content)
+
+		// Skip the blank lines between items of the same list
+		next := i
+		for next < len(lines) && strings.TrimSpace(lines[next]) == "" {
+			next++
+		}
+		if next < len(lines) && next != i {
+			if match := listItemRegex.FindStringSubmatch(lines[next]); match != nil && len(match[1]) == baseIndent {
+				i = next
+			}
+		}
+	}
+
+	if ordered {
+		b.WriteString("</ol>\n")
+	} else {
+		b.WriteString("</ul>\n")
+	}
+	return i
+}
+
+// renderTable renders the table starting at lines[start] and returns the
+// index of the line after it.
+func renderTable(b *strings.Builder, lines []string, start int) int {
+	header := splitTableRow(lines[start])
+	b.WriteString("<table>\n<thead>\n<tr>")
+	for _, cell := range header {
+		fmt.Fprintf(b, "<th>%s</th>", renderInline(cell))
+	}
+	b.WriteString("</tr>\n</thead>\n<tbody>\n")
+
+	i := start + 2
+	for ; i < len(lines) && strings.TrimSpace(lines[i]) != "" && strings.Contains(lines[i], "|"); i++ {
+		cells := splitTableRow(lines[i])
+		b.WriteString("<tr>")
+		for j := range header {
+			cell := ""
+			if j < len(cells) {
+				cell = cells[j]
+			}
+			fmt.Fprintf(b, "<td>%s</td>", renderInline(cell))
+		}
+		b.WriteString("</tr>\n")
+	}
+
+	b.WriteString("</tbody>\n</table>\n")
+	return i
+}
+
+// splitTableRow returns the cells of a table row, escaped pipes and pipes
+// inside code spans are kept in the cell.
+func splitTableRow(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, `\|`) {
+		line = strings.TrimSuffix(line, "|")
+	}
+
+	cells := []string{}
+	current := &strings.Builder{}
+	inCode := false
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '\\' && i+1 < len(line) && line[i+1] == '|':
+			current.WriteByte('|')
+			i++
+		case line[i] == '`':
+			inCode = !inCode
+			current.WriteByte('`')
+		case line[i] == '|' && !inCode:
+			cells = append(cells, strings.TrimSpace(current.String()))
+			current.Reset()
+		default:
+			current.WriteByte(line[i])
+		}
+	}
+	return append(cells, strings.TrimSpace(current.String()))
+}
+
+// renderInline renders the inline elements of the text.
+func renderInline(text string) string {
+	b := &strings.Builder{}
+	for i := 0; i < len(text); {
+		rest := text[i:]
+
+		switch {
+		case rest[0] == '\\' && len(rest) > 1 && strings.ContainsRune("\\`*_[]()#+-.!|<>", rune(rest[1])):
+			b.WriteString(html.EscapeString(rest[1:2]))
+			i += 2
+
+		case rest[0] == '`':
+			ticks := len(rest) - len(strings.TrimLeft(rest, "`"))
+			end := strings.Index(rest[ticks:], rest[:ticks])
+			if end < 0 {
+				b.WriteString(rest[:ticks])
+				i += ticks
+				continue
+			}
+			code := strings.TrimSpace(rest[ticks : ticks+end])
+			fmt.Fprintf(b, "<code>%s</code>", html.EscapeString(code))
+			i += ticks + end + ticks
+
+		case strings.HasPrefix(rest, "<br>"), strings.HasPrefix(rest, "<br/>"), strings.HasPrefix(rest, "<br />"):
+			b.WriteString("<br>")
+			i += strings.Index(rest, ">") + 1
+
+		case rest[0] == '<' && autolinkRegex.MatchString(rest):
+			url := autolinkRegex.FindStringSubmatch(rest)[1]
+			fmt.Fprintf(b, "<a href=\"%s\">%s</a>", html.EscapeString(url), html.EscapeString(url))
+			i += len(url) + 2
+
+		case strings.HasPrefix(rest, "!["), rest[0] == '[':
+			isImage := rest[0] == '!'
+			label, url, length, ok := parseLink(rest[boolToInt(isImage):])
+			if !ok {
+				b.WriteString(html.EscapeString(rest[:1]))
+				i++
+				continue
+			}
+			if isImage {
+				fmt.Fprintf(b, "<img src=\"%s\" alt=\"%s\">", html.EscapeString(safeURL(url)), html.EscapeString(label))
+			} else {
+				fmt.Fprintf(b, "<a href=\"%s\">%s</a>", html.EscapeString(safeURL(url)), renderInline(label))
+			}
+			i += boolToInt(isImage) + length
+
+		case strings.HasPrefix(rest, "**"), strings.HasPrefix(rest, "__"):
+			end := strings.Index(rest[2:], rest[:2])
+			if end <= 0 {
+				b.WriteString(html.EscapeString(rest[:2]))
+				i += 2
+				continue
+			}
+			fmt.Fprintf(b, "<strong>%s</strong>", renderInline(rest[2:2+end]))
+			i += 2 + end + 2
+
+		case rest[0] == '*' || (rest[0] == '_' && (i == 0 || !isWordByte(text[i-1]))):
+			end := strings.IndexByte(rest[1:], rest[0])
+			if end <= 0 || rest[1] == ' ' || (rest[0] == '_' && 1+end+1 < len(rest) && isWordByte(rest[1+end+1])) {
+				b.WriteString(html.EscapeString(rest[:1]))
+				i++
+				continue
+			}
+			fmt.Fprintf(b, "<em>%s</em>", renderInline(rest[1:1+end]))
+			i += 1 + end + 1
+
+		default:
+			b.WriteString(html.EscapeString(rest[:1]))
+			i++
+		}
+	}
+	return b.String()
+}
+
+var autolinkRegex = regexp.MustCompile(`^<((?:https?|mailto):[^\s<>]+)>`)
+
+// parseLink parses a [label](url) link at the start of text and returns
+// the label, the url and the length of the link.
+func parseLink(text string) (string, string, int, bool) {
+	depth := 0
+	labelEnd := -1
+	for i := 0; i < len(text) && labelEnd < 0; i++ {
+		switch text[i] {
+		case '\\':
+			i++
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				labelEnd = i
+			}
+		}
+	}
+	if labelEnd < 0 || labelEnd+1 >= len(text) || text[labelEnd+1] != '(' {
+		return "", "", 0, false
+	}
+
+	urlEnd := strings.IndexByte(text[labelEnd+2:], ')')
+	if urlEnd < 0 {
+		return "", "", 0, false
+	}
+	target := strings.TrimSpace(text[labelEnd+2 : labelEnd+2+urlEnd])
+	// Titles are ignored
+	if url, _, found := strings.Cut(target, " "); found {
+		target = url
+	}
+	target = strings.TrimSuffix(strings.TrimPrefix(target, "<"), ">")
+
+	return text[1:labelEnd], target, labelEnd + 2 + urlEnd + 1, true
+}
+
+// safeURL returns the url if it is relative or uses a safe scheme,
+// otherwise it returns "#" to avoid script injection.
+func safeURL(url string) string {
+	lower := strings.ToLower(strings.TrimSpace(url))
+	scheme, _, hasScheme := strings.Cut(lower, ":")
+	if !hasScheme || strings.ContainsAny(scheme, "/?#") {
+		return url
+	}
+	switch scheme {
+	case "http", "https", "mailto":
+		return url
+	default:
+		return "#"
+	}
+}
+
+// indentation returns the number of leading spaces of the line, tabs count
+// as four spaces.
+func indentation(line string) int {
+	count := 0
+	for _, r := range line {
+		switch r {
+		case ' ':
+			count++
+		case '\t':
+			count += 4
+		default:
+			return count
+		}
+	}
+	return count
+}
+
+// dedent removes up to n leading spaces from the line.
+func dedent(line string, n int) string {
+	for i := 0; i < n && len(line) > 0; i++ {
+		switch line[0] {
+		case ' ':
+			line = line[1:]
+		case '\t':
+			return line[1:]
+		default:
+			return line
+		}
+	}
+	return line
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9')
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,86 @@
+package docs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarkdownToHTML(t *testing.T) {
+	testCases := []struct {
+		name     string
+		markdown string
+		expected string
+	}{
+		{
+			name:     "Paragraphs",
+			markdown: "First line\nsame paragraph\n\nSecond paragraph",
+			expected: "<p>First line\nsame paragraph</p>\n<p>Second paragraph</p>\n",
+		},
+		{
+			name:     "Headings",
+			markdown: "# Title\n### Sub *title* ###\n#hashtag",
+			expected: "<h1 id=\"title\">Title</h1>\n<h3 id=\"sub-title\">Sub <em>title</em></h3>\n<p>#hashtag</p>\n",
+		},
+		{
+			name:     "Fenced code",
+			markdown: "```go\nfunc main() {\n\tfmt.Println(\"<hi>\")\n}\n```\nafter",
+			expected: "<pre><code class=\"language-go\">func main() {\n\tfmt.Println(&#34;&lt;hi&gt;&#34;)\n}</code></pre>\n<p>after</p>\n",
+		},
+		{
+			name:     "Unclosed fence",
+			markdown: "~~~\ncode",
+			expected: "<pre><code>code</code></pre>\n",
+		},
+		{
+			name:     "Lists",
+			markdown: "- one\n- two\n  - nested\n\n1. first\n2. second",
+			expected: "<ul>\n<li>one</li>\n<li>two\n<ul>\n<li>nested</li>\n</ul></li>\n</ul>\n<ol>\n<li>first</li>\n<li>second</li>\n</ol>\n",
+		},
+		{
+			name:     "Blockquote and rule",
+			markdown: "> quoted **text**\n\n---",
+			expected: "<blockquote>\n<p>quoted <strong>text</strong></p>\n</blockquote>\n<hr>\n",
+		},
+		{
+			name:     "Table",
+			markdown: "| A | B |\n| --- | :-: |\n| `a|b` | x \\| y<br>z |\n| only |",
+			expected: "<table>\n<thead>\n<tr><th>A</th><th>B</th></tr>\n</thead>\n<tbody>\n" +
+				"<tr><td><code>a|b</code></td><td>x | y<br>z</td></tr>\n" +
+				"<tr><td>only</td><td></td></tr>\n" +
+				"</tbody>\n</table>\n",
+		},
+		{
+			name:     "Inline elements",
+			markdown: "**bold** __bold__ *em* _em_ snake_case_name `code` [link](./a.md \"title\") ![img](i.png) <https://example.com>",
+			expected: "<p><strong>bold</strong> <strong>bold</strong> <em>em</em> <em>em</em> snake_case_name <code>code</code> " +
+				"<a href=\"./a.md\">link</a> <img src=\"i.png\" alt=\"img\"> <a href=\"https://example.com\">https://example.com</a></p>\n",
+		},
+		{
+			name:     "HTML is escaped",
+			markdown: "<script>alert(1)</script> & [x](javascript:alert(1))",
+			expected: "<p>&lt;script&gt;alert(1)&lt;/script&gt; &amp; <a href=\"#\">x</a>)</p>\n",
+		},
+		{
+			name:     "Escapes and unclosed markers",
+			markdown: "\\*not em\\* 2 * 3 `open [open",
+			expected: "<p>*not em* 2 * 3 `open [open</p>\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, markdownToHTML(tc.markdown))
+		})
+	}
+}
+
+func TestShiftHeadings(t *testing.T) {
+	input := "# One\n## Two\n```\n# not a heading\n```\n###### Six\n#tag"
+	expected := "## One\n### Two\n```\n# not a heading\n```\n###### Six\n#tag"
+	require.Equal(t, expected, shiftHeadings(input, 1))
+}
+
+func TestMarkdownToText(t *testing.T) {
+	require.Equal(t, "Use GetUser to fetch users & more.", markdownToText("Use `GetUser` to fetch [users](./u.md) & **more**."))
+}
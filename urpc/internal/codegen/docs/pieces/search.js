@@ -0,0 +1,74 @@
+// Searches the pages of the documentation using the index loaded by
+// search-index.js, it works offline and from the file system.
+(function () {
+  const input = document.getElementById("search");
+  const results = document.getElementById("search-results");
+  const root = document.body.dataset.root || "";
+  const index = window.URPC_SEARCH_INDEX || [];
+
+  const kindLabels = {
+    doc: "Documentation",
+    proc: "Procedure",
+    stream: "Stream",
+    type: "Type",
+    envelope: "Documentation",
+  };
+
+  function search(query) {
+    const terms = query.toLowerCase().split(/\s+/).filter(Boolean);
+    if (terms.length === 0) return [];
+
+    const scored = [];
+    for (const entry of index) {
+      const title = entry.title.toLowerCase();
+      const text = [entry.title, entry.summary, ...entry.fields]
+        .join(" ")
+        .toLowerCase();
+      if (!terms.every((term) => text.includes(term))) continue;
+
+      let score = 0;
+      for (const term of terms) {
+        if (title === term) score += 10;
+        else if (title.startsWith(term)) score += 5;
+        else if (title.includes(term)) score += 3;
+        else score += 1;
+      }
+      scored.push({ entry, score });
+    }
+
+    scored.sort((a, b) => b.score - a.score || a.entry.title.localeCompare(b.entry.title));
+    return scored.slice(0, 20).map((item) => item.entry);
+  }
+
+  function render(entries) {
+    results.replaceChildren();
+    for (const entry of entries) {
+      const item = document.createElement("li");
+      const link = document.createElement("a");
+      link.href = root + entry.url;
+      link.textContent = entry.title;
+      const kind = document.createElement("small");
+      kind.textContent = kindLabels[entry.kind] || entry.kind;
+      item.append(link, kind);
+      results.append(item);
+    }
+    results.hidden = input.value.trim() === "";
+    if (!results.hidden && entries.length === 0) {
+      const item = document.createElement("li");
+      item.textContent = "No results";
+      results.append(item);
+    }
+  }
+
+  input.addEventListener("input", () => render(search(input.value)));
+  input.addEventListener("keydown", (event) => {
+    if (event.key === "Enter") {
+      const first = results.querySelector("a");
+      if (first) window.location.href = first.href;
+    }
+    if (event.key === "Escape") {
+      input.value = "";
+      render([]);
+    }
+  });
+})();
@@ -0,0 +1,123 @@
+package codegen
+
+import (
+	"fmt"
+
+	"github.com/uforg/uforpc/urpc/internal/codegen/asyncapi"
+	"github.com/uforg/uforpc/urpc/internal/codegen/dart"
+	"github.com/uforg/uforpc/urpc/internal/codegen/docs"
+	"github.com/uforg/uforpc/urpc/internal/codegen/golang"
+	"github.com/uforg/uforpc/urpc/internal/codegen/jsonschema"
+	"github.com/uforg/uforpc/urpc/internal/codegen/openapi"
+	"github.com/uforg/uforpc/urpc/internal/codegen/outfs"
+	"github.com/uforg/uforpc/urpc/internal/codegen/playground"
+	"github.com/uforg/uforpc/urpc/internal/codegen/template"
+	"github.com/uforg/uforpc/urpc/internal/codegen/typescript"
+	"github.com/uforg/uforpc/urpc/internal/schema"
+	"github.com/uforg/uforpc/urpc/internal/urpc/ast"
+)
+
+// TemplateSource is the config of the "template" generator of
+// GenerateInMemory, the template text is given instead of read from disk.
+// The Template path of the config is only used to name the template in the
+// errors and defaults to "template".
+type TemplateSource struct {
+	Config template.Config
+	Text   string
+}
+
+// GenerateInMemory validates the config and runs a single generator without
+// writing its files, the file names are relative to the current directory
+// like the output paths of the config.
+//
+// The generator is the name used in the config file, e.g. "golang-server",
+// and the config must be its type: openapi.Config, playground.Config,
+// asyncapi.Config, golang.Config, typescript.Config, dart.Config,
+// docs.Config, jsonschema.Config or TemplateSource. Plugins are not
+// supported.
+func GenerateInMemory(generator string, config any, astSchema *ast.Schema, jsonSchema schema.Schema) ([]outfs.File, error) {
+	h, err := newHeader(jsonSchema)
+	if err != nil {
+		return nil, err
+	}
+	out := newOutput(".", h)
+
+	mismatch := fmt.Errorf("invalid config type %T for the %s generator", config, generator)
+	switch generator {
+	case "openapi":
+		cfg, ok := config.(openapi.Config)
+		if !ok {
+			return nil, mismatch
+		}
+		err = validateAndRun(cfg, func() error { return runOpenAPI(out, cfg, jsonSchema) })
+	case "playground":
+		cfg, ok := config.(playground.Config)
+		if !ok {
+			return nil, mismatch
+		}
+		err = validateAndRun(cfg, func() error { return runPlayground(out, &cfg, openapi.Config{}, astSchema, jsonSchema) })
+	case "asyncapi":
+		cfg, ok := config.(asyncapi.Config)
+		if !ok {
+			return nil, mismatch
+		}
+		err = validateAndRun(cfg, func() error { return runAsyncAPI(out, &cfg, jsonSchema) })
+	case "golang-server", "golang-client":
+		cfg, ok := config.(golang.Config)
+		if !ok {
+			return nil, mismatch
+		}
+		cfg.IncludeServer = generator == "golang-server"
+		cfg.IncludeClient = generator == "golang-client"
+		err = validateAndRun(cfg, func() error { return runGolang(out, &cfg, jsonSchema) })
+	case "typescript-client":
+		cfg, ok := config.(typescript.Config)
+		if !ok {
+			return nil, mismatch
+		}
+		cfg.IncludeServer = false
+		cfg.IncludeClient = true
+		err = validateAndRun(cfg, func() error { return runTypescript(out, &cfg, jsonSchema) })
+	case "dart-client":
+		cfg, ok := config.(dart.Config)
+		if !ok {
+			return nil, mismatch
+		}
+		err = validateAndRun(cfg, func() error { return runDart(out, &cfg, jsonSchema) })
+	case "docs":
+		cfg, ok := config.(docs.Config)
+		if !ok {
+			return nil, mismatch
+		}
+		err = validateAndRun(cfg, func() error { return runDocs(out, &cfg, jsonSchema) })
+	case "jsonschema":
+		cfg, ok := config.(jsonschema.Config)
+		if !ok {
+			return nil, mismatch
+		}
+		err = validateAndRun(cfg, func() error { return runJSONSchema(out, &cfg, jsonSchema) })
+	case "template":
+		src, ok := config.(TemplateSource)
+		if !ok {
+			return nil, mismatch
+		}
+		if src.Config.Template == "" {
+			src.Config.Template = "template"
+		}
+		err = validateAndRun(src.Config, func() error { return runTemplateText(out, &src.Config, src.Text, jsonSchema) })
+	default:
+		return nil, fmt.Errorf("unsupported generator: %s", generator)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to run %s code generator: %w", generator, err)
+	}
+
+	return out.fs.Files(), nil
+}
+
+func validateAndRun(config interface{ Validate() error }, run func() error) error {
+	if err := config.Validate(); err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+	return run()
+}
@@ -0,0 +1,109 @@
+package codegen
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/uforg/uforpc/urpc/internal/schema"
+	"github.com/uforg/uforpc/urpc/internal/version"
+)
+
+// header identifies the urpc version and the schema used to generate a
+// file, it is written as a comment in the generated code so `urpc generate
+// --check` can tell why a file is stale.
+type header struct {
+	Version    string
+	SchemaHash string
+}
+
+// headerRegex matches the header in any comment syntax.
+var headerRegex = regexp.MustCompile(`Generated by urpc (\S+) from schema sha256:([0-9a-f]+)`)
+
+// newHeader returns the header of the files generated by this version of
+// urpc from the schema. The hash is computed from the JSON representation
+// of the schema, so formatting changes do not make the files stale.
+func newHeader(sch schema.Schema) (header, error) {
+	schemaBytes, err := json.Marshal(sch)
+	if err != nil {
+		return header{}, fmt.Errorf("failed to encode schema: %w", err)
+	}
+	sum := sha256.Sum256(schemaBytes)
+	return header{
+		Version:    version.VersionWithPrefix,
+		SchemaHash: fmt.Sprintf("%x", sum[:8]),
+	}, nil
+}
+
+func (h header) String() string {
+	return fmt.Sprintf("Generated by urpc %s from schema sha256:%s", h.Version, h.SchemaHash)
+}
+
+// parseHeader returns the header found in the first lines of the content.
+func parseHeader(content []byte) (header, bool) {
+	lines := strings.SplitN(string(content), "\n", 6)
+	for _, line := range lines[:min(len(lines), 5)] {
+		if match := headerRegex.FindStringSubmatch(line); match != nil {
+			return header{Version: match[1], SchemaHash: match[2]}, true
+		}
+	}
+	return header{}, false
+}
+
+// commentFormats are the line comment formats of the file extensions that
+// can carry a header, other files are generated without it.
+var commentFormats = map[string]string{
+	".go":   "// %s",
+	".ts":   "// %s",
+	".dart": "// %s",
+	".yaml": "# %s",
+	".yml":  "# %s",
+}
+
+// addHeader adds the header as a comment to the content of the file at
+// path. It is placed after the "Code generated" line when there is one, so
+// that line keeps being the first of the file.
+func (h header) addHeader(path string, content string) string {
+	format, ok := commentFormats[filepath.Ext(path)]
+	if !ok {
+		return content
+	}
+	comment := fmt.Sprintf(format, h) + "\n"
+
+	firstLine, rest, found := strings.Cut(content, "\n")
+	if found && strings.Contains(firstLine, "Code generated") {
+		return firstLine + "\n" + comment + rest
+	}
+	return comment + content
+}
+
+// staleReason explains why the current content of a file differs from the
+// generated one.
+func (h header) staleReason(current []byte) string {
+	previous, ok := parseHeader(current)
+	switch {
+	case !ok:
+		return "content differs"
+	case previous.Version != h.Version:
+		return fmt.Sprintf("generated by urpc %s, the current version is %s", previous.Version, h.Version)
+	case previous.SchemaHash != h.SchemaHash:
+		return fmt.Sprintf("generated from schema sha256:%s, the current schema is sha256:%s", previous.SchemaHash, h.SchemaHash)
+	default:
+		return "content differs, the config or the file was changed"
+	}
+}
+
+// addJSONComment adds the header as the "$comment" keyword of a JSON Schema
+// document, JSON has no comments. The content must be an indented JSON
+// object.
+func (h header) addJSONComment(content string) string {
+	rest, ok := strings.CutPrefix(content, "{\n")
+	if !ok {
+		return content
+	}
+	comment, _ := json.Marshal(h.String())
+	return fmt.Sprintf("{\n  \"$comment\": %s,\n%s", comment, rest)
+}
@@ -0,0 +1,34 @@
+package codegen
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/uforg/uforpc/urpc/internal/schema"
+)
+
+func TestHeader(t *testing.T) {
+	h, err := newHeader(schema.Schema{Version: 1})
+	require.NoError(t, err)
+	require.Regexp(t, `^[0-9a-f]{16}$`, h.SchemaHash)
+
+	other, err := newHeader(schema.Schema{Version: 1, Nodes: []schema.Node{&schema.NodeType{Name: "User"}}})
+	require.NoError(t, err)
+	require.NotEqual(t, h.SchemaHash, other.SchemaHash)
+
+	h = header{Version: "v1.0.0", SchemaHash: "abc123"}
+	require.Equal(t, "// Code generated by UFO RPC. DO NOT EDIT.\n// Generated by urpc v1.0.0 from schema sha256:abc123\npackage a\n", h.addHeader("a/client.go", "// Code generated by UFO RPC. DO NOT EDIT.\npackage a\n"))
+	require.Equal(t, "# Generated by urpc v1.0.0 from schema sha256:abc123\nopenapi: 3.0.0\n", h.addHeader("openapi.yaml", "openapi: 3.0.0\n"))
+	require.Equal(t, "{}\n", h.addHeader("openapi.json", "{}\n"))
+
+	parsed, ok := parseHeader([]byte(h.addHeader("client.ts", "export {}\n")))
+	require.True(t, ok)
+	require.Equal(t, h, parsed)
+	_, ok = parseHeader([]byte("export {}\n"))
+	require.False(t, ok)
+
+	require.Equal(t, "content differs", h.staleReason([]byte("package a\n")))
+	require.Equal(t, "generated by urpc v0.9.0, the current version is v1.0.0", h.staleReason([]byte("// Generated by urpc v0.9.0 from schema sha256:abc123\n")))
+	require.Equal(t, "generated from schema sha256:def456, the current schema is sha256:abc123", h.staleReason([]byte("// Generated by urpc v1.0.0 from schema sha256:def456\n")))
+	require.Equal(t, "content differs, the config or the file was changed", h.staleReason([]byte("// Generated by urpc v1.0.0 from schema sha256:abc123\n")))
+}
@@ -0,0 +1,42 @@
+package jsonschema
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Config is the configuration for the JSON Schema generator.
+type Config struct {
+	// OutputFile is the file to output a single document with every
+	// definition in "$defs" to.
+	OutputFile string `toml:"output_file"`
+	// OutputDir is the directory to output one document per definition to,
+	// named after the definition, e.g. "User.json". It is mutually exclusive
+	// with OutputFile. The documents generated by a previous run for removed
+	// definitions are deleted, other files in the directory are kept.
+	OutputDir string `toml:"output_dir"`
+	// BaseID is the absolute URI the "$id" of the documents is resolved
+	// against, e.g. "https://example.com/schemas/". The documents have no
+	// "$id" when it is empty.
+	BaseID string `toml:"base_id"`
+}
+
+func (c Config) Validate() error {
+	if c.OutputFile == "" && c.OutputDir == "" {
+		return fmt.Errorf(`"output_file" or "output_dir" is required`)
+	}
+	if c.OutputFile != "" && c.OutputDir != "" {
+		return fmt.Errorf(`"output_file" and "output_dir" cannot be used together`)
+	}
+	if c.OutputFile != "" && !strings.HasSuffix(c.OutputFile, ".json") {
+		return fmt.Errorf(`"output_file" must end with ".json"`)
+	}
+	if c.BaseID != "" {
+		u, err := url.Parse(c.BaseID)
+		if err != nil || !u.IsAbs() {
+			return fmt.Errorf(`"base_id" must be an absolute URI, e.g. "https://example.com/schemas/"`)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,244 @@
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/uforg/uforpc/urpc/internal/schema"
+	"github.com/uforg/uforpc/urpc/internal/urpc/ast"
+	"github.com/uforg/uforpc/urpc/internal/util/strutil"
+)
+
+// Draft is the JSON Schema dialect of the generated documents.
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// OutputFile represents a single generated file.
+type OutputFile struct {
+	// Path is the output path of the file, relative to the config file.
+	Path    string `json:"path"`
+	Content string `json:"content"`
+}
+
+// Output represents the generated JSON Schema documents.
+type Output struct {
+	Files []OutputFile `json:"files"`
+}
+
+// Generate takes a schema and a config and generates the JSON Schema
+// documents of the types and of the inputs and outputs of the procedures
+// and streams, named <Name>Input and <Name>Output.
+func Generate(sch schema.Schema, config Config) (Output, error) {
+	g := generator{config: config, defs: map[string]map[string]any{}, owners: map[string]string{}}
+
+	for _, typeNode := range sch.GetTypeNodes() {
+		def := g.object(typeNode.Fields)
+		setDescription(def, typeNode.Doc, typeNode.Deprecated)
+		if err := g.add(typeNode.Name, "type "+typeNode.Name, def); err != nil {
+			return Output{}, err
+		}
+	}
+	for _, procNode := range sch.GetProcNodes() {
+		if err := g.addInputOutput("procedure", procNode.Name, procNode.Input, procNode.Output, procNode.Deprecated); err != nil {
+			return Output{}, err
+		}
+	}
+	for _, streamNode := range sch.GetStreamNodes() {
+		if err := g.addInputOutput("stream", streamNode.Name, streamNode.Input, streamNode.Output, streamNode.Deprecated); err != nil {
+			return Output{}, err
+		}
+	}
+
+	if config.OutputFile != "" {
+		doc := document{Schema: Draft, Defs: g.defs}
+		if config.BaseID != "" {
+			doc.ID = g.id(path.Base(config.OutputFile))
+		}
+		content, err := encode(doc, nil)
+		if err != nil {
+			return Output{}, err
+		}
+		return Output{Files: []OutputFile{{Path: config.OutputFile, Content: content}}}, nil
+	}
+
+	output := Output{Files: []OutputFile{}}
+	for _, name := range slices.Sorted(maps.Keys(g.defs)) {
+		doc := document{Schema: Draft}
+		if config.BaseID != "" {
+			doc.ID = g.id(name + ".json")
+		}
+		content, err := encode(doc, g.defs[name])
+		if err != nil {
+			return Output{}, err
+		}
+		output.Files = append(output.Files, OutputFile{Path: path.Join(config.OutputDir, name+".json"), Content: content})
+	}
+	return output, nil
+}
+
+// document holds the keywords that go first in a generated document.
+type document struct {
+	Schema string                    `json:"$schema"`
+	ID     string                    `json:"$id,omitempty"`
+	Defs   map[string]map[string]any `json:"$defs,omitempty"`
+}
+
+type generator struct {
+	config Config
+	defs   map[string]map[string]any
+	// owners are the declarations of the definitions, used to report
+	// conflicting names
+	owners map[string]string
+}
+
+func (g *generator) add(name string, owner string, def map[string]any) error {
+	if other, ok := g.owners[name]; ok {
+		return fmt.Errorf("the definition %s of %s conflicts with the one of %s", name, owner, other)
+	}
+	def["title"] = name
+	g.defs[name] = def
+	g.owners[name] = owner
+	return nil
+}
+
+func (g *generator) addInputOutput(kind string, name string, input []schema.FieldDefinition, output []schema.FieldDefinition, deprecated *string) error {
+	owner := kind + " " + name
+
+	inputDef := g.object(input)
+	inputDef["description"] = fmt.Sprintf("Input of the %s %s.", name, kind)
+	if deprecated != nil {
+		inputDef["deprecated"] = true
+	}
+	if err := g.add(name+"Input", owner, inputDef); err != nil {
+		return err
+	}
+
+	outputDef := g.object(output)
+	outputDef["description"] = fmt.Sprintf("Output of the %s %s.", name, kind)
+	if deprecated != nil {
+		outputDef["deprecated"] = true
+	}
+	return g.add(name+"Output", owner, outputDef)
+}
+
+// id returns the "$id" of the document with the given name.
+func (g *generator) id(name string) string {
+	return strings.TrimSuffix(g.config.BaseID, "/") + "/" + name
+}
+
+// ref returns the reference to the definition of a type.
+func (g *generator) ref(name string) string {
+	if g.config.OutputFile != "" {
+		return "#/$defs/" + name
+	}
+	return name + ".json"
+}
+
+// object returns the schema of an object with the given fields, the
+// optional fields are the only ones not listed as required.
+func (g *generator) object(fields []schema.FieldDefinition) map[string]any {
+	properties := map[string]any{}
+	required := []string{}
+
+	for _, field := range fields {
+		var prop map[string]any
+		switch {
+		case field.TypeInline != nil:
+			prop = g.object(field.TypeInline.Fields)
+		case ast.IsPrimitiveType(*field.TypeName):
+			prop = primitive(*field.TypeName)
+		default:
+			prop = map[string]any{"$ref": g.ref(*field.TypeName)}
+		}
+
+		if field.IsArray {
+			prop = map[string]any{"type": "array", "items": prop}
+		}
+		setDescription(prop, field.Doc, nil)
+
+		properties[field.Name] = prop
+		if !field.Optional {
+			required = append(required, field.Name)
+		}
+	}
+
+	object := map[string]any{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		object["required"] = required
+	}
+	return object
+}
+
+// primitive returns the schema of a primitive type.
+func primitive(name string) map[string]any {
+	switch name {
+	case ast.PrimitiveTypeInt:
+		return map[string]any{"type": "integer"}
+	case ast.PrimitiveTypeFloat:
+		return map[string]any{"type": "number"}
+	case ast.PrimitiveTypeBool:
+		return map[string]any{"type": "boolean"}
+	case ast.PrimitiveTypeDatetime:
+		return map[string]any{"type": "string", "format": "date-time"}
+	default:
+		return map[string]any{"type": "string"}
+	}
+}
+
+// setDescription sets the description and the deprecated annotation of a
+// schema, the deprecation message is appended to the description.
+func setDescription(def map[string]any, doc *string, deprecated *string) {
+	desc := ""
+	if doc != nil {
+		desc = strings.TrimSpace(strutil.NormalizeIndent(*doc))
+	}
+	if deprecated != nil {
+		def["deprecated"] = true
+		if *deprecated != "" {
+			desc = strings.TrimSpace(desc + "\n\nDeprecated: " + *deprecated)
+		}
+	}
+	if desc != "" {
+		def["description"] = desc
+	}
+}
+
+// encode returns the indented JSON of the document followed by the keywords
+// of the definition.
+func encode(doc document, def map[string]any) (string, error) {
+	raw, err := marshal(doc)
+	if err != nil {
+		return "", err
+	}
+	if len(def) > 0 {
+		rawDef, err := marshal(def)
+		if err != nil {
+			return "", err
+		}
+		raw = append(append(raw[:len(raw)-1], ','), rawDef[1:]...)
+	}
+
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, raw, "", "  "); err != nil {
+		return "", fmt.Errorf("failed to encode json schema: %w", err)
+	}
+	buf.WriteByte('\n')
+	return buf.String(), nil
+}
+
+func marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, fmt.Errorf("failed to encode json schema: %w", err)
+	}
+	return bytes.TrimSpace(buf.Bytes()), nil
+}
@@ -0,0 +1,197 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/stretchr/testify/require"
+	"github.com/uforg/uforpc/urpc/internal/schema"
+)
+
+func testSchema(t *testing.T) schema.Schema {
+	t.Helper()
+
+	sch, err := schema.ParseSchema(`{
+		"version": 1,
+		"nodes": [
+			{
+				"kind": "type",
+				"name": "User",
+				"doc": "  A user.  ",
+				"fields": [
+					{"name": "id", "typeName": "string", "isArray": false, "optional": false},
+					{"name": "createdAt", "doc": "Creation date.", "typeName": "datetime", "isArray": false, "optional": false},
+					{"name": "scores", "typeName": "float", "isArray": true, "optional": true},
+					{"name": "address", "typeInline": {"fields": [{"name": "zip", "typeName": "int", "isArray": false, "optional": false}]}, "isArray": false, "optional": false}
+				]
+			},
+			{
+				"kind": "type",
+				"name": "LegacyUser",
+				"deprecated": "Use User instead.",
+				"fields": [{"name": "id", "typeName": "string", "isArray": false, "optional": false}]
+			},
+			{
+				"kind": "proc",
+				"name": "GetUser",
+				"input": [{"name": "userId", "typeName": "string", "isArray": false, "optional": false}],
+				"output": [{"name": "user", "typeName": "User", "isArray": false, "optional": true}]
+			},
+			{
+				"kind": "stream",
+				"name": "WatchUsers",
+				"input": [],
+				"output": [{"name": "users", "typeName": "User", "isArray": true, "optional": false}]
+			}
+		]
+	}`)
+	require.NoError(t, err)
+	return sch
+}
+
+// compile compiles the definition of the generated documents, the files are
+// added as resources relative to baseURL.
+func compile(t *testing.T, output Output, baseURL string, ref string) *jsonschema.Schema {
+	t.Helper()
+
+	c := jsonschema.NewCompiler()
+	c.AssertFormat()
+	for _, file := range output.Files {
+		doc, err := jsonschema.UnmarshalJSON(strings.NewReader(file.Content))
+		require.NoError(t, err)
+		require.NoError(t, c.AddResource(baseURL+path.Clean(file.Path), doc))
+	}
+	compiled, err := c.Compile(baseURL + ref)
+	require.NoError(t, err)
+	return compiled
+}
+
+func validate(t *testing.T, compiled *jsonschema.Schema, instance string) error {
+	t.Helper()
+
+	value, err := jsonschema.UnmarshalJSON(strings.NewReader(instance))
+	require.NoError(t, err)
+	return compiled.Validate(value)
+}
+
+const (
+	validUser          = `{"id": "1", "createdAt": "2024-01-02T03:04:05Z", "scores": [1.5], "address": {"zip": 1000}}`
+	missingIDUser      = `{"createdAt": "2024-01-02T03:04:05Z", "address": {"zip": 1000}}`
+	invalidDateUser    = `{"id": "1", "createdAt": "yesterday", "address": {"zip": 1000}}`
+	invalidNestedUser  = `{"id": "1", "createdAt": "2024-01-02T03:04:05Z", "address": {"zip": "1000"}}`
+	invalidScoresUser  = `{"id": "1", "createdAt": "2024-01-02T03:04:05Z", "scores": 1.5, "address": {"zip": 1000}}`
+	validWatchOutput   = `{"users": [` + validUser + `]}`
+	invalidWatchOutput = `{"users": [` + missingIDUser + `]}`
+)
+
+func TestGenerateBundled(t *testing.T) {
+	output, err := Generate(testSchema(t), Config{OutputFile: "./schemas/api.json"})
+	require.NoError(t, err)
+	require.Len(t, output.Files, 1)
+	require.Equal(t, "./schemas/api.json", output.Files[0].Path)
+
+	doc := map[string]any{}
+	require.NoError(t, json.Unmarshal([]byte(output.Files[0].Content), &doc))
+	require.Equal(t, Draft, doc["$schema"])
+	require.NotContains(t, doc, "$id")
+
+	defs := doc["$defs"].(map[string]any)
+	require.ElementsMatch(t, []string{"User", "LegacyUser", "GetUserInput", "GetUserOutput", "WatchUsersInput", "WatchUsersOutput"}, slices.Collect(maps.Keys(defs)))
+
+	user := defs["User"].(map[string]any)
+	require.Equal(t, "User", user["title"])
+	require.Equal(t, "A user.", user["description"])
+	require.Equal(t, []any{"id", "createdAt", "address"}, user["required"])
+	createdAt := user["properties"].(map[string]any)["createdAt"].(map[string]any)
+	require.Equal(t, map[string]any{"type": "string", "format": "date-time", "description": "Creation date."}, createdAt)
+
+	legacy := defs["LegacyUser"].(map[string]any)
+	require.Equal(t, true, legacy["deprecated"])
+	require.Equal(t, "Deprecated: Use User instead.", legacy["description"])
+
+	getUserOutput := defs["GetUserOutput"].(map[string]any)
+	require.NotContains(t, getUserOutput, "required")
+	require.Equal(t, "Output of the GetUser procedure.", getUserOutput["description"])
+
+	baseURL := "file:///project/"
+	userSchema := compile(t, output, baseURL, "schemas/api.json#/$defs/User")
+	require.NoError(t, validate(t, userSchema, validUser))
+	require.ErrorContains(t, validate(t, userSchema, missingIDUser), "missing property 'id'")
+	require.ErrorContains(t, validate(t, userSchema, invalidDateUser), "is not valid date-time")
+	require.Error(t, validate(t, userSchema, invalidNestedUser))
+	require.Error(t, validate(t, userSchema, invalidScoresUser))
+
+	watchSchema := compile(t, output, baseURL, "schemas/api.json#/$defs/WatchUsersOutput")
+	require.NoError(t, validate(t, watchSchema, validWatchOutput))
+	require.Error(t, validate(t, watchSchema, invalidWatchOutput))
+
+	inputSchema := compile(t, output, baseURL, "schemas/api.json#/$defs/GetUserInput")
+	require.NoError(t, validate(t, inputSchema, `{"userId": "1"}`))
+	require.Error(t, validate(t, inputSchema, `{}`))
+}
+
+func TestGenerateSplit(t *testing.T) {
+	output, err := Generate(testSchema(t), Config{OutputDir: "schemas", BaseID: "https://example.com/schemas/"})
+	require.NoError(t, err)
+
+	paths := []string{}
+	for _, file := range output.Files {
+		paths = append(paths, file.Path)
+	}
+	require.Equal(t, []string{
+		"schemas/GetUserInput.json",
+		"schemas/GetUserOutput.json",
+		"schemas/LegacyUser.json",
+		"schemas/User.json",
+		"schemas/WatchUsersInput.json",
+		"schemas/WatchUsersOutput.json",
+	}, paths)
+
+	doc := map[string]any{}
+	require.NoError(t, json.Unmarshal([]byte(output.Files[5].Content), &doc))
+	require.Equal(t, Draft, doc["$schema"])
+	require.Equal(t, "https://example.com/schemas/WatchUsersOutput.json", doc["$id"])
+	users := doc["properties"].(map[string]any)["users"].(map[string]any)
+	require.Equal(t, map[string]any{"$ref": "User.json"}, users["items"])
+
+	// The documents reference each other relative to their $id
+	watchSchema := compile(t, output, "https://example.com/", "schemas/WatchUsersOutput.json")
+	require.NoError(t, validate(t, watchSchema, validWatchOutput))
+	require.Error(t, validate(t, watchSchema, invalidWatchOutput))
+
+	// And relative to their location when there is no base ID
+	output, err = Generate(testSchema(t), Config{OutputDir: "schemas"})
+	require.NoError(t, err)
+	require.NotContains(t, output.Files[0].Content, "$id")
+	watchSchema = compile(t, output, "file:///project/", "schemas/WatchUsersOutput.json")
+	require.NoError(t, validate(t, watchSchema, validWatchOutput))
+	require.Error(t, validate(t, watchSchema, invalidWatchOutput))
+}
+
+func TestGenerateConflictingNames(t *testing.T) {
+	sch, err := schema.ParseSchema(`{
+		"version": 1,
+		"nodes": [
+			{"kind": "type", "name": "GetUserInput", "fields": []},
+			{"kind": "proc", "name": "GetUser", "input": [], "output": []}
+		]
+	}`)
+	require.NoError(t, err)
+
+	_, err = Generate(sch, Config{OutputFile: "api.json"})
+	require.EqualError(t, err, "the definition GetUserInput of procedure GetUser conflicts with the one of type GetUserInput")
+}
+
+func TestConfigValidate(t *testing.T) {
+	require.NoError(t, Config{OutputFile: "api.json"}.Validate())
+	require.NoError(t, Config{OutputDir: "schemas", BaseID: "https://example.com/schemas/"}.Validate())
+	require.EqualError(t, Config{}.Validate(), `"output_file" or "output_dir" is required`)
+	require.EqualError(t, Config{OutputFile: "api.json", OutputDir: "schemas"}.Validate(), `"output_file" and "output_dir" cannot be used together`)
+	require.EqualError(t, Config{OutputFile: "api.yaml"}.Validate(), `"output_file" must end with ".json"`)
+	require.EqualError(t, Config{OutputDir: "schemas", BaseID: "schemas/"}.Validate(), `"base_id" must be an absolute URI, e.g. "https://example.com/schemas/"`)
+}
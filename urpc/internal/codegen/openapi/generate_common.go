@@ -9,10 +9,53 @@ import (
 	"github.com/uforg/uforpc/urpc/internal/util/strutil"
 )
 
-// generateProperties generates the JSON schema properties for a given list of fields.
+// GenerateTypeSchemas generates the component schemas of the types of the
+// schema, keyed by the type name.
+func GenerateTypeSchemas(sch schema.Schema) map[string]any {
+	schemas := map[string]any{}
+
+	for _, typeNode := range sch.GetTypeNodes() {
+		desc := ""
+		if typeNode.Doc != nil {
+			desc = strings.TrimSpace(strutil.NormalizeIndent(*typeNode.Doc))
+		}
+
+		if typeNode.Deprecated != nil {
+			desc += "\n\nDeprecated: "
+			if *typeNode.Deprecated == "" {
+				desc += "This type is deprecated and should not be used in new code."
+			} else {
+				desc += *typeNode.Deprecated
+			}
+		}
+
+		properties, requiredFields := GenerateProperties(typeNode.Fields)
+
+		typeSchema := map[string]any{
+			"deprecated": typeNode.Deprecated != nil,
+			"type":       "object",
+			"properties": properties,
+		}
+		if desc != "" {
+			typeSchema["description"] = desc
+		}
+		if len(requiredFields) > 0 {
+			typeSchema["required"] = requiredFields
+		}
+
+		schemas[typeNode.Name] = typeSchema
+	}
+
+	return schemas
+}
+
+// GenerateProperties generates the JSON schema properties for a given list of fields.
+//
+// The named types are referenced as #/components/schemas/<Name>, which is
+// also where the AsyncAPI generator places them.
 //
 // It returns a map of the JSON schema properties and a list of required fields.
-func generateProperties(fields []schema.FieldDefinition) (map[string]any, []string) {
+func GenerateProperties(fields []schema.FieldDefinition) (map[string]any, []string) {
 	properties := map[string]any{}
 	requiredFields := []string{}
 
@@ -78,7 +121,7 @@ func generateProperties(fields []schema.FieldDefinition) (map[string]any, []stri
 		}
 
 		if isInline {
-			childProps, childRequired := generateProperties(field.TypeInline.Fields)
+			childProps, childRequired := GenerateProperties(field.TypeInline.Fields)
 
 			prop := map[string]any{
 				"type":       "object",
@@ -123,7 +166,7 @@ func generateProperties(fields []schema.FieldDefinition) (map[string]any, []stri
 //
 // It returns a map of the JSON schema properties and a list of required fields.
 func generateOutputProperties(fields []schema.FieldDefinition) (map[string]any, []string) {
-	outputProperties, outputRequiredFields := generateProperties(fields)
+	outputProperties, outputRequiredFields := GenerateProperties(fields)
 	output := componentRequestBodySchema{
 		Type:       "object",
 		Properties: outputProperties,
@@ -135,27 +178,32 @@ func generateOutputProperties(fields []schema.FieldDefinition) (map[string]any,
 			"type": "boolean",
 		},
 		"output": output,
-		"error": map[string]any{
-			"type": "object",
-			"properties": map[string]any{
-				"message": map[string]any{
-					"type": "string",
-				},
-				"category": map[string]any{
-					"type": "string",
-				},
-				"code": map[string]any{
-					"type": "string",
-				},
-				"details": map[string]any{
-					"type":                 "object",
-					"properties":           map[string]any{},
-					"additionalProperties": true,
-				},
-			},
-			"required": []string{"message"},
-		},
+		"error":  ErrorSchema(),
 	}
 
 	return properties, []string{"ok"}
 }
+
+// ErrorSchema returns the JSON schema of the error of a failed response.
+func ErrorSchema() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"message": map[string]any{
+				"type": "string",
+			},
+			"category": map[string]any{
+				"type": "string",
+			},
+			"code": map[string]any{
+				"type": "string",
+			},
+			"details": map[string]any{
+				"type":                 "object",
+				"properties":           map[string]any{},
+				"additionalProperties": true,
+			},
+		},
+		"required": []string{"message"},
+	}
+}
@@ -5,7 +5,6 @@ import (
 	"strings"
 
 	"github.com/uforg/uforpc/urpc/internal/schema"
-	"github.com/uforg/uforpc/urpc/internal/util/strutil"
 )
 
 type componentRequestBodySchema struct {
@@ -34,49 +33,17 @@ func generateComponents(sch schema.Schema) (Components, error) {
 				"description": strings.TrimSpace(strings.ReplaceAll(authTokenDescription, "''", "`")),
 			},
 		},
-		Schemas:       map[string]any{},
+		Schemas:       GenerateTypeSchemas(sch),
 		RequestBodies: map[string]any{},
 		Responses:     map[string]any{},
 	}
 
-	for _, typeNode := range sch.GetTypeNodes() {
-		desc := ""
-		if typeNode.Doc != nil {
-			desc = strings.TrimSpace(strutil.NormalizeIndent(*typeNode.Doc))
-		}
-
-		if typeNode.Deprecated != nil {
-			desc += "\n\nDeprecated: "
-			if *typeNode.Deprecated == "" {
-				desc += "This type is deprecated and should not be used in new code."
-			} else {
-				desc += *typeNode.Deprecated
-			}
-		}
-
-		properties, requiredFields := generateProperties(typeNode.Fields)
-
-		typeSchema := map[string]any{
-			"deprecated": typeNode.Deprecated != nil,
-			"type":       "object",
-			"properties": properties,
-		}
-		if desc != "" {
-			typeSchema["description"] = desc
-		}
-		if len(requiredFields) > 0 {
-			typeSchema["required"] = requiredFields
-		}
-
-		components.Schemas[typeNode.Name] = typeSchema
-	}
-
 	for _, procNode := range sch.GetProcNodes() {
 		name := procNode.Name
 		inputName := fmt.Sprintf("%sInput", name)
 		outputName := fmt.Sprintf("%sOutput", name)
 
-		inputProperties, inputRequiredFields := generateProperties(procNode.Input)
+		inputProperties, inputRequiredFields := GenerateProperties(procNode.Input)
 		components.RequestBodies[inputName] = map[string]any{
 			"description": "Request body for the " + name + " procedure",
 			"content": map[string]any{
@@ -110,7 +77,7 @@ func generateComponents(sch schema.Schema) (Components, error) {
 		inputName := fmt.Sprintf("%sInput", name)
 		outputName := fmt.Sprintf("%sOutput", name)
 
-		inputProperties, inputRequiredFields := generateProperties(streamNode.Input)
+		inputProperties, inputRequiredFields := GenerateProperties(streamNode.Input)
 		components.RequestBodies[inputName] = map[string]any{
 			"description": "Request body for the " + name + " stream",
 			"content": map[string]any{
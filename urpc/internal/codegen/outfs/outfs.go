@@ -0,0 +1,200 @@
+// Package outfs provides the filesystems where the code generators write
+// their output.
+//
+// The generators write to an FS using slash separated names relative to
+// the root of the filesystem, usually the directory of the config file. A
+// Memory filesystem keeps the files so they can be inspected, compared or
+// returned by the WASM build, and a Disk filesystem writes them to disk.
+package outfs
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/uforg/uforpc/urpc/internal/util/fileutil"
+)
+
+// FS is a filesystem where the generated files are written.
+type FS interface {
+	// WriteFile writes the file with the given name, replacing it if it
+	// already exists. The name can start with ".." to write outside of the
+	// root, like the output paths of the config file.
+	WriteFile(name string, content []byte) error
+}
+
+// CleanName returns the canonical form of a name, or an error if it is not
+// a valid relative path.
+func CleanName(name string) (string, error) {
+	cleaned := path.Clean(filepath.ToSlash(name))
+	if name == "" || cleaned == "." || path.IsAbs(cleaned) || filepath.IsAbs(name) {
+		return "", fmt.Errorf("invalid output path %q, it must be a relative file path", name)
+	}
+	return cleaned, nil
+}
+
+// File is a file of a Memory filesystem.
+type File struct {
+	// Name is the slash separated path relative to the root.
+	Name    string
+	Content []byte
+}
+
+// Memory is a filesystem that keeps the files in memory.
+type Memory struct {
+	files map[string][]byte
+}
+
+// NewMemory returns an empty Memory filesystem.
+func NewMemory() *Memory {
+	return &Memory{files: map[string][]byte{}}
+}
+
+// WriteFile implements the FS interface.
+func (m *Memory) WriteFile(name string, content []byte) error {
+	cleaned, err := CleanName(name)
+	if err != nil {
+		return err
+	}
+	m.files[cleaned] = slices.Clone(content)
+	return nil
+}
+
+// ReadFile returns the content of a file and whether it exists.
+func (m *Memory) ReadFile(name string) ([]byte, bool) {
+	cleaned, err := CleanName(name)
+	if err != nil {
+		return nil, false
+	}
+	content, ok := m.files[cleaned]
+	return content, ok
+}
+
+// Files returns the files sorted by name.
+func (m *Memory) Files() []File {
+	files := make([]File, 0, len(m.files))
+	for _, name := range slices.Sorted(maps.Keys(m.files)) {
+		files = append(files, File{Name: name, Content: m.files[name]})
+	}
+	return files
+}
+
+// Disk is a filesystem that writes the files inside a root directory.
+//
+// Files are written atomically and only when their content changed, so
+// the modification time of unchanged files is preserved.
+type Disk struct {
+	root string
+}
+
+// NewDisk returns a Disk filesystem rooted at the given directory.
+func NewDisk(root string) *Disk {
+	return &Disk{root: root}
+}
+
+// Path returns the path in the OS format of the file with the given name.
+func (d *Disk) Path(name string) string {
+	return filepath.Join(d.root, filepath.FromSlash(name))
+}
+
+// WriteFile implements the FS interface.
+func (d *Disk) WriteFile(name string, content []byte) error {
+	cleaned, err := CleanName(name)
+	if err != nil {
+		return err
+	}
+	if _, err := fileutil.WriteFileIfChanged(d.Path(cleaned), content, 0644); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ReadFile returns the content of the file with the given name.
+func (d *Disk) ReadFile(name string) ([]byte, error) {
+	cleaned, err := CleanName(name)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadFile(d.Path(cleaned))
+}
+
+// ListFiles returns the sorted names of the files inside the directory, an
+// empty list is returned if the directory does not exist.
+func (d *Disk) ListFiles(dir string) ([]string, error) {
+	cleaned, err := CleanName(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	err = filepath.WalkDir(d.Path(cleaned), func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(d.root, p)
+		if err != nil {
+			return err
+		}
+		names = append(names, filepath.ToSlash(rel))
+		return nil
+	})
+	if errors.Is(err, os.ErrNotExist) {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return names, nil
+}
+
+// RemoveStale removes the files inside the directory that are not kept,
+// and the directories that become empty because of it. Directories that
+// were already empty are left untouched.
+func (d *Disk) RemoveStale(dir string, keep func(name string) bool) error {
+	names, err := d.ListFiles(dir)
+	if err != nil {
+		return err
+	}
+
+	cleaned, _ := CleanName(dir)
+	emptied := map[string]bool{}
+	for _, name := range names {
+		if keep(name) {
+			continue
+		}
+		if err := os.Remove(d.Path(name)); err != nil {
+			return err
+		}
+		for parent := path.Dir(name); parent != cleaned && strings.HasPrefix(parent, cleaned+"/"); parent = path.Dir(parent) {
+			emptied[parent] = true
+		}
+	}
+
+	// Remove the directories starting from the deepest ones, so a parent is
+	// empty once its children are removed
+	dirs := slices.SortedFunc(maps.Keys(emptied), func(a, b string) int {
+		return strings.Count(b, "/") - strings.Count(a, "/")
+	})
+	for _, emptiedDir := range dirs {
+		entries, err := os.ReadDir(d.Path(emptiedDir))
+		if err != nil {
+			return err
+		}
+		if len(entries) == 0 {
+			if err := os.Remove(d.Path(emptiedDir)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,82 @@
+package outfs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCleanName(t *testing.T) {
+	for name, expected := range map[string]string{
+		"a.go":          "a.go",
+		"./gen/a.go":    "gen/a.go",
+		"gen//b/../a":   "gen/a",
+		"../web/src/x":  "../web/src/x",
+		"dir/./sub/x.y": "dir/sub/x.y",
+	} {
+		cleaned, err := CleanName(name)
+		require.NoError(t, err)
+		require.Equal(t, expected, cleaned, name)
+	}
+
+	for _, name := range []string{"", ".", "./", "/abs/a.go"} {
+		_, err := CleanName(name)
+		require.Error(t, err, name)
+	}
+}
+
+func TestMemory(t *testing.T) {
+	m := NewMemory()
+	require.NoError(t, m.WriteFile("./b/file.txt", []byte("b")))
+	require.NoError(t, m.WriteFile("a.txt", []byte("old")))
+	require.NoError(t, m.WriteFile("a.txt", []byte("a")))
+	require.Error(t, m.WriteFile("", []byte("x")))
+
+	content, ok := m.ReadFile("b/file.txt")
+	require.True(t, ok)
+	require.Equal(t, "b", string(content))
+	_, ok = m.ReadFile("c.txt")
+	require.False(t, ok)
+
+	require.Equal(t, []File{
+		{Name: "a.txt", Content: []byte("a")},
+		{Name: "b/file.txt", Content: []byte("b")},
+	}, m.Files())
+}
+
+func TestDisk(t *testing.T) {
+	root := t.TempDir()
+	d := NewDisk(filepath.Join(root, "project"))
+
+	require.NoError(t, d.WriteFile("out/a.txt", []byte("a")))
+	require.NoError(t, d.WriteFile("out/nested/b.txt", []byte("b")))
+	require.NoError(t, d.WriteFile("../outside.txt", []byte("c")))
+
+	content, err := d.ReadFile("out/a.txt")
+	require.NoError(t, err)
+	require.Equal(t, "a", string(content))
+	content, err = os.ReadFile(filepath.Join(root, "outside.txt"))
+	require.NoError(t, err)
+	require.Equal(t, "c", string(content))
+
+	names, err := d.ListFiles("out")
+	require.NoError(t, err)
+	require.Equal(t, []string{"out/a.txt", "out/nested/b.txt"}, names)
+
+	names, err = d.ListFiles("missing")
+	require.NoError(t, err)
+	require.Empty(t, names)
+
+	require.NoError(t, os.MkdirAll(d.Path("out/empty"), 0755))
+	require.NoError(t, d.RemoveStale("out", func(name string) bool { return name == "out/a.txt" }))
+	names, err = d.ListFiles("out")
+	require.NoError(t, err)
+	require.Equal(t, []string{"out/a.txt"}, names)
+	_, err = os.Stat(d.Path("out/nested"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+	require.DirExists(t, d.Path("out/empty"))
+
+	require.NoError(t, d.RemoveStale("missing", func(string) bool { return false }))
+}
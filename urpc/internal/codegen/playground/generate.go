@@ -5,89 +5,80 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/fs"
-	"os"
-	"path/filepath"
+	"strings"
 
 	"github.com/uforg/uforpc/embedplayground"
 	"github.com/uforg/uforpc/urpc/internal/urpc/ast"
 	"github.com/uforg/uforpc/urpc/internal/urpc/formatter"
 )
 
-// Generate takes a schema and a config and generates the playground for the schema.
-func Generate(absConfigDir string, sch *ast.Schema, config Config) error {
-	outputDir := filepath.Join(absConfigDir, config.OutputDir)
+// Files returns the files of the playground for the schema in memory, keyed
+// by their slash separated path relative to the playground root. It
+// includes the embedded build, the formatted schema and, when the config
+// has defaults, the config.json file. The openapi.yaml file is not included.
+func Files(sch *ast.Schema, config Config) (map[string][]byte, error) {
+	files := map[string][]byte{}
 
-	if err := os.RemoveAll(outputDir); err != nil {
-		return fmt.Errorf("error emptying output directory: %w", err)
-	}
-
-	err := extractEmbedFS(embedplayground.BuildFS, "build", outputDir)
+	err := extractEmbedFS(embedplayground.BuildFS, "build", func(name string, content []byte) error {
+		files[name] = content
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("error extracting embedded filesystem: %w", err)
+		return nil, fmt.Errorf("error extracting embedded filesystem: %w", err)
 	}
 
-	gitkeepPath := filepath.Join(outputDir, ".gitkeep")
-	if err := os.Remove(gitkeepPath); err != nil {
-		return fmt.Errorf("error deleting .gitkeep file: %w", err)
-	}
-
-	formattedSchema := formatter.FormatSchema(sch)
-	formattedSchemaPath := filepath.Join(outputDir, "schema.urpc")
-	if err := os.WriteFile(formattedSchemaPath, []byte(formattedSchema), 0644); err != nil {
-		return fmt.Errorf("error writing formatted schema to %s: %w", formattedSchemaPath, err)
-	}
+	files["schema.urpc"] = []byte(formatter.FormatSchema(sch))
 
 	hasConfig := config.DefaultBaseURL != "" || len(config.DefaultHeaders) > 0
 	if hasConfig {
-		type jsonConfigHeader struct {
-			Key   string `json:"key"`
-			Value string `json:"value"`
-		}
-
-		type jsonConfig struct {
-			BaseURL string             `json:"baseUrl,omitempty,omitzero"`
-			Headers []jsonConfigHeader `json:"headers,omitempty,omitzero"`
-		}
-
-		jsonConfigHeaders := make([]jsonConfigHeader, len(config.DefaultHeaders))
-		for i, header := range config.DefaultHeaders {
-			jsonConfigHeaders[i] = jsonConfigHeader(header)
+		jsonConfigBytes, err := ConfigJSON(config.DefaultBaseURL, config.DefaultHeaders)
+		if err != nil {
+			return nil, err
 		}
+		files["config.json"] = jsonConfigBytes
+	}
 
-		jsonConf := jsonConfig{
-			BaseURL: config.DefaultBaseURL,
-			Headers: jsonConfigHeaders,
-		}
+	return files, nil
+}
 
-		jsonConfigBytes, err := json.Marshal(jsonConf)
-		if err != nil {
-			return fmt.Errorf("error marshalling config to JSON: %w", err)
-		}
+// ConfigJSON returns the content of the config.json file read by the
+// playground to set its default base URL and headers.
+func ConfigJSON(baseURL string, headers []Header) ([]byte, error) {
+	type jsonConfigHeader struct {
+		Key   string `json:"key"`
+		Value string `json:"value"`
+	}
+	type jsonConfig struct {
+		BaseURL string             `json:"baseUrl,omitempty,omitzero"`
+		Headers []jsonConfigHeader `json:"headers,omitempty,omitzero"`
+	}
 
-		configPath := filepath.Join(outputDir, "config.json")
-		if err := os.WriteFile(configPath, jsonConfigBytes, 0644); err != nil {
-			return fmt.Errorf("error writing config to %s: %w", configPath, err)
-		}
+	jsonConfigHeaders := make([]jsonConfigHeader, len(headers))
+	for i, header := range headers {
+		jsonConfigHeaders[i] = jsonConfigHeader(header)
 	}
 
-	return nil
+	jsonConfigBytes, err := json.Marshal(jsonConfig{
+		BaseURL: baseURL,
+		Headers: jsonConfigHeaders,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling config to JSON: %w", err)
+	}
+	return jsonConfigBytes, nil
 }
 
-func extractEmbedFS(embedFS embed.FS, rootDir string, destDir string) error {
+// extractEmbedFS calls writeFile with the slash separated path relative to
+// rootDir and the content of every file of the embedded filesystem. The
+// .gitkeep file is skipped.
+func extractEmbedFS(embedFS embed.FS, rootDir string, writeFile func(name string, content []byte) error) error {
 	return fs.WalkDir(embedFS, rootDir, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
 
-		relPath, err := filepath.Rel(rootDir, path)
-		if err != nil {
-			return err
-		}
-
-		destPath := filepath.Join(destDir, relPath)
-
-		if d.IsDir() {
-			return os.MkdirAll(destPath, 0o700)
+		if d.IsDir() || d.Name() == ".gitkeep" {
+			return nil
 		}
 
 		data, err := fs.ReadFile(embedFS, path)
@@ -95,6 +86,6 @@ func extractEmbedFS(embedFS embed.FS, rootDir string, destDir string) error {
 			return err
 		}
 
-		return os.WriteFile(destPath, data, 0o644)
+		return writeFile(strings.TrimPrefix(path, rootDir+"/"), data)
 	})
 }
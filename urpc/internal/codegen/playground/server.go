@@ -0,0 +1,242 @@
+package playground
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+)
+
+const (
+	// ProxyPath is the same-origin path the playground sends the requests
+	// to when the server has a target, the operation name is appended to it.
+	ProxyPath = "/__urpc/proxy"
+
+	// EventsPath is the path of the Server-Sent Events endpoint that
+	// notifies the browser to reload the page when the files change.
+	EventsPath = "/__urpc/events"
+)
+
+// reloadScript is injected into index.html to reload the page when the
+// server notifies that the files changed.
+const reloadScript = `<script>new EventSource("` + EventsPath + `").addEventListener("reload", () => location.reload());</script>`
+
+// Server serves the playground files from memory.
+//
+// When it has a target, the playground is configured to send the requests
+// to the ProxyPath of the same server, which forwards them to the target so
+// the browser never makes cross-origin requests. Streams are forwarded as
+// they arrive.
+type Server struct {
+	target *url.URL
+	proxy  *httputil.ReverseProxy
+
+	mu      sync.RWMutex
+	files   map[string][]byte
+	headers []Header
+	reload  chan struct{}
+}
+
+// NewServer creates a server that proxies the requests to the target base
+// URL, an empty target disables the proxy.
+func NewServer(target string) (*Server, error) {
+	s := &Server{
+		files:  map[string][]byte{},
+		reload: make(chan struct{}),
+	}
+
+	if target == "" {
+		return s, nil
+	}
+
+	targetURL, err := url.Parse(target)
+	if err != nil {
+		return nil, fmt.Errorf("invalid target URL: %w", err)
+	}
+	if targetURL.Scheme != "http" && targetURL.Scheme != "https" {
+		return nil, fmt.Errorf("invalid target URL %q: the scheme must be http or https", target)
+	}
+
+	s.target = targetURL
+	s.proxy = &httputil.ReverseProxy{
+		Rewrite: func(r *httputil.ProxyRequest) {
+			operationName := strings.TrimPrefix(r.In.URL.Path, ProxyPath+"/")
+			r.Out.URL.Scheme = targetURL.Scheme
+			r.Out.URL.Host = targetURL.Host
+			r.Out.URL.Path = strings.TrimRight(targetURL.Path, "/") + "/" + operationName
+			r.Out.URL.RawPath = ""
+			r.Out.URL.RawQuery = targetURL.RawQuery
+			r.Out.Host = targetURL.Host
+			// The request is no longer cross-origin from the point of view
+			// of the target
+			r.Out.Header.Del("Origin")
+			r.Out.Header.Del("Referer")
+		},
+		// Flush immediately so stream events are not buffered
+		FlushInterval: -1,
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			http.Error(w, fmt.Sprintf("failed to reach %s: %s", target, err), http.StatusBadGateway)
+		},
+	}
+
+	return s, nil
+}
+
+// Target returns the target base URL, or an empty string if the proxy is
+// disabled.
+func (s *Server) Target() string {
+	if s.target == nil {
+		return ""
+	}
+	return s.target.String()
+}
+
+// Update replaces the served files, as returned by Files plus the
+// openapi.yaml file, and the default headers, then notifies the connected
+// browsers to reload.
+func (s *Server) Update(files map[string][]byte, headers []Header) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.files = files
+	s.headers = headers
+
+	close(s.reload)
+	s.reload = make(chan struct{})
+}
+
+// ServeHTTP implements the http.Handler interface.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.URL.Path == ProxyPath || strings.HasPrefix(r.URL.Path, ProxyPath+"/"):
+		s.serveProxy(w, r)
+	case r.URL.Path == EventsPath:
+		s.serveEvents(w, r)
+	default:
+		s.serveFile(w, r)
+	}
+}
+
+// serveProxy forwards the request to the target.
+func (s *Server) serveProxy(w http.ResponseWriter, r *http.Request) {
+	if s.proxy == nil {
+		http.Error(w, "the proxy is disabled because no base URL is configured", http.StatusNotFound)
+		return
+	}
+	if operationName := strings.TrimPrefix(r.URL.Path, ProxyPath); operationName == "" || operationName == "/" {
+		http.Error(w, "missing operation name", http.StatusNotFound)
+		return
+	}
+	s.proxy.ServeHTTP(w, r)
+}
+
+// serveEvents sends a reload event when the files are updated.
+func (s *Server) serveEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	s.mu.RLock()
+	reload := s.reload
+	s.mu.RUnlock()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	select {
+	case <-r.Context().Done():
+	case <-reload:
+		_, _ = fmt.Fprint(w, "event: reload\ndata: {}\n\n")
+		flusher.Flush()
+	}
+}
+
+// serveFile serves a file from memory, config.json is generated for every
+// request so the proxy URL matches the host used by the browser.
+func (s *Server) serveFile(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	name := strings.TrimPrefix(path.Clean("/"+r.URL.Path), "/")
+	if name == "" {
+		name = "index.html"
+	}
+
+	s.mu.RLock()
+	content, ok := s.files[name]
+	headers := s.headers
+	s.mu.RUnlock()
+
+	switch {
+	case name == "config.json" && s.proxy != nil:
+		scheme := "http"
+		if r.TLS != nil {
+			scheme = "https"
+		}
+		configJSON, err := ConfigJSON(scheme+"://"+r.Host+ProxyPath, headers)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		content, ok = configJSON, true
+	case name == "index.html" && !ok:
+		content, ok = []byte(missingBuildPage), true
+	}
+
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	if name == "index.html" {
+		content = injectReloadScript(content)
+	}
+
+	contentType := mime.TypeByExtension(path.Ext(name))
+	if contentType == "" {
+		contentType = http.DetectContentType(content)
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control", "no-cache")
+	if r.Method == http.MethodHead {
+		return
+	}
+	_, _ = w.Write(content)
+}
+
+// injectReloadScript adds the reload script before the closing body tag,
+// or at the end if there is none.
+func injectReloadScript(html []byte) []byte {
+	index := bytes.LastIndex(html, []byte("</body>"))
+	if index < 0 {
+		return append(bytes.Clone(html), reloadScript...)
+	}
+
+	injected := make([]byte, 0, len(html)+len(reloadScript))
+	injected = append(injected, html[:index]...)
+	injected = append(injected, reloadScript...)
+	injected = append(injected, html[index:]...)
+	return injected
+}
+
+// missingBuildPage is served when the binary was built without the
+// playground, e.g. from a source checkout without running its build.
+const missingBuildPage = `<!doctype html>
+<html>
+<head><meta charset="utf-8"><title>UFO RPC Playground</title></head>
+<body>
+<p>The playground is not included in this build of urpc, build it with <code>npm run build</code> inside the playground directory and build urpc again.</p>
+</body>
+</html>
+`
@@ -0,0 +1,139 @@
+package playground
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer(t *testing.T) {
+	var gotPath, gotOrigin, gotAuth string
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotOrigin, gotAuth = r.URL.Path, r.Header.Get("Origin"), r.Header.Get("Authorization")
+		if strings.HasSuffix(r.URL.Path, "/Ticks") {
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprint(w, "data: {\"ok\":true}\n\n")
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"ok":true,"output":{}}`)
+	}))
+	defer target.Close()
+
+	server, err := NewServer(target.URL + "/api/v1/urpc/")
+	require.NoError(t, err)
+	server.Update(map[string][]byte{
+		"index.html":  []byte("<html><body><p>playground</p></body></html>"),
+		"app/main.js": []byte("console.log(1)"),
+		"schema.urpc": []byte("version 1\n"),
+	}, []Header{{Key: "X-Foo", Value: "bar"}})
+
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	get := func(t *testing.T, path string) (*http.Response, string) {
+		res, err := http.Get(ts.URL + path)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		return res, string(body)
+	}
+
+	t.Run("Serves the files", func(t *testing.T) {
+		res, body := get(t, "/app/main.js")
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.Contains(t, res.Header.Get("Content-Type"), "javascript")
+		require.Equal(t, "console.log(1)", body)
+
+		res, _ = get(t, "/missing.js")
+		require.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+
+	t.Run("Injects the reload script", func(t *testing.T) {
+		_, body := get(t, "/")
+		require.Equal(t, "<html><body><p>playground</p>"+reloadScript+"</body></html>", body)
+	})
+
+	t.Run("Config points to the proxy", func(t *testing.T) {
+		_, body := get(t, "/config.json")
+		require.JSONEq(t, fmt.Sprintf(`{
+			"baseUrl": "%s/__urpc/proxy",
+			"headers": [{"key": "X-Foo", "value": "bar"}]
+		}`, ts.URL), body)
+	})
+
+	t.Run("Proxies procedures", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, ts.URL+ProxyPath+"/GetUser", strings.NewReader(`{}`))
+		require.NoError(t, err)
+		req.Header.Set("Origin", "http://localhost:8090")
+		req.Header.Set("Authorization", "Bearer token")
+
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+
+		require.Equal(t, `{"ok":true,"output":{}}`, string(body))
+		require.Equal(t, "/api/v1/urpc/GetUser", gotPath)
+		require.Equal(t, "", gotOrigin)
+		require.Equal(t, "Bearer token", gotAuth)
+	})
+
+	t.Run("Proxies streams", func(t *testing.T) {
+		res, err := http.Post(ts.URL+ProxyPath+"/Ticks", "application/json", strings.NewReader(`{}`))
+		require.NoError(t, err)
+		defer res.Body.Close()
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+
+		require.Equal(t, "text/event-stream", res.Header.Get("Content-Type"))
+		require.Equal(t, "data: {\"ok\":true}\n\n", string(body))
+	})
+
+	t.Run("Notifies reloads", func(t *testing.T) {
+		res, err := http.Get(ts.URL + EventsPath)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		require.Equal(t, "text/event-stream", res.Header.Get("Content-Type"))
+
+		server.Update(map[string][]byte{"schema.urpc": []byte("version 1\n\ntype A {}\n")}, nil)
+
+		reader := bufio.NewReader(res.Body)
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		require.Equal(t, "event: reload\n", line)
+
+		_, body := get(t, "/schema.urpc")
+		require.Equal(t, "version 1\n\ntype A {}\n", body)
+	})
+}
+
+func TestServerWithoutTarget(t *testing.T) {
+	server, err := NewServer("")
+	require.NoError(t, err)
+	require.Equal(t, "", server.Target())
+	server.Update(map[string][]byte{"config.json": []byte(`{"baseUrl":"http://example.com"}`)}, nil)
+
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/config.json", nil))
+	require.Equal(t, `{"baseUrl":"http://example.com"}`, rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	server.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, ProxyPath+"/GetUser", nil))
+	require.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = httptest.NewRecorder()
+	server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Contains(t, rec.Body.String(), "The playground is not included in this build")
+
+	_, err = NewServer("localhost:8080")
+	require.EqualError(t, err, `invalid target URL "localhost:8080": the scheme must be http or https`)
+}
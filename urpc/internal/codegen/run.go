@@ -1,14 +1,26 @@
 package codegen
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"maps"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
+	"strings"
+	"time"
 
+	"github.com/uforg/uforpc/urpc/internal/codegen/asyncapi"
 	"github.com/uforg/uforpc/urpc/internal/codegen/dart"
+	"github.com/uforg/uforpc/urpc/internal/codegen/docs"
 	"github.com/uforg/uforpc/urpc/internal/codegen/golang"
+	"github.com/uforg/uforpc/urpc/internal/codegen/jsonschema"
 	"github.com/uforg/uforpc/urpc/internal/codegen/openapi"
+	"github.com/uforg/uforpc/urpc/internal/codegen/outfs"
 	"github.com/uforg/uforpc/urpc/internal/codegen/playground"
+	"github.com/uforg/uforpc/urpc/internal/codegen/template"
 	"github.com/uforg/uforpc/urpc/internal/codegen/typescript"
 	"github.com/uforg/uforpc/urpc/internal/schema"
 	"github.com/uforg/uforpc/urpc/internal/transpile"
@@ -18,16 +30,207 @@ import (
 	"github.com/uforg/uforpc/urpc/internal/util/filepathutil"
 )
 
+// Options are the options of RunWithOptions and Check.
+type Options struct {
+	// Jobs is the maximum number of targets generated concurrently, it
+	// defaults to the number of CPUs.
+	Jobs int
+	// Profile is the profile of the config to use, see LoadConfig.
+	Profile string
+}
+
+// Report describes a code generation.
+type Report struct {
+	// Targets are the generated targets in the order of the config file.
+	Targets []TargetReport
+}
+
+// TargetReport describes the generation of a single target.
+type TargetReport struct {
+	// Name is the name of the target, e.g. "golang-client[1]".
+	Name string
+	// Duration is the time the target took to generate.
+	Duration time.Duration
+	// Files is the number of files generated by the target.
+	Files int
+	// Err is the error of the target, if any.
+	Err error
+}
+
 // Run runs the code generator and returns an error if one occurred.
 func Run(configPath string) error {
-	configBytes, err := os.ReadFile(configPath)
+	_, err := RunWithOptions(configPath, Options{})
+	return err
+}
+
+// RunWithOptions runs the code generator and returns a report of the
+// generated targets.
+//
+// The targets are generated concurrently and in memory before writing, so
+// a failing target does not leave the output half written. The errors of
+// all the failing targets are returned together. Only the files whose
+// content changed are written.
+func RunWithOptions(configPath string, opts Options) (Report, error) {
+	out, report, err := render(configPath, opts)
 	if err != nil {
-		return fmt.Errorf("failed to read %s config file: %s", configPath, err)
+		return report, err
+	}
+
+	disk := outfs.NewDisk(out.rootDir)
+	for _, file := range out.fs.Files() {
+		if err := disk.WriteFile(file.Name, file.Content); err != nil {
+			return report, fmt.Errorf("failed to write generated code to file: %w", err)
+		}
 	}
 
-	config := Config{}
-	if err := config.UnmarshalAndValidate(configBytes); err != nil {
-		return fmt.Errorf("failed to unmarshal config: %w", err)
+	for _, dir := range out.cleanDirs {
+		keep := func(name string) bool { return !out.isStale(disk, dir, name) }
+		if err := disk.RemoveStale(dir.name, keep); err != nil {
+			return report, fmt.Errorf("failed to remove stale files of %s: %w", dir.name, err)
+		}
+	}
+
+	return report, nil
+}
+
+// StaleFile is a generated file whose content on disk is not up to date.
+type StaleFile struct {
+	// Path is the absolute path of the file.
+	Path string
+	// Reason explains why the file is stale, e.g. "missing".
+	Reason string
+}
+
+// CheckResult is the result of Check.
+type CheckResult struct {
+	// Checked is the number of generated files compared to disk.
+	Checked int
+	// Stale are the files that would be written or removed by Run.
+	Stale []StaleFile
+}
+
+// Check generates the code in memory and compares it to the files on disk
+// without writing anything.
+func Check(configPath string, opts Options) (CheckResult, error) {
+	out, _, err := render(configPath, opts)
+	if err != nil {
+		return CheckResult{}, err
+	}
+
+	disk := outfs.NewDisk(out.rootDir)
+	files := out.fs.Files()
+	result := CheckResult{Checked: len(files)}
+	for _, file := range files {
+		current, err := disk.ReadFile(file.Name)
+		if errors.Is(err, os.ErrNotExist) {
+			result.Stale = append(result.Stale, StaleFile{Path: disk.Path(file.Name), Reason: "missing"})
+			continue
+		}
+		if err != nil {
+			return CheckResult{}, fmt.Errorf("failed to read %s: %w", disk.Path(file.Name), err)
+		}
+		if !bytes.Equal(current, file.Content) {
+			result.Stale = append(result.Stale, StaleFile{Path: disk.Path(file.Name), Reason: out.header.staleReason(current)})
+		}
+	}
+
+	for _, dir := range out.cleanDirs {
+		names, err := disk.ListFiles(dir.name)
+		if err != nil {
+			return CheckResult{}, fmt.Errorf("failed to read %s: %w", disk.Path(dir.name), err)
+		}
+		for _, name := range names {
+			if out.isStale(disk, dir, name) {
+				result.Stale = append(result.Stale, StaleFile{Path: disk.Path(name), Reason: "not generated anymore"})
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// output is the code generated by all the targets, kept in memory until
+// it is written or checked.
+type output struct {
+	// rootDir is the directory the generated file names are relative to.
+	rootDir string
+	header  header
+	fs      *outfs.Memory
+	// cleanDirs are the directories where the files written by a previous
+	// generation and not generated anymore are removed.
+	cleanDirs []cleanDir
+}
+
+// cleanDir is a directory where stale files are removed.
+type cleanDir struct {
+	name string
+	// owned returns true if the file was written by the target, it is nil
+	// when the target owns every file of the directory.
+	owned func(name string, content []byte) bool
+}
+
+func newOutput(rootDir string, header header) *output {
+	return &output{rootDir: rootDir, header: header, fs: outfs.NewMemory()}
+}
+
+// addFile adds a file with its content as it is.
+func (o *output) addFile(name string, content []byte) error {
+	return o.fs.WriteFile(name, content)
+}
+
+// addCode adds a file of generated code, with the header if the file type
+// supports comments.
+func (o *output) addCode(name string, code string) error {
+	return o.addFile(name, []byte(o.header.addHeader(name, code)))
+}
+
+// addCleanDir marks the directory as fully owned by a target, every file
+// inside it that is not generated is removed.
+func (o *output) addCleanDir(dir string) error {
+	return o.addOwnedFiles(dir, nil)
+}
+
+// addOwnedFiles marks the files of the directory for which owned returns
+// true as written by a target, only them are removed when they are not
+// generated anymore. The root is never cleaned, it holds the config and the
+// files of the other targets.
+func (o *output) addOwnedFiles(dir string, owned func(name string, content []byte) bool) error {
+	if path.Clean(filepath.ToSlash(dir)) == "." {
+		return nil
+	}
+	cleaned, err := outfs.CleanName(dir)
+	if err != nil {
+		return err
+	}
+	o.cleanDirs = append(o.cleanDirs, cleanDir{name: cleaned, owned: owned})
+	return nil
+}
+
+// isStale returns true if the file of the clean directory is not generated
+// anymore and must be removed.
+func (o *output) isStale(disk *outfs.Disk, dir cleanDir, name string) bool {
+	if o.isGenerated(name) {
+		return false
+	}
+	if dir.owned == nil {
+		return true
+	}
+	content, err := disk.ReadFile(name)
+	return err == nil && dir.owned(name, content)
+}
+
+// isGenerated returns true if the file with the given name was generated.
+func (o *output) isGenerated(name string) bool {
+	_, ok := o.fs.ReadFile(name)
+	return ok
+}
+
+// render parses the config and the schema and runs all the code generators
+// in memory.
+func render(configPath string, opts Options) (*output, Report, error) {
+	config, err := LoadConfig(configPath, opts.Profile)
+	if err != nil {
+		return nil, Report{}, fmt.Errorf("invalid config: %w", err)
 	}
 
 	///////////////////////////////////////
@@ -36,7 +239,7 @@ func Run(configPath string) error {
 
 	absConfigPath, err := filepathutil.NormalizeFromWD(configPath)
 	if err != nil {
-		return fmt.Errorf("failed to normalize config path: %w", err)
+		return nil, Report{}, fmt.Errorf("failed to normalize config path: %w", err)
 	}
 
 	absConfigDir := filepath.Dir(absConfigPath)
@@ -44,12 +247,16 @@ func Run(configPath string) error {
 
 	an, err := analyzer.NewAnalyzer(docstore.NewDocstore())
 	if err != nil {
-		return fmt.Errorf("failed to create URPC analyzer: %w", err)
+		return nil, Report{}, fmt.Errorf("failed to create URPC analyzer: %w", err)
 	}
 
-	astSchema, _, err := an.Analyze(absSchemaPath)
+	astSchema, diagnostics, err := an.Analyze(absSchemaPath)
 	if err != nil {
-		return fmt.Errorf("invalid schema: %w", err)
+		diagnosticErrs := make([]error, len(diagnostics))
+		for i, diagnostic := range diagnostics {
+			diagnosticErrs[i] = diagnostic
+		}
+		return nil, Report{}, fmt.Errorf("invalid schema: %w", errors.Join(diagnosticErrs...))
 	}
 
 	///////////////////////
@@ -58,100 +265,226 @@ func Run(configPath string) error {
 
 	jsonSchema, err := transpile.ToJSON(*astSchema)
 	if err != nil {
-		return fmt.Errorf("failed to transpile schema to its JSON representation: %w", err)
+		return nil, Report{}, fmt.Errorf("failed to transpile schema to its JSON representation: %w", err)
 	}
 
 	/////////////////////////
 	// RUN CODE GENERATORS //
 	/////////////////////////
 
-	if config.HasOpenAPI() {
-		if err := runOpenAPI(absConfigDir, config.OpenAPI, jsonSchema); err != nil {
-			return fmt.Errorf("failed to run openapi code generator: %w", err)
-		}
+	if err := checkOutputPaths(absConfigDir, config); err != nil {
+		return nil, Report{}, err
 	}
 
-	if config.HasPlayground() {
-		if err := runPlayground(absConfigDir, config.Playground, config.OpenAPI, astSchema, jsonSchema); err != nil {
-			return fmt.Errorf("failed to run playground code generator: %w", err)
-		}
+	h, err := newHeader(jsonSchema)
+	if err != nil {
+		return nil, Report{}, err
 	}
 
-	if config.HasGolangServer() {
-		cfg := *config.GolangServer
-		cfg.IncludeServer = true
-		cfg.IncludeClient = false
-		if err := runGolang(absConfigDir, &cfg, jsonSchema); err != nil {
-			return fmt.Errorf("failed to run golang-server code generator: %w", err)
+	jobs := []job{}
+	addJobs := func(name string, count int, run func(out *output, index int) error) {
+		for i := range count {
+			jobs = append(jobs, job{name: targetName(name, i, count), run: func(out *output) error { return run(out, i) }})
 		}
 	}
 
-	if config.HasGolangClient() {
-		cfg := *config.GolangClient
-		cfg.IncludeServer = false
-		cfg.IncludeClient = true
-		if err := runGolang(absConfigDir, &cfg, jsonSchema); err != nil {
-			return fmt.Errorf("failed to run golang-client code generator: %w", err)
+	// The openapi entries without output file only hold the metadata used
+	// by the playground
+	for i, cfg := range config.OpenAPI {
+		if cfg.OutputFile == "" {
+			continue
 		}
+		jobs = append(jobs, job{
+			name: targetName("openapi", i, len(config.OpenAPI)),
+			run:  func(out *output) error { return runOpenAPI(out, cfg, jsonSchema) },
+		})
 	}
-
-	if config.HasTypescriptClient() {
-		cfg := *config.TypescriptClient
+	addJobs("playground", len(config.Playground), func(out *output, i int) error {
+		cfg := config.Playground[i]
+		return runPlayground(out, &cfg, config.OpenAPIMetadata(), astSchema, jsonSchema)
+	})
+	addJobs("asyncapi", len(config.AsyncAPI), func(out *output, i int) error {
+		cfg := config.AsyncAPI[i]
+		return runAsyncAPI(out, &cfg, jsonSchema)
+	})
+	addJobs("golang-server", len(config.GolangServer), func(out *output, i int) error {
+		cfg := config.GolangServer[i]
+		cfg.IncludeServer = true
+		cfg.IncludeClient = false
+		return runGolang(out, &cfg, jsonSchema)
+	})
+	addJobs("golang-client", len(config.GolangClient), func(out *output, i int) error {
+		cfg := config.GolangClient[i]
 		cfg.IncludeServer = false
 		cfg.IncludeClient = true
-		if err := runTypescript(absConfigDir, &cfg, jsonSchema); err != nil {
-			return fmt.Errorf("failed to run typescript-client code generator: %w", err)
+		return runGolang(out, &cfg, jsonSchema)
+	})
+	addJobs("typescript-client", len(config.TypescriptClient), func(out *output, i int) error {
+		cfg := config.TypescriptClient[i]
+		cfg.IncludeServer = false
+		cfg.IncludeClient = true
+		return runTypescript(out, &cfg, jsonSchema)
+	})
+	addJobs("dart-client", len(config.DartClient), func(out *output, i int) error {
+		cfg := config.DartClient[i]
+		return runDart(out, &cfg, jsonSchema)
+	})
+	addJobs("docs", len(config.Docs), func(out *output, i int) error {
+		cfg := config.Docs[i]
+		return runDocs(out, &cfg, jsonSchema)
+	})
+	addJobs("jsonschema", len(config.JSONSchema), func(out *output, i int) error {
+		cfg := config.JSONSchema[i]
+		return runJSONSchema(out, &cfg, jsonSchema)
+	})
+	addJobs("template", len(config.Template), func(out *output, i int) error {
+		cfg := config.Template[i]
+		return runTemplate(out, &cfg, jsonSchema)
+	})
+	addJobs("plugin", len(config.Plugin), func(out *output, i int) error {
+		cfg := config.Plugin[i]
+		return runPlugin(out, &cfg, jsonSchema)
+	})
+
+	return runJobs(absConfigDir, h, jobs, opts.Jobs)
+}
+
+// checkOutputPaths returns an error if two generators write to the same
+// output file or directory, one would overwrite the files of the other, or
+// if a generator writes inside the output directory of a generator that
+// removes its stale files, they would be removed.
+func checkOutputPaths(absConfigDir string, config Config) error {
+	type cleanedDir struct {
+		target     string
+		outputPath string
+		absPath    string
+	}
+	owners := map[string]string{}
+	cleanedDirs := []cleanedDir{}
+	check := func(name string, index int, count int, outputPath string) error {
+		absOutputPath := filepath.Join(absConfigDir, outputPath)
+		target := targetName(name, index, count)
+		if owner, ok := owners[absOutputPath]; ok {
+			return fmt.Errorf("%s and %s write to the same output path %s", owner, target, outputPath)
+		}
+		owners[absOutputPath] = target
+		return nil
+	}
+	// checkCleaned is like check for the output directories where stale
+	// files are removed, the root is never cleaned
+	checkCleaned := func(name string, index int, count int, outputDir string) error {
+		if err := check(name, index, count, outputDir); err != nil {
+			return err
 		}
+		absOutputDir := filepath.Join(absConfigDir, outputDir)
+		if absOutputDir != absConfigDir {
+			cleanedDirs = append(cleanedDirs, cleanedDir{target: targetName(name, index, count), outputPath: outputDir, absPath: absOutputDir})
+		}
+		return nil
 	}
 
-	if config.HasDartClient() {
-		if err := runDart(absConfigDir, config.DartClient, jsonSchema); err != nil {
-			return fmt.Errorf("failed to run dart-client code generator: %w", err)
+	for i, cfg := range config.OpenAPI {
+		if cfg.OutputFile == "" {
+			continue
+		}
+		if err := check("openapi", i, len(config.OpenAPI), cfg.OutputFile); err != nil {
+			return err
+		}
+	}
+	for i, cfg := range config.Playground {
+		if err := checkCleaned("playground", i, len(config.Playground), cfg.OutputDir); err != nil {
+			return err
+		}
+	}
+	for i, cfg := range config.AsyncAPI {
+		if err := check("asyncapi", i, len(config.AsyncAPI), cfg.OutputFile); err != nil {
+			return err
+		}
+	}
+	for i, cfg := range config.GolangServer {
+		if err := check("golang-server", i, len(config.GolangServer), cfg.OutputFile); err != nil {
+			return err
+		}
+	}
+	for i, cfg := range config.GolangClient {
+		if err := check("golang-client", i, len(config.GolangClient), cfg.OutputFile); err != nil {
+			return err
+		}
+	}
+	for i, cfg := range config.TypescriptClient {
+		if err := check("typescript-client", i, len(config.TypescriptClient), cfg.OutputFile); err != nil {
+			return err
+		}
+	}
+	for i, cfg := range config.DartClient {
+		if err := check("dart-client", i, len(config.DartClient), cfg.OutputDir); err != nil {
+			return err
+		}
+	}
+	for i, cfg := range config.Docs {
+		if err := check("docs", i, len(config.Docs), cfg.OutputDir); err != nil {
+			return err
+		}
+	}
+	for i, cfg := range config.JSONSchema {
+		if cfg.OutputDir != "" {
+			if err := checkCleaned("jsonschema", i, len(config.JSONSchema), cfg.OutputDir); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := check("jsonschema", i, len(config.JSONSchema), cfg.OutputFile); err != nil {
+			return err
+		}
+	}
+	for i, cfg := range config.Template {
+		if err := check("template", i, len(config.Template), cfg.OutputFile); err != nil {
+			return err
+		}
+	}
+	for i, cfg := range config.Plugin {
+		if err := check("plugin", i, len(config.Plugin), cfg.OutputDir); err != nil {
+			return err
 		}
 	}
 
+	for _, dir := range cleanedDirs {
+		for _, absOutputPath := range slices.Sorted(maps.Keys(owners)) {
+			owner := owners[absOutputPath]
+			if owner == dir.target || !strings.HasPrefix(absOutputPath, dir.absPath+string(filepath.Separator)) {
+				continue
+			}
+			rel, _ := filepath.Rel(absConfigDir, absOutputPath)
+			return fmt.Errorf("%s writes to %s inside the output directory %s of %s, which removes the stale files in it", owner, filepath.ToSlash(rel), dir.outputPath, dir.target)
+		}
+	}
 	return nil
 }
 
-func runOpenAPI(absConfigDir string, config openapi.Config, schema schema.Schema) error {
-	outputFile := filepath.Join(absConfigDir, config.OutputFile)
-	outputDir := filepath.Dir(outputFile)
-
-	// Ensure output directory exists
-	if err := os.MkdirAll(outputDir, 0755); err != nil {
-		return fmt.Errorf("failed to create output directory: %w", err)
-	}
-
+func runOpenAPI(out *output, config openapi.Config, schema schema.Schema) error {
 	// Generate the code
 	code, err := openapi.Generate(schema, config)
 	if err != nil {
 		return fmt.Errorf("failed to generate code: %w", err)
 	}
 
-	if err := os.WriteFile(outputFile, []byte(code), 0644); err != nil {
-		return fmt.Errorf("failed to write generated code to file: %w", err)
-	}
-
-	return nil
+	return out.addCode(config.OutputFile, code)
 }
 
-func runPlayground(absConfigDir string, config *playground.Config, openAPIConfig openapi.Config, astSchema *ast.Schema, jsonSchema schema.Schema) error {
-	outputDir := filepath.Join(absConfigDir, config.OutputDir)
-	openAPIOutputFile := filepath.Join(outputDir, "openapi.yaml")
-
-	// Ensure output directory exists
-	if err := os.MkdirAll(outputDir, 0755); err != nil {
-		return fmt.Errorf("failed to create output directory: %w", err)
-	}
-
+func runPlayground(out *output, config *playground.Config, openAPIConfig openapi.Config, astSchema *ast.Schema, jsonSchema schema.Schema) error {
 	// Generate the playground
-	err := playground.Generate(absConfigDir, astSchema, *config)
+	files, err := playground.Files(astSchema, *config)
 	if err != nil {
 		return fmt.Errorf("failed to generate playground: %w", err)
 	}
+	for _, name := range slices.Sorted(maps.Keys(files)) {
+		if err := out.addFile(path.Join(config.OutputDir, name), files[name]); err != nil {
+			return err
+		}
+	}
 
 	// Generate the openapi.yaml file
-	openAPIConfig.OutputFile = openAPIOutputFile
+	openAPIConfig.OutputFile = path.Join(config.OutputDir, "openapi.yaml")
 	if openAPIConfig.BaseURL == "" {
 		openAPIConfig.BaseURL = config.DefaultBaseURL
 	}
@@ -160,83 +493,123 @@ func runPlayground(absConfigDir string, config *playground.Config, openAPIConfig
 	if err != nil {
 		return fmt.Errorf("failed to generate openapi.yaml code: %w", err)
 	}
-
-	if err := os.WriteFile(openAPIOutputFile, []byte(code), 0644); err != nil {
-		return fmt.Errorf("failed to write generated openapi.yaml code to file: %w", err)
+	if err := out.addCode(openAPIConfig.OutputFile, code); err != nil {
+		return err
 	}
 
-	return nil
+	// Files left in the output directory by previous generations are removed
+	return out.addCleanDir(config.OutputDir)
 }
 
-func runGolang(absConfigDir string, config *golang.Config, schema schema.Schema) error {
-	outputFile := filepath.Join(absConfigDir, config.OutputFile)
-	outputDir := filepath.Dir(outputFile)
-
-	// Ensure output directory exists
-	if err := os.MkdirAll(outputDir, 0755); err != nil {
-		return fmt.Errorf("failed to create output directory: %w", err)
+func runAsyncAPI(out *output, config *asyncapi.Config, schema schema.Schema) error {
+	// Generate the code
+	code, err := asyncapi.Generate(schema, *config)
+	if err != nil {
+		return fmt.Errorf("failed to generate code: %w", err)
 	}
 
+	return out.addCode(config.OutputFile, code)
+}
+
+func runGolang(out *output, config *golang.Config, schema schema.Schema) error {
 	// Generate the code
 	code, err := golang.Generate(schema, *config)
 	if err != nil {
 		return fmt.Errorf("failed to generate code: %w", err)
 	}
 
-	// Write the code to the output file
-	if err := os.WriteFile(outputFile, []byte(code), 0644); err != nil {
-		return fmt.Errorf("failed to write generated code to file: %w", err)
-	}
-
-	return nil
+	return out.addCode(config.OutputFile, code)
 }
 
-func runTypescript(absConfigDir string, config *typescript.Config, schema schema.Schema) error {
-	outputFile := filepath.Join(absConfigDir, config.OutputFile)
-	outputDir := filepath.Dir(outputFile)
-
-	// Ensure output directory exists
-	if err := os.MkdirAll(outputDir, 0755); err != nil {
-		return fmt.Errorf("failed to create output directory: %w", err)
+func runTypescript(out *output, config *typescript.Config, schema schema.Schema) error {
+	// Generate the code
+	code, err := typescript.Generate(schema, *config)
+	if err != nil {
+		return fmt.Errorf("failed to generate code: %w", err)
 	}
 
+	return out.addCode(config.OutputFile, code)
+}
+
+func runDart(out *output, config *dart.Config, schema schema.Schema) error {
 	// Generate the code
-	code, err := typescript.Generate(schema, *config)
+	generated, err := dart.Generate(schema, *config)
 	if err != nil {
 		return fmt.Errorf("failed to generate code: %w", err)
 	}
 
-	// Write the code to the output file
-	if err := os.WriteFile(outputFile, []byte(code), 0644); err != nil {
-		return fmt.Errorf("failed to write generated code to file: %w", err)
+	for _, file := range generated.Files {
+		if err := out.addCode(path.Join(config.OutputDir, file.Path), file.Content); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
-func runDart(absConfigDir string, config *dart.Config, schema schema.Schema) error {
-	outputDir := filepath.Join(absConfigDir, config.OutputDir)
+func runDocs(out *output, config *docs.Config, schema schema.Schema) error {
+	// Generate the documentation
+	generated, err := docs.Generate(schema, *config)
+	if err != nil {
+		return fmt.Errorf("failed to generate documentation: %w", err)
+	}
 
-	// Ensure output directory exists
-	if err := os.MkdirAll(outputDir, 0755); err != nil {
-		return fmt.Errorf("failed to create output directory: %w", err)
+	for _, file := range generated.Files {
+		if err := out.addFile(path.Join(config.OutputDir, file.Path), []byte(file.Content)); err != nil {
+			return err
+		}
 	}
 
-	// Generate the code
-	output, err := dart.Generate(schema, *config)
+	return nil
+}
+
+func runJSONSchema(out *output, config *jsonschema.Config, schema schema.Schema) error {
+	// Generate the documents
+	generated, err := jsonschema.Generate(schema, *config)
 	if err != nil {
-		return fmt.Errorf("failed to generate code: %w", err)
+		return fmt.Errorf("failed to generate json schema: %w", err)
 	}
 
-	for _, file := range output.Files {
-		outputFile := filepath.Join(outputDir, file.Path)
-		outputFileDir := filepath.Dir(outputFile)
-		if err := os.MkdirAll(outputFileDir, 0755); err != nil {
-			return fmt.Errorf("failed to create output directory: %w", err)
+	for _, file := range generated.Files {
+		if err := out.addFile(file.Path, []byte(out.header.addJSONComment(file.Content))); err != nil {
+			return err
 		}
+	}
+
+	// Documents of removed definitions are removed from the output
+	// directory, the other files in it are left untouched
+	if config.OutputDir != "" {
+		dir := path.Clean(filepath.ToSlash(config.OutputDir))
+		return out.addOwnedFiles(dir, func(name string, content []byte) bool {
+			if path.Dir(name) != dir || path.Ext(name) != ".json" {
+				return false
+			}
+			_, ok := parseHeader(content)
+			return ok
+		})
+	}
+	return nil
+}
+
+func runTemplate(out *output, config *template.Config, schema schema.Schema) error {
+	templatePath := filepath.Join(out.rootDir, config.Template)
+	templateBytes, err := os.ReadFile(templatePath)
+	if err != nil {
+		return fmt.Errorf("failed to read template: %w", err)
+	}
+
+	return runTemplateText(out, config, string(templateBytes), schema)
+}
+
+func runTemplateText(out *output, config *template.Config, templateText string, schema schema.Schema) error {
+	generated, err := template.Generate(schema, *config, templateText)
+	if err != nil {
+		return fmt.Errorf("failed to render template: %w", err)
+	}
 
-		if err := os.WriteFile(outputFile, []byte(file.Content), 0644); err != nil {
-			return fmt.Errorf("failed to write generated code to file %s: %w", outputFile, err)
+	for _, file := range generated.Files {
+		if err := out.addFile(file.Path, []byte(file.Content)); err != nil {
+			return err
 		}
 	}
 
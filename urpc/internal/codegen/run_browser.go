@@ -4,8 +4,13 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/uforg/uforpc/urpc/internal/codegen/asyncapi"
 	"github.com/uforg/uforpc/urpc/internal/codegen/dart"
+	"github.com/uforg/uforpc/urpc/internal/codegen/docs"
 	"github.com/uforg/uforpc/urpc/internal/codegen/golang"
+	"github.com/uforg/uforpc/urpc/internal/codegen/jsonschema"
+	"github.com/uforg/uforpc/urpc/internal/codegen/openapi"
+	"github.com/uforg/uforpc/urpc/internal/codegen/playground"
 	"github.com/uforg/uforpc/urpc/internal/codegen/typescript"
 	"github.com/uforg/uforpc/urpc/internal/transpile"
 	"github.com/uforg/uforpc/urpc/internal/urpc/parser"
@@ -14,7 +19,9 @@ import (
 // RunWasmOptions contains options for running code generators in WASM mode
 // without writing to files.
 type RunWasmOptions struct {
-	// Generator must be one of: "golang-server", "golang-client", "typescript-client", "dart-client".
+	// Generator must be one of: "golang-server", "golang-client", "typescript-client", "dart-client",
+	// "openapi", "playground", "docs", "jsonschema", "asyncapi". The "template" and "plugin"
+	// generators are not supported because they read template files or run external commands.
 	Generator string `json:"generator"`
 	// SchemaInput is the schema content as a string (URPC schema only).
 	SchemaInput string `json:"schemaInput"`
@@ -50,7 +57,10 @@ func RunWasmString(opts RunWasmOptions) (string, error) {
 	return string(jsonOutput), nil
 }
 
-// runWasm executes a single generator and returns the generated code as a string.
+// runWasm executes a single generator and returns the generated files.
+//
+// It runs the same generators as Run, writing to an in-memory filesystem
+// instead of the disk.
 func runWasm(opts RunWasmOptions) (RunWasmOutput, error) {
 	if opts.Generator == "" {
 		return RunWasmOutput{}, fmt.Errorf("missing generator")
@@ -69,51 +79,54 @@ func runWasm(opts RunWasmOptions) (RunWasmOutput, error) {
 		return RunWasmOutput{}, fmt.Errorf("failed to transpile URPC to JSON: %s", err)
 	}
 
-	if opts.Generator == "golang-server" {
+	h, err := newHeader(jsonSchema)
+	if err != nil {
+		return RunWasmOutput{}, err
+	}
+	out := newOutput(".", h)
+
+	switch opts.Generator {
+	case "golang-server":
 		if opts.GolangPackageName == "" {
 			return RunWasmOutput{}, fmt.Errorf("golang-server requires 'GolangPackageName'")
 		}
-		cfg := golang.Config{PackageName: opts.GolangPackageName, IncludeServer: true, IncludeClient: false}
-		fileContent, err := golang.Generate(jsonSchema, cfg)
-		if err != nil {
-			return RunWasmOutput{}, fmt.Errorf("failed to generate golang server: %s", err)
-		}
-		return RunWasmOutput{Files: []RunWasmOutputFile{{Path: "server.go", Content: fileContent}}}, nil
-	}
-
-	if opts.Generator == "golang-client" {
+		cfg := golang.Config{OutputFile: "server.go", PackageName: opts.GolangPackageName, IncludeServer: true, IncludeClient: false}
+		err = runGolang(out, &cfg, jsonSchema)
+	case "golang-client":
 		if opts.GolangPackageName == "" {
 			return RunWasmOutput{}, fmt.Errorf("golang-client requires 'GolangPackageName'")
 		}
-		cfg := golang.Config{PackageName: opts.GolangPackageName, IncludeServer: false, IncludeClient: true}
-		fileContent, err := golang.Generate(jsonSchema, cfg)
-		if err != nil {
-			return RunWasmOutput{}, fmt.Errorf("failed to generate golang client: %s", err)
-		}
-		return RunWasmOutput{Files: []RunWasmOutputFile{{Path: "client.go", Content: fileContent}}}, nil
+		cfg := golang.Config{OutputFile: "client.go", PackageName: opts.GolangPackageName, IncludeServer: false, IncludeClient: true}
+		err = runGolang(out, &cfg, jsonSchema)
+	case "typescript-client":
+		cfg := typescript.Config{OutputFile: "client.ts", IncludeServer: false, IncludeClient: true}
+		err = runTypescript(out, &cfg, jsonSchema)
+	case "dart-client":
+		cfg := dart.Config{OutputDir: ".", PackageName: opts.DartPackageName}
+		err = runDart(out, &cfg, jsonSchema)
+	case "openapi":
+		err = runOpenAPI(out, openapi.Config{OutputFile: "openapi.yaml"}, jsonSchema)
+	case "playground":
+		err = runPlayground(out, &playground.Config{OutputDir: "."}, openapi.Config{}, astSchema, jsonSchema)
+	case "docs":
+		err = runDocs(out, &docs.Config{OutputDir: "."}, jsonSchema)
+	case "jsonschema":
+		err = runJSONSchema(out, &jsonschema.Config{OutputFile: "schema.json"}, jsonSchema)
+	case "asyncapi":
+		err = runAsyncAPI(out, &asyncapi.Config{OutputFile: "asyncapi.yaml"}, jsonSchema)
+	case "template", "plugin":
+		return RunWasmOutput{}, fmt.Errorf("the %s generator is not supported in the browser", opts.Generator)
+	default:
+		return RunWasmOutput{}, fmt.Errorf("unsupported generator: %s", opts.Generator)
 	}
-
-	if opts.Generator == "typescript-client" {
-		cfg := typescript.Config{IncludeServer: false, IncludeClient: true}
-		fileContent, err := typescript.Generate(jsonSchema, cfg)
-		if err != nil {
-			return RunWasmOutput{}, fmt.Errorf("failed to generate typescript client: %s", err)
-		}
-		return RunWasmOutput{Files: []RunWasmOutputFile{{Path: "client.ts", Content: fileContent}}}, nil
+	if err != nil {
+		return RunWasmOutput{}, fmt.Errorf("failed to generate %s: %s", opts.Generator, err)
 	}
 
-	if opts.Generator == "dart-client" {
-		cfg := dart.Config{PackageName: opts.DartPackageName}
-		output, err := dart.Generate(jsonSchema, cfg)
-		if err != nil {
-			return RunWasmOutput{}, fmt.Errorf("failed to generate dart client: %s", err)
-		}
-		files := make([]RunWasmOutputFile, len(output.Files))
-		for i, file := range output.Files {
-			files[i] = RunWasmOutputFile{Path: file.Path, Content: file.Content}
-		}
-		return RunWasmOutput{Files: files}, nil
+	files := out.fs.Files()
+	output := RunWasmOutput{Files: make([]RunWasmOutputFile, len(files))}
+	for i, file := range files {
+		output.Files[i] = RunWasmOutputFile{Path: file.Name, Content: string(file.Content)}
 	}
-
-	return RunWasmOutput{}, fmt.Errorf("unsupported generator: %s", opts.Generator)
+	return output, nil
 }
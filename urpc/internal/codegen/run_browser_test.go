@@ -0,0 +1,54 @@
+package codegen
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunWasm(t *testing.T) {
+	schemaInput := "version 1\n\ntype User {\n  id: string\n}\n\nproc GetUser {\n  input {\n    id: string\n  }\n\n  output {\n    user: User\n  }\n}\n"
+
+	paths := func(output RunWasmOutput) []string {
+		result := []string{}
+		for _, file := range output.Files {
+			result = append(result, file.Path)
+		}
+		return result
+	}
+
+	tests := []struct {
+		opts     RunWasmOptions
+		expected []string
+	}{
+		{RunWasmOptions{Generator: "golang-server", GolangPackageName: "api"}, []string{"server.go"}},
+		{RunWasmOptions{Generator: "golang-client", GolangPackageName: "api"}, []string{"client.go"}},
+		{RunWasmOptions{Generator: "typescript-client"}, []string{"client.ts"}},
+		{RunWasmOptions{Generator: "dart-client", DartPackageName: "api"}, []string{".gitignore", "lib/client.dart", "pubspec.lock", "pubspec.yaml"}},
+		{RunWasmOptions{Generator: "openapi"}, []string{"openapi.yaml"}},
+		{RunWasmOptions{Generator: "jsonschema"}, []string{"schema.json"}},
+		{RunWasmOptions{Generator: "asyncapi"}, []string{"asyncapi.yaml"}},
+	}
+	for _, test := range tests {
+		test.opts.SchemaInput = schemaInput
+		output, err := runWasm(test.opts)
+		require.NoError(t, err, test.opts.Generator)
+		require.Equal(t, test.expected, paths(output), test.opts.Generator)
+	}
+
+	output, err := runWasm(RunWasmOptions{Generator: "playground", SchemaInput: schemaInput})
+	require.NoError(t, err)
+	require.Contains(t, paths(output), "schema.urpc")
+	require.Contains(t, paths(output), "openapi.yaml")
+
+	output, err = runWasm(RunWasmOptions{Generator: "docs", SchemaInput: schemaInput})
+	require.NoError(t, err)
+	require.Contains(t, paths(output), "index.html")
+
+	_, err = runWasm(RunWasmOptions{Generator: "golang-server", SchemaInput: schemaInput})
+	require.EqualError(t, err, "golang-server requires 'GolangPackageName'")
+	_, err = runWasm(RunWasmOptions{Generator: "template", SchemaInput: schemaInput})
+	require.EqualError(t, err, "the template generator is not supported in the browser")
+	_, err = runWasm(RunWasmOptions{Generator: "kotlin", SchemaInput: schemaInput})
+	require.EqualError(t, err, "unsupported generator: kotlin")
+}
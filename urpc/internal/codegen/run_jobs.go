@@ -0,0 +1,80 @@
+package codegen
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// job generates a single target.
+type job struct {
+	name string
+	run  func(out *output) error
+}
+
+// runJobs runs the jobs concurrently, at most maxJobs at the same time or
+// the number of CPUs if maxJobs is not positive.
+//
+// Every job writes to its own output and the outputs are merged in the
+// order of the jobs, so the result does not depend on the scheduling. Two
+// jobs writing the same file is an error. The errors of all the failing jobs
+// are returned joined.
+func runJobs(rootDir string, h header, jobs []job, maxJobs int) (*output, Report, error) {
+	if maxJobs <= 0 {
+		maxJobs = runtime.GOMAXPROCS(0)
+	}
+
+	outputs := make([]*output, len(jobs))
+	report := Report{Targets: make([]TargetReport, len(jobs))}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, maxJobs)
+	for i, j := range jobs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			startTime := time.Now()
+			jobOut := newOutput(rootDir, h)
+			err := j.run(jobOut)
+			outputs[i] = jobOut
+			report.Targets[i] = TargetReport{
+				Name:     j.name,
+				Duration: time.Since(startTime),
+				Files:    len(jobOut.fs.Files()),
+				Err:      err,
+			}
+		}()
+	}
+	wg.Wait()
+
+	out := newOutput(rootDir, h)
+	owners := map[string]string{}
+	errs := []error{}
+	for i, target := range report.Targets {
+		if target.Err != nil {
+			errs = append(errs, fmt.Errorf("failed to run %s code generator: %w", target.Name, target.Err))
+			continue
+		}
+		for _, file := range outputs[i].fs.Files() {
+			if owner, ok := owners[file.Name]; ok {
+				errs = append(errs, fmt.Errorf("%s and %s code generators both write to %s", owner, target.Name, file.Name))
+				continue
+			}
+			owners[file.Name] = target.Name
+			if err := out.addFile(file.Name, file.Content); err != nil {
+				return nil, report, err
+			}
+		}
+		out.cleanDirs = append(out.cleanDirs, outputs[i].cleanDirs...)
+	}
+	if len(errs) > 0 {
+		return nil, report, errors.Join(errs...)
+	}
+
+	return out, report, nil
+}
@@ -0,0 +1,123 @@
+package codegen
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunJobsDeterministic(t *testing.T) {
+	jobs := []job{}
+	for _, name := range []string{"c", "a", "b", "d", "e"} {
+		jobs = append(jobs, job{name: name, run: func(out *output) error {
+			return out.addFile(name+".txt", []byte(name))
+		}})
+	}
+
+	for _, maxJobs := range []int{0, 1, 3, 10} {
+		out, report, err := runJobs(".", header{}, jobs, maxJobs)
+		require.NoError(t, err)
+
+		names := []string{}
+		for _, target := range report.Targets {
+			names = append(names, target.Name)
+			require.Equal(t, 1, target.Files)
+		}
+		require.Equal(t, []string{"c", "a", "b", "d", "e"}, names)
+
+		content, ok := out.fs.ReadFile("a.txt")
+		require.True(t, ok)
+		require.Equal(t, "a", string(content))
+		require.Len(t, out.fs.Files(), 5)
+	}
+}
+
+func TestRunJobsAggregatesErrors(t *testing.T) {
+	jobs := []job{
+		{name: "first", run: func(out *output) error { return errors.New("first problem") }},
+		{name: "ok", run: func(out *output) error { return out.addFile("ok.txt", nil) }},
+		{name: "second", run: func(out *output) error { return errors.New("second problem") }},
+	}
+
+	_, report, err := runJobs(".", header{}, jobs, 2)
+	require.EqualError(t, err, "failed to run first code generator: first problem\nfailed to run second code generator: second problem")
+	require.Len(t, report.Targets, 3)
+	require.NoError(t, report.Targets[1].Err)
+	require.EqualError(t, report.Targets[2].Err, "second problem")
+}
+
+func TestRunJobsDuplicatedFile(t *testing.T) {
+	jobs := []job{
+		{name: "template", run: func(out *output) error { return out.addFile("./gen/user.txt", []byte("template")) }},
+		{name: "ok", run: func(out *output) error { return out.addFile("gen/ok.txt", nil) }},
+		{name: "plugin", run: func(out *output) error { return out.addFile("gen/user.txt", []byte("plugin")) }},
+	}
+
+	_, _, err := runJobs(".", header{}, jobs, 2)
+	require.EqualError(t, err, "template and plugin code generators both write to gen/user.txt")
+}
+
+func TestRunWithOptionsParallel(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "schema.urpc"), []byte("version 1\n\ntype User {\n  id: string\n}\n"), 0644))
+
+	configPath := filepath.Join(dir, "uforpc.toml")
+	require.NoError(t, os.WriteFile(configPath, []byte(`
+version = 1
+schema = "./schema.urpc"
+
+[[openapi]]
+output_file = "./openapi.json"
+
+[[playground]]
+output_dir = "./playground"
+
+[[golang-server]]
+output_file = "./server/server.go"
+package_name = "server"
+
+[[golang-client]]
+output_file = "./a/client.go"
+package_name = "a"
+
+[[golang-client]]
+output_file = "./b/client.go"
+package_name = "b"
+
+[[typescript-client]]
+output_file = "./ts/client.ts"
+
+[[dart-client]]
+output_dir = "./dart_a"
+package_name = "dart_a"
+
+[[dart-client]]
+output_dir = "./dart_b"
+package_name = "dart_b"
+
+[[docs]]
+output_dir = "./docs"
+`), 0644))
+
+	report, err := RunWithOptions(configPath, Options{Jobs: 3})
+	require.NoError(t, err)
+
+	names := []string{}
+	for _, target := range report.Targets {
+		names = append(names, target.Name)
+	}
+	require.Equal(t, []string{"openapi", "playground", "golang-server", "golang-client[0]", "golang-client[1]", "typescript-client", "dart-client[0]", "dart-client[1]", "docs"}, names)
+
+	for _, pkg := range []string{"dart_a", "dart_b"} {
+		content, err := os.ReadFile(filepath.Join(dir, pkg, "pubspec.yaml"))
+		require.NoError(t, err)
+		require.Contains(t, string(content), "name: \""+pkg+"\"\n")
+	}
+
+	result, err := Check(configPath, Options{Jobs: 1})
+	require.NoError(t, err)
+	require.Empty(t, result.Stale)
+}
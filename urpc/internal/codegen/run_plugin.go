@@ -0,0 +1,91 @@
+package codegen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/uforg/uforpc/urpc/internal/schema"
+	"github.com/uforg/uforpc/urpc/plugin"
+)
+
+func runPlugin(out *output, config *PluginConfig, schema schema.Schema) error {
+	outputDir := filepath.Join(out.rootDir, config.OutputDir)
+
+	options := config.Options
+	if options == nil {
+		options = map[string]any{}
+	}
+	reqBytes, err := json.Marshal(plugin.Request{
+		ProtocolVersion: plugin.ProtocolVersion,
+		Schema:          schema,
+		Options:         options,
+		OutputDir:       outputDir,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode plugin request: %w", err)
+	}
+
+	// Relative paths are resolved from the config directory, bare names are
+	// looked up in the PATH
+	command := config.Command
+	if !filepath.IsAbs(command) && (strings.ContainsRune(command, '/') || strings.ContainsRune(command, filepath.Separator)) {
+		command = filepath.Join(out.rootDir, command)
+	}
+
+	var stdout bytes.Buffer
+	cmd := exec.Command(command, config.Args...)
+	cmd.Dir = out.rootDir
+	cmd.Stdin = bytes.NewReader(reqBytes)
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to run plugin %s: %w", config.Command, err)
+	}
+
+	res := plugin.Response{}
+	if err := json.Unmarshal(stdout.Bytes(), &res); err != nil {
+		return fmt.Errorf("invalid response of plugin %s: %w", config.Command, err)
+	}
+
+	errs := []string{}
+	for _, diagnostic := range res.Diagnostics {
+		switch diagnostic.Severity {
+		case plugin.SeverityError:
+			errs = append(errs, diagnostic.Message)
+		case plugin.SeverityWarning, plugin.SeverityInfo:
+			log.Printf("UFO RPC: %s: %s: %s", config.Command, diagnostic.Severity, diagnostic.Message)
+		default:
+			return fmt.Errorf("invalid response of plugin %s: unknown diagnostic severity %q", config.Command, diagnostic.Severity)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("plugin %s reported errors: %s", config.Command, strings.Join(errs, "; "))
+	}
+
+	// All the paths are checked before adding any file to the output
+	seen := map[string]bool{}
+	for _, file := range res.Files {
+		if !filepath.IsLocal(filepath.FromSlash(file.Path)) {
+			return fmt.Errorf("invalid response of plugin %s: file path %q must be relative to the output directory", config.Command, file.Path)
+		}
+		if seen[file.Path] {
+			return fmt.Errorf("invalid response of plugin %s: duplicated file path %q", config.Command, file.Path)
+		}
+		seen[file.Path] = true
+	}
+
+	for _, file := range res.Files {
+		if err := out.addFile(path.Join(config.OutputDir, file.Path), []byte(file.Content)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,88 @@
+package codegen
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/uforg/uforpc/urpc/internal/schema"
+	"github.com/uforg/uforpc/urpc/plugin"
+)
+
+// TestPluginHelperProcess is not a real test, it is the plugin executed by
+// the tests of runPlugin using the test binary as command.
+func TestPluginHelperProcess(t *testing.T) {
+	mode := os.Getenv("URPC_TEST_PLUGIN_MODE")
+	if mode == "" {
+		return
+	}
+
+	plugin.Main(func(req plugin.Request) (plugin.Response, error) {
+		res := plugin.Response{}
+		switch mode {
+		case "files":
+			for _, typeNode := range req.Schema.GetTypeNodes() {
+				res.AddFile("types/"+typeNode.Name+".txt", typeNode.Name+" "+req.Options["suffix"].(string))
+			}
+			res.Warnf("written to %s", filepath.Base(req.OutputDir))
+		case "error":
+			res.Errorf("first problem")
+			res.Errorf("second problem")
+		case "escape":
+			res.AddFile("../outside.txt", "")
+		}
+		return res, nil
+	})
+	os.Exit(0)
+}
+
+func writePluginProject(t *testing.T, mode string) string {
+	t.Helper()
+	t.Setenv("URPC_TEST_PLUGIN_MODE", mode)
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "schema.urpc"), []byte("version 1\n\ntype User {\n  id: string\n}\n"), 0644))
+
+	configPath := filepath.Join(dir, "uforpc.toml")
+	require.NoError(t, os.WriteFile(configPath, []byte(`
+version = 1
+schema = "./schema.urpc"
+
+[[plugin]]
+command = "`+os.Args[0]+`"
+args = ["-test.run=TestPluginHelperProcess"]
+output_dir = "./out"
+options = { suffix = "generated" }
+`), 0644))
+	return configPath
+}
+
+func TestRunPlugin(t *testing.T) {
+	configPath := writePluginProject(t, "files")
+	require.NoError(t, Run(configPath))
+
+	content, err := os.ReadFile(filepath.Join(filepath.Dir(configPath), "out", "types", "User.txt"))
+	require.NoError(t, err)
+	require.Equal(t, "User generated", string(content))
+}
+
+func TestRunPluginErrors(t *testing.T) {
+	configPath := writePluginProject(t, "error")
+	err := Run(configPath)
+	require.ErrorContains(t, err, "failed to run plugin code generator: plugin ")
+	require.ErrorContains(t, err, " reported errors: first problem; second problem")
+
+	configPath = writePluginProject(t, "escape")
+	err = Run(configPath)
+	require.ErrorContains(t, err, `file path "../outside.txt" must be relative to the output directory`)
+}
+
+func TestPluginConfigValidate(t *testing.T) {
+	require.EqualError(t, PluginConfig{OutputDir: "./out"}.Validate(), `"command" is required`)
+	require.EqualError(t, PluginConfig{Command: "gen"}.Validate(), `"output_dir" is required`)
+	require.NoError(t, PluginConfig{Command: "gen", OutputDir: "./out"}.Validate())
+
+	err := runPlugin(newOutput(t.TempDir(), header{}), &PluginConfig{Command: "urpc-gen-does-not-exist", OutputDir: "out"}, schema.Schema{Version: 1})
+	require.ErrorContains(t, err, "failed to run plugin urpc-gen-does-not-exist")
+}
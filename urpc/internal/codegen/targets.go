@@ -0,0 +1,62 @@
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Targets are the entries of a generator in the config file. It can be
+// written as a single table, e.g. [golang-client], or as an array of tables,
+// e.g. [[golang-client]], to generate the same target more than once.
+type Targets[T any] []T
+
+// UnmarshalTOML implements the toml.Unmarshaler interface.
+func (t *Targets[T]) UnmarshalTOML(data any) error {
+	tables := []map[string]any{}
+	switch value := data.(type) {
+	case map[string]any:
+		tables = append(tables, value)
+	case []map[string]any:
+		tables = value
+	case []any:
+		for _, item := range value {
+			table, ok := item.(map[string]any)
+			if !ok {
+				return fmt.Errorf("expected a table or an array of tables")
+			}
+			tables = append(tables, table)
+		}
+	default:
+		return fmt.Errorf("expected a table or an array of tables")
+	}
+
+	targets := make(Targets[T], 0, len(tables))
+	for i, table := range tables {
+		// The table is encoded again to decode it with the toml tags of T
+		var buf bytes.Buffer
+		if err := toml.NewEncoder(&buf).Encode(table); err != nil {
+			return fmt.Errorf("entry %d: %w", i, err)
+		}
+
+		var target T
+		if _, err := toml.Decode(buf.String(), &target); err != nil {
+			return fmt.Errorf("entry %d: %w", i, err)
+		}
+		targets = append(targets, target)
+	}
+
+	*t = targets
+	return nil
+}
+
+// targetName returns the name used in the errors for the entry of a
+// generator, the index is only included when there are several entries,
+// e.g. "golang-client" or "golang-client[1]".
+func targetName(name string, index int, count int) string {
+	if count <= 1 {
+		return name
+	}
+	return fmt.Sprintf("%s[%d]", name, index)
+}
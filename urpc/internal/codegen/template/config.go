@@ -0,0 +1,50 @@
+package template
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// EachType renders the template once for every type.
+	EachType = "type"
+	// EachProc renders the template once for every procedure.
+	EachProc = "proc"
+	// EachStream renders the template once for every stream.
+	EachStream = "stream"
+)
+
+// Config is the configuration for the text/template generator.
+type Config struct {
+	// Template is the text/template file to render, relative to the config
+	// file.
+	Template string `toml:"template"`
+	// OutputFile is the file to output the rendered template to. When Each
+	// is set it is also a template rendered for every node, e.g.
+	// "./sql/{{snake .Node.Name}}.sql".
+	OutputFile string `toml:"output_file"`
+	// Each renders the template once per "type", "proc" or "stream" instead
+	// of once for the whole schema.
+	Each string `toml:"each"`
+	// Options are available in the template as .Options.
+	Options map[string]any `toml:"options"`
+}
+
+func (c Config) Validate() error {
+	if c.Template == "" {
+		return fmt.Errorf(`"template" is required`)
+	}
+	if c.OutputFile == "" {
+		return fmt.Errorf(`"output_file" is required`)
+	}
+	switch c.Each {
+	case "":
+	case EachType, EachProc, EachStream:
+		if !strings.Contains(c.OutputFile, "{{") {
+			return fmt.Errorf(`"output_file" must be a template, e.g. "./out/{{snake .Node.Name}}.txt", when "each" is set`)
+		}
+	default:
+		return fmt.Errorf(`"each" must be %q, %q or %q`, EachType, EachProc, EachStream)
+	}
+	return nil
+}
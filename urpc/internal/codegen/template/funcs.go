@@ -0,0 +1,147 @@
+package template
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/uforg/uforpc/urpc/internal/schema"
+	"github.com/uforg/uforpc/urpc/internal/util/strutil"
+)
+
+// funcs are the helper functions available in the templates.
+var funcs = texttemplate.FuncMap{
+	// Strings
+	"pascal":  strutil.ToPascalCase,
+	"camel":   strutil.ToCamelCase,
+	"snake":   strutil.ToSnakeCase,
+	"kebab":   strutil.ToKebabCase,
+	"upper":   strings.ToUpper,
+	"lower":   strings.ToLower,
+	"trim":    strings.TrimSpace,
+	"replace": func(old, new, s string) string { return strings.ReplaceAll(s, old, new) },
+	"join":    func(sep string, elems []string) string { return strings.Join(elems, sep) },
+	"quote":   strconv.Quote,
+	"json":    toJSON,
+
+	// Docs
+	"docLines": docLines,
+
+	// Fields
+	"goType":        goType,
+	"tsType":        tsType,
+	"isOptional":    func(field schema.FieldDefinition) bool { return field.Optional },
+	"isArray":       func(field schema.FieldDefinition) bool { return field.IsArray },
+	"isInline":      func(field schema.FieldDefinition) bool { return field.IsInline() },
+	"isCustomType":  func(field schema.FieldDefinition) bool { return field.IsCustomType() },
+	"isBuiltInType": func(field schema.FieldDefinition) bool { return field.IsBuiltInType() },
+	"typeName":      typeName,
+}
+
+func toJSON(value any) (string, error) {
+	jsonBytes, err := json.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode JSON: %w", err)
+	}
+	return string(jsonBytes), nil
+}
+
+// docLines returns the lines of a docstring without the common indentation,
+// or no lines if the docstring is nil or empty.
+func docLines(doc any) []string {
+	text := ""
+	switch value := doc.(type) {
+	case string:
+		text = value
+	case *string:
+		if value != nil {
+			text = *value
+		}
+	}
+
+	text = strings.TrimSpace(strutil.NormalizeIndent(text))
+	if text == "" {
+		return []string{}
+	}
+	return strings.Split(text, "\n")
+}
+
+// typeName returns the URPC type of the field, e.g. "string", "User[]" or
+// "object" for inline objects.
+func typeName(field schema.FieldDefinition) string {
+	name := "object"
+	if field.IsNamed() {
+		name = *field.TypeName
+	}
+	if field.IsArray {
+		name += "[]"
+	}
+	return name
+}
+
+// inlineTypeName returns the name of the type generated for an inline
+// object, the same used by the built-in generators when the parent type
+// name is given.
+func inlineTypeName(field schema.FieldDefinition, parent []string) string {
+	return strings.Join(parent, "") + strutil.ToPascalCase(field.Name)
+}
+
+// goType returns the Go type of the field. Optional fields are not wrapped,
+// use isOptional to render them as pointers or with the Optional type.
+func goType(field schema.FieldDefinition, parent ...string) string {
+	literal := "any"
+	switch {
+	case field.IsInline():
+		literal = inlineTypeName(field, parent)
+	case field.IsBuiltInType():
+		switch *field.TypeName {
+		case "string":
+			literal = "string"
+		case "int":
+			literal = "int"
+		case "float":
+			literal = "float64"
+		case "bool":
+			literal = "bool"
+		case "datetime":
+			literal = "time.Time"
+		}
+	case field.IsCustomType():
+		literal = *field.TypeName
+	}
+
+	if field.IsArray {
+		return "[]" + literal
+	}
+	return literal
+}
+
+// tsType returns the TypeScript type of the field. Optional fields are not
+// wrapped, use isOptional to render the "?" of the property.
+func tsType(field schema.FieldDefinition, parent ...string) string {
+	literal := "any"
+	switch {
+	case field.IsInline():
+		literal = inlineTypeName(field, parent)
+	case field.IsBuiltInType():
+		switch *field.TypeName {
+		case "string":
+			literal = "string"
+		case "int", "float":
+			literal = "number"
+		case "bool":
+			literal = "boolean"
+		case "datetime":
+			literal = "Date"
+		}
+	case field.IsCustomType():
+		literal = *field.TypeName
+	}
+
+	if field.IsArray {
+		return literal + "[]"
+	}
+	return literal
+}
@@ -0,0 +1,131 @@
+// Package template renders user defined text/template files against the
+// JSON representation of the schema.
+package template
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/uforg/uforpc/urpc/internal/schema"
+)
+
+// OutputFile represents a rendered file.
+type OutputFile struct {
+	Path    string `json:"path"`
+	Content string `json:"content"`
+}
+
+// Output represents the rendered files.
+type Output struct {
+	Files []OutputFile `json:"files"`
+}
+
+// Data is the data the templates are executed with.
+type Data struct {
+	Schema  schema.Schema
+	Types   []*schema.NodeType
+	Procs   []*schema.NodeProc
+	Streams []*schema.NodeStream
+	// Node is the *schema.NodeType, *schema.NodeProc or *schema.NodeStream
+	// being rendered when Each is set, nil otherwise.
+	Node any
+	// Options are the options of the config.
+	Options map[string]any
+}
+
+// Generate renders the template text for the schema. The name of the
+// template in the errors is the base name of config.Template so they point
+// at the file and line, e.g. "template: routes.tmpl:3:5: ...".
+func Generate(sch schema.Schema, config Config, templateText string) (Output, error) {
+	tmpl, err := texttemplate.New(path.Base(config.Template)).
+		Option("missingkey=error").
+		Funcs(funcs).
+		Parse(templateText)
+	if err != nil {
+		return Output{}, err
+	}
+
+	outputTmpl, err := texttemplate.New("output_file").
+		Option("missingkey=error").
+		Funcs(funcs).
+		Parse(config.OutputFile)
+	if err != nil {
+		return Output{}, fmt.Errorf("invalid output_file: %w", err)
+	}
+
+	options := config.Options
+	if options == nil {
+		options = map[string]any{}
+	}
+	data := Data{
+		Schema:  sch,
+		Types:   sch.GetTypeNodes(),
+		Procs:   sch.GetProcNodes(),
+		Streams: sch.GetStreamNodes(),
+		Options: options,
+	}
+
+	type namedNode struct {
+		kind string
+		name string
+		node any
+	}
+	nodes := []namedNode{}
+	switch config.Each {
+	case "":
+		file, err := render(tmpl, outputTmpl, data)
+		if err != nil {
+			return Output{}, err
+		}
+		return Output{Files: []OutputFile{file}}, nil
+	case EachType:
+		for _, node := range data.Types {
+			nodes = append(nodes, namedNode{kind: EachType, name: node.Name, node: node})
+		}
+	case EachProc:
+		for _, node := range data.Procs {
+			nodes = append(nodes, namedNode{kind: EachProc, name: node.Name, node: node})
+		}
+	case EachStream:
+		for _, node := range data.Streams {
+			nodes = append(nodes, namedNode{kind: EachStream, name: node.Name, node: node})
+		}
+	}
+
+	output := Output{Files: []OutputFile{}}
+	rendered := map[string]string{}
+	for _, n := range nodes {
+		data.Node = n.node
+		file, err := render(tmpl, outputTmpl, data)
+		if err != nil {
+			return Output{}, fmt.Errorf("failed to render %s %s: %w", n.kind, n.name, err)
+		}
+		if previous, ok := rendered[file.Path]; ok {
+			return Output{}, fmt.Errorf("%s and %s %s render the same output_file %s", previous, n.kind, n.name, file.Path)
+		}
+		rendered[file.Path] = n.kind + " " + n.name
+		output.Files = append(output.Files, file)
+	}
+
+	return output, nil
+}
+
+func render(tmpl *texttemplate.Template, outputTmpl *texttemplate.Template, data Data) (OutputFile, error) {
+	var outputPath strings.Builder
+	if err := outputTmpl.Execute(&outputPath, data); err != nil {
+		return OutputFile{}, fmt.Errorf("invalid output_file: %w", err)
+	}
+	if strings.TrimSpace(outputPath.String()) == "" {
+		return OutputFile{}, fmt.Errorf("output_file rendered to an empty path")
+	}
+
+	var content bytes.Buffer
+	if err := tmpl.Execute(&content, data); err != nil {
+		return OutputFile{}, err
+	}
+
+	return OutputFile{Path: outputPath.String(), Content: content.String()}, nil
+}
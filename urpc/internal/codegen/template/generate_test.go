@@ -0,0 +1,133 @@
+package template
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/uforg/uforpc/urpc/internal/schema"
+)
+
+func testSchema(t *testing.T) schema.Schema {
+	t.Helper()
+
+	sch, err := schema.ParseSchema(`{
+		"version": 1,
+		"nodes": [
+			{
+				"kind": "type",
+				"name": "User",
+				"doc": "  A user.\n\n  Second paragraph.  ",
+				"fields": [
+					{"name": "id", "typeName": "string", "isArray": false, "optional": false},
+					{"name": "createdAt", "typeName": "datetime", "isArray": false, "optional": false},
+					{"name": "scores", "typeName": "float", "isArray": true, "optional": true},
+					{"name": "address", "typeInline": {"fields": [{"name": "zip", "typeName": "int", "isArray": false, "optional": false}]}, "isArray": false, "optional": false}
+				]
+			},
+			{
+				"kind": "proc",
+				"name": "GetUser",
+				"input": [{"name": "userId", "typeName": "string", "isArray": false, "optional": false}],
+				"output": [{"name": "user", "typeName": "User", "isArray": false, "optional": false}]
+			},
+			{
+				"kind": "proc",
+				"name": "DeleteUser",
+				"input": [],
+				"output": []
+			},
+			{
+				"kind": "stream",
+				"name": "WatchUsers",
+				"input": [],
+				"output": [{"name": "users", "typeName": "User", "isArray": true, "optional": false}]
+			}
+		]
+	}`)
+	require.NoError(t, err)
+	return sch
+}
+
+func TestGenerateOnce(t *testing.T) {
+	config := Config{Template: "./templates/routes.tmpl", OutputFile: "./routes.txt", Options: map[string]any{"prefix": "/api"}}
+	output, err := Generate(testSchema(t), config, `
+{{- range .Procs }}
+POST {{ $.Options.prefix }}/{{ kebab .Name }} {{ snake .Name }}
+{{- end }}
+{{- range .Streams }}
+GET {{ $.Options.prefix }}/{{ kebab .Name }} (stream)
+{{- end }}
+`)
+	require.NoError(t, err)
+	require.Equal(t, Output{Files: []OutputFile{{
+		Path:    "./routes.txt",
+		Content: "\nPOST /api/get-user get_user\nPOST /api/delete-user delete_user\nGET /api/watch-users (stream)\n",
+	}}}, output)
+}
+
+func TestGenerateEach(t *testing.T) {
+	config := Config{Template: "type.ts.tmpl", OutputFile: "./types/{{ kebab .Node.Name }}.ts", Each: EachType}
+	output, err := Generate(testSchema(t), config, `
+{{- range docLines .Node.Doc }}
+// {{ . }}
+{{- end }}
+export interface {{ .Node.Name }} {
+{{- range .Node.Fields }}
+  {{ camel .Name }}{{ if isOptional . }}?{{ end }}: {{ tsType . $.Node.Name }}; // {{ typeName . }} {{ goType . $.Node.Name }}
+{{- end }}
+}
+`)
+	require.NoError(t, err)
+	require.Equal(t, Output{Files: []OutputFile{{
+		Path: "./types/user.ts",
+		Content: `
+// A user.
+// 
+// Second paragraph.
+export interface User {
+  id: string; // string string
+  createdAt: Date; // datetime time.Time
+  scores?: number[]; // float[] []float64
+  address: UserAddress; // object UserAddress
+}
+`,
+	}}}, output)
+
+	config = Config{Template: "proc.tmpl", OutputFile: "{{ snake .Node.Name }}.sql", Each: EachProc}
+	output, err = Generate(testSchema(t), config, `{{ quote .Node.Name }} {{ len .Node.Input }} {{ json .Node.Output }}`)
+	require.NoError(t, err)
+	require.Equal(t, []OutputFile{
+		{Path: "get_user.sql", Content: `"GetUser" 1 [{"name":"user","typeName":"User","isArray":false,"optional":false}]`},
+		{Path: "delete_user.sql", Content: `"DeleteUser" 0 []`},
+	}, output.Files)
+}
+
+func TestGenerateErrors(t *testing.T) {
+	sch := testSchema(t)
+
+	_, err := Generate(sch, Config{Template: "./tpl/bad.tmpl", OutputFile: "out.txt"}, "line 1\n{{ .Types }\n")
+	require.EqualError(t, err, `template: bad.tmpl:2: unexpected "}" in operand`)
+
+	_, err = Generate(sch, Config{Template: "./tpl/bad.tmpl", OutputFile: "out.txt"}, "line 1\nline 2\n  {{ .Missing }}\n")
+	require.ErrorContains(t, err, `template: bad.tmpl:3:5: executing "bad.tmpl" at <.Missing>: can't evaluate field Missing`)
+
+	_, err = Generate(sch, Config{Template: "bad.tmpl", OutputFile: "out.txt"}, "{{ .Options.missing }}")
+	require.ErrorContains(t, err, `template: bad.tmpl:1:11: executing "bad.tmpl" at <.Options.missing>: map has no entry for key "missing"`)
+
+	_, err = Generate(sch, Config{Template: "t.tmpl", OutputFile: "{{ .Node.Name }}.txt", Each: EachType}, "{{ .Node.Input }}")
+	require.ErrorContains(t, err, "failed to render type User: template: t.tmpl:1:8:")
+
+	_, err = Generate(sch, Config{Template: "t.tmpl", OutputFile: "{{ if .Node.Name }}same{{ end }}.txt", Each: EachProc}, "")
+	require.EqualError(t, err, "proc GetUser and proc DeleteUser render the same output_file same.txt")
+
+	_, err = Generate(sch, Config{Template: "t.tmpl", OutputFile: "{{ .Nope }}", Each: EachProc}, "")
+	require.ErrorContains(t, err, "failed to render proc GetUser: invalid output_file: template: output_file:1:3:")
+}
+
+func TestConfigValidate(t *testing.T) {
+	require.EqualError(t, Config{OutputFile: "a"}.Validate(), `"template" is required`)
+	require.EqualError(t, Config{Template: "a"}.Validate(), `"output_file" is required`)
+	require.EqualError(t, Config{Template: "a", OutputFile: "b", Each: "field"}.Validate(), `"each" must be "type", "proc" or "stream"`)
+	require.ErrorContains(t, Config{Template: "a", OutputFile: "b", Each: EachType}.Validate(), `"output_file" must be a template`)
+	require.NoError(t, Config{Template: "a", OutputFile: "{{ .Node.Name }}", Each: EachStream}.Validate())
+}
@@ -0,0 +1,65 @@
+package codegen
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+
+	"github.com/uforg/uforpc/urpc/internal/urpc/analyzer"
+	"github.com/uforg/uforpc/urpc/internal/urpc/docstore"
+	"github.com/uforg/uforpc/urpc/internal/util/filepathutil"
+)
+
+// WatchedFiles returns the sorted absolute paths of the files the code
+// generation depends on: the config file and the files it extends, the
+// schema file, the files of the template generators and the external
+// markdown files referenced by the schema docstrings.
+//
+// Referenced files that do not exist are also returned so their creation
+// can be detected. Errors in the schema are ignored, but if the config is
+// invalid the error is returned together with the config files.
+func WatchedFiles(configPath string, profile string) ([]string, error) {
+	absConfigPath, err := filepathutil.NormalizeFromWD(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to normalize config path: %w", err)
+	}
+	files := []string{absConfigPath}
+
+	config, err := LoadConfig(configPath, profile)
+	files = append(files, config.SourceFiles()...)
+	if err != nil {
+		return files, fmt.Errorf("invalid config: %w", err)
+	}
+
+	absSchemaPath := filepath.Join(filepath.Dir(absConfigPath), config.Schema)
+	files = append(files, absSchemaPath)
+	for _, cfg := range config.Template {
+		files = append(files, filepath.Join(filepath.Dir(absConfigPath), cfg.Template))
+	}
+
+	recorder := &recordingFileProvider{fileProvider: docstore.NewDocstore()}
+	an, err := analyzer.NewAnalyzer(recorder)
+	if err != nil {
+		return files, fmt.Errorf("failed to create URPC analyzer: %w", err)
+	}
+	_, _, _ = an.Analyze(absSchemaPath)
+
+	files = append(files, recorder.paths...)
+	slices.Sort(files)
+	return slices.Compact(files), nil
+}
+
+// recordingFileProvider is an analyzer.FileProvider that records the
+// normalized paths of all the files requested through it.
+type recordingFileProvider struct {
+	fileProvider analyzer.FileProvider
+	paths        []string
+}
+
+// GetFileAndHash implements the analyzer.FileProvider interface.
+func (r *recordingFileProvider) GetFileAndHash(relativeTo string, path string) (string, string, error) {
+	if normPath, err := filepathutil.Normalize(relativeTo, path); err == nil {
+		r.paths = append(r.paths, normPath)
+	}
+	return r.fileProvider.GetFileAndHash(relativeTo, path)
+}
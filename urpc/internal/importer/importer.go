@@ -0,0 +1,313 @@
+// Package importer contains the helpers shared by the converters of API
+// definitions written in other formats (e.g. OpenAPI or Protocol Buffers)
+// to URPC schemas.
+//
+// Converters build the JSON representation of the schema with a Builder,
+// which takes care of producing valid URPC identifiers and of the
+// constructs URPC does not support, and then call Builder.Result to get the
+// AST schema.
+package importer
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/uforg/uforpc/urpc/internal/schema"
+	"github.com/uforg/uforpc/urpc/internal/transpile"
+	"github.com/uforg/uforpc/urpc/internal/urpc/ast"
+	"github.com/uforg/uforpc/urpc/internal/urpc/formatter"
+	"github.com/uforg/uforpc/urpc/internal/urpc/token"
+	"github.com/uforg/uforpc/urpc/internal/util/strutil"
+)
+
+// Warning is a construct of the source document that could not be
+// converted exactly.
+type Warning struct {
+	// Location is where the construct is in the source document, e.g.
+	// "components.schemas.User.properties.tags".
+	Location string
+	Message  string
+}
+
+// String returns the warning as "location: message".
+func (w Warning) String() string {
+	if w.Location == "" {
+		return w.Message
+	}
+	return w.Location + ": " + w.Message
+}
+
+// Result is the outcome of an import.
+type Result struct {
+	Schema   ast.Schema
+	Warnings []Warning
+}
+
+// Format returns the formatted URPC schema.
+func (r Result) Format() string {
+	return formatter.FormatSchema(&r.Schema)
+}
+
+// Builder accumulates the nodes and warnings of an import.
+type Builder struct {
+	nodes    []schema.Node
+	warnings []Warning
+	names    map[string]string
+}
+
+// NewBuilder creates an empty builder.
+func NewBuilder() *Builder {
+	return &Builder{names: map[string]string{}}
+}
+
+// Warnf records a warning at the location, warnings already recorded are
+// ignored because the same construct can be converted more than once.
+func (b *Builder) Warnf(location string, format string, args ...any) {
+	warning := Warning{Location: location, Message: fmt.Sprintf(format, args...)}
+	if !slices.Contains(b.warnings, warning) {
+		b.warnings = append(b.warnings, warning)
+	}
+}
+
+// AddDoc adds a standalone documentation node, empty content is ignored.
+func (b *Builder) AddDoc(content string) {
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return
+	}
+	b.nodes = append(b.nodes, &schema.NodeDoc{Kind: "doc", Content: Docstring(content)})
+}
+
+// AddNode adds a type, proc or stream node.
+func (b *Builder) AddNode(node schema.Node) {
+	b.nodes = append(b.nodes, node)
+}
+
+// DeclareName returns a unique PascalCase name for the type, procedure or
+// stream of the source document found at the location. Declaring the same
+// location again returns the same name, so references can be resolved
+// before the declaration is added.
+func (b *Builder) DeclareName(location string, sourceName string) string {
+	if name, ok := b.names[location]; ok {
+		return name
+	}
+
+	name := PascalName(sourceName)
+	base := name
+	for n := 2; slices.Contains(b.declaredNames(), name); n++ {
+		name = fmt.Sprintf("%s%d", base, n)
+	}
+	// Capitalizing the name is expected, e.g. for OpenAPI operation ids
+	if name != strutil.Capitalize(sourceName) {
+		b.Warnf(location, "renamed %q to %q", sourceName, name)
+	}
+
+	b.names[location] = name
+	return name
+}
+
+// LookupName returns the name declared for the location.
+func (b *Builder) LookupName(location string) (string, bool) {
+	name, ok := b.names[location]
+	return name, ok
+}
+
+func (b *Builder) declaredNames() []string {
+	names := make([]string, 0, len(b.names))
+	for _, name := range b.names {
+		names = append(names, name)
+	}
+	return names
+}
+
+// FieldName returns a valid URPC field name for the source name, warning
+// if it had to be changed. The wire name of a renamed field changes too.
+func (b *Builder) FieldName(location string, sourceName string) string {
+	name := CamelName(sourceName)
+	if name != sourceName {
+		b.Warnf(location, "field %q renamed to %q, the JSON name changes too", sourceName, name)
+	}
+	return name
+}
+
+// Result breaks the circular references between types, which URPC does
+// not support, and returns the AST schema with all the warnings.
+func (b *Builder) Result() (Result, error) {
+	sch := schema.Schema{Version: 1, Nodes: b.nodes}
+	b.breakCycles(sch)
+	indentFieldDocs(sch)
+
+	astSchema, err := transpile.ToURPC(sch)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to build URPC schema: %w", err)
+	}
+
+	return Result{Schema: astSchema, Warnings: b.warnings}, nil
+}
+
+// breakCycles removes the fields that close a circular reference between
+// types.
+func (b *Builder) breakCycles(sch schema.Schema) {
+	types := sch.GetTypeNodesMap()
+	done := map[string]bool{}
+
+	var visitType func(name string, stack []string)
+	var visitFields func(fields []schema.FieldDefinition, stack []string, path string) []schema.FieldDefinition
+
+	visitFields = func(fields []schema.FieldDefinition, stack []string, path string) []schema.FieldDefinition {
+		kept := fields[:0]
+		for _, field := range fields {
+			fieldPath := path + "." + field.Name
+			switch {
+			case field.IsInline():
+				field.TypeInline.Fields = visitFields(field.TypeInline.Fields, stack, fieldPath)
+			case field.IsCustomType() && slices.Contains(stack, *field.TypeName):
+				cycle := append(slices.Clone(stack[slices.Index(stack, *field.TypeName):]), *field.TypeName)
+				b.Warnf(fieldPath, "field removed, circular references between types are not supported (%s)", strings.Join(cycle, " -> "))
+				continue
+			case field.IsCustomType():
+				visitType(*field.TypeName, stack)
+			}
+			kept = append(kept, field)
+		}
+		return kept
+	}
+
+	visitType = func(name string, stack []string) {
+		typeNode, ok := types[name]
+		if !ok || done[name] {
+			return
+		}
+		typeNode.Fields = visitFields(typeNode.Fields, append(stack, name), name)
+		done[name] = true
+	}
+
+	for _, typeNode := range sch.GetTypeNodes() {
+		visitType(typeNode.Name, nil)
+	}
+}
+
+// indentFieldDocs indents the multi-line docstrings of the fields to the
+// depth of the field because the formatter keeps docstrings as they are.
+func indentFieldDocs(sch schema.Schema) {
+	var indentFields func(fields []schema.FieldDefinition, depth int)
+	indentFields = func(fields []schema.FieldDefinition, depth int) {
+		indent := strings.Repeat("  ", depth)
+		for i := range fields {
+			if doc := fields[i].Doc; doc != nil && strings.Contains(*doc, "\n") {
+				lines := strings.Split(strings.Trim(*doc, "\n"), "\n")
+				for j, line := range lines {
+					if line != "" {
+						lines[j] = indent + line
+					}
+				}
+				indented := "\n" + strings.Join(lines, "\n") + "\n" + indent
+				fields[i].Doc = &indented
+			}
+			if fields[i].IsInline() {
+				indentFields(fields[i].TypeInline.Fields, depth+1)
+			}
+		}
+	}
+
+	for _, node := range sch.Nodes {
+		switch n := node.(type) {
+		case *schema.NodeType:
+			indentFields(n.Fields, 1)
+		case *schema.NodeProc:
+			indentFields(n.Input, 2)
+			indentFields(n.Output, 2)
+		case *schema.NodeStream:
+			indentFields(n.Input, 2)
+			indentFields(n.Output, 2)
+		}
+	}
+}
+
+// PascalName converts a name of the source document to a valid PascalCase
+// URPC identifier, keeping the casing of the words.
+//
+// Example:
+//
+//	"user_profile" -> "UserProfile"
+//	"getUserByID"  -> "GetUserByID"
+//	"2fa-status"   -> "T2faStatus"
+func PascalName(sourceName string) string {
+	name := joinWords(sourceName)
+	if name == "" {
+		return "Unnamed"
+	}
+	if !unicode.IsLetter(rune(name[0])) {
+		name = "T" + name
+	}
+	return name
+}
+
+// CamelName converts a name of the source document to a valid camelCase
+// URPC identifier that is not a keyword.
+//
+// Example:
+//
+//	"user_id"   -> "userId"
+//	"CreatedAt" -> "createdAt"
+//	"type"      -> "typeValue"
+func CamelName(sourceName string) string {
+	name := joinWords(sourceName)
+	if name == "" {
+		return "field"
+	}
+	if !unicode.IsLetter(rune(name[0])) {
+		name = "f" + name
+	} else {
+		name = strings.ToLower(name[:1]) + name[1:]
+	}
+	if token.IsKeyword(name) {
+		name += "Value"
+	}
+	return name
+}
+
+// joinWords joins the words of the string with the first letter of each
+// word capitalized.
+func joinWords(str string) string {
+	name := ""
+	for _, word := range identifierWords(str) {
+		name += strutil.Capitalize(word)
+	}
+	return name
+}
+
+// identifierWords splits the string in the words separated by characters
+// that are not valid in an URPC identifier.
+func identifierWords(str string) []string {
+	return strings.FieldsFunc(str, func(r rune) bool {
+		isLetter := ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z')
+		isDigit := '0' <= r && r <= '9'
+		return !isLetter && !isDigit
+	})
+}
+
+// Docstring returns the text formatted as the value of a docstring, or an
+// empty string if there is no text.
+func Docstring(text string) string {
+	text = strings.TrimSpace(strutil.NormalizeIndent(text))
+	if text == "" {
+		return ""
+	}
+	// Docstrings can't be escaped
+	text = strings.ReplaceAll(text, `"""`, `'''`)
+	if strings.Contains(text, "\n") {
+		return "\n" + text + "\n"
+	}
+	return " " + text + " "
+}
+
+// Ptr returns a pointer to the string, or nil if it is empty.
+func Ptr(str string) *string {
+	if str == "" {
+		return nil
+	}
+	return &str
+}
@@ -0,0 +1,108 @@
+package importer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/uforg/uforpc/urpc/internal/schema"
+)
+
+func TestPascalName(t *testing.T) {
+	testCases := map[string]string{
+		"User":         "User",
+		"user_profile": "UserProfile",
+		"getUserByID":  "GetUserByID",
+		"2fa-status":   "T2faStatus",
+		"pkg.v1.Order": "PkgV1Order",
+		"---":          "Unnamed",
+	}
+	for input, expected := range testCases {
+		require.Equal(t, expected, PascalName(input), input)
+	}
+}
+
+func TestCamelName(t *testing.T) {
+	testCases := map[string]string{
+		"id":         "id",
+		"user_id":    "userId",
+		"CreatedAt":  "createdAt",
+		"type":       "typeValue",
+		"datetime":   "datetimeValue",
+		"2fa":        "f2fa",
+		"@odata.id":  "odataId",
+		"first name": "firstName",
+		"_":          "field",
+	}
+	for input, expected := range testCases {
+		require.Equal(t, expected, CamelName(input), input)
+	}
+}
+
+func TestDocstring(t *testing.T) {
+	require.Equal(t, "", Docstring("  \n "))
+	require.Equal(t, " One line. ", Docstring("One line.\n"))
+	require.Equal(t, "\nFirst.\n\n  Indented.\n", Docstring("\n    First.\n\n      Indented.\n"))
+	require.Equal(t, " Quotes ''' inside. ", Docstring(`Quotes """ inside.`))
+}
+
+func TestBuilderNames(t *testing.T) {
+	b := NewBuilder()
+	require.Equal(t, "User", b.DeclareName("a.User", "User"))
+	require.Equal(t, "User", b.DeclareName("a.User", "User"))
+	require.Equal(t, "User2", b.DeclareName("b.user", "user"))
+	require.Equal(t, "GetUser", b.DeclareName("c", "getUser"))
+	require.Equal(t, "userId", b.FieldName("d", "user_id"))
+
+	name, ok := b.LookupName("b.user")
+	require.True(t, ok)
+	require.Equal(t, "User2", name)
+
+	require.Equal(t, []Warning{
+		{Location: "b.user", Message: `renamed "user" to "User2"`},
+		{Location: "d", Message: `field "user_id" renamed to "userId", the JSON name changes too`},
+	}, b.warnings)
+}
+
+func TestBuilderResult(t *testing.T) {
+	field := func(name string, typeName string) schema.FieldDefinition {
+		return schema.FieldDefinition{Name: name, TypeName: &typeName}
+	}
+
+	b := NewBuilder()
+	b.AddDoc("# Title")
+	b.AddNode(&schema.NodeType{Kind: "type", Name: "A", Fields: []schema.FieldDefinition{
+		field("id", "string"),
+		field("b", "B"),
+	}})
+	b.AddNode(&schema.NodeType{Kind: "type", Name: "B", Fields: []schema.FieldDefinition{
+		{Name: "meta", TypeInline: &schema.InlineTypeDefinition{Fields: []schema.FieldDefinition{field("a", "A")}}},
+		field("self", "B"),
+		field("count", "int"),
+	}})
+	b.Warnf("x", "first")
+	b.Warnf("x", "first")
+
+	result, err := b.Result()
+	require.NoError(t, err)
+	require.Equal(t, `version 1
+
+""" # Title """
+
+type A {
+  id: string
+  b: B
+}
+
+type B {
+  meta: {}
+  count: int
+}
+`, result.Format())
+
+	require.Equal(t, []string{
+		"x: first",
+		"B.meta.a: field removed, circular references between types are not supported (A -> B -> A)",
+		"B.self: field removed, circular references between types are not supported (B -> B)",
+	}, []string{result.Warnings[0].String(), result.Warnings[1].String(), result.Warnings[2].String()})
+	require.Len(t, result.Warnings, 3)
+}
@@ -0,0 +1,185 @@
+package openapi
+
+import (
+	"fmt"
+
+	"github.com/goccy/go-yaml"
+)
+
+// This file contains the subset of the OpenAPI 3 document model used by the
+// importer, anything else in the document is ignored.
+
+type document struct {
+	OpenAPI    string               `yaml:"openapi"`
+	Swagger    string               `yaml:"swagger"`
+	Info       info                 `yaml:"info"`
+	Paths      orderedMap[pathItem] `yaml:"paths"`
+	Components components           `yaml:"components"`
+	Webhooks   orderedMap[pathItem] `yaml:"webhooks"`
+}
+
+type info struct {
+	Title       string `yaml:"title"`
+	Description string `yaml:"description"`
+}
+
+type components struct {
+	Schemas       orderedMap[schemaObject] `yaml:"schemas"`
+	Parameters    orderedMap[parameter]    `yaml:"parameters"`
+	RequestBodies orderedMap[requestBody]  `yaml:"requestBodies"`
+	Responses     orderedMap[response]     `yaml:"responses"`
+}
+
+type pathItem struct {
+	Ref        string       `yaml:"$ref"`
+	Parameters []*parameter `yaml:"parameters"`
+	Get        *operation   `yaml:"get"`
+	Put        *operation   `yaml:"put"`
+	Post       *operation   `yaml:"post"`
+	Delete     *operation   `yaml:"delete"`
+	Options    *operation   `yaml:"options"`
+	Head       *operation   `yaml:"head"`
+	Patch      *operation   `yaml:"patch"`
+	Trace      *operation   `yaml:"trace"`
+}
+
+// operations returns the operations of the path item in the order they are
+// converted, paired with their method.
+func (p *pathItem) operations() []methodOperation {
+	all := []methodOperation{
+		{"get", p.Get}, {"put", p.Put}, {"post", p.Post}, {"delete", p.Delete},
+		{"options", p.Options}, {"head", p.Head}, {"patch", p.Patch}, {"trace", p.Trace},
+	}
+
+	operations := []methodOperation{}
+	for _, op := range all {
+		if op.Operation != nil {
+			operations = append(operations, op)
+		}
+	}
+	return operations
+}
+
+type methodOperation struct {
+	Method    string
+	Operation *operation
+}
+
+type operation struct {
+	OperationID string               `yaml:"operationId"`
+	Summary     string               `yaml:"summary"`
+	Description string               `yaml:"description"`
+	Deprecated  bool                 `yaml:"deprecated"`
+	Parameters  []*parameter         `yaml:"parameters"`
+	RequestBody *requestBody         `yaml:"requestBody"`
+	Responses   orderedMap[response] `yaml:"responses"`
+}
+
+type parameter struct {
+	Ref         string                `yaml:"$ref"`
+	Name        string                `yaml:"name"`
+	In          string                `yaml:"in"`
+	Description string                `yaml:"description"`
+	Required    bool                  `yaml:"required"`
+	Schema      *schemaObject         `yaml:"schema"`
+	Content     orderedMap[mediaType] `yaml:"content"`
+}
+
+type requestBody struct {
+	Ref         string                `yaml:"$ref"`
+	Description string                `yaml:"description"`
+	Required    bool                  `yaml:"required"`
+	Content     orderedMap[mediaType] `yaml:"content"`
+}
+
+type response struct {
+	Ref         string                `yaml:"$ref"`
+	Description string                `yaml:"description"`
+	Content     orderedMap[mediaType] `yaml:"content"`
+}
+
+type mediaType struct {
+	Schema *schemaObject `yaml:"schema"`
+}
+
+type schemaObject struct {
+	Ref                  string                   `yaml:"$ref"`
+	Type                 typeList                 `yaml:"type"`
+	Format               string                   `yaml:"format"`
+	Description          string                   `yaml:"description"`
+	Deprecated           bool                     `yaml:"deprecated"`
+	Nullable             bool                     `yaml:"nullable"`
+	Enum                 []any                    `yaml:"enum"`
+	Const                any                      `yaml:"const"`
+	Properties           orderedMap[schemaObject] `yaml:"properties"`
+	Required             []string                 `yaml:"required"`
+	Items                *schemaObject            `yaml:"items"`
+	PrefixItems          []*schemaObject          `yaml:"prefixItems"`
+	AllOf                []*schemaObject          `yaml:"allOf"`
+	OneOf                []*schemaObject          `yaml:"oneOf"`
+	AnyOf                []*schemaObject          `yaml:"anyOf"`
+	Not                  any                      `yaml:"not"`
+	AdditionalProperties any                      `yaml:"additionalProperties"`
+
+	// propertyLocations contains the locations of the properties merged
+	// from other schemas with allOf
+	propertyLocations map[string]string
+}
+
+// typeList is the type of a schema, which can be a single type or, since
+// OpenAPI 3.1, a list of types.
+type typeList []string
+
+func (t *typeList) UnmarshalYAML(unmarshal func(any) error) error {
+	var single string
+	if err := unmarshal(&single); err == nil {
+		*t = typeList{single}
+		return nil
+	}
+
+	var list []string
+	if err := unmarshal(&list); err != nil {
+		return fmt.Errorf("type must be a string or a list of strings: %w", err)
+	}
+	*t = list
+	return nil
+}
+
+// orderedMap is a YAML mapping that keeps the order of its keys, which is
+// used to keep the order of the document in the generated schema.
+type orderedMap[T any] struct {
+	Keys   []string
+	Values map[string]*T
+}
+
+func (m *orderedMap[T]) UnmarshalYAML(data []byte) error {
+	var items yaml.MapSlice
+	if err := yaml.UnmarshalWithOptions(data, &items, yaml.UseOrderedMap()); err != nil {
+		return err
+	}
+
+	m.Keys = make([]string, 0, len(items))
+	m.Values = make(map[string]*T, len(items))
+	for _, item := range items {
+		// Keys like response codes are decoded as numbers
+		key := fmt.Sprint(item.Key)
+
+		raw, err := yaml.Marshal(item.Value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		value := new(T)
+		if err := yaml.Unmarshal(raw, value); err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+
+		m.Keys = append(m.Keys, key)
+		m.Values[key] = value
+	}
+	return nil
+}
+
+// Len returns the number of entries of the map.
+func (m orderedMap[T]) Len() int {
+	return len(m.Keys)
+}
@@ -0,0 +1,628 @@
+// Package openapi converts OpenAPI 3 documents to URPC schemas.
+//
+// Object schemas of the components become types and operations become
+// procedures, with the path and query parameters and the JSON request body
+// as input and the JSON body of the successful response as output.
+// Constructs that can't be represented in URPC are skipped and reported as
+// warnings.
+package openapi
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/uforg/uforpc/urpc/internal/importer"
+	"github.com/uforg/uforpc/urpc/internal/schema"
+)
+
+// Import converts an OpenAPI 3.0 or 3.1 document in YAML or JSON format to
+// an URPC schema.
+func Import(content []byte) (importer.Result, error) {
+	var doc document
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return importer.Result{}, fmt.Errorf("failed to parse OpenAPI document: %w", err)
+	}
+
+	if doc.Swagger != "" {
+		return importer.Result{}, fmt.Errorf("swagger %s documents are not supported, only OpenAPI 3 documents are supported", doc.Swagger)
+	}
+	if !strings.HasPrefix(doc.OpenAPI, "3.") {
+		return importer.Result{}, fmt.Errorf("unsupported OpenAPI version %q, only OpenAPI 3 documents are supported", doc.OpenAPI)
+	}
+
+	c := &converter{doc: &doc, b: importer.NewBuilder(), resolving: map[string]bool{}}
+	c.convert()
+	return c.b.Result()
+}
+
+type converter struct {
+	doc *document
+	b   *importer.Builder
+	// resolving contains the component schemas being resolved, used to
+	// detect references to themselves of schemas that are not types
+	resolving map[string]bool
+}
+
+// fieldType is the URPC type of a schema.
+type fieldType struct {
+	TypeName   *string
+	TypeInline *schema.InlineTypeDefinition
+	IsArray    bool
+	Nullable   bool
+	// Doc contains notes about the original schema, like the allowed values
+	Doc string
+}
+
+func (c *converter) convert() {
+	title := strings.TrimSpace(c.doc.Info.Title)
+	if title != "" {
+		title = "# " + title
+	}
+	c.b.AddDoc(strings.TrimSpace(title + "\n\n" + c.doc.Info.Description))
+
+	if c.doc.Webhooks.Len() > 0 {
+		c.b.Warnf("webhooks", "webhooks are not supported, skipped")
+	}
+
+	// Types are declared before they are converted so references to types
+	// declared later in the document can be resolved
+	schemas := c.doc.Components.Schemas
+	for _, name := range schemas.Keys {
+		if c.isObjectSchema(schemas.Values[name]) {
+			c.b.DeclareName(schemaLocation(name), name)
+		}
+	}
+
+	for _, name := range schemas.Keys {
+		c.convertComponentSchema(name, schemas.Values[name])
+	}
+
+	for _, path := range c.doc.Paths.Keys {
+		item := c.doc.Paths.Values[path]
+		location := "paths." + path
+		if item == nil {
+			continue
+		}
+		if item.Ref != "" {
+			c.b.Warnf(location, "path item references are not supported, skipped")
+			continue
+		}
+		for _, op := range item.operations() {
+			c.convertOperation(location+"."+op.Method, path, op.Method, item, op.Operation)
+		}
+	}
+}
+
+func (c *converter) convertComponentSchema(name string, sch *schemaObject) {
+	location := schemaLocation(name)
+	typeName, ok := c.b.LookupName(location)
+	if !ok {
+		c.b.Warnf(location, "only object schemas are converted to types, it is inlined where it is used")
+		return
+	}
+
+	sch = c.mergeAllOf(location, sch)
+	c.b.AddNode(&schema.NodeType{
+		Kind:       "type",
+		Name:       typeName,
+		Doc:        importer.Ptr(importer.Docstring(sch.Description)),
+		Deprecated: deprecation(sch.Deprecated),
+		Fields:     c.convertProperties(location, sch),
+	})
+}
+
+func (c *converter) convertOperation(location string, path string, method string, item *pathItem, op *operation) {
+	sourceName := op.OperationID
+	if sourceName == "" {
+		sourceName = operationName(method, path)
+	}
+
+	proc := &schema.NodeProc{
+		Kind:       "proc",
+		Name:       c.b.DeclareName(location, sourceName),
+		Doc:        importer.Ptr(importer.Docstring(strings.TrimSpace(op.Summary + "\n\n" + op.Description))),
+		Deprecated: deprecation(op.Deprecated),
+		Input:      []schema.FieldDefinition{},
+		Output:     []schema.FieldDefinition{},
+	}
+
+	for _, param := range c.operationParameters(location, item, op) {
+		paramLocation := location + ".parameters." + param.Name
+		if param.In == "header" || param.In == "cookie" {
+			c.b.Warnf(paramLocation, "%s parameters are not supported, skipped", param.In)
+			continue
+		}
+		if param.Schema == nil {
+			c.b.Warnf(paramLocation, "parameters without schema are not supported, skipped")
+			continue
+		}
+
+		field, ok := c.convertField(paramLocation, param.Name, param.Schema, param.Required || param.In == "path")
+		if !ok {
+			continue
+		}
+		if param.Description != "" {
+			field.Doc = importer.Ptr(importer.Docstring(param.Description))
+		}
+		proc.Input = c.appendField(paramLocation, proc.Input, field)
+	}
+
+	if op.RequestBody != nil {
+		bodyLocation := location + ".requestBody"
+		body := c.resolveRequestBody(bodyLocation, op.RequestBody)
+		if body != nil {
+			if sch, ok := c.jsonSchema(bodyLocation, body.Content); ok {
+				for _, field := range c.convertBody(bodyLocation, "body", sch, body.Required) {
+					proc.Input = c.appendField(bodyLocation, proc.Input, field)
+				}
+			}
+		}
+	}
+
+	responseLocation, res := c.successResponse(location, op)
+	if res != nil {
+		if sch, ok := c.jsonSchema(responseLocation, res.Content); ok {
+			proc.Output = c.convertBody(responseLocation, "data", sch, true)
+		}
+	}
+
+	c.b.AddNode(proc)
+}
+
+// operationParameters returns the parameters of the path item overridden
+// by the parameters of the operation with the same name and location.
+func (c *converter) operationParameters(location string, item *pathItem, op *operation) []*parameter {
+	params := []*parameter{}
+	for _, param := range slices.Concat(item.Parameters, op.Parameters) {
+		param = c.resolveParameter(location, param)
+		if param == nil {
+			continue
+		}
+		index := slices.IndexFunc(params, func(p *parameter) bool {
+			return p.Name == param.Name && p.In == param.In
+		})
+		if index >= 0 {
+			params[index] = param
+		} else {
+			params = append(params, param)
+		}
+	}
+	return params
+}
+
+// successResponse returns the 200 response of the operation or, if there
+// is none, the first 2XX response.
+func (c *converter) successResponse(location string, op *operation) (string, *response) {
+	code := ""
+	if slices.Contains(op.Responses.Keys, "200") {
+		code = "200"
+	} else {
+		for _, key := range op.Responses.Keys {
+			if strings.HasPrefix(key, "2") {
+				code = key
+				break
+			}
+		}
+	}
+
+	if code == "" {
+		c.b.Warnf(location+".responses", "no successful response found, the output is empty")
+		return "", nil
+	}
+
+	responseLocation := location + ".responses." + code
+	return responseLocation, c.resolveResponse(responseLocation, op.Responses.Values[code])
+}
+
+// jsonSchema returns the schema of the JSON media type of the content.
+// Empty content is not reported.
+func (c *converter) jsonSchema(location string, content orderedMap[mediaType]) (*schemaObject, bool) {
+	for _, name := range content.Keys {
+		mediaType := strings.TrimSpace(strings.Split(name, ";")[0])
+		if mediaType != "application/json" && !strings.HasSuffix(mediaType, "+json") {
+			continue
+		}
+		media := content.Values[name]
+		if media == nil || media.Schema == nil {
+			return nil, false
+		}
+		return media.Schema, true
+	}
+
+	if content.Len() > 0 {
+		c.b.Warnf(location, "only JSON content is supported (found %s), skipped", strings.Join(content.Keys, ", "))
+	}
+	return nil, false
+}
+
+// convertBody converts the schema of a body to fields. The properties of
+// object schemas become the fields, any other schema is wrapped in a field
+// with the fallback name.
+func (c *converter) convertBody(location string, fallbackName string, sch *schemaObject, required bool) []schema.FieldDefinition {
+	resolvedLocation, resolved := c.resolveSchema(location, sch)
+	if c.isObjectSchema(resolved) {
+		return c.convertProperties(resolvedLocation, c.mergeAllOf(resolvedLocation, resolved))
+	}
+
+	field, ok := c.convertField(location, fallbackName, sch, required)
+	if !ok {
+		return []schema.FieldDefinition{}
+	}
+	return []schema.FieldDefinition{field}
+}
+
+// convertProperties converts the properties of an object schema to fields.
+func (c *converter) convertProperties(location string, sch *schemaObject) []schema.FieldDefinition {
+	if isEnabled(sch.AdditionalProperties) {
+		c.b.Warnf(location+".additionalProperties", "additional properties are not supported, ignored")
+	}
+
+	fields := []schema.FieldDefinition{}
+	for _, name := range sch.Properties.Keys {
+		propertyLocation := propertyLocation(location, sch, name)
+		field, ok := c.convertField(propertyLocation, name, sch.Properties.Values[name], slices.Contains(sch.Required, name))
+		if !ok {
+			continue
+		}
+		fields = c.appendField(propertyLocation, fields, field)
+	}
+	return fields
+}
+
+// appendField appends the field unless there is already a field with the
+// same name.
+func (c *converter) appendField(location string, fields []schema.FieldDefinition, field schema.FieldDefinition) []schema.FieldDefinition {
+	if slices.ContainsFunc(fields, func(f schema.FieldDefinition) bool { return f.Name == field.Name }) {
+		c.b.Warnf(location, "duplicate field %q, skipped", field.Name)
+		return fields
+	}
+	return append(fields, field)
+}
+
+func (c *converter) convertField(location string, name string, sch *schemaObject, required bool) (schema.FieldDefinition, bool) {
+	typ, ok := c.convertType(location, sch)
+	if !ok {
+		return schema.FieldDefinition{}, false
+	}
+
+	doc := ""
+	if sch != nil {
+		doc = sch.Description
+		if sch.Deprecated {
+			doc = strings.TrimSpace("Deprecated.\n\n" + doc)
+		}
+	}
+	doc = strings.TrimSpace(doc + "\n\n" + typ.Doc)
+
+	return schema.FieldDefinition{
+		Name:       c.b.FieldName(location, name),
+		Doc:        importer.Ptr(importer.Docstring(doc)),
+		TypeName:   typ.TypeName,
+		TypeInline: typ.TypeInline,
+		IsArray:    typ.IsArray,
+		Optional:   !required || typ.Nullable,
+	}, true
+}
+
+// convertType returns the URPC type of the schema, unsupported schemas
+// are reported and return false.
+func (c *converter) convertType(location string, sch *schemaObject) (fieldType, bool) {
+	if sch == nil {
+		c.b.Warnf(location, "missing schema, skipped")
+		return fieldType{}, false
+	}
+
+	if sch.Ref != "" {
+		name, ok := componentName(sch.Ref, "schemas")
+		if !ok {
+			c.b.Warnf(location, "only references to local component schemas are supported (found %q), skipped", sch.Ref)
+			return fieldType{}, false
+		}
+		if typeName, ok := c.b.LookupName(schemaLocation(name)); ok {
+			return fieldType{TypeName: &typeName}, true
+		}
+		component, ok := c.doc.Components.Schemas.Values[name]
+		if !ok {
+			c.b.Warnf(location, "reference to unknown schema %q, skipped", sch.Ref)
+			return fieldType{}, false
+		}
+		if c.resolving[name] {
+			c.b.Warnf(location, "circular reference to schema %q, skipped", name)
+			return fieldType{}, false
+		}
+		c.resolving[name] = true
+		defer delete(c.resolving, name)
+		return c.convertType(location, component)
+	}
+
+	if len(sch.OneOf) > 0 || len(sch.AnyOf) > 0 {
+		variants := slices.Concat(sch.OneOf, sch.AnyOf)
+		nonNull := slices.DeleteFunc(slices.Clone(variants), isNullSchema)
+		// A union with null is a nullable type
+		if len(nonNull) == 1 {
+			typ, ok := c.convertType(location, nonNull[0])
+			typ.Nullable = typ.Nullable || len(nonNull) < len(variants)
+			return typ, ok
+		}
+		c.b.Warnf(location, "oneOf and anyOf are not supported, skipped")
+		return fieldType{}, false
+	}
+
+	if len(sch.AllOf) > 0 {
+		sch = c.mergeAllOf(location, sch)
+	}
+
+	types := slices.DeleteFunc(slices.Clone(sch.Type), func(t string) bool { return t == "null" })
+	nullable := sch.Nullable || len(types) < len(sch.Type)
+	if len(types) > 1 {
+		c.b.Warnf(location, "multiple types are not supported (found %s), skipped", strings.Join(types, ", "))
+		return fieldType{}, false
+	}
+
+	typ := ""
+	if len(types) == 1 {
+		typ = types[0]
+	} else if sch.Properties.Len() > 0 {
+		typ = "object"
+	}
+
+	result := fieldType{Nullable: nullable, Doc: enumDoc(sch)}
+	switch typ {
+	case "string":
+		if sch.Format == "date-time" {
+			result.TypeName = importer.Ptr("datetime")
+		} else {
+			result.TypeName = importer.Ptr("string")
+		}
+	case "integer":
+		result.TypeName = importer.Ptr("int")
+	case "number":
+		result.TypeName = importer.Ptr("float")
+	case "boolean":
+		result.TypeName = importer.Ptr("bool")
+	case "array":
+		if sch.Items == nil {
+			c.b.Warnf(location, "arrays without items are not supported, skipped")
+			return fieldType{}, false
+		}
+		items, ok := c.convertType(location+".items", sch.Items)
+		if !ok {
+			return fieldType{}, false
+		}
+		if items.IsArray {
+			c.b.Warnf(location, "nested arrays are not supported, skipped")
+			return fieldType{}, false
+		}
+		if items.Nullable {
+			c.b.Warnf(location+".items", "nullable array items are not supported, items are required")
+		}
+		result.TypeName = items.TypeName
+		result.TypeInline = items.TypeInline
+		result.IsArray = true
+		result.Doc = strings.TrimSpace(result.Doc + "\n\n" + items.Doc)
+	case "object":
+		if sch.Properties.Len() == 0 {
+			if isEnabled(sch.AdditionalProperties) {
+				c.b.Warnf(location, "maps are not supported, skipped")
+			} else {
+				c.b.Warnf(location, "objects without properties are not supported, skipped")
+			}
+			return fieldType{}, false
+		}
+		result.TypeInline = &schema.InlineTypeDefinition{Fields: c.convertProperties(location, sch)}
+	case "":
+		c.b.Warnf(location, "schemas without type are not supported, skipped")
+		return fieldType{}, false
+	default:
+		c.b.Warnf(location, "type %q is not supported, skipped", typ)
+		return fieldType{}, false
+	}
+
+	return result, true
+}
+
+// mergeAllOf returns an object schema with the properties of all the
+// schemas of allOf merged with the properties of the schema.
+func (c *converter) mergeAllOf(location string, sch *schemaObject) *schemaObject {
+	if len(sch.AllOf) == 0 {
+		return sch
+	}
+
+	merged := &schemaObject{
+		Type:        typeList{"object"},
+		Description: sch.Description,
+		Deprecated:  sch.Deprecated,
+		Nullable:    sch.Nullable,
+		Properties:  orderedMap[schemaObject]{Values: map[string]*schemaObject{}},
+
+		propertyLocations: map[string]string{},
+	}
+
+	add := func(partLocation string, part *schemaObject) {
+		for _, name := range part.Properties.Keys {
+			if _, ok := merged.Properties.Values[name]; !ok {
+				merged.Properties.Keys = append(merged.Properties.Keys, name)
+			}
+			merged.Properties.Values[name] = part.Properties.Values[name]
+			merged.propertyLocations[name] = propertyLocation(partLocation, part, name)
+		}
+		merged.Required = append(merged.Required, part.Required...)
+		if merged.AdditionalProperties == nil {
+			merged.AdditionalProperties = part.AdditionalProperties
+		}
+	}
+
+	for i, part := range sch.AllOf {
+		partLocation, part := c.resolveSchema(fmt.Sprintf("%s.allOf.%d", location, i), part)
+		if part == nil {
+			continue
+		}
+		if !c.isObjectSchema(part) {
+			c.b.Warnf(partLocation, "only object schemas can be merged with allOf, skipped")
+			continue
+		}
+		part = c.mergeAllOf(partLocation, part)
+		if merged.Description == "" {
+			merged.Description = part.Description
+		}
+		add(partLocation, part)
+	}
+	add(location, sch)
+
+	return merged
+}
+
+// isObjectSchema returns true if the schema, following references, is an
+// object with properties that can be converted to a type.
+func (c *converter) isObjectSchema(sch *schemaObject) bool {
+	if sch == nil || len(sch.OneOf) > 0 || len(sch.AnyOf) > 0 {
+		return false
+	}
+	if sch.Ref != "" {
+		return false
+	}
+	if len(sch.AllOf) > 0 {
+		return true
+	}
+	isObject := sch.Type == nil || slices.Contains(sch.Type, "object")
+	return isObject && sch.Properties.Len() > 0
+}
+
+// resolveSchema follows the references to component schemas, it returns
+// the resolved schema and its location.
+func (c *converter) resolveSchema(location string, sch *schemaObject) (string, *schemaObject) {
+	for depth := 0; sch != nil && sch.Ref != ""; depth++ {
+		name, ok := componentName(sch.Ref, "schemas")
+		if !ok || depth > 32 {
+			c.b.Warnf(location, "unsupported reference %q, skipped", sch.Ref)
+			return location, nil
+		}
+		location = schemaLocation(name)
+		sch = c.doc.Components.Schemas.Values[name]
+	}
+	return location, sch
+}
+
+func (c *converter) resolveParameter(location string, param *parameter) *parameter {
+	if param == nil || param.Ref == "" {
+		return param
+	}
+	name, ok := componentName(param.Ref, "parameters")
+	resolved := c.doc.Components.Parameters.Values[name]
+	if !ok || resolved == nil || resolved.Ref != "" {
+		c.b.Warnf(location, "unsupported parameter reference %q, skipped", param.Ref)
+		return nil
+	}
+	return resolved
+}
+
+func (c *converter) resolveRequestBody(location string, body *requestBody) *requestBody {
+	if body.Ref == "" {
+		return body
+	}
+	name, ok := componentName(body.Ref, "requestBodies")
+	resolved := c.doc.Components.RequestBodies.Values[name]
+	if !ok || resolved == nil || resolved.Ref != "" {
+		c.b.Warnf(location, "unsupported request body reference %q, skipped", body.Ref)
+		return nil
+	}
+	return resolved
+}
+
+func (c *converter) resolveResponse(location string, res *response) *response {
+	if res == nil || res.Ref == "" {
+		return res
+	}
+	name, ok := componentName(res.Ref, "responses")
+	resolved := c.doc.Components.Responses.Values[name]
+	if !ok || resolved == nil || resolved.Ref != "" {
+		c.b.Warnf(location, "unsupported response reference %q, skipped", res.Ref)
+		return nil
+	}
+	return resolved
+}
+
+// componentName returns the name of the component of the section
+// referenced by a local reference like "#/components/schemas/User".
+func componentName(ref string, section string) (string, bool) {
+	prefix := "#/components/" + section + "/"
+	if !strings.HasPrefix(ref, prefix) {
+		return "", false
+	}
+	name := strings.TrimPrefix(ref, prefix)
+	// JSON pointer escapes
+	name = strings.ReplaceAll(name, "~1", "/")
+	name = strings.ReplaceAll(name, "~0", "~")
+	return name, true
+}
+
+// propertyLocation returns the location of a property of the schema.
+func propertyLocation(location string, sch *schemaObject, name string) string {
+	if merged, ok := sch.propertyLocations[name]; ok {
+		return merged
+	}
+	return location + ".properties." + name
+}
+
+func schemaLocation(name string) string {
+	return "components.schemas." + name
+}
+
+// operationName returns the name of an operation without operationId,
+// e.g. "get /users/{id}/posts" becomes "GetUsersByIdPosts".
+func operationName(method string, path string) string {
+	words := []string{method}
+	for _, segment := range strings.Split(path, "/") {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			words = append(words, "by", strings.Trim(segment, "{}"))
+			continue
+		}
+		words = append(words, segment)
+	}
+	return importer.PascalName(strings.Join(words, " "))
+}
+
+// enumDoc returns a note with the allowed values of enum and const
+// schemas, which URPC can't represent.
+func enumDoc(sch *schemaObject) string {
+	values := sch.Enum
+	if sch.Const != nil {
+		values = []any{sch.Const}
+	}
+	if len(values) == 0 {
+		return ""
+	}
+
+	formatted := make([]string, 0, len(values))
+	for _, value := range values {
+		if value == nil {
+			continue
+		}
+		formatted = append(formatted, fmt.Sprintf("`%v`", value))
+	}
+	return "Allowed values: " + strings.Join(formatted, ", ") + "."
+}
+
+func deprecation(deprecated bool) *string {
+	if !deprecated {
+		return nil
+	}
+	message := ""
+	return &message
+}
+
+func isNullSchema(sch *schemaObject) bool {
+	return sch != nil && len(sch.Type) == 1 && sch.Type[0] == "null"
+}
+
+// isEnabled returns true if a boolean or schema keyword like
+// additionalProperties is present and not false.
+func isEnabled(value any) bool {
+	if value == nil {
+		return false
+	}
+	enabled, isBool := value.(bool)
+	return !isBool || enabled
+}
@@ -0,0 +1,230 @@
+package openapi
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/uforg/uforpc/urpc/internal/importer"
+	"github.com/uforg/uforpc/urpc/internal/urpc/analyzer"
+	"github.com/uforg/uforpc/urpc/internal/urpc/docstore"
+)
+
+func importString(t *testing.T, content string) importer.Result {
+	t.Helper()
+	result, err := Import([]byte(content))
+	require.NoError(t, err)
+	return result
+}
+
+// requireValidSchema checks that the formatted schema passes the analyzer.
+func requireValidSchema(t *testing.T, formatted string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "schema.urpc")
+	require.NoError(t, os.WriteFile(path, []byte(formatted), 0644))
+
+	an, err := analyzer.NewAnalyzer(docstore.NewDocstore())
+	require.NoError(t, err)
+	_, diagnostics, err := an.Analyze(path)
+	require.NoError(t, err, "diagnostics: %v", diagnostics)
+}
+
+func warningStrings(result importer.Result) []string {
+	warnings := []string{}
+	for _, warning := range result.Warnings {
+		warnings = append(warnings, warning.String())
+	}
+	return warnings
+}
+
+func TestImportPetstore(t *testing.T) {
+	content, err := os.ReadFile("testdata/petstore.yaml")
+	require.NoError(t, err)
+	expected, err := os.ReadFile("testdata/petstore.urpc")
+	require.NoError(t, err)
+
+	result, err := Import(content)
+	require.NoError(t, err)
+
+	formatted := result.Format()
+	require.Equal(t, string(expected), formatted)
+	requireValidSchema(t, formatted)
+
+	require.Equal(t, []string{
+		"components.schemas.Id: only object schemas are converted to types, it is inlined where it is used",
+		`components.schemas.NewPet.properties.type: field "type" renamed to "typeValue", the JSON name changes too`,
+		`components.schemas.NewPet.properties.tag_names: field "tag_names" renamed to "tagNames", the JSON name changes too`,
+		"components.schemas.NewPet.properties.matrix: nested arrays are not supported, skipped",
+		"components.schemas.NewPet.properties.attributes: maps are not supported, skipped",
+		"components.schemas.NewPet.properties.owner: oneOf and anyOf are not supported, skipped",
+		"paths./pets.get.parameters.X-Request-Id: header parameters are not supported, skipped",
+		`paths./pets/{petId}.delete: renamed "delete-pet" to "DeletePet"`,
+		"paths./pets/{petId}/photo.put.requestBody: only JSON content is supported (found image/png), skipped",
+		"paths./pets/{petId}/photo.put.responses.200: only JSON content is supported (found text/plain), skipped",
+		"Pet.parent: field removed, circular references between types are not supported (Pet -> Pet)",
+	}, warningStrings(result))
+}
+
+func TestImportJSON(t *testing.T) {
+	result := importString(t, `{
+		"openapi": "3.1.0",
+		"info": {"title": "Example"},
+		"paths": {
+			"/items": {
+				"post": {
+					"requestBody": {
+						"content": {
+							"application/json; charset=utf-8": {"schema": {"type": "array", "items": {"type": "string"}}}
+						}
+					},
+					"responses": {
+						"200": {
+							"description": "OK",
+							"content": {
+								"application/json": {
+									"schema": {
+										"type": "object",
+										"properties": {
+											"count": {"type": ["integer", "null"]},
+											"updatedAt": {"anyOf": [{"type": "string", "format": "date-time"}, {"type": "null"}]},
+											"kind": {"const": "list", "type": "string"}
+										},
+										"required": ["count", "updatedAt", "kind"]
+									}
+								}
+							}
+						}
+					}
+				}
+			}
+		}
+	}`)
+
+	formatted := result.Format()
+	require.Equal(t, `version 1
+
+""" # Example """
+
+proc PostItems {
+  input {
+    body?: string[]
+  }
+
+  output {
+    count?: int
+    updatedAt?: datetime
+
+    """ Allowed values: `+"`list`"+`. """
+    kind: string
+  }
+}
+`, formatted)
+	requireValidSchema(t, formatted)
+	require.Empty(t, result.Warnings)
+}
+
+func TestImportComponentReferences(t *testing.T) {
+	result := importString(t, `
+openapi: 3.0.0
+info:
+  title: ""
+paths:
+  /orders/{id}:
+    get:
+      operationId: get_order
+      parameters:
+        - name: id
+          in: path
+          schema: {type: integer}
+        - $ref: "#/components/parameters/Missing"
+      responses:
+        "200":
+          $ref: "#/components/responses/Order"
+    patch:
+      operationId: getOrder
+      requestBody:
+        $ref: "#/components/requestBodies/Order"
+      responses:
+        "500":
+          description: Error
+components:
+  schemas:
+    Order:
+      type: object
+      properties:
+        id: {type: integer}
+        tags: {$ref: "#/components/schemas/Tags"}
+        external: {$ref: "other.yaml#/Order"}
+        untyped: {}
+    Tags:
+      type: array
+      items: {type: string}
+  requestBodies:
+    Order:
+      content:
+        application/json:
+          schema: {$ref: "#/components/schemas/Order"}
+  responses:
+    Order:
+      description: The order
+      content:
+        application/json:
+          schema: {$ref: "#/components/schemas/Order"}
+`)
+
+	formatted := result.Format()
+	require.Equal(t, `version 1
+
+type Order {
+  id?: int
+  tags?: string[]
+}
+
+proc GetOrder {
+  input {
+    id: int
+  }
+
+  output {
+    id?: int
+    tags?: string[]
+  }
+}
+
+proc GetOrder2 {
+  input {
+    id?: int
+    tags?: string[]
+  }
+}
+`, formatted)
+	requireValidSchema(t, formatted)
+
+	require.Equal(t, []string{
+		`components.schemas.Order.properties.external: only references to local component schemas are supported (found "other.yaml#/Order"), skipped`,
+		"components.schemas.Order.properties.untyped: schemas without type are not supported, skipped",
+		"components.schemas.Tags: only object schemas are converted to types, it is inlined where it is used",
+		`paths./orders/{id}.get: renamed "get_order" to "GetOrder"`,
+		`paths./orders/{id}.get: unsupported parameter reference "#/components/parameters/Missing", skipped`,
+		`paths./orders/{id}.patch: renamed "getOrder" to "GetOrder2"`,
+		"paths./orders/{id}.patch.responses: no successful response found, the output is empty",
+	}, warningStrings(result))
+}
+
+func TestImportErrors(t *testing.T) {
+	_, err := Import([]byte("swagger: \"2.0\"\n"))
+	require.EqualError(t, err, "swagger 2.0 documents are not supported, only OpenAPI 3 documents are supported")
+
+	_, err = Import([]byte("info:\n  title: x\n"))
+	require.EqualError(t, err, `unsupported OpenAPI version "", only OpenAPI 3 documents are supported`)
+
+	_, err = Import([]byte("openapi: [\n"))
+	require.ErrorContains(t, err, "failed to parse OpenAPI document")
+}
+
+func TestOperationName(t *testing.T) {
+	require.Equal(t, "GetUsersByIdPosts", operationName("get", "/users/{id}/posts"))
+	require.Equal(t, "Post", operationName("post", "/"))
+	require.Equal(t, "DeleteV1UserGroups", operationName("delete", "/v1/user-groups"))
+}
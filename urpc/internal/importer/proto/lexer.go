@@ -0,0 +1,274 @@
+package proto
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenNumber
+	tokenString
+	tokenSymbol
+)
+
+// token is a lexical token of a proto file.
+type token struct {
+	Kind  tokenKind
+	Value string
+	Line  int
+	Col   int
+	// Comments are the comments found between the previous token and this
+	// one, separated in blocks by blank lines.
+	Comments []comment
+	// TrailingComment is the comment in the same line after this token,
+	// filled by the lexer when the next token is read.
+	TrailingComment string
+}
+
+// comment is a block of consecutive comment lines.
+type comment struct {
+	Text string
+	// EndLine is the line where the comment ends.
+	EndLine int
+}
+
+func (t token) String() string {
+	if t.Kind == tokenEOF {
+		return "end of file"
+	}
+	return fmt.Sprintf("%q", t.Value)
+}
+
+// lexer splits a proto file in tokens, keeping the comments so they can be
+// converted to docstrings.
+type lexer struct {
+	input []rune
+	pos   int
+	line  int
+	col   int
+}
+
+func newLexer(content string) *lexer {
+	return &lexer{input: []rune(content), line: 1, col: 1}
+}
+
+// tokenize returns all the tokens of the input, the last one is always
+// tokenEOF.
+func (l *lexer) tokenize() ([]token, error) {
+	tokens := []token{}
+	for {
+		comments, err := l.skipSpaceAndComments(tokens)
+		if err != nil {
+			return nil, err
+		}
+
+		tok, err := l.next()
+		if err != nil {
+			return nil, err
+		}
+		tok.Comments = comments
+		tokens = append(tokens, tok)
+		if tok.Kind == tokenEOF {
+			return tokens, nil
+		}
+	}
+}
+
+func (l *lexer) errorf(format string, args ...any) error {
+	return fmt.Errorf("%d:%d: %s", l.line, l.col, fmt.Sprintf(format, args...))
+}
+
+func (l *lexer) peek(offset int) rune {
+	if l.pos+offset >= len(l.input) {
+		return 0
+	}
+	return l.input[l.pos+offset]
+}
+
+func (l *lexer) advance() rune {
+	r := l.input[l.pos]
+	l.pos++
+	if r == '\n' {
+		l.line++
+		l.col = 1
+	} else {
+		l.col++
+	}
+	return r
+}
+
+// skipSpaceAndComments skips the whitespace and returns the comments found.
+// A comment that starts in the line of the previous token is set as its
+// trailing comment.
+func (l *lexer) skipSpaceAndComments(tokens []token) ([]comment, error) {
+	comments := []comment{}
+	lastLine := -1
+	if len(tokens) > 0 {
+		lastLine = tokens[len(tokens)-1].Line
+	}
+
+	for l.pos < len(l.input) {
+		r := l.peek(0)
+		switch {
+		case unicode.IsSpace(r):
+			l.advance()
+		case r == '/' && (l.peek(1) == '/' || l.peek(1) == '*'):
+			startLine := l.line
+			text, err := l.readComment()
+			if err != nil {
+				return nil, err
+			}
+
+			if startLine == lastLine && len(comments) == 0 {
+				tokens[len(tokens)-1].TrailingComment = text
+				continue
+			}
+			// Consecutive line comments are merged in one block
+			if len(comments) > 0 && comments[len(comments)-1].EndLine == startLine-1 {
+				comments[len(comments)-1].Text += "\n" + text
+				comments[len(comments)-1].EndLine = l.endLine()
+				continue
+			}
+			comments = append(comments, comment{Text: text, EndLine: l.endLine()})
+		default:
+			return comments, nil
+		}
+	}
+	return comments, nil
+}
+
+// endLine returns the line of the last character read.
+func (l *lexer) endLine() int {
+	if l.col == 1 {
+		return l.line - 1
+	}
+	return l.line
+}
+
+func (l *lexer) readComment() (string, error) {
+	l.advance()
+	if l.advance() == '/' {
+		start := l.pos
+		for l.pos < len(l.input) && l.peek(0) != '\n' {
+			l.advance()
+		}
+		return strings.TrimPrefix(string(l.input[start:l.pos]), " "), nil
+	}
+
+	start := l.pos
+	for l.pos < len(l.input) {
+		if l.peek(0) == '*' && l.peek(1) == '/' {
+			text := string(l.input[start:l.pos])
+			l.advance()
+			l.advance()
+			return cleanBlockComment(text), nil
+		}
+		l.advance()
+	}
+	return "", l.errorf("unterminated block comment")
+}
+
+// cleanBlockComment removes the leading asterisks of the lines of a block
+// comment.
+func cleanBlockComment(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimLeft(line, " \t")
+		if strings.HasPrefix(trimmed, "*") {
+			line = strings.TrimPrefix(strings.TrimPrefix(trimmed, "*"), " ")
+		}
+		lines[i] = line
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+func (l *lexer) next() (token, error) {
+	if l.pos >= len(l.input) {
+		return token{Kind: tokenEOF, Line: l.line, Col: l.col}, nil
+	}
+
+	tok := token{Line: l.line, Col: l.col}
+	r := l.peek(0)
+	start := l.pos
+
+	switch {
+	case r == '_' || isLetter(r):
+		for isLetter(l.peek(0)) || isDigit(l.peek(0)) || l.peek(0) == '_' {
+			l.advance()
+		}
+		tok.Kind = tokenIdent
+	case isDigit(r) || (r == '.' && isDigit(l.peek(1))):
+		for isLetter(l.peek(0)) || isDigit(l.peek(0)) || l.peek(0) == '.' ||
+			((l.peek(0) == '-' || l.peek(0) == '+') && (l.input[l.pos-1] == 'e' || l.input[l.pos-1] == 'E')) {
+			l.advance()
+		}
+		tok.Kind = tokenNumber
+	case r == '"' || r == '\'':
+		value, err := l.readString()
+		if err != nil {
+			return token{}, err
+		}
+		tok.Kind = tokenString
+		tok.Value = value
+		return tok, nil
+	case strings.ContainsRune(";{}[]()<>=,.-+:/", r):
+		l.advance()
+		tok.Kind = tokenSymbol
+	default:
+		return token{}, l.errorf("unexpected character %q", r)
+	}
+
+	tok.Value = string(l.input[start:l.pos])
+	return tok, nil
+}
+
+// readString reads a quoted string and returns its value with the escape
+// sequences resolved.
+func (l *lexer) readString() (string, error) {
+	quote := l.advance()
+	b := strings.Builder{}
+	for {
+		if l.pos >= len(l.input) || l.peek(0) == '\n' {
+			return "", l.errorf("unterminated string")
+		}
+		r := l.advance()
+		if r == quote {
+			return b.String(), nil
+		}
+		if r != '\\' {
+			b.WriteRune(r)
+			continue
+		}
+		if l.pos >= len(l.input) {
+			return "", l.errorf("unterminated string")
+		}
+		switch escaped := l.advance(); escaped {
+		case 'n':
+			b.WriteRune('\n')
+		case 't':
+			b.WriteRune('\t')
+		case 'r':
+			b.WriteRune('\r')
+		default:
+			// Octal, hex and unicode escapes are kept as they are, string
+			// values are only used in options
+			if !strings.ContainsRune(`"'\`, escaped) {
+				b.WriteRune('\\')
+			}
+			b.WriteRune(escaped)
+		}
+	}
+}
+
+func isLetter(r rune) bool {
+	return ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z')
+}
+
+func isDigit(r rune) bool {
+	return '0' <= r && r <= '9'
+}
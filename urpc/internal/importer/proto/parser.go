@@ -0,0 +1,685 @@
+package proto
+
+import (
+	"fmt"
+	"strings"
+)
+
+// This file contains a parser for the subset of the protobuf language
+// needed to convert proto files: messages, enums and services. Options,
+// imports, extensions and reserved ranges are parsed and ignored.
+
+type protoFile struct {
+	Syntax   string
+	Package  string
+	Messages []*message
+	Enums    []*enum
+	Services []*service
+	// Extends contains the names of the messages extended by the file,
+	// which are not supported.
+	Extends []string
+}
+
+type message struct {
+	Name string
+	// FullName is the name including the package and the parent messages.
+	FullName   string
+	Doc        string
+	Deprecated bool
+	Fields     []*field
+	Messages   []*message
+	Enums      []*enum
+	Parent     *message
+}
+
+type field struct {
+	Name       string
+	Type       string
+	Doc        string
+	Label      string
+	Oneof      string
+	JSONName   string
+	Deprecated bool
+	// IsMap indicates if the field is a map<K, V>, Type contains the value
+	// type.
+	IsMap bool
+}
+
+type enum struct {
+	Name     string
+	FullName string
+	Doc      string
+	Values   []string
+}
+
+type service struct {
+	Name string
+	Doc  string
+	RPCs []*rpc
+}
+
+type rpc struct {
+	Name            string
+	Doc             string
+	Input           string
+	Output          string
+	ClientStreaming bool
+	ServerStreaming bool
+	Deprecated      bool
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+	file   *protoFile
+}
+
+// parse parses the content of a proto file.
+func parse(content string) (*protoFile, error) {
+	tokens, err := newLexer(content).tokenize()
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens, file: &protoFile{}}
+	if err := p.parseFile(); err != nil {
+		return nil, err
+	}
+	return p.file, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.Kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) errorf(tok token, format string, args ...any) error {
+	return fmt.Errorf("%d:%d: %s", tok.Line, tok.Col, fmt.Sprintf(format, args...))
+}
+
+// accept consumes the next token if it has the value.
+func (p *parser) accept(value string) bool {
+	tok := p.peek()
+	if tok.Kind != tokenString && tok.Value == value {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(value string) (token, error) {
+	tok := p.next()
+	if tok.Kind == tokenString || tok.Value != value {
+		return tok, p.errorf(tok, "expected %q, found %s", value, tok)
+	}
+	return tok, nil
+}
+
+func (p *parser) expectIdent() (token, error) {
+	tok := p.next()
+	if tok.Kind != tokenIdent {
+		return tok, p.errorf(tok, "expected identifier, found %s", tok)
+	}
+	return tok, nil
+}
+
+// expectFullIdent reads a dotted name, optionally starting with a dot,
+// like ".google.protobuf.Timestamp".
+func (p *parser) expectFullIdent() (string, error) {
+	name := ""
+	if p.accept(".") {
+		name = "."
+	}
+	for {
+		tok, err := p.expectIdent()
+		if err != nil {
+			return "", err
+		}
+		name += tok.Value
+		if !p.accept(".") {
+			return name, nil
+		}
+		name += "."
+	}
+}
+
+func (p *parser) expectString() (string, error) {
+	tok := p.next()
+	if tok.Kind != tokenString {
+		return "", p.errorf(tok, "expected string, found %s", tok)
+	}
+	value := tok.Value
+	// Adjacent strings are concatenated
+	for p.peek().Kind == tokenString {
+		value += p.next().Value
+	}
+	return value, nil
+}
+
+// docOf returns the comment directly above the token.
+func docOf(tok token) string {
+	if len(tok.Comments) == 0 {
+		return ""
+	}
+	last := tok.Comments[len(tok.Comments)-1]
+	if last.EndLine != tok.Line-1 {
+		return ""
+	}
+	return last.Text
+}
+
+func (p *parser) parseFile() error {
+	for {
+		tok := p.peek()
+		switch {
+		case tok.Kind == tokenEOF:
+			return nil
+		case p.accept(";"):
+		case p.accept("syntax"), p.accept("edition"):
+			if _, err := p.expect("="); err != nil {
+				return err
+			}
+			syntax, err := p.expectString()
+			if err != nil {
+				return err
+			}
+			if tok.Value == "edition" {
+				syntax = "edition " + syntax
+			}
+			p.file.Syntax = syntax
+			if _, err := p.expect(";"); err != nil {
+				return err
+			}
+		case p.accept("package"):
+			name, err := p.expectFullIdent()
+			if err != nil {
+				return err
+			}
+			p.file.Package = name
+			if _, err := p.expect(";"); err != nil {
+				return err
+			}
+		case p.accept("import"):
+			if !p.accept("public") {
+				p.accept("weak")
+			}
+			if _, err := p.expectString(); err != nil {
+				return err
+			}
+			if _, err := p.expect(";"); err != nil {
+				return err
+			}
+		case p.accept("option"):
+			if _, err := p.parseOption(";"); err != nil {
+				return err
+			}
+		case p.accept("message"):
+			msg, err := p.parseMessage(tok, p.file.Package, nil)
+			if err != nil {
+				return err
+			}
+			p.file.Messages = append(p.file.Messages, msg)
+		case p.accept("enum"):
+			e, err := p.parseEnum(tok, p.file.Package)
+			if err != nil {
+				return err
+			}
+			p.file.Enums = append(p.file.Enums, e)
+		case p.accept("service"):
+			svc, err := p.parseService(tok)
+			if err != nil {
+				return err
+			}
+			p.file.Services = append(p.file.Services, svc)
+		case p.accept("extend"):
+			if err := p.parseExtend(); err != nil {
+				return err
+			}
+		default:
+			return p.errorf(tok, "unexpected %s", tok)
+		}
+	}
+}
+
+// parseOption parses an option after the "option" keyword up to the end
+// token and returns its name and value, aggregate values are returned as
+// an empty string.
+func (p *parser) parseOption(end string) (option, error) {
+	opt := option{}
+	for p.peek().Value != "=" {
+		tok := p.next()
+		if tok.Kind == tokenEOF {
+			return opt, p.errorf(tok, "unexpected end of file in option")
+		}
+		opt.Name += tok.Value
+	}
+	p.next()
+
+	value, err := p.parseConstant()
+	if err != nil {
+		return opt, err
+	}
+	opt.Value = value
+
+	if _, err := p.expect(end); err != nil {
+		return opt, err
+	}
+	return opt, nil
+}
+
+type option struct {
+	Name  string
+	Value string
+}
+
+// parseConstant parses an option value, aggregate values in braces are
+// skipped.
+func (p *parser) parseConstant() (string, error) {
+	tok := p.peek()
+	switch {
+	case tok.Kind == tokenString:
+		return p.expectString()
+	case tok.Value == "{":
+		return "", p.skipBlock()
+	case tok.Value == "-" || tok.Value == "+":
+		p.next()
+		number := p.next()
+		return tok.Value + number.Value, nil
+	case tok.Kind == tokenIdent || tok.Kind == tokenNumber:
+		p.next()
+		return tok.Value, nil
+	default:
+		return "", p.errorf(tok, "expected constant, found %s", tok)
+	}
+}
+
+// skipBlock skips a block delimited by braces including nested blocks.
+func (p *parser) skipBlock() error {
+	open, err := p.expect("{")
+	if err != nil {
+		return err
+	}
+	depth := 1
+	for depth > 0 {
+		tok := p.next()
+		switch {
+		case tok.Kind == tokenEOF:
+			return p.errorf(open, "unclosed block")
+		case tok.Kind == tokenString:
+		case tok.Value == "{":
+			depth++
+		case tok.Value == "}":
+			depth--
+		}
+	}
+	return nil
+}
+
+// skipStatement skips everything up to the next semicolon.
+func (p *parser) skipStatement() error {
+	for {
+		tok := p.next()
+		if tok.Kind == tokenEOF {
+			return p.errorf(tok, "expected \";\", found %s", tok)
+		}
+		if tok.Kind != tokenString && tok.Value == ";" {
+			return nil
+		}
+	}
+}
+
+// parseFieldOptions parses the options in brackets after a field.
+func (p *parser) parseFieldOptions(f *field) error {
+	if !p.accept("[") {
+		return nil
+	}
+	for {
+		opt, err := p.parseFieldOption()
+		if err != nil {
+			return err
+		}
+		switch opt.Name {
+		case "deprecated":
+			f.Deprecated = opt.Value == "true"
+		case "json_name":
+			f.JSONName = opt.Value
+		}
+		if p.accept("]") {
+			return nil
+		}
+		if _, err := p.expect(","); err != nil {
+			return err
+		}
+	}
+}
+
+func (p *parser) parseFieldOption() (option, error) {
+	opt := option{}
+	for p.peek().Value != "=" {
+		tok := p.next()
+		if tok.Kind == tokenEOF {
+			return opt, p.errorf(tok, "unexpected end of file in option")
+		}
+		opt.Name += tok.Value
+	}
+	p.next()
+	value, err := p.parseConstant()
+	opt.Value = value
+	return opt, err
+}
+
+func (p *parser) parseMessage(start token, scope string, parent *message) (*message, error) {
+	name, err := p.expectIdent()
+	if err != nil {
+		return nil, err
+	}
+
+	msg := &message{
+		Name:     name.Value,
+		FullName: joinName(scope, name.Value),
+		Doc:      docOf(start),
+		Parent:   parent,
+	}
+
+	if _, err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	if err := p.parseMessageBody(msg, ""); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// parseMessageBody parses the declarations of a message, or of a oneof of
+// the message if oneof is not empty, up to the closing brace.
+func (p *parser) parseMessageBody(msg *message, oneof string) error {
+	for {
+		tok := p.peek()
+		switch {
+		case tok.Kind == tokenEOF:
+			return p.errorf(tok, "expected \"}\", found %s", tok)
+		case p.accept("}"):
+			return nil
+		case p.accept(";"):
+		case oneof == "" && p.accept("message"):
+			nested, err := p.parseMessage(tok, msg.FullName, msg)
+			if err != nil {
+				return err
+			}
+			msg.Messages = append(msg.Messages, nested)
+		case oneof == "" && p.accept("enum"):
+			e, err := p.parseEnum(tok, msg.FullName)
+			if err != nil {
+				return err
+			}
+			msg.Enums = append(msg.Enums, e)
+		case p.accept("option"):
+			opt, err := p.parseOption(";")
+			if err != nil {
+				return err
+			}
+			if opt.Name == "deprecated" && opt.Value == "true" && oneof == "" {
+				msg.Deprecated = true
+			}
+		case oneof == "" && p.accept("oneof"):
+			name, err := p.expectIdent()
+			if err != nil {
+				return err
+			}
+			if _, err := p.expect("{"); err != nil {
+				return err
+			}
+			if err := p.parseMessageBody(msg, name.Value); err != nil {
+				return err
+			}
+		case oneof == "" && (p.accept("reserved") || p.accept("extensions")):
+			if err := p.skipStatement(); err != nil {
+				return err
+			}
+		case oneof == "" && p.accept("extend"):
+			if err := p.parseExtend(); err != nil {
+				return err
+			}
+		default:
+			f, err := p.parseField()
+			if err != nil {
+				return err
+			}
+			f.Oneof = oneof
+			msg.Fields = append(msg.Fields, f)
+		}
+	}
+}
+
+func (p *parser) parseField() (*field, error) {
+	start := p.peek()
+	f := &field{Doc: docOf(start)}
+
+	if start.Kind == tokenIdent {
+		switch start.Value {
+		case "optional", "repeated", "required":
+			p.next()
+			f.Label = start.Value
+		case "group":
+			return nil, p.errorf(start, "groups are not supported")
+		}
+	}
+
+	if p.accept("map") {
+		if _, err := p.expect("<"); err != nil {
+			return nil, err
+		}
+		if _, err := p.expectFullIdent(); err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(","); err != nil {
+			return nil, err
+		}
+		valueType, err := p.expectFullIdent()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(">"); err != nil {
+			return nil, err
+		}
+		f.Type = valueType
+		f.IsMap = true
+	} else {
+		typeName, err := p.expectFullIdent()
+		if err != nil {
+			return nil, err
+		}
+		f.Type = typeName
+	}
+
+	name, err := p.expectIdent()
+	if err != nil {
+		return nil, err
+	}
+	f.Name = name.Value
+
+	if _, err := p.expect("="); err != nil {
+		return nil, err
+	}
+	if number := p.next(); number.Kind != tokenNumber {
+		return nil, p.errorf(number, "expected field number, found %s", number)
+	}
+	if err := p.parseFieldOptions(f); err != nil {
+		return nil, err
+	}
+
+	end, err := p.expect(";")
+	if err != nil {
+		return nil, err
+	}
+	if f.Doc == "" {
+		f.Doc = end.TrailingComment
+	}
+	return f, nil
+}
+
+func (p *parser) parseEnum(start token, scope string) (*enum, error) {
+	name, err := p.expectIdent()
+	if err != nil {
+		return nil, err
+	}
+	e := &enum{Name: name.Value, FullName: joinName(scope, name.Value), Doc: docOf(start)}
+
+	if _, err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	for {
+		tok := p.peek()
+		switch {
+		case tok.Kind == tokenEOF:
+			return nil, p.errorf(tok, "expected \"}\", found %s", tok)
+		case p.accept("}"):
+			return e, nil
+		case p.accept(";"):
+		case p.accept("option"):
+			if _, err := p.parseOption(";"); err != nil {
+				return nil, err
+			}
+		case p.accept("reserved"):
+			if err := p.skipStatement(); err != nil {
+				return nil, err
+			}
+		default:
+			value, err := p.expectIdent()
+			if err != nil {
+				return nil, err
+			}
+			e.Values = append(e.Values, value.Value)
+			if err := p.skipStatement(); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+func (p *parser) parseService(start token) (*service, error) {
+	name, err := p.expectIdent()
+	if err != nil {
+		return nil, err
+	}
+	svc := &service{Name: name.Value, Doc: docOf(start)}
+
+	if _, err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	for {
+		tok := p.peek()
+		switch {
+		case tok.Kind == tokenEOF:
+			return nil, p.errorf(tok, "expected \"}\", found %s", tok)
+		case p.accept("}"):
+			return svc, nil
+		case p.accept(";"):
+		case p.accept("option"):
+			if _, err := p.parseOption(";"); err != nil {
+				return nil, err
+			}
+		case p.accept("rpc"):
+			r, err := p.parseRPC(tok)
+			if err != nil {
+				return nil, err
+			}
+			svc.RPCs = append(svc.RPCs, r)
+		default:
+			return nil, p.errorf(tok, "unexpected %s in service", tok)
+		}
+	}
+}
+
+func (p *parser) parseRPC(start token) (*rpc, error) {
+	name, err := p.expectIdent()
+	if err != nil {
+		return nil, err
+	}
+	r := &rpc{Name: name.Value, Doc: docOf(start)}
+
+	parseType := func() (string, bool, error) {
+		if _, err := p.expect("("); err != nil {
+			return "", false, err
+		}
+		// "stream" is also a valid message name
+		streaming := p.peek().Value == "stream" && p.tokens[p.pos+1].Value != ")"
+		if streaming {
+			p.next()
+		}
+		typeName, err := p.expectFullIdent()
+		if err != nil {
+			return "", false, err
+		}
+		_, err = p.expect(")")
+		return typeName, streaming, err
+	}
+
+	if r.Input, r.ClientStreaming, err = parseType(); err != nil {
+		return nil, err
+	}
+	if _, err := p.expect("returns"); err != nil {
+		return nil, err
+	}
+	if r.Output, r.ServerStreaming, err = parseType(); err != nil {
+		return nil, err
+	}
+
+	if p.accept(";") {
+		return r, nil
+	}
+	if _, err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	for !p.accept("}") {
+		tok := p.peek()
+		switch {
+		case tok.Kind == tokenEOF:
+			return nil, p.errorf(tok, "expected \"}\", found %s", tok)
+		case p.accept(";"):
+		case p.accept("option"):
+			opt, err := p.parseOption(";")
+			if err != nil {
+				return nil, err
+			}
+			if opt.Name == "deprecated" && opt.Value == "true" {
+				r.Deprecated = true
+			}
+		default:
+			return nil, p.errorf(tok, "unexpected %s in rpc", tok)
+		}
+	}
+	return r, nil
+}
+
+// parseExtend skips an extend block, recording the extended message.
+func (p *parser) parseExtend() error {
+	name, err := p.expectFullIdent()
+	if err != nil {
+		return err
+	}
+	p.file.Extends = append(p.file.Extends, name)
+	return p.skipBlock()
+}
+
+func joinName(scope string, name string) string {
+	if scope == "" {
+		return name
+	}
+	return scope + "." + name
+}
+
+// relativeName returns the full name without the package.
+func relativeName(pkg string, fullName string) string {
+	if pkg == "" {
+		return fullName
+	}
+	return strings.TrimPrefix(fullName, pkg+".")
+}
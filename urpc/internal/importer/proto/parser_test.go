@@ -0,0 +1,116 @@
+package proto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	file, err := parse(`
+// License header.
+
+// Detached comment.
+
+syntax = "proto3";
+package a.b;
+
+import public "other.proto";
+option (custom.opt).nested = { key: "value" nested { x: 1 } };
+option java_package = "com." "example";
+
+// Status of a thing.
+enum Status {
+  option allow_alias = true;
+  UNKNOWN = 0;
+  ACTIVE = 1 [(custom) = -1];
+  reserved 2 to 5;
+}
+
+/**
+ * A thing.
+ *
+ * With details.
+ */
+message Thing {
+  // The id.
+  // Second line.
+  string id = 1 [json_name = "ID", deprecated = true];
+
+  int32 count = 2; // Trailing.
+  map<string, Thing> children = 3;
+  oneof value {
+    option (custom.oneof) = true;
+    string text = 4;
+    double number = 0x5;
+  }
+  option deprecated = true;
+}
+
+service Things {
+  rpc Watch(.a.b.Thing) returns (stream Thing) {
+    option idempotency_level = NO_SIDE_EFFECTS;
+  };
+}
+`)
+	require.NoError(t, err)
+
+	require.Equal(t, "proto3", file.Syntax)
+	require.Equal(t, "a.b", file.Package)
+
+	require.Len(t, file.Enums, 1)
+	require.Equal(t, &enum{Name: "Status", FullName: "a.b.Status", Doc: "Status of a thing.", Values: []string{"UNKNOWN", "ACTIVE"}}, file.Enums[0])
+
+	require.Len(t, file.Messages, 1)
+	msg := file.Messages[0]
+	require.Equal(t, "a.b.Thing", msg.FullName)
+	require.Equal(t, "A thing.\n\nWith details.", msg.Doc)
+	require.True(t, msg.Deprecated)
+	require.Equal(t, []*field{
+		{Name: "id", Type: "string", Doc: "The id.\nSecond line.", JSONName: "ID", Deprecated: true},
+		{Name: "count", Type: "int32", Doc: "Trailing."},
+		{Name: "children", Type: "Thing", IsMap: true},
+		{Name: "text", Type: "string", Oneof: "value"},
+		{Name: "number", Type: "double", Oneof: "value"},
+	}, msg.Fields)
+
+	require.Equal(t, []*service{{
+		Name: "Things",
+		RPCs: []*rpc{{Name: "Watch", Input: ".a.b.Thing", Output: "Thing", ServerStreaming: true}},
+	}}, file.Services)
+}
+
+func TestParseNested(t *testing.T) {
+	file, err := parse(`message A { message B { message C {} } B b = 1; }`)
+	require.NoError(t, err)
+
+	a := file.Messages[0]
+	b := a.Messages[0]
+	c := b.Messages[0]
+	require.Equal(t, "A.B", b.FullName)
+	require.Equal(t, "A.B.C", c.FullName)
+	require.Same(t, a, b.Parent)
+	require.Same(t, b, c.Parent)
+}
+
+func TestParseErrors(t *testing.T) {
+	testCases := []struct {
+		content  string
+		expected string
+	}{
+		{content: `syntax = proto3;`, expected: `1:10: expected string, found "proto3"`},
+		{content: `message A {`, expected: `1:12: expected "}", found end of file`},
+		{content: `message A { string a = 1 }`, expected: `1:26: expected ";", found "}"`},
+		{content: `message A { group G = 1 {} }`, expected: `1:13: groups are not supported`},
+		{content: `service S { message A {} }`, expected: `1:13: unexpected "message" in service`},
+		{content: `/* open`, expected: `1:8: unterminated block comment`},
+		{content: `option a = "open`, expected: `1:17: unterminated string`},
+		{content: `message A { string a = 1; } $`, expected: `1:29: unexpected character '$'`},
+		{content: `rpc`, expected: `1:1: unexpected "rpc"`},
+	}
+
+	for _, tc := range testCases {
+		_, err := parse(tc.content)
+		require.EqualError(t, err, tc.expected, tc.content)
+	}
+}
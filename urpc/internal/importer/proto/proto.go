@@ -0,0 +1,431 @@
+// Package proto converts Protocol Buffers definitions to URPC schemas
+// without depending on protoc.
+//
+// Messages become types, unary rpcs become procedures and server-streaming
+// rpcs become streams. The fields of the request and response messages of
+// an rpc become the input and output of the procedure, so messages only
+// used as requests or responses are not converted to types. Nested
+// messages used by a single field of their parent become inline objects
+// and the others become types prefixed with the name of their parents.
+//
+// The field names follow the JSON mapping of protobuf, e.g. user_id is
+// converted to userId, unless the field has a json_name option.
+package proto
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/uforg/uforpc/urpc/internal/importer"
+	"github.com/uforg/uforpc/urpc/internal/schema"
+)
+
+// Import converts the content of a proto file to an URPC schema.
+func Import(content []byte) (importer.Result, error) {
+	file, err := parse(string(content))
+	if err != nil {
+		return importer.Result{}, fmt.Errorf("failed to parse proto file: %w", err)
+	}
+
+	c := newConverter(file)
+	c.convert()
+	return c.b.Result()
+}
+
+// scalarTypes maps the protobuf scalar types to URPC primitive types.
+var scalarTypes = map[string]string{
+	"double":   "float",
+	"float":    "float",
+	"int32":    "int",
+	"int64":    "int",
+	"uint32":   "int",
+	"uint64":   "int",
+	"sint32":   "int",
+	"sint64":   "int",
+	"fixed32":  "int",
+	"fixed64":  "int",
+	"sfixed32": "int",
+	"sfixed64": "int",
+	"bool":     "bool",
+	"string":   "string",
+	"bytes":    "string",
+}
+
+// wellKnownType is the URPC type of a well-known type of protobuf.
+type wellKnownType struct {
+	TypeName string
+	// Optional is true for the wrapper types, which are nullable
+	Optional bool
+	Doc      string
+}
+
+var wellKnownTypes = map[string]wellKnownType{
+	"google.protobuf.Timestamp":   {TypeName: "datetime"},
+	"google.protobuf.Duration":    {TypeName: "string", Doc: "Duration in seconds with the \"s\" suffix, e.g. `1.5s`."},
+	"google.protobuf.FieldMask":   {TypeName: "string", Doc: "Comma separated list of field paths."},
+	"google.protobuf.DoubleValue": {TypeName: "float", Optional: true},
+	"google.protobuf.FloatValue":  {TypeName: "float", Optional: true},
+	"google.protobuf.Int64Value":  {TypeName: "int", Optional: true},
+	"google.protobuf.UInt64Value": {TypeName: "int", Optional: true},
+	"google.protobuf.Int32Value":  {TypeName: "int", Optional: true},
+	"google.protobuf.UInt32Value": {TypeName: "int", Optional: true},
+	"google.protobuf.BoolValue":   {TypeName: "bool", Optional: true},
+	"google.protobuf.StringValue": {TypeName: "string", Optional: true},
+	"google.protobuf.BytesValue":  {TypeName: "string", Optional: true, Doc: "Base64 encoded bytes."},
+}
+
+const emptyMessage = "google.protobuf.Empty"
+
+type converter struct {
+	file *protoFile
+	b    *importer.Builder
+
+	messages map[string]*message
+	enums    map[string]*enum
+	// fieldRefs contains the messages with fields that reference each
+	// message, rpcRefs the messages used as request or response.
+	fieldRefs map[string][]*message
+	rpcRefs   map[string]bool
+	// inlining contains the messages being converted to inline objects,
+	// used to guard against infinite recursion.
+	inlining map[string]bool
+}
+
+func newConverter(file *protoFile) *converter {
+	c := &converter{
+		file:      file,
+		b:         importer.NewBuilder(),
+		messages:  map[string]*message{},
+		enums:     map[string]*enum{},
+		fieldRefs: map[string][]*message{},
+		rpcRefs:   map[string]bool{},
+		inlining:  map[string]bool{},
+	}
+
+	var register func(messages []*message)
+	register = func(messages []*message) {
+		for _, msg := range messages {
+			c.messages[msg.FullName] = msg
+			for _, e := range msg.Enums {
+				c.enums[e.FullName] = e
+			}
+			register(msg.Messages)
+		}
+	}
+	register(file.Messages)
+	for _, e := range file.Enums {
+		c.enums[e.FullName] = e
+	}
+
+	var countRefs func(messages []*message)
+	countRefs = func(messages []*message) {
+		for _, msg := range messages {
+			for _, f := range msg.Fields {
+				if name, ok := c.resolveMessage(msg.FullName, f.Type); ok {
+					c.fieldRefs[name] = append(c.fieldRefs[name], msg)
+				}
+			}
+			countRefs(msg.Messages)
+		}
+	}
+	countRefs(file.Messages)
+	for _, svc := range file.Services {
+		for _, r := range svc.RPCs {
+			for _, typeName := range []string{r.Input, r.Output} {
+				if name, ok := c.resolveMessage(file.Package, typeName); ok {
+					c.rpcRefs[name] = true
+				}
+			}
+		}
+	}
+
+	return c
+}
+
+func (c *converter) convert() {
+	switch c.file.Syntax {
+	case "proto3":
+	case "":
+		c.b.Warnf("syntax", "missing syntax, proto2 semantics are not supported, converted as proto3")
+	default:
+		c.b.Warnf("syntax", "%q semantics are not supported, converted as proto3", c.file.Syntax)
+	}
+	for _, name := range c.file.Extends {
+		c.b.Warnf("extend "+name, "extensions are not supported, skipped")
+	}
+
+	// Types are declared before they are converted so references to types
+	// declared later in the file can be resolved
+	var declare func(messages []*message)
+	declare = func(messages []*message) {
+		for _, msg := range messages {
+			if c.isType(msg) {
+				c.b.DeclareName(msg.FullName, c.typeSourceName(msg))
+			}
+			declare(msg.Messages)
+		}
+	}
+	declare(c.file.Messages)
+
+	var convertMessages func(messages []*message)
+	convertMessages = func(messages []*message) {
+		for _, msg := range messages {
+			if typeName, ok := c.b.LookupName(msg.FullName); ok {
+				c.b.AddNode(&schema.NodeType{
+					Kind:       "type",
+					Name:       typeName,
+					Doc:        importer.Ptr(importer.Docstring(msg.Doc)),
+					Deprecated: deprecation(msg.Deprecated),
+					Fields:     c.convertFields(msg),
+				})
+			}
+			convertMessages(msg.Messages)
+		}
+	}
+	convertMessages(c.file.Messages)
+
+	for _, svc := range c.file.Services {
+		if svc.Doc != "" {
+			c.b.AddDoc("# " + svc.Name + "\n\n" + svc.Doc)
+		}
+		for _, r := range svc.RPCs {
+			c.convertRPC(svc, r)
+		}
+	}
+}
+
+// isType returns true if the message is converted to a type instead of an
+// inline object or the input or output of rpcs.
+func (c *converter) isType(msg *message) bool {
+	refs := c.fieldRefs[msg.FullName]
+	if msg.Parent != nil && len(refs) == 1 && refs[0] == msg.Parent && !c.rpcRefs[msg.FullName] {
+		return false
+	}
+	return len(refs) > 0 || !c.rpcRefs[msg.FullName]
+}
+
+// typeSourceName returns the name of the type of a message, nested
+// messages are prefixed with the names of their parents.
+func (c *converter) typeSourceName(msg *message) string {
+	name := ""
+	for m := msg; m != nil; m = m.Parent {
+		name = m.Name + name
+	}
+	return name
+}
+
+func (c *converter) location(fullName string) string {
+	return relativeName(c.file.Package, fullName)
+}
+
+func (c *converter) convertRPC(svc *service, r *rpc) {
+	location := svc.Name + "." + r.Name
+	if r.ClientStreaming {
+		c.b.Warnf(location, "client streaming rpcs are not supported, skipped")
+		return
+	}
+
+	name := c.b.DeclareName(location, r.Name)
+	doc := importer.Ptr(importer.Docstring(r.Doc))
+	input := c.convertRPCMessage(location+" request", r.Input)
+	output := c.convertRPCMessage(location+" response", r.Output)
+
+	if r.ServerStreaming {
+		c.b.AddNode(&schema.NodeStream{
+			Kind:       "stream",
+			Name:       name,
+			Doc:        doc,
+			Deprecated: deprecation(r.Deprecated),
+			Input:      input,
+			Output:     output,
+		})
+		return
+	}
+
+	c.b.AddNode(&schema.NodeProc{
+		Kind:       "proc",
+		Name:       name,
+		Doc:        doc,
+		Deprecated: deprecation(r.Deprecated),
+		Input:      input,
+		Output:     output,
+	})
+}
+
+// convertRPCMessage returns the fields of the request or response message
+// of an rpc.
+func (c *converter) convertRPCMessage(location string, typeName string) []schema.FieldDefinition {
+	if name, ok := c.resolveMessage(c.file.Package, typeName); ok {
+		return c.convertFields(c.messages[name])
+	}
+	if strings.TrimPrefix(typeName, ".") != emptyMessage {
+		c.b.Warnf(location, "unknown message %q, only messages defined in the same file are supported, the fields are empty", typeName)
+	}
+	return []schema.FieldDefinition{}
+}
+
+func (c *converter) convertFields(msg *message) []schema.FieldDefinition {
+	fields := []schema.FieldDefinition{}
+	for _, f := range msg.Fields {
+		field, ok := c.convertField(msg, f)
+		if !ok {
+			continue
+		}
+		location := c.location(msg.FullName) + "." + f.Name
+		if containsField(fields, field.Name) {
+			c.b.Warnf(location, "duplicate field %q, skipped", field.Name)
+			continue
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+func (c *converter) convertField(msg *message, f *field) (schema.FieldDefinition, bool) {
+	location := c.location(msg.FullName) + "." + f.Name
+	if f.IsMap {
+		c.b.Warnf(location, "maps are not supported, skipped")
+		return schema.FieldDefinition{}, false
+	}
+
+	field := schema.FieldDefinition{
+		Name:     c.b.FieldName(location, jsonName(f)),
+		IsArray:  f.Label == "repeated",
+		Optional: f.Label == "optional" || f.Oneof != "",
+	}
+	notes := []string{}
+	if f.Deprecated {
+		notes = append(notes, "Deprecated.")
+	}
+	if f.Doc != "" {
+		notes = append(notes, f.Doc)
+	}
+
+	if scalar, ok := scalarTypes[f.Type]; ok {
+		field.TypeName = &scalar
+		if f.Type == "bytes" {
+			notes = append(notes, "Base64 encoded bytes.")
+		}
+	} else if name, ok := c.resolveEnum(msg.FullName, f.Type); ok {
+		field.TypeName = importer.Ptr("string")
+		notes = append(notes, "Allowed values: `"+strings.Join(c.enums[name].Values, "`, `")+"`.")
+	} else if name, ok := c.resolveMessage(msg.FullName, f.Type); ok {
+		if typeName, ok := c.b.LookupName(name); ok {
+			field.TypeName = &typeName
+		} else if c.inlining[name] {
+			c.b.Warnf(location, "circular reference to message %q, skipped", f.Type)
+			return schema.FieldDefinition{}, false
+		} else {
+			// The docs of inline messages are kept in the field
+			if f.Doc == "" && c.messages[name].Doc != "" {
+				notes = append(notes, c.messages[name].Doc)
+			}
+			c.inlining[name] = true
+			field.TypeInline = &schema.InlineTypeDefinition{Fields: c.convertFields(c.messages[name])}
+			delete(c.inlining, name)
+		}
+	} else if wkt, ok := wellKnownTypes[strings.TrimPrefix(f.Type, ".")]; ok {
+		field.TypeName = &wkt.TypeName
+		field.Optional = field.Optional || wkt.Optional
+		if wkt.Doc != "" {
+			notes = append(notes, wkt.Doc)
+		}
+	} else {
+		c.b.Warnf(location, "unsupported type %q, only scalars, well-known types and types defined in the same file are supported, skipped", f.Type)
+		return schema.FieldDefinition{}, false
+	}
+
+	if f.Oneof != "" {
+		notes = append(notes, fmt.Sprintf("Only one of the fields of %s can be set.", c.oneofFields(msg, f.Oneof)))
+	}
+
+	field.Doc = importer.Ptr(importer.Docstring(strings.Join(notes, "\n\n")))
+	return field, true
+}
+
+// oneofFields returns the JSON names of the fields of a oneof as a list.
+func (c *converter) oneofFields(msg *message, oneof string) string {
+	names := []string{}
+	for _, f := range msg.Fields {
+		if f.Oneof == oneof {
+			names = append(names, "`"+importer.CamelName(jsonName(f))+"`")
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// resolveMessage returns the full name of the message referenced from the
+// scope, following the scoping rules of protobuf.
+func (c *converter) resolveMessage(scope string, typeName string) (string, bool) {
+	name, ok := resolve(scope, typeName, func(name string) bool { return c.messages[name] != nil })
+	return name, ok
+}
+
+func (c *converter) resolveEnum(scope string, typeName string) (string, bool) {
+	name, ok := resolve(scope, typeName, func(name string) bool { return c.enums[name] != nil })
+	return name, ok
+}
+
+// resolve searches the type name in the scope and its parents, names
+// starting with a dot are fully qualified.
+func resolve(scope string, typeName string, exists func(name string) bool) (string, bool) {
+	if strings.HasPrefix(typeName, ".") {
+		name := strings.TrimPrefix(typeName, ".")
+		return name, exists(name)
+	}
+
+	for {
+		name := joinName(scope, typeName)
+		if exists(name) {
+			return name, true
+		}
+		if scope == "" {
+			return "", false
+		}
+		index := strings.LastIndex(scope, ".")
+		if index < 0 {
+			scope = ""
+		} else {
+			scope = scope[:index]
+		}
+	}
+}
+
+// jsonName returns the name of the field in the JSON mapping of protobuf.
+func jsonName(f *field) string {
+	if f.JSONName != "" {
+		return f.JSONName
+	}
+
+	b := strings.Builder{}
+	upper := false
+	for _, r := range f.Name {
+		switch {
+		case r == '_':
+			upper = true
+		case upper:
+			b.WriteString(strings.ToUpper(string(r)))
+			upper = false
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func containsField(fields []schema.FieldDefinition, name string) bool {
+	for _, f := range fields {
+		if f.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func deprecation(deprecated bool) *string {
+	if !deprecated {
+		return nil
+	}
+	message := ""
+	return &message
+}
@@ -0,0 +1,157 @@
+package proto
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/uforg/uforpc/urpc/internal/importer"
+	"github.com/uforg/uforpc/urpc/internal/urpc/analyzer"
+	"github.com/uforg/uforpc/urpc/internal/urpc/docstore"
+)
+
+// requireValidSchema checks that the formatted schema passes the analyzer.
+func requireValidSchema(t *testing.T, formatted string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "schema.urpc")
+	require.NoError(t, os.WriteFile(path, []byte(formatted), 0644))
+
+	an, err := analyzer.NewAnalyzer(docstore.NewDocstore())
+	require.NoError(t, err)
+	_, diagnostics, err := an.Analyze(path)
+	require.NoError(t, err, "diagnostics: %v", diagnostics)
+}
+
+func warningStrings(result importer.Result) []string {
+	warnings := []string{}
+	for _, warning := range result.Warnings {
+		warnings = append(warnings, warning.String())
+	}
+	return warnings
+}
+
+func TestImportUsers(t *testing.T) {
+	content, err := os.ReadFile("testdata/users.proto")
+	require.NoError(t, err)
+	expected, err := os.ReadFile("testdata/users.urpc")
+	require.NoError(t, err)
+
+	result, err := Import(content)
+	require.NoError(t, err)
+
+	formatted := result.Format()
+	require.Equal(t, string(expected), formatted)
+	requireValidSchema(t, formatted)
+
+	require.Equal(t, []string{
+		"User.labels: maps are not supported, skipped",
+		"UserService.Upload: client streaming rpcs are not supported, skipped",
+		"UserAddress.owner: field removed, circular references between types are not supported (User -> UserAddress -> User)",
+	}, warningStrings(result))
+}
+
+func TestImportScopes(t *testing.T) {
+	result, err := Import([]byte(`
+syntax = "proto3";
+package shop;
+
+message Order {
+  message Item {
+    string sku = 1;
+  }
+  message Note {
+    string text = 1;
+  }
+  repeated Item items = 1;
+  .shop.Order.Note note = 2;
+  Customer customer = 3;
+  other.Unknown unknown = 4;
+  google.protobuf.Any payload = 5;
+  .google.protobuf.Int32Value priority = 6;
+}
+
+message Customer {
+  Order.Note note = 1;
+  string type = 2;
+}
+
+service Orders {
+  rpc Get(Customer) returns (Order);
+  rpc Stream(stream Order) returns (stream Order);
+  rpc Missing(other.Request) returns (Order);
+}
+`))
+	require.NoError(t, err)
+
+	formatted := result.Format()
+	// Order is only used by rpcs so it is not a type
+	require.Equal(t, `version 1
+
+type OrderNote {
+  text: string
+}
+
+type Customer {
+  note: OrderNote
+  typeValue: string
+}
+
+proc Get {
+  input {
+    note: OrderNote
+    typeValue: string
+  }
+
+  output {
+    items: {
+      sku: string
+    }[]
+    note: OrderNote
+    customer: Customer
+    priority?: int
+  }
+}
+
+proc Missing {
+  output {
+    items: {
+      sku: string
+    }[]
+    note: OrderNote
+    customer: Customer
+    priority?: int
+  }
+}
+`, formatted)
+	requireValidSchema(t, formatted)
+
+	require.Equal(t, []string{
+		`Customer.type: field "type" renamed to "typeValue", the JSON name changes too`,
+		`Order.unknown: unsupported type "other.Unknown", only scalars, well-known types and types defined in the same file are supported, skipped`,
+		`Order.payload: unsupported type "google.protobuf.Any", only scalars, well-known types and types defined in the same file are supported, skipped`,
+		"Orders.Stream: client streaming rpcs are not supported, skipped",
+		`Orders.Missing request: unknown message "other.Request", only messages defined in the same file are supported, the fields are empty`,
+	}, warningStrings(result))
+}
+
+func TestImportSyntax(t *testing.T) {
+	result, err := Import([]byte(`message A { required int32 id = 1; }
+extend google.protobuf.FieldOptions { string x = 50000; }`))
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"syntax: missing syntax, proto2 semantics are not supported, converted as proto3",
+		"extend google.protobuf.FieldOptions: extensions are not supported, skipped",
+	}, warningStrings(result))
+	require.Equal(t, "version 1\n\ntype A {\n  id: int\n}\n", result.Format())
+
+	_, err = Import([]byte("syntax = \"proto3\";\nmessage A {\n  string id = ;\n}"))
+	require.EqualError(t, err, `failed to parse proto file: 3:15: expected field number, found ";"`)
+}
+
+func TestJSONName(t *testing.T) {
+	require.Equal(t, "userId", jsonName(&field{Name: "user_id"}))
+	require.Equal(t, "fooBar2", jsonName(&field{Name: "foo_bar_2"}))
+	require.Equal(t, "Upper", jsonName(&field{Name: "Upper"}))
+	require.Equal(t, "custom", jsonName(&field{Name: "user_id", JSONName: "custom"}))
+}
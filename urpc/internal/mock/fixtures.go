@@ -0,0 +1,284 @@
+package mock
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/uforg/uforpc/urpc/internal/schema"
+)
+
+// Fixtures overrides the default behavior of the mock server.
+//
+// It is loaded from a TOML or JSON file:
+//
+//	seed = 42
+//
+//	[defaults]
+//	latency = "50ms"
+//
+//	[procs.GetUser]
+//	latency = "300ms"
+//	output = { name = "Ada Lovelace" }
+//
+//	[procs.DeleteUser]
+//	error_rate = 0.5
+//	error = { message = "User not found", code = "USER_NOT_FOUND" }
+//
+//	[streams.Ticks]
+//	interval = "200ms"
+//	count = 10
+type Fixtures struct {
+	// Seed makes the synthesized outputs and the injected errors
+	// reproducible, a random seed is used when it is nil.
+	Seed *uint64 `toml:"seed" json:"seed"`
+	// Defaults applies to all the operations, the per operation fixtures
+	// override it field by field.
+	Defaults Fixture `toml:"defaults" json:"defaults"`
+	// Procs are the fixtures of the procedures by name.
+	Procs map[string]Fixture `toml:"procs" json:"procs"`
+	// Streams are the fixtures of the streams by name.
+	Streams map[string]Fixture `toml:"streams" json:"streams"`
+}
+
+// Fixture overrides the behavior of one or all operations.
+type Fixture struct {
+	// Latency is the delay before the response or the first stream event.
+	Latency *Duration `toml:"latency" json:"latency"`
+	// ErrorRate is the probability, between 0 and 1, of failing a request
+	// or a stream event with Error.
+	ErrorRate *float64 `toml:"error_rate" json:"error_rate"`
+	// Error is the error injected according to ErrorRate. If no error rate
+	// is set for the operation or in the defaults, it always fails with it.
+	Error *Error `toml:"error" json:"error"`
+	// Output replaces fields of the synthesized output, nested objects are
+	// merged and the fields that are not set keep a synthesized value.
+	Output map[string]any `toml:"output" json:"output"`
+	// Outputs are the stream events emitted in order, cycling when the end
+	// is reached. Each one replaces fields of a synthesized output.
+	Outputs []map[string]any `toml:"outputs" json:"outputs"`
+	// Interval is the time between stream events, defaults to one second.
+	Interval *Duration `toml:"interval" json:"interval"`
+	// Count is the number of stream events to emit before closing the
+	// stream, zero emits events until the client disconnects.
+	Count *int `toml:"count" json:"count"`
+}
+
+// Duration is a time.Duration that is decoded from strings like "150ms".
+type Duration struct {
+	time.Duration
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (d *Duration) UnmarshalText(text []byte) error {
+	duration, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	d.Duration = duration
+	return nil
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// LoadFixtures reads a fixtures file, files with the .json extension are
+// decoded as JSON and any other file as TOML. Unknown keys are reported as
+// errors to catch typos.
+func LoadFixtures(path string) (Fixtures, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Fixtures{}, fmt.Errorf("failed to read fixtures file: %w", err)
+	}
+
+	fixtures := Fixtures{}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&fixtures); err != nil {
+			return Fixtures{}, fmt.Errorf("failed to decode JSON fixtures: %w", err)
+		}
+		return fixtures, nil
+	}
+
+	meta, err := toml.Decode(string(data), &fixtures)
+	if err != nil {
+		return Fixtures{}, fmt.Errorf("failed to decode TOML fixtures: %w", err)
+	}
+	for _, key := range meta.Undecoded() {
+		if !isFreeFormKey(key) {
+			return Fixtures{}, fmt.Errorf("unknown fixtures key %q", key.String())
+		}
+	}
+	return fixtures, nil
+}
+
+// isFreeFormKey reports whether a TOML key is inside a value without a fixed
+// structure (outputs and error details), those keys are reported as
+// undecoded by the TOML decoder but are valid.
+func isFreeFormKey(key toml.Key) bool {
+	// Skip the "defaults" table or the "procs.<name>" and "streams.<name>"
+	// tables to get the fixture field name
+	fieldIndex := 1
+	if len(key) > 0 && (key[0] == "procs" || key[0] == "streams") {
+		fieldIndex = 2
+	}
+	if len(key) <= fieldIndex+1 {
+		return false
+	}
+
+	switch key[fieldIndex] {
+	case "output", "outputs":
+		return true
+	case "error":
+		return key[fieldIndex+1] == "details"
+	default:
+		return false
+	}
+}
+
+// validate checks the fixtures against the schema.
+func (f Fixtures) validate(s *Server) error {
+	if err := f.Defaults.validate("defaults"); err != nil {
+		return err
+	}
+	if len(f.Defaults.Output) > 0 || len(f.Defaults.Outputs) > 0 {
+		return fmt.Errorf("defaults: output and outputs can only be set per operation")
+	}
+
+	for _, name := range sortedKeys(f.Procs) {
+		proc, ok := s.procs[name]
+		if !ok {
+			return fmt.Errorf("procs.%s: procedure %q is not declared in the schema", name, name)
+		}
+		fixture := f.Procs[name]
+		if err := fixture.validate("procs." + name); err != nil {
+			return err
+		}
+		if len(fixture.Outputs) > 0 {
+			return fmt.Errorf("procs.%s.outputs: only streams can have outputs, use output instead", name)
+		}
+		if fixture.Interval != nil || fixture.Count != nil {
+			return fmt.Errorf("procs.%s: interval and count can only be set for streams", name)
+		}
+		if fixture.Output != nil {
+			if err := s.validateOutput(proc.Output, fixture.Output); err != nil {
+				return fmt.Errorf("procs.%s.output: %w", name, err)
+			}
+		}
+	}
+
+	for _, name := range sortedKeys(f.Streams) {
+		stream, ok := s.streams[name]
+		if !ok {
+			return fmt.Errorf("streams.%s: stream %q is not declared in the schema", name, name)
+		}
+		fixture := f.Streams[name]
+		if err := fixture.validate("streams." + name); err != nil {
+			return err
+		}
+		if fixture.Output != nil {
+			if err := s.validateOutput(stream.Output, fixture.Output); err != nil {
+				return fmt.Errorf("streams.%s.output: %w", name, err)
+			}
+		}
+		for i, output := range fixture.Outputs {
+			if err := s.validateOutput(stream.Output, output); err != nil {
+				return fmt.Errorf("streams.%s.outputs[%d]: %w", name, i, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// validate checks the values of a single fixture, the path is used to
+// report the failing fixture.
+func (f Fixture) validate(path string) error {
+	if f.Latency != nil && f.Latency.Duration < 0 {
+		return fmt.Errorf("%s.latency: must not be negative", path)
+	}
+	if f.ErrorRate != nil && (*f.ErrorRate < 0 || *f.ErrorRate > 1) {
+		return fmt.Errorf("%s.error_rate: must be between 0 and 1", path)
+	}
+	if f.Error != nil && f.Error.Message == "" {
+		return fmt.Errorf("%s.error.message: is required", path)
+	}
+	if f.Interval != nil && f.Interval.Duration <= 0 {
+		return fmt.Errorf("%s.interval: must be greater than zero", path)
+	}
+	if f.Count != nil && *f.Count < 0 {
+		return fmt.Errorf("%s.count: must not be negative", path)
+	}
+
+	return nil
+}
+
+// validateOutput checks an output fixture merged over a synthesized output,
+// so fixtures only need to set the fields they want to override.
+func (s *Server) validateOutput(fields []schema.FieldDefinition, output map[string]any) error {
+	synth := s.newSynthesizer()
+	merged := synth.object(fields, 0).merge(output)
+
+	data, err := json.Marshal(merged)
+	if err != nil {
+		return fmt.Errorf("failed to encode output: %w", err)
+	}
+	decoded, err := schema.DecodeValue(data)
+	if err != nil {
+		return fmt.Errorf("failed to decode output: %w", err)
+	}
+
+	return s.validator.Validate(fields, decoded)
+}
+
+// resolve returns the fixture of an operation with the unset values taken
+// from the defaults.
+func (f Fixtures) resolve(operationType string, name string) Fixture {
+	resolved := f.Defaults
+
+	operation, ok := f.Procs[name]
+	if operationType == operationTypeStream {
+		operation, ok = f.Streams[name]
+	}
+	if !ok {
+		return resolved
+	}
+
+	if operation.Latency != nil {
+		resolved.Latency = operation.Latency
+	}
+	if operation.ErrorRate != nil {
+		resolved.ErrorRate = operation.ErrorRate
+	}
+	if operation.Error != nil {
+		resolved.Error = operation.Error
+	}
+	if operation.Interval != nil {
+		resolved.Interval = operation.Interval
+	}
+	if operation.Count != nil {
+		resolved.Count = operation.Count
+	}
+	resolved.Output = operation.Output
+	resolved.Outputs = operation.Outputs
+	return resolved
+}
+
+// sortedKeys returns the keys of a map in sorted order.
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
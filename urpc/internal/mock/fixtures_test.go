@@ -0,0 +1,151 @@
+package mock
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeFixtures(t *testing.T, name string, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestLoadFixtures(t *testing.T) {
+	t.Run("TOML", func(t *testing.T) {
+		path := writeFixtures(t, "fixtures.toml", `
+seed = 7
+
+[defaults]
+latency = "10ms"
+
+[procs.GetUser]
+error_rate = 0.25
+error = { message = "User not found", code = "USER_NOT_FOUND", details = { id = "1" } }
+output = { user = { email = "ada@example.com" } }
+
+[streams.Ticks]
+interval = "200ms"
+count = 5
+outputs = [{ value = 1 }, { value = 2 }]
+`)
+
+		fixtures, err := LoadFixtures(path)
+		require.NoError(t, err)
+		require.Equal(t, uint64(7), *fixtures.Seed)
+		require.Equal(t, 10*time.Millisecond, fixtures.Defaults.Latency.Duration)
+
+		getUser := fixtures.Procs["GetUser"]
+		require.Equal(t, 0.25, *getUser.ErrorRate)
+		require.Equal(t, &Error{Message: "User not found", Code: "USER_NOT_FOUND", Details: map[string]any{"id": "1"}}, getUser.Error)
+		require.Equal(t, map[string]any{"user": map[string]any{"email": "ada@example.com"}}, getUser.Output)
+
+		ticks := fixtures.Streams["Ticks"]
+		require.Equal(t, 200*time.Millisecond, ticks.Interval.Duration)
+		require.Equal(t, 5, *ticks.Count)
+		require.Len(t, ticks.Outputs, 2)
+
+		_, err = NewServer(newTestSchema(t), fixtures)
+		require.NoError(t, err)
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		path := writeFixtures(t, "fixtures.json", `{
+			"defaults": {"latency": "1s"},
+			"streams": {"Ticks": {"interval": "5ms", "output": {"label": "tick"}}}
+		}`)
+
+		fixtures, err := LoadFixtures(path)
+		require.NoError(t, err)
+		require.Equal(t, time.Second, fixtures.Defaults.Latency.Duration)
+		require.Equal(t, 5*time.Millisecond, fixtures.Streams["Ticks"].Interval.Duration)
+		require.Equal(t, map[string]any{"label": "tick"}, fixtures.Streams["Ticks"].Output)
+	})
+
+	t.Run("Unknown keys", func(t *testing.T) {
+		_, err := LoadFixtures(writeFixtures(t, "fixtures.toml", "[procs.GetUser]\nlatncy = \"1s\"\n"))
+		require.ErrorContains(t, err, `unknown fixtures key "procs.GetUser.latncy"`)
+
+		_, err = LoadFixtures(writeFixtures(t, "fixtures.json", `{"defaults":{"latncy":"1s"}}`))
+		require.ErrorContains(t, err, `unknown field "latncy"`)
+	})
+
+	t.Run("Invalid duration", func(t *testing.T) {
+		_, err := LoadFixtures(writeFixtures(t, "fixtures.toml", "[defaults]\nlatency = \"soon\"\n"))
+		require.ErrorContains(t, err, "soon")
+	})
+}
+
+func TestFixturesValidate(t *testing.T) {
+	half := 0.5
+	tooHigh := 1.5
+	zero := 0
+	testCases := []struct {
+		name     string
+		fixtures Fixtures
+		expected string
+	}{
+		{
+			name:     "Unknown procedure",
+			fixtures: Fixtures{Procs: map[string]Fixture{"Missing": {}}},
+			expected: `procs.Missing: procedure "Missing" is not declared in the schema`,
+		},
+		{
+			name:     "Unknown stream",
+			fixtures: Fixtures{Streams: map[string]Fixture{"GetUser": {}}},
+			expected: `streams.GetUser: stream "GetUser" is not declared in the schema`,
+		},
+		{
+			name:     "Error rate out of range",
+			fixtures: Fixtures{Defaults: Fixture{ErrorRate: &tooHigh}},
+			expected: "defaults.error_rate: must be between 0 and 1",
+		},
+		{
+			name:     "Error without message",
+			fixtures: Fixtures{Procs: map[string]Fixture{"GetUser": {ErrorRate: &half, Error: &Error{Code: "X"}}}},
+			expected: "procs.GetUser.error.message: is required",
+		},
+		{
+			name:     "Stream settings in procedure",
+			fixtures: Fixtures{Procs: map[string]Fixture{"GetUser": {Count: &zero}}},
+			expected: "procs.GetUser: interval and count can only be set for streams",
+		},
+		{
+			name:     "Output in defaults",
+			fixtures: Fixtures{Defaults: Fixture{Output: map[string]any{"id": "1"}}},
+			expected: "defaults: output and outputs can only be set per operation",
+		},
+		{
+			name:     "Output with invalid type",
+			fixtures: Fixtures{Procs: map[string]Fixture{"CreateUser": {Output: map[string]any{"id": 1}}}},
+			expected: "procs.CreateUser.output: field id: expected a string, got number",
+		},
+		{
+			name:     "Output with invalid nested type",
+			fixtures: Fixtures{Procs: map[string]Fixture{"GetUser": {Output: map[string]any{"user": map[string]any{"address": "home"}}}}},
+			expected: "procs.GetUser.output: field user.address: expected an object, got string",
+		},
+		{
+			name:     "Output with null required field",
+			fixtures: Fixtures{Procs: map[string]Fixture{"GetUser": {Output: map[string]any{"user": map[string]any{"email": nil}}}}},
+			expected: "procs.GetUser.output: field user: field email is required",
+		},
+		{
+			name:     "Stream outputs with invalid type",
+			fixtures: Fixtures{Streams: map[string]Fixture{"Ticks": {Outputs: []map[string]any{{"value": 1}, {"value": "two"}}}}},
+			expected: "streams.Ticks.outputs[1]: field value: expected an int, got string",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewServer(newTestSchema(t), tc.fixtures)
+			require.EqualError(t, err, "invalid fixtures: "+tc.expected)
+		})
+	}
+}
@@ -0,0 +1,305 @@
+// Package mock implements an HTTP server that mocks the UFO RPC request
+// lifecycle of a schema, so clients can be developed before the handlers
+// exist.
+//
+// Every procedure is served at POST /<Proc> and every stream at
+// POST /<Stream>, any path prefix is accepted because the operation name
+// is the last path segment, like in the generated Go server. Inputs are
+// validated like the generated Go server does and outputs are synthesized
+// from the field types and names. The responses, latency, injected errors
+// and stream emission rates can be overridden with Fixtures.
+package mock
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/uforg/uforpc/urpc/internal/schema"
+)
+
+const (
+	operationTypeProc   = "proc"
+	operationTypeStream = "stream"
+)
+
+// defaultStreamInterval is the time between stream events when no fixture
+// sets it.
+const defaultStreamInterval = time.Second
+
+// defaultInjectedError is the error injected by an error rate without an
+// error fixture.
+var defaultInjectedError = Error{
+	Message:  "Mock error injected by the error rate",
+	Category: "MockError",
+	Code:     "MOCK_ERROR",
+}
+
+// Error is the UFO RPC error returned in the response envelope.
+type Error struct {
+	Message  string         `json:"message" toml:"message"`
+	Category string         `json:"category,omitempty" toml:"category"`
+	Code     string         `json:"code,omitempty" toml:"code"`
+	Details  map[string]any `json:"details,omitempty" toml:"details"`
+}
+
+// Error implements the error interface.
+func (e Error) Error() string {
+	return e.Message
+}
+
+// response is the UFO RPC response envelope.
+type response struct {
+	Ok     bool   `json:"ok"`
+	Output any    `json:"output,omitempty"`
+	Error  *Error `json:"error,omitempty"`
+}
+
+// Server is an http.Handler that mocks the operations of a schema.
+type Server struct {
+	procs     map[string]*schema.NodeProc
+	streams   map[string]*schema.NodeStream
+	types     map[string]*schema.NodeType
+	validator *schema.ValueValidator
+	fixtures  Fixtures
+
+	// randMu protects rand, the handlers run concurrently
+	randMu sync.Mutex
+	rand   *rand.Rand
+}
+
+// NewServer creates a mock server for the schema.
+//
+// Returns an error if the fixtures reference operations that are not in the
+// schema or contain invalid values.
+func NewServer(sch schema.Schema, fixtures Fixtures) (*Server, error) {
+	seed := uint64(time.Now().UnixNano())
+	if fixtures.Seed != nil {
+		seed = *fixtures.Seed
+	}
+
+	s := &Server{
+		procs:     sch.GetProcNodesMap(),
+		streams:   sch.GetStreamNodesMap(),
+		types:     sch.GetTypeNodesMap(),
+		validator: schema.NewValueValidator(sch),
+		fixtures:  fixtures,
+		rand:      rand.New(rand.NewPCG(seed, seed)),
+	}
+
+	if err := fixtures.validate(s); err != nil {
+		return nil, fmt.Errorf("invalid fixtures: %w", err)
+	}
+
+	return s, nil
+}
+
+// ServeHTTP implements the http.Handler interface.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// The mock is meant for clients running in other origins (e.g. a
+	// frontend dev server), so CORS is always allowed
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "*")
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST, OPTIONS")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	operationName := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeProcResponse(w, response{Error: &Error{Message: "Invalid request body"}})
+		return
+	}
+	input, err := schema.DecodeValue(body)
+	if err != nil {
+		writeProcResponse(w, response{Error: &Error{Message: "Invalid request body"}})
+		return
+	}
+
+	if stream, ok := s.streams[operationName]; ok {
+		s.serveStream(r.Context(), w, stream, input)
+		return
+	}
+
+	proc, ok := s.procs[operationName]
+	if !ok {
+		writeProcResponse(w, response{Error: &Error{Message: "Invalid operation name"}})
+		return
+	}
+	s.serveProc(r.Context(), w, proc, input)
+}
+
+// serveProc writes the response of a procedure after the configured
+// latency.
+func (s *Server) serveProc(ctx context.Context, w http.ResponseWriter, proc *schema.NodeProc, input any) {
+	fixture := s.fixtures.resolve(operationTypeProc, proc.Name)
+
+	if err := s.validateInput(proc.Name, proc.Input, input); err != nil {
+		writeProcResponse(w, errorResponse(err))
+		return
+	}
+
+	if fixture.Latency != nil {
+		if err := sleep(ctx, fixture.Latency.Duration); err != nil {
+			return
+		}
+	}
+
+	if err := s.injectedError(fixture); err != nil {
+		writeProcResponse(w, errorResponse(*err))
+		return
+	}
+
+	writeProcResponse(w, response{Ok: true, Output: s.output(proc.Output, fixture.Output)})
+}
+
+// serveStream writes the Server-Sent Events of a stream until the
+// configured count is reached, an error is injected or the client
+// disconnects.
+func (s *Server) serveStream(ctx context.Context, w http.ResponseWriter, stream *schema.NodeStream, input any) {
+	fixture := s.fixtures.resolve(operationTypeStream, stream.Name)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	if err := s.validateInput(stream.Name, stream.Input, input); err != nil {
+		writeEvent(w, errorResponse(err))
+		return
+	}
+
+	interval := defaultStreamInterval
+	if fixture.Interval != nil {
+		interval = fixture.Interval.Duration
+	}
+	count := 0
+	if fixture.Count != nil {
+		count = *fixture.Count
+	}
+
+	delay := time.Duration(0)
+	if fixture.Latency != nil {
+		delay = fixture.Latency.Duration
+	}
+
+	for i := 0; count == 0 || i < count; i++ {
+		if err := sleep(ctx, delay); err != nil {
+			return
+		}
+		delay = interval
+
+		if err := s.injectedError(fixture); err != nil {
+			writeEvent(w, errorResponse(*err))
+			return
+		}
+
+		override := fixture.Output
+		if len(fixture.Outputs) > 0 {
+			override = fixture.Outputs[i%len(fixture.Outputs)]
+		}
+		if err := writeEvent(w, response{Ok: true, Output: s.output(stream.Output, override)}); err != nil {
+			return
+		}
+	}
+}
+
+// output synthesizes an output for the fields with the override values.
+func (s *Server) output(fields []schema.FieldDefinition, override map[string]any) object {
+	return s.newSynthesizer().object(fields, 0).merge(override)
+}
+
+// injectedError returns the error to fail the request with according to
+// the fixture, or nil if the request should succeed.
+func (s *Server) injectedError(fixture Fixture) *Error {
+	if fixture.ErrorRate == nil {
+		return fixture.Error
+	}
+
+	s.randMu.Lock()
+	failed := s.rand.Float64() < *fixture.ErrorRate
+	s.randMu.Unlock()
+	if !failed {
+		return nil
+	}
+
+	if fixture.Error != nil {
+		return fixture.Error
+	}
+	injected := defaultInjectedError
+	return &injected
+}
+
+// newSynthesizer returns a synthesizer with its own random source seeded
+// from the server one, so concurrent requests do not share it.
+func (s *Server) newSynthesizer() *synthesizer {
+	s.randMu.Lock()
+	seed1, seed2 := s.rand.Uint64(), s.rand.Uint64()
+	s.randMu.Unlock()
+
+	return &synthesizer{
+		rand:  rand.New(rand.NewPCG(seed1, seed2)),
+		types: s.types,
+		now:   time.Now(),
+	}
+}
+
+// errorResponse returns the failed response for an error.
+func errorResponse(err error) response {
+	rpcErr, ok := err.(Error)
+	if !ok {
+		rpcErr = Error{Message: err.Error()}
+	}
+	return response{Ok: false, Error: &rpcErr}
+}
+
+// writeProcResponse writes a procedure response as JSON.
+func writeProcResponse(w http.ResponseWriter, res response) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(res)
+}
+
+// writeEvent writes a response as a Server-Sent Event and flushes it.
+func writeEvent(w http.ResponseWriter, res response) error {
+	data, err := json.Marshal(res)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "data: %s\n\n", data); err != nil {
+		return err
+	}
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	return nil
+}
+
+// sleep waits for the duration or until the context is done.
+func sleep(ctx context.Context, duration time.Duration) error {
+	if duration <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
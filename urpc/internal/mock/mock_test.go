@@ -0,0 +1,349 @@
+package mock
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/uforg/uforpc/urpc/internal/schema"
+	"github.com/uforg/uforpc/urpc/internal/transpile"
+	"github.com/uforg/uforpc/urpc/internal/urpc/parser"
+)
+
+const testSchema = `
+version 1
+
+type Address {
+  street: string
+  city: string
+}
+
+type User {
+  id: string
+  email: string
+  age: int
+  rating: float
+  active: bool
+  createdAt: datetime
+  address: Address
+  tags: string[]
+  nickname?: string
+  friends?: User[]
+}
+
+proc GetUser {
+  input {
+    id: string
+    withFriends?: bool
+  }
+  output {
+    user: User
+  }
+}
+
+proc CreateUser {
+  input {
+    email: string
+    addresses: Address[]
+    meta?: {
+      source: string
+    }
+  }
+  output {
+    id: string
+  }
+}
+
+stream Ticks {
+  input {
+    prefix: string
+  }
+  output {
+    value: int
+    label: string
+  }
+}
+`
+
+func newTestSchema(t *testing.T) schema.Schema {
+	t.Helper()
+	astSchema, err := parser.ParserInstance.ParseString("schema.urpc", testSchema)
+	require.NoError(t, err)
+	sch, err := transpile.ToJSON(*astSchema)
+	require.NoError(t, err)
+	return sch
+}
+
+func newTestServer(t *testing.T, fixtures Fixtures) *Server {
+	t.Helper()
+	if fixtures.Seed == nil {
+		seed := uint64(1)
+		fixtures.Seed = &seed
+	}
+	server, err := NewServer(newTestSchema(t), fixtures)
+	require.NoError(t, err)
+	return server
+}
+
+func call(t *testing.T, server http.Handler, operation string, body string) map[string]any {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/urpc/"+operation, strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	res := map[string]any{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+	return res
+}
+
+func events(t *testing.T, server http.Handler, operation string, body string) []map[string]any {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/"+operation, strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+
+	require.Equal(t, "text/event-stream", rec.Header().Get("Content-Type"))
+
+	result := []map[string]any{}
+	scanner := bufio.NewScanner(rec.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+		require.True(t, strings.HasPrefix(line, "data: "), line)
+		event := map[string]any{}
+		require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &event))
+		result = append(result, event)
+	}
+	return result
+}
+
+func TestServerProc(t *testing.T) {
+	server := newTestServer(t, Fixtures{})
+
+	t.Run("Synthesizes a valid output", func(t *testing.T) {
+		res := call(t, server, "GetUser", `{"id":"1"}`)
+		require.Equal(t, true, res["ok"])
+
+		output, err := json.Marshal(res["output"])
+		require.NoError(t, err)
+		decoded, err := schema.DecodeValue(output)
+		require.NoError(t, err)
+		require.NoError(t, server.validator.Validate(server.procs["GetUser"].Output, decoded))
+
+		user := res["output"].(map[string]any)["user"].(map[string]any)
+		require.Contains(t, user["email"], "@example.com")
+		_, err = time.Parse(time.RFC3339, user["createdAt"].(string))
+		require.NoError(t, err)
+	})
+
+	t.Run("Keeps the order of the fields", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/GetUser", strings.NewReader(`{"id":"1"}`))
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, req)
+		body := rec.Body.String()
+		require.Less(t, strings.Index(body, `"id"`), strings.Index(body, `"email"`))
+		require.Less(t, strings.Index(body, `"email"`), strings.Index(body, `"address"`))
+	})
+
+	t.Run("Invalid request body", func(t *testing.T) {
+		res := call(t, server, "GetUser", `{`)
+		require.Equal(t, map[string]any{"ok": false, "error": map[string]any{"message": "Invalid request body"}}, res)
+	})
+
+	t.Run("Invalid operation name", func(t *testing.T) {
+		res := call(t, server, "Missing", `{}`)
+		require.Equal(t, map[string]any{"ok": false, "error": map[string]any{"message": "Invalid operation name"}}, res)
+	})
+
+	t.Run("Missing required field", func(t *testing.T) {
+		res := call(t, server, "GetUser", `{"withFriends":true}`)
+		require.Equal(t, map[string]any{
+			"ok": false,
+			"error": map[string]any{
+				"message":  "field id is required",
+				"category": "ValidationError",
+				"code":     "MISSING_REQUIRED_FIELD",
+			},
+		}, res)
+	})
+
+	t.Run("Missing nested required field", func(t *testing.T) {
+		res := call(t, server, "CreateUser", `{"email":"a@b.c","addresses":[{"street":"s","city":"c"},{"street":"s"}]}`)
+		require.Equal(t, "field addresses: field city is required", res["error"].(map[string]any)["message"])
+
+		res = call(t, server, "CreateUser", `{"email":"a@b.c","addresses":[],"meta":{}}`)
+		require.Equal(t, "field meta: field source is required", res["error"].(map[string]any)["message"])
+	})
+
+	t.Run("Null is not present", func(t *testing.T) {
+		res := call(t, server, "GetUser", `{"id":null}`)
+		require.Equal(t, "field id is required", res["error"].(map[string]any)["message"])
+
+		res = call(t, server, "GetUser", `{"id":"1","withFriends":null}`)
+		require.Equal(t, true, res["ok"])
+	})
+
+	t.Run("Invalid field type", func(t *testing.T) {
+		res := call(t, server, "GetUser", `{"id":1}`)
+		require.Equal(t, map[string]any{"message": "failed to unmarshal GetUser input: field id: expected a string, got number"}, res["error"])
+
+		res = call(t, server, "CreateUser", `{"email":"a","addresses":[{"street":"s","city":1}]}`)
+		require.Equal(t, "failed to unmarshal CreateUser input: field addresses[0].city: expected a string, got number", res["error"].(map[string]any)["message"])
+
+		res = call(t, server, "CreateUser", `{"email":"a","addresses":{}}`)
+		require.Equal(t, "failed to unmarshal CreateUser input: field addresses: expected an array, got object", res["error"].(map[string]any)["message"])
+
+		res = call(t, server, "GetUser", `[]`)
+		require.Equal(t, "failed to unmarshal GetUser input: expected an object, got array", res["error"].(map[string]any)["message"])
+	})
+
+	t.Run("Method not allowed", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/GetUser", nil))
+		require.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	})
+
+	t.Run("CORS preflight", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, httptest.NewRequest(http.MethodOptions, "/GetUser", nil))
+		require.Equal(t, http.StatusNoContent, rec.Code)
+		require.Equal(t, "*", rec.Header().Get("Access-Control-Allow-Origin"))
+	})
+}
+
+func TestServerProcFixtures(t *testing.T) {
+	one := 1.0
+	server := newTestServer(t, Fixtures{
+		Procs: map[string]Fixture{
+			"CreateUser": {Output: map[string]any{"id": "fixed-id"}},
+			"GetUser": {
+				ErrorRate: &one,
+				Error:     &Error{Message: "User not found", Code: "USER_NOT_FOUND"},
+			},
+		},
+	})
+
+	res := call(t, server, "CreateUser", `{"email":"a@b.c","addresses":[]}`)
+	require.Equal(t, map[string]any{"ok": true, "output": map[string]any{"id": "fixed-id"}}, res)
+
+	t.Run("Nested outputs are merged", func(t *testing.T) {
+		server := newTestServer(t, Fixtures{
+			Procs: map[string]Fixture{"GetUser": {Output: map[string]any{"user": map[string]any{"email": "ada@example.com"}}}},
+		})
+		res := call(t, server, "GetUser", `{"id":"1"}`)
+		user := res["output"].(map[string]any)["user"].(map[string]any)
+		require.Equal(t, "ada@example.com", user["email"])
+		require.NotEmpty(t, user["id"])
+		require.NotEmpty(t, user["address"])
+	})
+
+	res = call(t, server, "GetUser", `{"id":"1"}`)
+	require.Equal(t, map[string]any{
+		"ok":    false,
+		"error": map[string]any{"message": "User not found", "code": "USER_NOT_FOUND"},
+	}, res)
+
+	t.Run("Inputs are validated before injecting errors", func(t *testing.T) {
+		res := call(t, server, "GetUser", `{}`)
+		require.Equal(t, "field id is required", res["error"].(map[string]any)["message"])
+	})
+
+	t.Run("Default injected error", func(t *testing.T) {
+		server := newTestServer(t, Fixtures{Defaults: Fixture{ErrorRate: &one}})
+		res := call(t, server, "CreateUser", `{"email":"a@b.c","addresses":[]}`)
+		require.Equal(t, "MOCK_ERROR", res["error"].(map[string]any)["code"])
+	})
+
+	t.Run("Latency", func(t *testing.T) {
+		server := newTestServer(t, Fixtures{
+			Defaults: Fixture{Latency: &Duration{time.Hour}},
+			Procs:    map[string]Fixture{"GetUser": {Latency: &Duration{50 * time.Millisecond}}},
+		})
+
+		start := time.Now()
+		res := call(t, server, "GetUser", `{"id":"1"}`)
+		require.Equal(t, true, res["ok"])
+		require.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+		require.Less(t, time.Since(start), time.Hour)
+	})
+}
+
+func TestServerStream(t *testing.T) {
+	t.Run("Emits the configured events", func(t *testing.T) {
+		count := 3
+		server := newTestServer(t, Fixtures{
+			Streams: map[string]Fixture{
+				"Ticks": {
+					Interval: &Duration{time.Millisecond},
+					Count:    &count,
+					Outputs:  []map[string]any{{"value": 1}, {"value": 2}},
+				},
+			},
+		})
+
+		got := events(t, server, "Ticks", `{"prefix":"a"}`)
+		require.Len(t, got, 3)
+		for i, expected := range []float64{1, 2, 1} {
+			require.Equal(t, true, got[i]["ok"])
+			output := got[i]["output"].(map[string]any)
+			require.Equal(t, expected, output["value"])
+			require.IsType(t, "", output["label"])
+		}
+	})
+
+	t.Run("Invalid input", func(t *testing.T) {
+		server := newTestServer(t, Fixtures{})
+		got := events(t, server, "Ticks", `{}`)
+		require.Equal(t, []map[string]any{{
+			"ok": false,
+			"error": map[string]any{
+				"message":  "field prefix is required",
+				"category": "ValidationError",
+				"code":     "MISSING_REQUIRED_FIELD",
+			},
+		}}, got)
+	})
+
+	t.Run("Injected error closes the stream", func(t *testing.T) {
+		server := newTestServer(t, Fixtures{
+			Streams: map[string]Fixture{"Ticks": {Error: &Error{Message: "boom"}}},
+		})
+		got := events(t, server, "Ticks", `{"prefix":"a"}`)
+		require.Equal(t, []map[string]any{{"ok": false, "error": map[string]any{"message": "boom"}}}, got)
+	})
+
+	t.Run("Stops when the client disconnects", func(t *testing.T) {
+		server := httptest.NewServer(newTestServer(t, Fixtures{
+			Streams: map[string]Fixture{"Ticks": {Interval: &Duration{time.Millisecond}}},
+		}))
+		defer server.Close()
+
+		res, err := http.Post(server.URL+"/Ticks", "application/json", strings.NewReader(`{"prefix":"a"}`))
+		require.NoError(t, err)
+		reader := bufio.NewReader(res.Body)
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(line, `data: {"ok":true`))
+		require.NoError(t, res.Body.Close())
+	})
+}
+
+func TestSynthesizerIsDeterministic(t *testing.T) {
+	first := call(t, newTestServer(t, Fixtures{}), "GetUser", `{"id":"1"}`)
+	second := call(t, newTestServer(t, Fixtures{}), "GetUser", `{"id":"1"}`)
+
+	// Datetimes depend on the current time
+	delete(first["output"].(map[string]any)["user"].(map[string]any), "createdAt")
+	delete(second["output"].(map[string]any)["user"].(map[string]any), "createdAt")
+	require.Equal(t, first, second)
+}
@@ -0,0 +1,271 @@
+package mock
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"github.com/uforg/uforpc/urpc/internal/schema"
+)
+
+// maxSynthesizeDepth is the nesting depth after which optional fields are
+// omitted and arrays are left empty, so optional self references end.
+const maxSynthesizeDepth = 4
+
+// object is a JSON object that keeps the order of its members, so the
+// synthesized outputs follow the order of the schema fields.
+type object []member
+
+type member struct {
+	Key   string
+	Value any
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (o object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, m := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(m.Key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(m.Value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// merge returns a copy of the object with the given values, existing keys
+// are replaced in place and new keys are appended in sorted order. Nested
+// objects are merged recursively, any other value is replaced.
+func (o object) merge(values map[string]any) object {
+	merged := make(object, 0, len(o)+len(values))
+	seen := map[string]bool{}
+	for _, m := range o {
+		if value, ok := values[m.Key]; ok {
+			nestedObject, isObject := m.Value.(object)
+			nestedValues, isMap := value.(map[string]any)
+			if isObject && isMap {
+				m.Value = nestedObject.merge(nestedValues)
+			} else {
+				m.Value = value
+			}
+		}
+		merged = append(merged, m)
+		seen[m.Key] = true
+	}
+	for _, key := range sortedKeys(values) {
+		if !seen[key] {
+			merged = append(merged, member{Key: key, Value: values[key]})
+		}
+	}
+	return merged
+}
+
+// synthesizer generates realistic random values for schema fields.
+type synthesizer struct {
+	rand  *rand.Rand
+	types map[string]*schema.NodeType
+	now   time.Time
+}
+
+// object returns an object with a value for every required field and for
+// most of the optional ones.
+func (s *synthesizer) object(fields []schema.FieldDefinition, depth int) object {
+	obj := make(object, 0, len(fields))
+	for _, field := range fields {
+		if field.Optional && (depth >= maxSynthesizeDepth || s.rand.IntN(4) == 0) {
+			continue
+		}
+		obj = append(obj, member{Key: field.Name, Value: s.field(field, depth)})
+	}
+	return obj
+}
+
+// field returns a value for the field, arrays have between one and three
+// items.
+func (s *synthesizer) field(field schema.FieldDefinition, depth int) any {
+	if !field.IsArray {
+		return s.single(field, depth)
+	}
+
+	items := []any{}
+	if depth >= maxSynthesizeDepth {
+		return items
+	}
+	for range 1 + s.rand.IntN(3) {
+		items = append(items, s.single(field, depth))
+	}
+	return items
+}
+
+// single returns a value for the type of the field ignoring if it is an
+// array.
+func (s *synthesizer) single(field schema.FieldDefinition, depth int) any {
+	if field.IsInline() {
+		return s.object(field.TypeInline.Fields, depth+1)
+	}
+	if typeNode, ok := s.types[*field.TypeName]; ok {
+		return s.object(typeNode.Fields, depth+1)
+	}
+	return s.primitive(*field.TypeName, field.Name)
+}
+
+// primitive returns a value for a primitive type, the field name is used to
+// pick a realistic value (e.g. an email address for a field named email).
+func (s *synthesizer) primitive(typeName string, fieldName string) any {
+	name := strings.ToLower(fieldName)
+
+	switch typeName {
+	case schema.PrimitiveTypeString.Value:
+		return s.string(name)
+	case schema.PrimitiveTypeInt.Value:
+		return s.int(name)
+	case schema.PrimitiveTypeFloat.Value:
+		return s.float(name)
+	case schema.PrimitiveTypeBool.Value:
+		return s.rand.IntN(2) == 0
+	case schema.PrimitiveTypeDatetime.Value:
+		offset := time.Duration(s.rand.Int64N(int64(30 * 24 * time.Hour)))
+		return s.now.Add(-offset).UTC().Truncate(time.Second).Format(time.RFC3339)
+	default:
+		return nil
+	}
+}
+
+var (
+	firstNames = []string{"Ada", "Alan", "Grace", "Linus", "Margaret", "Ken", "Barbara", "Dennis"}
+	lastNames  = []string{"Lovelace", "Turing", "Hopper", "Torvalds", "Hamilton", "Thompson", "Liskov", "Ritchie"}
+	loremWords = []string{
+		"lorem", "ipsum", "dolor", "sit", "amet", "consectetur", "adipiscing", "elit",
+		"sed", "do", "eiusmod", "tempor", "incididunt", "ut", "labore", "et", "dolore",
+	}
+	cities    = []string{"Lisbon", "Tokyo", "Buenos Aires", "Toronto", "Nairobi", "Berlin"}
+	countries = []string{"PT", "JP", "AR", "CA", "KE", "DE"}
+	statuses  = []string{"active", "pending", "archived"}
+)
+
+// string returns a string value based on the lowercase field name.
+func (s *synthesizer) string(name string) string {
+	first := pick(s.rand, firstNames)
+	last := pick(s.rand, lastNames)
+
+	switch {
+	case strings.Contains(name, "email"):
+		return fmt.Sprintf("%s.%s@example.com", strings.ToLower(first), strings.ToLower(last))
+	case strings.Contains(name, "url"), strings.Contains(name, "link"), strings.Contains(name, "website"):
+		return fmt.Sprintf("https://example.com/%s", pick(s.rand, loremWords))
+	case strings.Contains(name, "avatar"), strings.Contains(name, "image"), strings.Contains(name, "photo"):
+		return fmt.Sprintf("https://picsum.photos/seed/%d/200", s.rand.IntN(1000))
+	case name == "id", name == "uuid", strings.HasSuffix(name, "id"), strings.HasSuffix(name, "uuid"):
+		return s.uuid()
+	case strings.Contains(name, "phone"):
+		return fmt.Sprintf("+1-555-%04d", s.rand.IntN(10000))
+	case strings.Contains(name, "firstname"):
+		return first
+	case strings.Contains(name, "lastname"), strings.Contains(name, "surname"):
+		return last
+	case strings.Contains(name, "username"), strings.Contains(name, "login"), strings.Contains(name, "handle"):
+		return strings.ToLower(first) + fmt.Sprint(s.rand.IntN(100))
+	case strings.Contains(name, "name"):
+		return first + " " + last
+	case strings.Contains(name, "city"):
+		return pick(s.rand, cities)
+	case strings.Contains(name, "country"):
+		return pick(s.rand, countries)
+	case strings.Contains(name, "address"), strings.Contains(name, "street"):
+		return fmt.Sprintf("%d %s Street", 1+s.rand.IntN(999), last)
+	case strings.Contains(name, "status"), strings.Contains(name, "state"):
+		return pick(s.rand, statuses)
+	case strings.Contains(name, "color"), strings.Contains(name, "colour"):
+		return fmt.Sprintf("#%06x", s.rand.IntN(0xffffff+1))
+	case strings.Contains(name, "token"), strings.Contains(name, "hash"), strings.Contains(name, "secret"):
+		return fmt.Sprintf("%016x%016x", s.rand.Uint64(), s.rand.Uint64())
+	case strings.Contains(name, "password"):
+		return "********"
+	case strings.Contains(name, "title"), strings.Contains(name, "subject"):
+		return s.lorem(3, 6)
+	case strings.Contains(name, "description"), strings.Contains(name, "content"), strings.Contains(name, "body"),
+		strings.Contains(name, "bio"), strings.Contains(name, "message"), strings.Contains(name, "text"),
+		strings.Contains(name, "comment"), strings.Contains(name, "summary"):
+		return s.lorem(8, 16) + "."
+	default:
+		return s.lorem(2, 3)
+	}
+}
+
+// int returns an int value based on the lowercase field name.
+func (s *synthesizer) int(name string) int64 {
+	switch {
+	case strings.Contains(name, "page"):
+		return 1 + s.rand.Int64N(10)
+	case strings.Contains(name, "age"):
+		return 18 + s.rand.Int64N(63)
+	case strings.Contains(name, "year"):
+		return 2000 + s.rand.Int64N(31)
+	case strings.Contains(name, "count"), strings.Contains(name, "total"), strings.Contains(name, "quantity"),
+		strings.Contains(name, "size"), strings.Contains(name, "limit"):
+		return s.rand.Int64N(101)
+	default:
+		return 1 + s.rand.Int64N(1000)
+	}
+}
+
+// float returns a float value based on the lowercase field name.
+func (s *synthesizer) float(name string) float64 {
+	switch {
+	case strings.Contains(name, "lat"):
+		return round(s.rand.Float64()*180-90, 6)
+	case strings.Contains(name, "lng"), strings.Contains(name, "lon"):
+		return round(s.rand.Float64()*360-180, 6)
+	case strings.Contains(name, "rating"), strings.Contains(name, "score"):
+		return round(s.rand.Float64()*5, 1)
+	case strings.Contains(name, "percent"), strings.Contains(name, "ratio"):
+		return round(s.rand.Float64()*100, 2)
+	default:
+		return round(1+s.rand.Float64()*499, 2)
+	}
+}
+
+// lorem returns between min and max lorem ipsum words.
+func (s *synthesizer) lorem(minWords int, maxWords int) string {
+	words := make([]string, minWords+s.rand.IntN(maxWords-minWords+1))
+	for i := range words {
+		words[i] = pick(s.rand, loremWords)
+	}
+	sentence := strings.Join(words, " ")
+	return strings.ToUpper(sentence[:1]) + sentence[1:]
+}
+
+// uuid returns a random version 4 UUID.
+func (s *synthesizer) uuid() string {
+	hi, lo := s.rand.Uint64(), s.rand.Uint64()
+	hi = (hi &^ 0xf000) | 0x4000
+	lo = (lo &^ (0xc << 60)) | (0x8 << 60)
+	return fmt.Sprintf("%08x-%04x-%04x-%04x-%012x", hi>>32, (hi>>16)&0xffff, hi&0xffff, lo>>48, lo&0xffffffffffff)
+}
+
+// pick returns a random element of the slice.
+func pick[T any](r *rand.Rand, values []T) T {
+	return values[r.IntN(len(values))]
+}
+
+// round rounds a float to the given number of decimals.
+func round(value float64, decimals int) float64 {
+	factor := math.Pow(10, float64(decimals))
+	return math.Round(value*factor) / factor
+}
@@ -0,0 +1,26 @@
+package mock
+
+import (
+	"fmt"
+
+	"github.com/uforg/uforpc/urpc/internal/schema"
+)
+
+// validateInput validates the decoded input of an operation and returns
+// the same errors the generated Go server does: type errors are reported
+// as unmarshal errors and missing fields as validation errors.
+func (s *Server) validateInput(operationName string, fields []schema.FieldDefinition, input any) error {
+	if err := s.validator.CheckTypes(fields, input, ""); err != nil {
+		return Error{Message: fmt.Sprintf("failed to unmarshal %s input: %s", operationName, err)}
+	}
+
+	if err := s.validator.CheckRequired(fields, input); err != nil {
+		return Error{
+			Category: "ValidationError",
+			Code:     "MISSING_REQUIRED_FIELD",
+			Message:  err.Error(),
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,250 @@
+// Package scaffold renders the project templates used by urpc init.
+//
+// A template is a tree of files. Files ending in ".tmpl" are rendered with
+// text/template and written without the suffix, the rest are copied as
+// they are. Paths can also contain variables, e.g. "{{.GoPackage}}/doc.go".
+package scaffold
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+//go:embed all:templates
+var templatesFS embed.FS
+
+const templateSuffix = ".tmpl"
+
+// Template is a tree of files that can be rendered into a new project.
+type Template struct {
+	Name        string
+	Description string
+	// layers are rendered in order, a file of a layer replaces the file
+	// with the same path of the previous layers.
+	layers []fs.FS
+}
+
+// builtinTemplates are the templates embedded in the binary, the files of
+// the base directory are shared by all of them.
+var builtinTemplates = []struct {
+	name        string
+	description string
+}{
+	{name: "go-server", description: "Go server with net/http wiring"},
+	{name: "go-client", description: "Go client with a main.go calling the server"},
+	{name: "ts-client", description: "TypeScript client project"},
+	{name: "dart", description: "Dart package using the Dart client"},
+	{name: "fullstack", description: "Go server and a TypeScript web app"},
+}
+
+// Builtins returns the built-in templates.
+func Builtins() []Template {
+	templates := make([]Template, 0, len(builtinTemplates))
+	for _, builtin := range builtinTemplates {
+		templates = append(templates, Template{
+			Name:        builtin.name,
+			Description: builtin.description,
+			layers:      []fs.FS{subFS("base"), subFS(builtin.name)},
+		})
+	}
+	return templates
+}
+
+func subFS(dir string) fs.FS {
+	sub, err := fs.Sub(templatesFS, path.Join("templates", dir))
+	if err != nil {
+		panic(fmt.Sprintf("invalid built-in template directory %s: %s", dir, err))
+	}
+	return sub
+}
+
+// Builtin returns the built-in template with the given name.
+func Builtin(name string) (Template, bool) {
+	for _, t := range Builtins() {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return Template{}, false
+}
+
+// BuiltinNames returns the names of the built-in templates.
+func BuiltinNames() []string {
+	names := make([]string, 0, len(builtinTemplates))
+	for _, builtin := range builtinTemplates {
+		names = append(names, builtin.name)
+	}
+	return names
+}
+
+// FromDir returns a template with the files of a user provided directory.
+func FromDir(dir string) (Template, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return Template{}, fmt.Errorf("failed to read template directory: %w", err)
+	}
+	if !info.IsDir() {
+		return Template{}, fmt.Errorf("template path must be a directory, not a file: %s", dir)
+	}
+
+	return Template{
+		Name:        filepath.Base(dir),
+		Description: "Template from " + dir,
+		layers:      []fs.FS{os.DirFS(dir)},
+	}, nil
+}
+
+// Vars are the variables available to the templates, e.g. {{.ModulePath}}.
+type Vars map[string]string
+
+// DefaultVars returns the variables derived from the project name. They
+// are:
+//
+//   - Name: the project name
+//   - ModulePath: the Go module path, e.g. example.com/my-api
+//   - GoPackage: the Go package of the generated code
+//   - DartPackage: the Dart package name, e.g. my_api
+//   - NpmPackage: the npm package name, e.g. my-api
+func DefaultVars(name string) Vars {
+	words := splitWords(name)
+	if len(words) == 0 {
+		words = []string{"app"}
+	}
+
+	dartPackage := strings.Join(words, "_")
+	if unicode.IsDigit(rune(dartPackage[0])) {
+		dartPackage = "app_" + dartPackage
+	}
+
+	return Vars{
+		"Name":        name,
+		"ModulePath":  "example.com/" + strings.Join(words, "-"),
+		"GoPackage":   "uforpc",
+		"DartPackage": dartPackage,
+		"NpmPackage":  strings.Join(words, "-"),
+	}
+}
+
+// splitWords returns the lowercase alphanumeric words of the name.
+func splitWords(name string) []string {
+	return strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !('a' <= r && r <= 'z') && !('0' <= r && r <= '9')
+	})
+}
+
+// File is a rendered file of a template.
+type File struct {
+	// Path is the slash separated path relative to the project directory.
+	Path    string
+	Content []byte
+}
+
+// Render renders the files of the template sorted by path.
+func (t Template) Render(vars Vars) ([]File, error) {
+	files := map[string][]byte{}
+
+	for _, layer := range t.layers {
+		err := fs.WalkDir(layer, ".", func(filePath string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if entry.IsDir() {
+				return nil
+			}
+
+			content, err := fs.ReadFile(layer, filePath)
+			if err != nil {
+				return fmt.Errorf("failed to read %s: %w", filePath, err)
+			}
+
+			outputPath, err := renderString(filePath, filePath, vars)
+			if err != nil {
+				return err
+			}
+			if before, ok := strings.CutSuffix(outputPath, templateSuffix); ok {
+				outputPath = before
+				rendered, err := renderString(filePath, string(content), vars)
+				if err != nil {
+					return err
+				}
+				content = []byte(rendered)
+			}
+			if !filepath.IsLocal(filepath.FromSlash(outputPath)) {
+				return fmt.Errorf("%s: rendered path %q is outside of the project directory", filePath, outputPath)
+			}
+
+			files[outputPath] = content
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to render template %s: %w", t.Name, err)
+		}
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("template %s has no files", t.Name)
+	}
+
+	result := make([]File, 0, len(files))
+	for filePath, content := range files {
+		result = append(result, File{Path: filePath, Content: content})
+	}
+	slices.SortFunc(result, func(a, b File) int { return strings.Compare(a.Path, b.Path) })
+	return result, nil
+}
+
+// renderString executes the text as a template, the name is used in the
+// errors to point at the file.
+func renderString(name string, text string, vars Vars) (string, error) {
+	if !strings.Contains(text, "{{") {
+		return text, nil
+	}
+
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, map[string]string(vars)); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// WriteFiles writes the files in the directory and returns the written
+// paths. It refuses to write any of them if one of the files already
+// exists.
+func WriteFiles(dir string, files []File) ([]string, error) {
+	existing := []string{}
+	for _, file := range files {
+		if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(file.Path))); err == nil {
+			existing = append(existing, file.Path)
+		}
+	}
+	if len(existing) > 0 {
+		return nil, fmt.Errorf("refusing to overwrite existing files: %s", strings.Join(existing, ", "))
+	}
+
+	written := make([]string, 0, len(files))
+	for _, file := range files {
+		filePath := filepath.Join(dir, filepath.FromSlash(file.Path))
+		if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+			return written, fmt.Errorf("failed to create directory: %w", err)
+		}
+		if err := os.WriteFile(filePath, file.Content, 0644); err != nil {
+			return written, fmt.Errorf("failed to write %s: %w", file.Path, err)
+		}
+		written = append(written, filePath)
+	}
+	return written, nil
+}
@@ -0,0 +1,153 @@
+package scaffold
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/uforg/uforpc/urpc/internal/codegen"
+)
+
+func filePaths(files []File) []string {
+	paths := []string{}
+	for _, file := range files {
+		paths = append(paths, file.Path)
+	}
+	return paths
+}
+
+func findFile(t *testing.T, files []File, path string) string {
+	t.Helper()
+	for _, file := range files {
+		if file.Path == path {
+			return string(file.Content)
+		}
+	}
+	require.Failf(t, "file not found", "%s not found in %v", path, filePaths(files))
+	return ""
+}
+
+func TestDefaultVars(t *testing.T) {
+	require.Equal(t, Vars{
+		"Name":        "My API",
+		"ModulePath":  "example.com/my-api",
+		"GoPackage":   "uforpc",
+		"DartPackage": "my_api",
+		"NpmPackage":  "my-api",
+	}, DefaultVars("My API"))
+
+	require.Equal(t, "app_2fa", DefaultVars("2FA")["DartPackage"])
+	require.Equal(t, "example.com/app", DefaultVars("---")["ModulePath"])
+}
+
+func TestBuiltins(t *testing.T) {
+	require.Equal(t, []string{"go-server", "go-client", "ts-client", "dart", "fullstack"}, BuiltinNames())
+
+	for _, tmpl := range Builtins() {
+		t.Run(tmpl.Name, func(t *testing.T) {
+			files, err := tmpl.Render(DefaultVars("demo"))
+			require.NoError(t, err)
+
+			paths := filePaths(files)
+			require.Contains(t, paths, "schema.urpc")
+			require.Contains(t, paths, ".gitignore")
+			for _, path := range paths {
+				require.NotContains(t, path, templateSuffix)
+			}
+
+			// Every template must have a valid config
+			config := codegen.Config{}
+			require.NoError(t, config.UnmarshalAndValidate([]byte(findFile(t, files, "uforpc.toml"))))
+		})
+	}
+}
+
+func TestBuiltinGoServer(t *testing.T) {
+	tmpl, ok := Builtin("go-server")
+	require.True(t, ok)
+
+	vars := DefaultVars("demo")
+	vars["ModulePath"] = "github.com/acme/demo"
+	vars["GoPackage"] = "api"
+	files, err := tmpl.Render(vars)
+	require.NoError(t, err)
+
+	require.Equal(t, []string{".gitignore", "README.md", "go.mod", "main.go", "schema.urpc", "uforpc.toml"}, filePaths(files))
+	require.Contains(t, findFile(t, files, "go.mod"), "module github.com/acme/demo\n")
+	require.Contains(t, findFile(t, files, "main.go"), `"github.com/acme/demo/ufogen/api"`)
+	require.Contains(t, findFile(t, files, "main.go"), "server := api.NewServer[AppProps]()")
+	require.Contains(t, findFile(t, files, "uforpc.toml"), `package_name = "api"`)
+
+	_, ok = Builtin("missing")
+	require.False(t, ok)
+}
+
+func TestFromDir(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "{{.GoPackage}}"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "{{.GoPackage}}", "doc.go.tmpl"), []byte("package {{.GoPackage}}\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "raw.txt"), []byte("{{.NotRendered}}"), 0644))
+
+	tmpl, err := FromDir(dir)
+	require.NoError(t, err)
+	require.Equal(t, filepath.Base(dir), tmpl.Name)
+
+	files, err := tmpl.Render(Vars{"GoPackage": "api"})
+	require.NoError(t, err)
+	require.Equal(t, []File{
+		{Path: "api/doc.go", Content: []byte("package api\n")},
+		{Path: "raw.txt", Content: []byte("{{.NotRendered}}")},
+	}, files)
+
+	_, err = FromDir(filepath.Join(dir, "raw.txt"))
+	require.ErrorContains(t, err, "template path must be a directory")
+	_, err = FromDir(filepath.Join(dir, "missing"))
+	require.ErrorContains(t, err, "failed to read template directory")
+}
+
+func TestRenderErrors(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.txt.tmpl"), []byte("line\n{{.Missing}}\n"), 0644))
+	tmpl, err := FromDir(dir)
+	require.NoError(t, err)
+
+	_, err = tmpl.Render(Vars{})
+	require.ErrorContains(t, err, `a.txt.tmpl:2:2: executing "a.txt.tmpl" at <.Missing>: map has no entry for key "Missing"`)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.txt.tmpl"), []byte("{{.Name"), 0644))
+	_, err = tmpl.Render(Vars{})
+	require.ErrorContains(t, err, "a.txt.tmpl:1: unclosed action")
+
+	require.NoError(t, os.Remove(filepath.Join(dir, "a.txt.tmpl")))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "{{.Dir}}.txt"), nil, 0644))
+	_, err = tmpl.Render(Vars{"Dir": "../outside"})
+	require.ErrorContains(t, err, `rendered path "../outside.txt" is outside of the project directory`)
+
+	require.NoError(t, os.Remove(filepath.Join(dir, "{{.Dir}}.txt")))
+	_, err = tmpl.Render(Vars{})
+	require.ErrorContains(t, err, "has no files")
+}
+
+func TestWriteFiles(t *testing.T) {
+	dir := t.TempDir()
+	files := []File{
+		{Path: "a.txt", Content: []byte("a")},
+		{Path: "nested/b.txt", Content: []byte("b")},
+	}
+
+	written, err := WriteFiles(dir, files)
+	require.NoError(t, err)
+	require.Equal(t, []string{filepath.Join(dir, "a.txt"), filepath.Join(dir, "nested", "b.txt")}, written)
+
+	content, err := os.ReadFile(filepath.Join(dir, "nested", "b.txt"))
+	require.NoError(t, err)
+	require.Equal(t, "b", string(content))
+
+	// Nothing is written if any file exists
+	require.NoError(t, os.Remove(filepath.Join(dir, "a.txt")))
+	_, err = WriteFiles(dir, files)
+	require.EqualError(t, err, "refusing to overwrite existing files: nested/b.txt")
+	_, err = os.Stat(filepath.Join(dir, "a.txt"))
+	require.True(t, os.IsNotExist(err))
+}
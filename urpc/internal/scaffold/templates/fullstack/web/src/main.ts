@@ -0,0 +1,19 @@
+import { NewClient } from "../ufogen/client";
+
+const client = NewClient("/api/v1/urpc").build();
+
+const form = document.querySelector<HTMLFormElement>("#form")!;
+const input = document.querySelector<HTMLInputElement>("#first-field")!;
+const result = document.querySelector<HTMLPreElement>("#result")!;
+
+form.addEventListener("submit", async (event) => {
+  event.preventDefault();
+  try {
+    const output = await client.procs.barProc().execute({
+      foo: { firstField: input.value, secondField: [] },
+    });
+    result.textContent = JSON.stringify(output, null, 2);
+  } catch (error) {
+    result.textContent = String(error);
+  }
+});
@@ -0,0 +1,11 @@
+import { defineConfig } from "vite";
+
+// The requests to the API are proxied to the Go server so the browser
+// calls it from the same origin.
+export default defineConfig({
+  server: {
+    proxy: {
+      "/api": "http://localhost:8080",
+    },
+  },
+});
@@ -0,0 +1,10 @@
+import { NewClient } from "../ufogen/client";
+
+const baseURL = process.env.URPC_BASE_URL ?? "http://localhost:8080/api/v1/urpc";
+const client = NewClient(baseURL).build();
+
+const output = await client.procs.barProc().execute({
+  foo: { firstField: "hello", secondField: [1, 2, 3] },
+});
+
+console.log(`BarProc returned baz=${output.baz}`);
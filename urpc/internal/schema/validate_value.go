@@ -0,0 +1,238 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// DecodeValue decodes a JSON value keeping the numbers as json.Number, as
+// expected by the ValueValidator.
+func DecodeValue(data []byte) (any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	if decoder.More() {
+		return nil, fmt.Errorf("unexpected data after the JSON value")
+	}
+	return value, nil
+}
+
+// ValueValidator validates JSON values decoded with DecodeValue against the
+// fields of a schema, with the same rules used by the generated Go server:
+//
+//   - Null values are treated as not present.
+//   - Unknown fields are ignored.
+//   - Ints must be integral numbers and datetimes RFC 3339 strings.
+//
+// The validation is done in two steps like in the generated Go server,
+// first CheckTypes checks the types as done when unmarshaling and then
+// CheckRequired checks the required fields as done by the generated
+// validate methods.
+type ValueValidator struct {
+	types map[string]*NodeType
+}
+
+// NewValueValidator creates a ValueValidator for the types of the schema.
+func NewValueValidator(sch Schema) *ValueValidator {
+	return &ValueValidator{types: sch.GetTypeNodesMap()}
+}
+
+// Validate runs CheckTypes and CheckRequired.
+func (v *ValueValidator) Validate(fields []FieldDefinition, value any) error {
+	if err := v.CheckTypes(fields, value, ""); err != nil {
+		return err
+	}
+	return v.CheckRequired(fields, value)
+}
+
+// CheckTypes checks that every present field has a value of its type.
+//
+// The path is the dot separated location of the object, used to report
+// the failing field (e.g. "field user.address: expected an object, got
+// string"), an empty path is the root object.
+func (v *ValueValidator) CheckTypes(fields []FieldDefinition, value any, path string) error {
+	if value == nil {
+		return nil
+	}
+
+	object, ok := value.(map[string]any)
+	if !ok {
+		if path == "" {
+			return fmt.Errorf("expected an object, got %s", jsonKind(value))
+		}
+		return fmt.Errorf("field %s: expected an object, got %s", path, jsonKind(value))
+	}
+
+	for _, field := range fields {
+		fieldValue, present := object[field.Name]
+		if !present || fieldValue == nil {
+			continue
+		}
+
+		fieldPath := joinPath(path, field.Name)
+		if !field.IsArray {
+			if err := v.checkFieldType(field, fieldValue, fieldPath); err != nil {
+				return err
+			}
+			continue
+		}
+
+		items, ok := fieldValue.([]any)
+		if !ok {
+			return fmt.Errorf("field %s: expected an array, got %s", fieldPath, jsonKind(fieldValue))
+		}
+		for i, item := range items {
+			itemPath := fmt.Sprintf("%s[%d]", fieldPath, i)
+			if item == nil {
+				// Null items are decoded as zero values by the Go server
+				continue
+			}
+			if err := v.checkFieldType(field, item, itemPath); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkFieldType checks a single non null value against the type of the
+// field, ignoring if the field is an array.
+func (v *ValueValidator) checkFieldType(field FieldDefinition, value any, path string) error {
+	if field.IsInline() {
+		return v.CheckTypes(field.TypeInline.Fields, value, path)
+	}
+	if typeNode, ok := v.types[*field.TypeName]; ok {
+		return v.CheckTypes(typeNode.Fields, value, path)
+	}
+
+	if err := checkPrimitive(*field.TypeName, value); err != nil {
+		return fmt.Errorf("field %s: %w", path, err)
+	}
+	return nil
+}
+
+// checkPrimitive checks a non null value against a primitive type.
+func checkPrimitive(typeName string, value any) error {
+	switch typeName {
+	case PrimitiveTypeString.Value:
+		if _, ok := value.(string); !ok {
+			return fmt.Errorf("expected a string, got %s", jsonKind(value))
+		}
+	case PrimitiveTypeInt.Value:
+		number, ok := value.(json.Number)
+		if !ok {
+			return fmt.Errorf("expected an int, got %s", jsonKind(value))
+		}
+		if _, err := strconv.ParseInt(number.String(), 10, 64); err != nil {
+			return fmt.Errorf("expected an int, got %s", number)
+		}
+	case PrimitiveTypeFloat.Value:
+		number, ok := value.(json.Number)
+		if !ok {
+			return fmt.Errorf("expected a float, got %s", jsonKind(value))
+		}
+		if _, err := strconv.ParseFloat(number.String(), 64); err != nil {
+			return fmt.Errorf("expected a float, got %s", number)
+		}
+	case PrimitiveTypeBool.Value:
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("expected a bool, got %s", jsonKind(value))
+		}
+	case PrimitiveTypeDatetime.Value:
+		str, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("expected an RFC 3339 datetime string, got %s", jsonKind(value))
+		}
+		if _, err := time.Parse(time.RFC3339, str); err != nil {
+			return fmt.Errorf("expected an RFC 3339 datetime string, got %q", str)
+		}
+	default:
+		return fmt.Errorf("unknown type %q", typeName)
+	}
+	return nil
+}
+
+// CheckRequired checks that all the required fields are present, including
+// the ones of nested objects. The value must have been checked with
+// CheckTypes first.
+//
+// Errors are nested like in the generated Go server, e.g. "field address:
+// field street is required".
+func (v *ValueValidator) CheckRequired(fields []FieldDefinition, value any) error {
+	object, _ := value.(map[string]any)
+
+	for _, field := range fields {
+		fieldValue := object[field.Name]
+		if fieldValue == nil {
+			if !field.Optional {
+				return fmt.Errorf("field %s is required", field.Name)
+			}
+			continue
+		}
+
+		childFields := v.childFields(field)
+		if childFields == nil {
+			continue
+		}
+
+		items := []any{fieldValue}
+		if field.IsArray {
+			items, _ = fieldValue.([]any)
+		}
+		for _, item := range items {
+			if err := v.CheckRequired(childFields, item); err != nil {
+				return fmt.Errorf("field %s: %w", field.Name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// childFields returns the fields of an inline or custom type field, or nil
+// if the field has a primitive type.
+func (v *ValueValidator) childFields(field FieldDefinition) []FieldDefinition {
+	if field.IsInline() {
+		return field.TypeInline.Fields
+	}
+	if typeNode, ok := v.types[*field.TypeName]; ok {
+		return typeNode.Fields
+	}
+	return nil
+}
+
+// jsonKind returns the JSON kind of a decoded value for error messages.
+func jsonKind(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case json.Number, float64, int64:
+		return "number"
+	case bool:
+		return "bool"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+// joinPath joins a parent path and a field name with a dot.
+func joinPath(path string, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
@@ -0,0 +1,88 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValueValidator(t *testing.T) {
+	sch, err := ParseSchema(`{
+		"version": 1,
+		"nodes": [
+			{
+				"kind": "type",
+				"name": "Address",
+				"fields": [
+					{"name": "street", "typeName": "string", "isArray": false, "optional": false},
+					{"name": "zip", "typeName": "int", "isArray": false, "optional": true}
+				]
+			},
+			{
+				"kind": "proc",
+				"name": "CreateUser",
+				"input": [
+					{"name": "name", "typeName": "string", "isArray": false, "optional": false},
+					{"name": "score", "typeName": "float", "isArray": false, "optional": true},
+					{"name": "active", "typeName": "bool", "isArray": false, "optional": true},
+					{"name": "birthday", "typeName": "datetime", "isArray": false, "optional": true},
+					{"name": "addresses", "typeName": "Address", "isArray": true, "optional": true},
+					{
+						"name": "meta",
+						"typeInline": {"fields": [{"name": "source", "typeName": "string", "isArray": false, "optional": false}]},
+						"isArray": false,
+						"optional": true
+					}
+				],
+				"output": []
+			}
+		]
+	}`)
+	require.NoError(t, err)
+
+	validator := NewValueValidator(sch)
+	fields := sch.GetProcNodesMap()["CreateUser"].Input
+
+	testCases := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"Valid", `{"name":"a","score":1.5,"active":true,"birthday":"2024-01-02T03:04:05Z","addresses":[{"street":"s","zip":1}],"meta":{"source":"web"}}`, ""},
+		{"Unknown fields are ignored", `{"name":"a","other":1}`, ""},
+		{"Null is not present", `{"name":"a","score":null,"meta":null}`, ""},
+		{"Null array items are zero values", `{"name":"a","addresses":[null]}`, "field addresses: field street is required"},
+		{"Missing required field", `{}`, "field name is required"},
+		{"Null required field", `{"name":null}`, "field name is required"},
+		{"Not an object", `"a"`, "expected an object, got string"},
+		{"Invalid string", `{"name":1}`, "field name: expected a string, got number"},
+		{"Invalid int", `{"name":"a","addresses":[{"street":"s","zip":1.5}]}`, "field addresses[0].zip: expected an int, got 1.5"},
+		{"Invalid float", `{"name":"a","score":"1"}`, "field score: expected a float, got string"},
+		{"Invalid bool", `{"name":"a","active":"yes"}`, "field active: expected a bool, got string"},
+		{"Invalid datetime", `{"name":"a","birthday":"2024-01-02"}`, `field birthday: expected an RFC 3339 datetime string, got "2024-01-02"`},
+		{"Invalid array", `{"name":"a","addresses":{}}`, "field addresses: expected an array, got object"},
+		{"Invalid inline object", `{"name":"a","meta":[]}`, "field meta: expected an object, got array"},
+		{"Missing nested field", `{"name":"a","addresses":[{"street":"s"},{}]}`, "field addresses: field street is required"},
+		{"Missing inline field", `{"name":"a","meta":{}}`, "field meta: field source is required"},
+		{"Type errors come first", `{"score":"1"}`, "field score: expected a float, got string"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			value, err := DecodeValue([]byte(tc.input))
+			require.NoError(t, err)
+
+			err = validator.Validate(fields, value)
+			if tc.expected == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.EqualError(t, err, tc.expected)
+		})
+	}
+
+	t.Run("Trailing data", func(t *testing.T) {
+		_, err := DecodeValue([]byte(`{} {}`))
+		require.Error(t, err)
+	})
+}
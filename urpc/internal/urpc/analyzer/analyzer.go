@@ -1,6 +1,11 @@
 // Package analyzer provides semantic analysis for URPC schemas.
-// This implementation prioritizes simplicity and maintainability over performance
-// by performing a full analysis without caching results between calls.
+//
+// Analyzer performs a full analysis on every call without caching results,
+// it is the simplest option for one-shot tools like the CLI.
+//
+// CachedAnalyzer keeps the results of previous analyses and only repeats the
+// work affected by a change, it is meant for long running processes like
+// the language server where the same schema is analyzed on every keystroke.
 package analyzer
 
 import (
@@ -30,52 +35,28 @@ func NewAnalyzer(fileProvider FileProvider) (*Analyzer, error) {
 // It consists of two phases:
 //   - Resolution phase: Parses the entry point file and resolves all external docstrings.
 //   - Semantic analysis phase: Performs semantic analysis on the resolved schema.
+//
+// Syntax errors do not stop the analysis, the parser recovers at declaration
+// boundaries so a partial schema is returned together with every parse,
+// docstring and semantic diagnostic found in one pass. The returned schema
+// is only nil when the entry point file cannot be read.
 func (a *Analyzer) Analyze(entryPointFilePath string) (*ast.Schema, []Diagnostic, error) {
 	fileContent, _, err := a.fileProvider.GetFileAndHash("", entryPointFilePath)
 	if err != nil {
-		diag := Diagnostic{
-			Positions: Positions{
-				Pos:    ast.Position{Filename: entryPointFilePath, Line: 1, Column: 1, Offset: 0},
-				EndPos: ast.Position{Filename: entryPointFilePath, Line: 1, Column: 1, Offset: 0},
-			},
-			Message: fmt.Sprintf("failed to read entry point file: %s", err.Error()),
-		}
-		return nil, []Diagnostic{diag}, err
+		return nil, []Diagnostic{entryPointReadDiagnostic(entryPointFilePath, err)}, err
 	}
 
-	astSchema, err := parser.ParserInstance.ParseString(entryPointFilePath, fileContent)
-	if err != nil {
-		diag := Diagnostic{
-			Positions: Positions{
-				Pos:    ast.Position{Filename: entryPointFilePath, Line: 1, Column: 1, Offset: 0},
-				EndPos: ast.Position{Filename: entryPointFilePath, Line: 1, Column: 1, Offset: 0},
-			},
-			Message: fmt.Sprintf("error parsing file: %v", err),
-		}
-
-		// Assert parser error if possible
-		if parserErr, ok := err.(parser.Error); ok {
-			diag = Diagnostic{
-				Positions: Positions{
-					Pos:    parserErr.Position(),
-					EndPos: parserErr.Position(),
-				},
-				Message: parserErr.Message(),
-			}
-		}
-
-		return nil, []Diagnostic{diag}, err
-	}
+	astSchema, diagnostics := parseWithDiagnostics(entryPointFilePath, fileContent)
 
 	astSchema, dsResolverDiagnostics, _ := a.docstringResolver.resolve(astSchema)
-	if len(dsResolverDiagnostics) > 0 {
-		return astSchema, dsResolverDiagnostics, dsResolverDiagnostics[0]
-	}
+	diagnostics = append(diagnostics, dsResolverDiagnostics...)
 
 	semanalyzer := newSemanalyzer(astSchema)
 	semanalyzerDiagnostics, _ := semanalyzer.analyze()
-	if len(semanalyzerDiagnostics) > 0 {
-		return astSchema, semanalyzerDiagnostics, semanalyzerDiagnostics[0]
+	diagnostics = append(diagnostics, semanalyzerDiagnostics...)
+
+	if len(diagnostics) > 0 {
+		return astSchema, diagnostics, diagnostics[0]
 	}
 
 	return astSchema, nil, nil
@@ -91,3 +72,37 @@ func (a *Analyzer) AnalyzeAstSchema(astSchema *ast.Schema) ([]Diagnostic, error)
 
 	return nil, nil
 }
+
+// entryPointReadDiagnostic creates the diagnostic reported when the entry
+// point file cannot be read.
+func entryPointReadDiagnostic(entryPointFilePath string, err error) Diagnostic {
+	return Diagnostic{
+		Positions: Positions{
+			Pos:    ast.Position{Filename: entryPointFilePath, Line: 1, Column: 1, Offset: 0},
+			EndPos: ast.Position{Filename: entryPointFilePath, Line: 1, Column: 1, Offset: 0},
+		},
+		Message: fmt.Sprintf("failed to read entry point file: %s", err.Error()),
+	}
+}
+
+// parseWithDiagnostics parses the file content recovering from syntax errors
+// and converts the syntax errors to diagnostics.
+func parseWithDiagnostics(filePath string, fileContent string) (*ast.Schema, []Diagnostic) {
+	astSchema, parserErrs := parser.ParseWithRecovery(filePath, fileContent)
+	return astSchema, parserDiagnostics(parserErrs)
+}
+
+// parserDiagnostics converts syntax errors to diagnostics.
+func parserDiagnostics(parserErrs []parser.Error) []Diagnostic {
+	diagnostics := []Diagnostic{}
+	for _, parserErr := range parserErrs {
+		diagnostics = append(diagnostics, Diagnostic{
+			Positions: Positions{
+				Pos:    parserErr.Position(),
+				EndPos: parserErr.Position(),
+			},
+			Message: parserErr.Message(),
+		})
+	}
+	return diagnostics
+}
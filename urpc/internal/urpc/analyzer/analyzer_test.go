@@ -0,0 +1,77 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyzer_Analyze(t *testing.T) {
+	t.Run("Valid schema", func(t *testing.T) {
+		provider := &mockFileProvider{
+			files: map[string]string{
+				"/main.urpc": `
+					version 1
+
+					type User {
+						id: string
+					}
+				`,
+			},
+		}
+
+		an, err := NewAnalyzer(provider)
+		require.NoError(t, err)
+
+		astSchema, diagnostics, err := an.Analyze("/main.urpc")
+		require.NoError(t, err)
+		require.Empty(t, diagnostics)
+		require.Len(t, astSchema.GetTypes(), 1)
+	})
+
+	t.Run("Missing entry point", func(t *testing.T) {
+		an, err := NewAnalyzer(&mockFileProvider{files: map[string]string{}})
+		require.NoError(t, err)
+
+		astSchema, diagnostics, err := an.Analyze("/main.urpc")
+		require.Error(t, err)
+		require.Nil(t, astSchema)
+		require.Len(t, diagnostics, 1)
+	})
+
+	t.Run("Reports all diagnostics in one pass", func(t *testing.T) {
+		provider := &mockFileProvider{
+			files: map[string]string{
+				"/main.urpc": "version 1\n" +
+					"type Broken {\n" +
+					"  field: \n" +
+					"}\n" +
+					"\"\"\" docs/missing.md \"\"\"\n" +
+					"type User {\n" +
+					"  address: Address\n" +
+					"}\n" +
+					"proc GetUser {\n" +
+					"  input {\n" +
+					"    id: \n" +
+					"  }\n" +
+					"}\n",
+			},
+		}
+
+		an, err := NewAnalyzer(provider)
+		require.NoError(t, err)
+
+		astSchema, diagnostics, err := an.Analyze("/main.urpc")
+		require.Error(t, err)
+		require.NotNil(t, astSchema)
+		require.Len(t, astSchema.GetTypes(), 1)
+		require.Equal(t, "User", astSchema.GetTypes()[0].Name)
+
+		require.Len(t, diagnostics, 4)
+		require.Equal(t, 4, diagnostics[0].Pos.Line)
+		require.Equal(t, 12, diagnostics[1].Pos.Line)
+		require.Contains(t, diagnostics[2].Message, "external markdown file not found")
+		require.Contains(t, diagnostics[3].Message, `type "Address" referenced at type "User" is not declared`)
+		require.Equal(t, err, diagnostics[0])
+	})
+}
@@ -0,0 +1,209 @@
+package analyzer
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/uforg/uforpc/urpc/internal/urpc/ast"
+	"github.com/uforg/uforpc/urpc/internal/urpc/parser"
+)
+
+// CachedAnalyzer manages the analysis process for URPC schemas caching the
+// results between calls.
+//
+// For every entry point it keeps the content hash and the result of the last
+// analysis, and for every declaration segment of the schema (see
+// parser.SplitSegments) its parsed nodes with the external docstrings already
+// resolved, together with the hashes of the external docstring files, so:
+//   - If neither the entry point nor the external docstrings changed, the
+//     previous result is returned without parsing or analyzing anything.
+//   - If something changed, only the segments whose content, position or
+//     external docstrings changed are parsed and resolved again, the rest are
+//     reused. Edits that add or remove lines move the segments after them, so
+//     those segments are parsed again too.
+//
+// The semantic analysis always runs on the whole schema because its checks
+// relate declarations with each other, it is cheap compared to parsing.
+//
+// It is safe for concurrent use. The returned schemas are shared between
+// calls and must not be modified.
+type CachedAnalyzer struct {
+	fileProvider FileProvider
+	mu           sync.Mutex
+	entries      map[string]*analysisCacheEntry
+}
+
+// analysisCacheEntry is the cached result of analyzing an entry point.
+type analysisCacheEntry struct {
+	hash        string
+	segments    map[parser.Segment]*segmentCacheEntry
+	schema      *ast.Schema
+	diagnostics []Diagnostic
+}
+
+// segmentCacheEntry is a parsed segment of an entry point with its external
+// docstrings resolved.
+type segmentCacheEntry struct {
+	parsed       *parser.ParsedSegment
+	diagnostics  []Diagnostic
+	dependencies []fileDependency
+}
+
+// fileDependency is a file read while resolving external docstrings.
+type fileDependency struct {
+	relativeTo string
+	path       string
+	hash       string
+	found      bool
+}
+
+// NewCachedAnalyzer creates a new CachedAnalyzer instance.
+func NewCachedAnalyzer(fileProvider FileProvider) (*CachedAnalyzer, error) {
+	return &CachedAnalyzer{
+		fileProvider: fileProvider,
+		entries:      map[string]*analysisCacheEntry{},
+	}, nil
+}
+
+// Analyze performs semantic analysis on a URPC schema starting from the given
+// entry point, see Analyzer.Analyze for details. The results are the same as
+// the ones returned by Analyzer.Analyze.
+func (a *CachedAnalyzer) Analyze(entryPointFilePath string) (*ast.Schema, []Diagnostic, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	fileContent, hash, err := a.fileProvider.GetFileAndHash("", entryPointFilePath)
+	if err != nil {
+		delete(a.entries, entryPointFilePath)
+		return nil, []Diagnostic{entryPointReadDiagnostic(entryPointFilePath, err)}, err
+	}
+
+	previous := a.entries[entryPointFilePath]
+	if previous != nil && previous.hash == hash && a.segmentsUnchanged(previous.segments) {
+		return previous.result()
+	}
+
+	entry := &analysisCacheEntry{
+		hash:     hash,
+		segments: map[parser.Segment]*segmentCacheEntry{},
+	}
+
+	segments := parser.SplitSegments(fileContent)
+	parsedSegments := make([]*parser.ParsedSegment, 0, len(segments))
+	dsResolverDiagnostics := []Diagnostic{}
+	for _, segment := range segments {
+		var cached *segmentCacheEntry
+		if previous != nil {
+			cached = previous.segments[segment]
+		}
+		if cached == nil || !a.dependenciesUnchanged(cached.dependencies) {
+			cached = a.analyzeSegment(entryPointFilePath, segment)
+		}
+
+		entry.segments[segment] = cached
+		parsedSegments = append(parsedSegments, cached.parsed)
+		dsResolverDiagnostics = append(dsResolverDiagnostics, cached.diagnostics...)
+	}
+
+	astSchema, parserErrs := parser.JoinSegments(parsedSegments)
+	diagnostics := append(parserDiagnostics(parserErrs), dsResolverDiagnostics...)
+
+	semanalyzer := newSemanalyzer(astSchema)
+	semanalyzerDiagnostics, _ := semanalyzer.analyze()
+	diagnostics = append(diagnostics, semanalyzerDiagnostics...)
+
+	entry.schema = astSchema
+	entry.diagnostics = diagnostics
+	a.entries[entryPointFilePath] = entry
+	return entry.result()
+}
+
+// analyzeSegment parses a segment and resolves its external docstrings,
+// recording the files read to resolve them.
+func (a *CachedAnalyzer) analyzeSegment(entryPointFilePath string, segment parser.Segment) *segmentCacheEntry {
+	parsed := parser.ParseSegment(entryPointFilePath, segment)
+
+	recorder := &recordingFileProvider{fileProvider: a.fileProvider}
+	diagnostics := newDocstringResolver(recorder).resolveChildren(parsed.Children, nil)
+
+	return &segmentCacheEntry{
+		parsed:       parsed,
+		diagnostics:  diagnostics,
+		dependencies: recorder.dependencies,
+	}
+}
+
+// AnalyzeAstSchema performs the semantic analysis on an already parsed schema,
+// see Analyzer.AnalyzeAstSchema for details. It is not cached.
+func (a *CachedAnalyzer) AnalyzeAstSchema(astSchema *ast.Schema) ([]Diagnostic, error) {
+	semanalyzer := newSemanalyzer(astSchema)
+
+	semanalyzerDiagnostics, _ := semanalyzer.analyze()
+	if len(semanalyzerDiagnostics) > 0 {
+		return semanalyzerDiagnostics, semanalyzerDiagnostics[0]
+	}
+
+	return nil, nil
+}
+
+// segmentsUnchanged returns true if the external docstrings of all the given
+// segments are unchanged.
+func (a *CachedAnalyzer) segmentsUnchanged(segments map[parser.Segment]*segmentCacheEntry) bool {
+	for _, segment := range segments {
+		if !a.dependenciesUnchanged(segment.dependencies) {
+			return false
+		}
+	}
+	return true
+}
+
+// dependenciesUnchanged returns true if all the given files still have the
+// same hash they had when they were read.
+func (a *CachedAnalyzer) dependenciesUnchanged(dependencies []fileDependency) bool {
+	for _, dep := range dependencies {
+		_, hash, err := a.fileProvider.GetFileAndHash(dep.relativeTo, dep.path)
+		if (err == nil) != dep.found || hash != dep.hash {
+			return false
+		}
+	}
+	return true
+}
+
+// result returns the cached analysis result in the same format as Analyze.
+func (e *analysisCacheEntry) result() (*ast.Schema, []Diagnostic, error) {
+	if len(e.diagnostics) > 0 {
+		diagnostics := slices.Clone(e.diagnostics)
+		return e.schema, diagnostics, diagnostics[0]
+	}
+	return e.schema, nil, nil
+}
+
+// recordingFileProvider is a FileProvider that records every file requested
+// through it, it is used to know which files a schema depends on.
+type recordingFileProvider struct {
+	fileProvider FileProvider
+	dependencies []fileDependency
+	seen         map[[2]string]bool
+}
+
+// GetFileAndHash implements the FileProvider interface.
+func (r *recordingFileProvider) GetFileAndHash(relativeTo string, path string) (string, string, error) {
+	content, hash, err := r.fileProvider.GetFileAndHash(relativeTo, path)
+
+	key := [2]string{relativeTo, path}
+	if r.seen[key] {
+		return content, hash, err
+	}
+	if r.seen == nil {
+		r.seen = map[[2]string]bool{}
+	}
+	r.seen[key] = true
+
+	r.dependencies = append(r.dependencies, fileDependency{
+		relativeTo: relativeTo,
+		path:       path,
+		hash:       hash,
+		found:      err == nil,
+	})
+	return content, hash, err
+}
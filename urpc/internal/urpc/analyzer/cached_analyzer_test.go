@@ -0,0 +1,275 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/uforg/uforpc/urpc/internal/urpc/ast"
+	"github.com/uforg/uforpc/urpc/internal/urpc/docstore"
+	"github.com/uforg/uforpc/urpc/internal/urpc/parser"
+)
+
+// parsedTypes returns the parsed segment of every type of the last analysis
+// of an entry, by type name.
+func parsedTypes(an *CachedAnalyzer, path string) map[string]*parser.ParsedSegment {
+	parsed := map[string]*parser.ParsedSegment{}
+	for _, segment := range an.entries[path].segments {
+		for _, child := range segment.parsed.Children {
+			if child.Type != nil {
+				parsed[child.Type.Name] = segment.parsed
+			}
+		}
+	}
+	return parsed
+}
+
+func TestCachedAnalyzer_Analyze(t *testing.T) {
+	t.Run("Matches the uncached analyzer across edits", func(t *testing.T) {
+		provider := &mockFileProvider{
+			files: map[string]string{
+				"docs/user.md": "# User",
+			},
+		}
+
+		cached, err := NewCachedAnalyzer(provider)
+		require.NoError(t, err)
+		uncached, err := NewAnalyzer(provider)
+		require.NoError(t, err)
+
+		versions := []string{
+			"version 1\n\"\"\" docs/user.md \"\"\"\ntype User {\n  id: string\n}\n",
+			"version 1\n\"\"\" docs/user.md \"\"\"\ntype User {\n  id: string\n  address: Address\n}\n",
+			"version 1\n\"\"\" docs/user.md \"\"\"\ntype User {\n  id: string\n  address: \n}\n",
+			"version 1\n\"\"\" docs/missing.md \"\"\"\ntype User {\n  id: string\n  id: int\n}\nproc P { input {} input {} }\n",
+			"version 1\ntype User {\n  id: string\n}\n",
+			"version 1\ntype User {\n  id: string\n\n\"\"\" docs/user.md \"\"\"\ntype Post {\n  author: User\n}\nproc P { input { x: } }\n",
+			"version 1\n\"\"\" docs/missing.md \"\"\"\ntype User {\n  id: string\n}\n\"\"\" docs/user.md \"\"\"\ntype Post {\n  author: User\n}\n",
+		}
+
+		for i, version := range versions {
+			provider.files["/main.urpc"] = version
+
+			expectedSchema, expectedDiags, expectedErr := uncached.Analyze("/main.urpc")
+			gotSchema, gotDiags, gotErr := cached.Analyze("/main.urpc")
+
+			require.Equal(t, expectedErr, gotErr, "version %d", i)
+			require.Equal(t, expectedDiags, gotDiags, "version %d", i)
+			require.Equal(t, expectedSchema, gotSchema, "version %d", i)
+		}
+	})
+
+	t.Run("Reuses the result when nothing changed", func(t *testing.T) {
+		provider := &mockFileProvider{
+			files: map[string]string{
+				"/main.urpc": "version 1\ntype User {\n  address: Address\n}\n",
+			},
+		}
+
+		an, err := NewCachedAnalyzer(provider)
+		require.NoError(t, err)
+
+		first, firstDiags, _ := an.Analyze("/main.urpc")
+		second, secondDiags, _ := an.Analyze("/main.urpc")
+
+		require.Same(t, first, second)
+		require.Equal(t, firstDiags, secondDiags)
+		require.Len(t, secondDiags, 1)
+	})
+
+	t.Run("Detects changes in external docstrings", func(t *testing.T) {
+		provider := &mockFileProvider{
+			files: map[string]string{
+				"/main.urpc":   "version 1\n\"\"\" docs/user.md \"\"\"\ntype User {\n  id: string\n}\n",
+				"docs/user.md": "# User",
+			},
+		}
+
+		an, err := NewCachedAnalyzer(provider)
+		require.NoError(t, err)
+
+		astSchema, _, err := an.Analyze("/main.urpc")
+		require.NoError(t, err)
+		require.Equal(t, "# User", astSchema.GetTypes()[0].Docstring.Value)
+
+		provider.files["docs/user.md"] = "# Updated user"
+		astSchema, _, err = an.Analyze("/main.urpc")
+		require.NoError(t, err)
+		require.Equal(t, "# Updated user", astSchema.GetTypes()[0].Docstring.Value)
+
+		delete(provider.files, "docs/user.md")
+		_, diagnostics, err := an.Analyze("/main.urpc")
+		require.Error(t, err)
+		require.Len(t, diagnostics, 1)
+		require.Contains(t, diagnostics[0].Message, "external markdown file not found")
+	})
+
+	t.Run("Only parses the changed declarations", func(t *testing.T) {
+		provider := &mockFileProvider{
+			files: map[string]string{
+				"/main.urpc":   "version 1\n\"\"\" A user \"\"\"\ntype User {\n  id: string\n}\n\"\"\" docs/post.md \"\"\"\ntype Post {\n  author: User\n}\n",
+				"docs/post.md": "# Post",
+			},
+		}
+
+		an, err := NewCachedAnalyzer(provider)
+		require.NoError(t, err)
+
+		_, _, err = an.Analyze("/main.urpc")
+		require.NoError(t, err)
+		before := parsedTypes(an, "/main.urpc")
+
+		// Editing a docstring in place only parses its declaration again
+		provider.files["/main.urpc"] = strings.Replace(provider.files["/main.urpc"], "A user", "B user", 1)
+		astSchema, _, err := an.Analyze("/main.urpc")
+		require.NoError(t, err)
+		after := parsedTypes(an, "/main.urpc")
+		require.NotSame(t, before["User"], after["User"])
+		require.Same(t, before["Post"], after["Post"])
+		require.Equal(t, "# Post", astSchema.GetTypesMap()["Post"].Docstring.Value)
+
+		// Renaming a type still reports the broken references elsewhere
+		provider.files["/main.urpc"] = strings.Replace(provider.files["/main.urpc"], "type User", "type Users", 1)
+		_, diagnostics, err := an.Analyze("/main.urpc")
+		require.Error(t, err)
+		require.Len(t, diagnostics, 1)
+		require.Contains(t, diagnostics[0].Message, `type "User" referenced at type "Post" is not declared`)
+		require.Same(t, before["Post"], parsedTypes(an, "/main.urpc")["Post"])
+
+		// Adding a line moves the declarations after it
+		provider.files["/main.urpc"] = strings.Replace(provider.files["/main.urpc"], "type Users", "\ntype User", 1)
+		_, _, err = an.Analyze("/main.urpc")
+		require.NoError(t, err)
+		require.NotSame(t, before["Post"], parsedTypes(an, "/main.urpc")["Post"])
+	})
+
+	t.Run("Missing entry point", func(t *testing.T) {
+		an, err := NewCachedAnalyzer(&mockFileProvider{files: map[string]string{}})
+		require.NoError(t, err)
+
+		astSchema, diagnostics, err := an.Analyze("/main.urpc")
+		require.Error(t, err)
+		require.Nil(t, astSchema)
+		require.Len(t, diagnostics, 1)
+	})
+}
+
+// generateBenchmarkSchema generates a valid schema with roughly the given
+// number of lines, it contains a docstring marker "KEYSTROKE" and a field
+// named "keystroke" in the middle of the schema to simulate edits.
+func generateBenchmarkSchema(lines int) string {
+	var sb strings.Builder
+	sb.WriteString("version 1\n\n")
+
+	// Each type and proc pair generates 21 lines
+	count := lines / 21
+	for i := range count {
+		fieldName := "name"
+		docstring := fmt.Sprintf("Type number %d", i)
+		if i == count/2 {
+			fieldName = "keystroke"
+			docstring = "KEYSTROKE"
+		}
+
+		fmt.Fprintf(&sb, "\"\"\" %s \"\"\"\n", docstring)
+		fmt.Fprintf(&sb, "type Type%d {\n", i)
+		sb.WriteString("  id: string\n")
+		fmt.Fprintf(&sb, "  %s: string\n", fieldName)
+		sb.WriteString("  tags: string[]\n")
+		sb.WriteString("  meta: {\n")
+		sb.WriteString("    createdAt: datetime\n")
+		sb.WriteString("  }\n")
+		if i > 0 {
+			fmt.Fprintf(&sb, "  previous?: Type%d\n", i-1)
+		}
+		sb.WriteString("}\n\n")
+
+		fmt.Fprintf(&sb, "proc GetType%d {\n", i)
+		sb.WriteString("  input {\n")
+		sb.WriteString("    id: string\n")
+		sb.WriteString("  }\n")
+		sb.WriteString("  output {\n")
+		fmt.Fprintf(&sb, "    item: Type%d\n", i)
+		sb.WriteString("  }\n")
+		sb.WriteString("}\n\n")
+	}
+
+	return sb.String()
+}
+
+// schemaAnalyzer is the interface shared by Analyzer and CachedAnalyzer.
+type schemaAnalyzer interface {
+	Analyze(entryPointFilePath string) (*ast.Schema, []Diagnostic, error)
+}
+
+// benchmarkKeystroke measures the time from an edit of an open document to
+// its diagnostics being available, like the language server does on every
+// change. The edit function returns the schema after the n-th keystroke.
+func benchmarkKeystroke(b *testing.B, newAnalyzer func(FileProvider) schemaAnalyzer, edit func(n int) string) {
+	b.Helper()
+
+	const uri = "file:///bench/schema.urpc"
+	store := docstore.NewDocstore()
+	an := newAnalyzer(store)
+
+	require.NoError(b, store.OpenInMem(uri, edit(0)))
+	_, _, err := an.Analyze(uri)
+	require.NoError(b, err)
+
+	n := 0
+	for b.Loop() {
+		n++
+		if err := store.ChangeInMem(uri, edit(n)); err != nil {
+			b.Fatal(err)
+		}
+		if _, _, err := an.Analyze(uri); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// keystrokeEdits returns the edits used by the benchmarks on the given schema.
+func keystrokeEdits(schema string) map[string]func(n int) string {
+	letters := "abcdefghijklmnopqrstuvwxyz"
+	return map[string]func(n int) string{
+		"Docstring": func(n int) string {
+			return strings.Replace(schema, "KEYSTROKE", "KEYSTROKE"+letters[:n%len(letters)], 1)
+		},
+		"FieldName": func(n int) string {
+			return strings.Replace(schema, "keystroke:", "keystroke"+letters[:n%len(letters)]+":", 1)
+		},
+		"NewLine": func(n int) string {
+			return strings.Replace(schema, "\"\"\" KEYSTROKE", strings.Repeat("\n", n%2)+"\"\"\" KEYSTROKE", 1)
+		},
+		"NoChange": func(n int) string {
+			return schema
+		},
+	}
+}
+
+func BenchmarkAnalyzerKeystroke(b *testing.B) {
+	schema := generateBenchmarkSchema(5000)
+
+	for name, edit := range keystrokeEdits(schema) {
+		b.Run(name, func(b *testing.B) {
+			benchmarkKeystroke(b, func(fp FileProvider) schemaAnalyzer {
+				an, _ := NewAnalyzer(fp)
+				return an
+			}, edit)
+		})
+	}
+}
+
+func BenchmarkCachedAnalyzerKeystroke(b *testing.B) {
+	schema := generateBenchmarkSchema(5000)
+
+	for name, edit := range keystrokeEdits(schema) {
+		b.Run(name, func(b *testing.B) {
+			benchmarkKeystroke(b, func(fp FileProvider) schemaAnalyzer {
+				an, _ := NewCachedAnalyzer(fp)
+				return an
+			}, edit)
+		})
+	}
+}
@@ -29,77 +29,67 @@ func newDocstringResolver(fileProvider FileProvider) *docstringResolver {
 //   - A list of diagnostics that occurred during the analysis.
 //   - The first diagnostic converted to Error interface if any.
 func (r *docstringResolver) resolve(astSchema *ast.Schema) (*ast.Schema, []Diagnostic, error) {
-	diagnostics := []Diagnostic{}
+	diagnostics := r.resolveChildren(astSchema.Children, []Diagnostic{})
 
-	for _, docstring := range astSchema.GetDocstrings() {
-		diagnostics = r.resolveExternalDocstring(docstring, diagnostics)
+	// Return the first diagnostic as error if any
+	if len(diagnostics) > 0 {
+		return astSchema, diagnostics, diagnostics[0]
 	}
+	return astSchema, nil, nil
+}
 
-	for _, typeDecl := range astSchema.GetTypes() {
-		if typeDecl.Docstring != nil {
-			diagnostics = r.resolveExternalDocstring(typeDecl.Docstring, diagnostics)
-		}
-
-		for _, field := range typeDecl.GetFlattenedFields() {
-			if field.Docstring != nil {
-				diagnostics = r.resolveExternalDocstring(field.Docstring, diagnostics)
+// resolveChildren resolves the external docstrings of the given top level
+// nodes in order, appending the diagnostics to the given ones.
+func (r *docstringResolver) resolveChildren(children []*ast.SchemaChild, diagnostics []Diagnostic) []Diagnostic {
+	for _, child := range children {
+		switch child.Kind() {
+		case ast.SchemaChildKindDocstring:
+			diagnostics = r.resolveExternalDocstring(child.Docstring, diagnostics)
+
+		case ast.SchemaChildKindType:
+			if child.Type.Docstring != nil {
+				diagnostics = r.resolveExternalDocstring(child.Type.Docstring, diagnostics)
 			}
-		}
-	}
-
-	for _, proc := range astSchema.GetProcs() {
-		if proc.Docstring != nil {
-			diagnostics = r.resolveExternalDocstring(proc.Docstring, diagnostics)
-		}
+			diagnostics = r.resolveFields(child.Type.GetFlattenedFields(), diagnostics)
 
-		for _, child := range proc.Children {
-			if child.Input != nil {
-				for _, field := range child.Input.GetFlattenedFields() {
-					if field.Docstring != nil {
-						diagnostics = r.resolveExternalDocstring(field.Docstring, diagnostics)
-					}
-				}
+		case ast.SchemaChildKindProc:
+			if child.Proc.Docstring != nil {
+				diagnostics = r.resolveExternalDocstring(child.Proc.Docstring, diagnostics)
 			}
+			diagnostics = r.resolveProcOrStreamChildren(child.Proc.Children, diagnostics)
 
-			if child.Output != nil {
-				for _, field := range child.Output.GetFlattenedFields() {
-					if field.Docstring != nil {
-						diagnostics = r.resolveExternalDocstring(field.Docstring, diagnostics)
-					}
-				}
+		case ast.SchemaChildKindStream:
+			if child.Stream.Docstring != nil {
+				diagnostics = r.resolveExternalDocstring(child.Stream.Docstring, diagnostics)
 			}
+			diagnostics = r.resolveProcOrStreamChildren(child.Stream.Children, diagnostics)
 		}
 	}
+	return diagnostics
+}
 
-	for _, stream := range astSchema.GetStreams() {
-		if stream.Docstring != nil {
-			diagnostics = r.resolveExternalDocstring(stream.Docstring, diagnostics)
+// resolveProcOrStreamChildren resolves the external docstrings of the fields
+// of the input and output blocks of a proc or stream.
+func (r *docstringResolver) resolveProcOrStreamChildren(children []*ast.ProcOrStreamDeclChild, diagnostics []Diagnostic) []Diagnostic {
+	for _, child := range children {
+		if child.Input != nil {
+			diagnostics = r.resolveFields(child.Input.GetFlattenedFields(), diagnostics)
 		}
-
-		for _, child := range stream.Children {
-			if child.Input != nil {
-				for _, field := range child.Input.GetFlattenedFields() {
-					if field.Docstring != nil {
-						diagnostics = r.resolveExternalDocstring(field.Docstring, diagnostics)
-					}
-				}
-			}
-
-			if child.Output != nil {
-				for _, field := range child.Output.GetFlattenedFields() {
-					if field.Docstring != nil {
-						diagnostics = r.resolveExternalDocstring(field.Docstring, diagnostics)
-					}
-				}
-			}
+		if child.Output != nil {
+			diagnostics = r.resolveFields(child.Output.GetFlattenedFields(), diagnostics)
 		}
 	}
+	return diagnostics
+}
 
-	// Return the first diagnostic as error if any
-	if len(diagnostics) > 0 {
-		return astSchema, diagnostics, diagnostics[0]
+// resolveFields resolves the external docstrings of the given fields.
+func (r *docstringResolver) resolveFields(fields []*ast.Field, diagnostics []Diagnostic) []Diagnostic {
+	for _, field := range fields {
+		if field.Docstring != nil {
+			diagnostics = r.resolveExternalDocstring(field.Docstring, diagnostics)
+		}
 	}
-	return astSchema, nil, nil
+	return diagnostics
 }
 
 // resolveExternalDocstring is the logic to resolve a single external docstring
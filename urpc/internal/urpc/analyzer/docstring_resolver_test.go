@@ -1,6 +1,8 @@
 package analyzer
 
 import (
+	"crypto/sha256"
+	"fmt"
 	"os"
 	"testing"
 
@@ -14,16 +16,20 @@ type mockFileProvider struct {
 }
 
 func (m *mockFileProvider) GetFileAndHash(relativeTo string, path string) (string, string, error) {
+	hash := func(content string) string {
+		return fmt.Sprintf("%x", sha256.Sum256([]byte(content)))
+	}
+
 	// Try with the path as is
 	if content, ok := m.files[path]; ok {
-		return content, "mock-hash", nil
+		return content, hash(content), nil
 	}
 
 	// If relativeTo is provided, try with the path relative to it
 	if relativeTo != "" {
 		relativePath := relativeTo + "/" + path
 		if content, ok := m.files[relativePath]; ok {
-			return content, "mock-hash", nil
+			return content, hash(content), nil
 		}
 	}
 
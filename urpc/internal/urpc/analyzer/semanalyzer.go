@@ -123,18 +123,14 @@ func (a *semanalyzer) validateUniqueResourceNames() {
 
 // validateCustomTypeReferences validates that all referenced custom types exist.
 func (a *semanalyzer) validateCustomTypeReferences() {
+	declaredTypes := a.astSchema.GetTypesMap()
 	isValidType := func(typeName string) bool {
 		if ast.IsPrimitiveType(typeName) {
 			return true
 		}
 
-		for _, typeDecl := range a.astSchema.GetTypes() {
-			if typeDecl.Name == typeName {
-				return true
-			}
-		}
-
-		return false
+		_, isDeclared := declaredTypes[typeName]
+		return isDeclared
 	}
 
 	var checkFieldTypeReferences func([]*ast.Field, string)
@@ -248,8 +244,9 @@ func (a *semanalyzer) validateTypeFieldUniqueness() {
 // validateTypeCircularDependencies validates that there are no circular dependencies between types.
 func (a *semanalyzer) validateTypeCircularDependencies() {
 	types := a.astSchema.GetTypesMap()
+	acyclic := map[string]bool{}
 	for name, typeDecl := range types {
-		if err := validateTypeCircularDependenciesCheckType(name, types, []string{}); err != nil {
+		if err := validateTypeCircularDependenciesCheckType(name, types, []string{}, acyclic); err != nil {
 			a.diagnostics = append(a.diagnostics, Diagnostic{
 				Positions: Positions{
 					Pos:    typeDecl.Pos,
@@ -262,7 +259,16 @@ func (a *semanalyzer) validateTypeCircularDependencies() {
 }
 
 // validateTypeCircularDependenciesCheckType checks if a type has a circular dependency.
-func validateTypeCircularDependenciesCheckType(name string, types map[string]*ast.TypeDecl, stack []string) error {
+//
+// The acyclic map holds the types already known to be free of cycles, so
+// they are not checked again.
+func validateTypeCircularDependenciesCheckType(
+	name string, types map[string]*ast.TypeDecl, stack []string, acyclic map[string]bool,
+) error {
+	if acyclic[name] {
+		return nil
+	}
+
 	// Is it already in the stack (cycle)?
 	if slices.Contains(stack, name) {
 		return fmt.Errorf("circular dependency detected between types: %s", strings.Join(stack, " -> "))
@@ -281,21 +287,24 @@ func validateTypeCircularDependenciesCheckType(name string, types map[string]*as
 
 	// Check every field in the type (including nested types)
 	for _, field := range extractFields(typ.Children) {
-		if err := validateTypeCircularDependenciesCheckField(field.Type, types, stack); err != nil {
+		if err := validateTypeCircularDependenciesCheckField(field.Type, types, stack, acyclic); err != nil {
 			return err
 		}
 	}
 
+	acyclic[name] = true
 	return nil
 }
 
 // validateTypeCircularDependenciesCheckField checks if a field has a circular dependency.
-func validateTypeCircularDependenciesCheckField(fieldType ast.FieldType, types map[string]*ast.TypeDecl, stack []string) error {
+func validateTypeCircularDependenciesCheckField(
+	fieldType ast.FieldType, types map[string]*ast.TypeDecl, stack []string, acyclic map[string]bool,
+) error {
 	// If it's a custom named type, check it
 	if fieldType.Base.Named != nil {
 		typeName := *fieldType.Base.Named
 		if !ast.IsPrimitiveType(typeName) {
-			return validateTypeCircularDependenciesCheckType(typeName, types, stack)
+			return validateTypeCircularDependenciesCheckType(typeName, types, stack, acyclic)
 		}
 	}
 
@@ -303,7 +312,7 @@ func validateTypeCircularDependenciesCheckField(fieldType ast.FieldType, types m
 	if fieldType.Base.Object != nil {
 		objectFields := extractFields(fieldType.Base.Object.Children)
 		for _, field := range objectFields {
-			if err := validateTypeCircularDependenciesCheckField(field.Type, types, stack); err != nil {
+			if err := validateTypeCircularDependenciesCheckField(field.Type, types, stack, acyclic); err != nil {
 				return err
 			}
 		}
@@ -28,10 +28,34 @@ type Positions struct {
 	EndPos ast.Position
 }
 
+// Severity represents how serious a diagnostic is. The zero value is
+// SeverityError so every diagnostic is an error unless stated otherwise.
+type Severity int
+
+const (
+	SeverityError Severity = iota
+	SeverityWarning
+	SeverityInfo
+)
+
+// String implements fmt.Stringer interface.
+func (s Severity) String() string {
+	switch s {
+	case SeverityWarning:
+		return "warning"
+	case SeverityInfo:
+		return "info"
+	default:
+		return "error"
+	}
+}
+
 // Diagnostic represents an error or warning found during analysis.
 type Diagnostic struct {
-	Positions        // The range of the source code where the diagnostic occurred.
-	Message   string // The diagnostic message.
+	Positions          // The range of the source code where the diagnostic occurred.
+	Message   string   // The diagnostic message.
+	Severity  Severity // The diagnostic severity, defaults to SeverityError.
+	Code      string   // Optional code that identifies the diagnostic source (e.g. a lint rule name).
 }
 
 // String implements fmt.Stringer interface.
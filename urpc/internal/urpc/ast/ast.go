@@ -6,14 +6,14 @@ import (
 	"github.com/uforg/uforpc/urpc/internal/util/strutil"
 )
 
-// This AST is used for parsing the URPC schema and it uses the
-// participle library for parsing.
+// This AST is produced by the hand-written parser in the parser package.
+// The struct tags describe the same grammar in participle syntax and are
+// kept as its reference definition.
 //
 // It includes embedded Positions fields for each node to track the
 // position of the node in the original source code, it is used
 // later in the analyzer and LSP to give useful error messages
-// and auto-completion. Those positions are automatically populated
-// by the participle library.
+// and auto-completion. Those positions are populated by the parser.
 
 // PrimitiveType represents a primitive type.
 type PrimitiveType = string
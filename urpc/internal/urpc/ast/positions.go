@@ -2,12 +2,13 @@ package ast
 
 import plexer "github.com/alecthomas/participle/v2/lexer"
 
-// Any node in the AST containing a field Pos lexer.Position
-// will be automatically populated from the nearest matching token.
+// The Pos field of a node is populated with the position of its first
+// token.
 //
-// Any node in the AST containing a field EndPos lexer.Position
-// will be automatically populated from the token at the end of the node.
+// The EndPos field of a node is populated with the position of the token
+// right after its last token, including whitespace and newlines.
 //
+// These are the same semantics used by the participle library:
 // https://github.com/alecthomas/participle/blob/master/README.md#error-reporting
 
 // Position is an alias for the participle.Position type.
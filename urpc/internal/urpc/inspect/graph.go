@@ -0,0 +1,265 @@
+// Package inspect analyzes the dependencies and the shape of a resolved
+// URPC schema, it is used to understand the impact of changes in the
+// schema.
+package inspect
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/uforg/uforpc/urpc/internal/urpc/ast"
+)
+
+// Kinds of the nodes of the dependency graph.
+const (
+	KindType   = "type"
+	KindProc   = "proc"
+	KindStream = "stream"
+)
+
+// Node is a declaration of the schema.
+type Node struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+}
+
+// String returns the node as "kind Name".
+func (n Node) String() string {
+	return n.Kind + " " + n.Name
+}
+
+// Edge is a dependency of a declaration on a type.
+type Edge struct {
+	From Node
+	To   string
+	// Fields are the paths of the fields of the declaration that reference
+	// the type, e.g. "output.user" or "addresses[].country".
+	Fields []string
+}
+
+// Graph is the dependency graph of the declarations of a schema, with the
+// nodes and edges in declaration order.
+type Graph struct {
+	Nodes []Node
+	Edges []Edge
+}
+
+// NewGraph builds the dependency graph of an analyzed schema.
+func NewGraph(sch *ast.Schema) *Graph {
+	g := &Graph{}
+
+	addEdges := func(from Node, children []*ast.FieldOrComment, prefix string) {
+		walkFields(children, prefix, func(field *ast.Field, path string) {
+			named := field.Type.Base.Named
+			if named == nil || ast.IsPrimitiveType(*named) {
+				return
+			}
+			index := slices.IndexFunc(g.Edges, func(e Edge) bool { return e.From == from && e.To == *named })
+			if index < 0 {
+				g.Edges = append(g.Edges, Edge{From: from, To: *named})
+				index = len(g.Edges) - 1
+			}
+			g.Edges[index].Fields = append(g.Edges[index].Fields, path)
+		})
+	}
+
+	for _, child := range sch.Children {
+		switch {
+		case child.Type != nil:
+			node := Node{Kind: KindType, Name: child.Type.Name}
+			g.Nodes = append(g.Nodes, node)
+			addEdges(node, child.Type.Children, "")
+		case child.Proc != nil:
+			node := Node{Kind: KindProc, Name: child.Proc.Name}
+			g.Nodes = append(g.Nodes, node)
+			addOperationEdges(child.Proc.Children, node, addEdges)
+		case child.Stream != nil:
+			node := Node{Kind: KindStream, Name: child.Stream.Name}
+			g.Nodes = append(g.Nodes, node)
+			addOperationEdges(child.Stream.Children, node, addEdges)
+		}
+	}
+
+	return g
+}
+
+func addOperationEdges(children []*ast.ProcOrStreamDeclChild, node Node, addEdges func(Node, []*ast.FieldOrComment, string)) {
+	for _, child := range children {
+		if child.Input != nil {
+			addEdges(node, child.Input.Children, "input.")
+		}
+		if child.Output != nil {
+			addEdges(node, child.Output.Children, "output.")
+		}
+	}
+}
+
+// walkFields calls fn for every field, including the fields of inline
+// objects, with its path.
+func walkFields(children []*ast.FieldOrComment, prefix string, fn func(field *ast.Field, path string)) {
+	for _, child := range children {
+		if child.Field == nil {
+			continue
+		}
+		path := prefix + child.Field.Name
+		if child.Field.Type.IsArray {
+			path += "[]"
+		}
+		fn(child.Field, path)
+		if child.Field.Type.Base.Object != nil {
+			walkFields(child.Field.Type.Base.Object.Children, path+".", fn)
+		}
+	}
+}
+
+// HasType returns true if the schema declares the type.
+func (g *Graph) HasType(name string) bool {
+	return slices.Contains(g.Nodes, Node{Kind: KindType, Name: name})
+}
+
+// Step is a step of the path from a declaration to a type it depends on.
+type Step struct {
+	Node Node `json:"node"`
+	// Field is the path of the field of the node that references the next
+	// step.
+	Field string `json:"field"`
+}
+
+// Usage is a declaration that depends on a type directly or through other
+// types.
+type Usage struct {
+	Node Node `json:"node"`
+	// Depth is 1 for direct usages and the number of steps to reach the
+	// type for transitive usages.
+	Depth int `json:"depth"`
+	// Path is a shortest chain of references from the declaration to the
+	// type.
+	Path []Step `json:"path"`
+}
+
+// PathString returns the path as "GetUser.output.user -> User.address ->
+// Address".
+func (u Usage) PathString(typeName string) string {
+	parts := make([]string, 0, len(u.Path)+1)
+	for _, step := range u.Path {
+		parts = append(parts, step.Node.Name+"."+step.Field)
+	}
+	return strings.Join(append(parts, typeName), " -> ")
+}
+
+// Usages returns the declarations that depend directly or transitively on
+// the type, sorted by depth and declaration order.
+func (g *Graph) Usages(typeName string) ([]Usage, error) {
+	if !g.HasType(typeName) {
+		return nil, fmt.Errorf("type %q is not declared in the schema", typeName)
+	}
+
+	target := Node{Kind: KindType, Name: typeName}
+	// next contains the step from each visited node towards the type
+	next := map[Node]Step{}
+	depth := map[Node]int{target: 0}
+	queue := []Node{target}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if current.Kind != KindType {
+			continue
+		}
+		for _, edge := range g.Edges {
+			if edge.To != current.Name {
+				continue
+			}
+			if _, visited := depth[edge.From]; visited {
+				continue
+			}
+			depth[edge.From] = depth[current] + 1
+			next[edge.From] = Step{Node: current, Field: edge.Fields[0]}
+			queue = append(queue, edge.From)
+		}
+	}
+
+	usages := []Usage{}
+	for _, node := range g.Nodes {
+		d, ok := depth[node]
+		if !ok || node == target {
+			continue
+		}
+
+		path := []Step{}
+		for current := node; current != target; current = next[current].Node {
+			path = append(path, Step{Node: current, Field: next[current].Field})
+		}
+		usages = append(usages, Usage{Node: node, Depth: d, Path: path})
+	}
+
+	slices.SortStableFunc(usages, func(a, b Usage) int { return a.Depth - b.Depth })
+	return usages, nil
+}
+
+// DOT returns the graph in the Graphviz DOT language.
+func (g *Graph) DOT() string {
+	b := &strings.Builder{}
+	b.WriteString("digraph urpc {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [fontname=\"Helvetica\"];\n")
+	b.WriteString("  edge [fontname=\"Helvetica\", fontsize=10];\n")
+
+	if len(g.Nodes) > 0 {
+		b.WriteString("\n")
+	}
+	for _, node := range g.Nodes {
+		shape := "box"
+		switch node.Kind {
+		case KindProc:
+			shape = "ellipse"
+		case KindStream:
+			shape = "hexagon"
+		}
+		fmt.Fprintf(b, "  %q [label=%q, shape=%s];\n", dotID(node), node.Name, shape)
+	}
+
+	if len(g.Edges) > 0 {
+		b.WriteString("\n")
+	}
+	for _, edge := range g.Edges {
+		to := Node{Kind: KindType, Name: edge.To}
+		fmt.Fprintf(b, "  %q -> %q [label=%q];\n", dotID(edge.From), dotID(to), strings.Join(edge.Fields, "\n"))
+	}
+
+	b.WriteString("}\n")
+	return b.String()
+}
+
+func dotID(node Node) string {
+	return node.Kind + ":" + node.Name
+}
+
+// Mermaid returns the graph as a Mermaid flowchart.
+func (g *Graph) Mermaid() string {
+	b := &strings.Builder{}
+	b.WriteString("flowchart LR\n")
+
+	for _, node := range g.Nodes {
+		switch node.Kind {
+		case KindProc:
+			fmt.Fprintf(b, "  %s([\"%s\"])\n", mermaidID(node), node.Name)
+		case KindStream:
+			fmt.Fprintf(b, "  %s{{\"%s\"}}\n", mermaidID(node), node.Name)
+		default:
+			fmt.Fprintf(b, "  %s[\"%s\"]\n", mermaidID(node), node.Name)
+		}
+	}
+
+	for _, edge := range g.Edges {
+		to := Node{Kind: KindType, Name: edge.To}
+		fmt.Fprintf(b, "  %s -->|\"%s\"| %s\n", mermaidID(edge.From), strings.Join(edge.Fields, ", "), mermaidID(to))
+	}
+
+	return b.String()
+}
+
+func mermaidID(node Node) string {
+	return node.Kind + "_" + node.Name
+}
@@ -0,0 +1,152 @@
+package inspect
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/uforg/uforpc/urpc/internal/urpc/ast"
+	"github.com/uforg/uforpc/urpc/internal/urpc/parser"
+)
+
+// parseSchema parses the given input string into an ast.Schema.
+// this is a test helper function.
+func parseSchema(t *testing.T, input string) *ast.Schema {
+	t.Helper()
+	schema, err := parser.ParserInstance.ParseString("test.urpc", input)
+	require.NoError(t, err)
+	return schema
+}
+
+const testSchema = `
+	version 1
+
+	type Country {
+		code: string
+	}
+
+	type Address {
+		street: string
+		country: Country
+	}
+
+	type User {
+		id: string
+		address?: Address
+		previous: Address[]
+		meta: {
+			origin: Country
+		}[]
+	}
+
+	proc GetUser {
+		input {
+			id: string
+		}
+		output {
+			user: User
+		}
+	}
+
+	stream WatchCountries {
+		output {
+			country: Country
+		}
+	}
+`
+
+func TestNewGraph(t *testing.T) {
+	g := NewGraph(parseSchema(t, testSchema))
+
+	require.Equal(t, []Node{
+		{Kind: KindType, Name: "Country"},
+		{Kind: KindType, Name: "Address"},
+		{Kind: KindType, Name: "User"},
+		{Kind: KindProc, Name: "GetUser"},
+		{Kind: KindStream, Name: "WatchCountries"},
+	}, g.Nodes)
+
+	require.Equal(t, []Edge{
+		{From: Node{Kind: KindType, Name: "Address"}, To: "Country", Fields: []string{"country"}},
+		{From: Node{Kind: KindType, Name: "User"}, To: "Address", Fields: []string{"address", "previous[]"}},
+		{From: Node{Kind: KindType, Name: "User"}, To: "Country", Fields: []string{"meta[].origin"}},
+		{From: Node{Kind: KindProc, Name: "GetUser"}, To: "User", Fields: []string{"output.user"}},
+		{From: Node{Kind: KindStream, Name: "WatchCountries"}, To: "Country", Fields: []string{"output.country"}},
+	}, g.Edges)
+}
+
+func TestUsages(t *testing.T) {
+	g := NewGraph(parseSchema(t, testSchema))
+
+	usages, err := g.Usages("Address")
+	require.NoError(t, err)
+	require.Len(t, usages, 2)
+	require.Equal(t, Node{Kind: KindType, Name: "User"}, usages[0].Node)
+	require.Equal(t, 1, usages[0].Depth)
+	require.Equal(t, "User.address -> Address", usages[0].PathString("Address"))
+	require.Equal(t, Node{Kind: KindProc, Name: "GetUser"}, usages[1].Node)
+	require.Equal(t, 2, usages[1].Depth)
+	require.Equal(t, "GetUser.output.user -> User.address -> Address", usages[1].PathString("Address"))
+
+	usages, err = g.Usages("Country")
+	require.NoError(t, err)
+	names := []string{}
+	for _, usage := range usages {
+		names = append(names, usage.Node.String())
+	}
+	// User references Country directly through an inline object
+	require.Equal(t, []string{"type Address", "type User", "stream WatchCountries", "proc GetUser"}, names)
+
+	usages, err = g.Usages("User")
+	require.NoError(t, err)
+	require.Len(t, usages, 1)
+
+	_, err = g.Usages("Missing")
+	require.EqualError(t, err, `type "Missing" is not declared in the schema`)
+}
+
+func TestDOT(t *testing.T) {
+	g := NewGraph(parseSchema(t, `
+		version 1
+		type Item {
+			id: string
+		}
+		proc GetItem {
+			output {
+				item: Item
+				related: Item[]
+			}
+		}
+	`))
+
+	require.Equal(t, `digraph urpc {
+  rankdir=LR;
+  node [fontname="Helvetica"];
+  edge [fontname="Helvetica", fontsize=10];
+
+  "type:Item" [label="Item", shape=box];
+  "proc:GetItem" [label="GetItem", shape=ellipse];
+
+  "proc:GetItem" -> "type:Item" [label="output.item\noutput.related[]"];
+}
+`, g.DOT())
+}
+
+func TestMermaid(t *testing.T) {
+	g := NewGraph(parseSchema(t, `
+		version 1
+		type Item {
+			id: string
+		}
+		stream Items {
+			output {
+				item: Item
+			}
+		}
+	`))
+
+	require.Equal(t, `flowchart LR
+  type_Item["Item"]
+  stream_Items{{"Items"}}
+  stream_Items -->|"output.item"| type_Item
+`, g.Mermaid())
+}
@@ -0,0 +1,175 @@
+package inspect
+
+import (
+	"slices"
+
+	"github.com/uforg/uforpc/urpc/internal/urpc/ast"
+)
+
+// Stats are the counts and sizes of the declarations of a schema.
+type Stats struct {
+	Types   int `json:"types"`
+	Procs   int `json:"procs"`
+	Streams int `json:"streams"`
+	// Fields counts the fields of types, inputs and outputs including the
+	// fields of inline objects.
+	Fields         int `json:"fields"`
+	OptionalFields int `json:"optionalFields"`
+	ArrayFields    int `json:"arrayFields"`
+	InlineObjects  int `json:"inlineObjects"`
+	Deprecated     int `json:"deprecated"`
+	// MaxDepth is the deepest nesting of objects, following inline objects
+	// and custom types, of all the payloads.
+	MaxDepth Payload `json:"maxDepth"`
+	// Payloads contains the types, inputs and outputs sorted by size.
+	Payloads []Payload `json:"payloads"`
+}
+
+// Payload is the shape of a type or of the input or output of a procedure
+// or stream.
+type Payload struct {
+	// Name is the declaration, e.g. "type User" or "proc GetUser output".
+	Name string `json:"name"`
+	// Fields counts all the fields of the payload, following inline
+	// objects and custom types.
+	Fields int `json:"fields"`
+	// Depth is the nesting depth, 1 for payloads without nested objects.
+	Depth int `json:"depth"`
+	// DeepestPath is the path of a field at the maximum depth.
+	DeepestPath string `json:"deepestPath"`
+}
+
+// ComputeStats computes the stats of an analyzed schema.
+func ComputeStats(sch *ast.Schema) Stats {
+	c := &statsCalculator{types: sch.GetTypesMap(), shapes: map[string]shape{}}
+	stats := Stats{Payloads: []Payload{}}
+
+	countFields := func(children []*ast.FieldOrComment) {
+		walkFields(children, "", func(field *ast.Field, _ string) {
+			stats.Fields++
+			if field.Optional {
+				stats.OptionalFields++
+			}
+			if field.Type.IsArray {
+				stats.ArrayFields++
+			}
+			if field.Type.Base.Object != nil {
+				stats.InlineObjects++
+			}
+		})
+	}
+
+	addPayload := func(name string, children []*ast.FieldOrComment) {
+		countFields(children)
+		s := c.shapeOf(children)
+		stats.Payloads = append(stats.Payloads, Payload{Name: name, Fields: s.fields, Depth: s.depth, DeepestPath: s.deepestPath})
+	}
+
+	addOperation := func(node Node, children []*ast.ProcOrStreamDeclChild) {
+		for _, child := range children {
+			if child.Input != nil {
+				addPayload(node.String()+" input", child.Input.Children)
+			}
+			if child.Output != nil {
+				addPayload(node.String()+" output", child.Output.Children)
+			}
+		}
+	}
+
+	for _, child := range sch.Children {
+		switch {
+		case child.Type != nil:
+			stats.Types++
+			if child.Type.Deprecated != nil {
+				stats.Deprecated++
+			}
+			addPayload(Node{Kind: KindType, Name: child.Type.Name}.String(), child.Type.Children)
+		case child.Proc != nil:
+			stats.Procs++
+			if child.Proc.Deprecated != nil {
+				stats.Deprecated++
+			}
+			addOperation(Node{Kind: KindProc, Name: child.Proc.Name}, child.Proc.Children)
+		case child.Stream != nil:
+			stats.Streams++
+			if child.Stream.Deprecated != nil {
+				stats.Deprecated++
+			}
+			addOperation(Node{Kind: KindStream, Name: child.Stream.Name}, child.Stream.Children)
+		}
+	}
+
+	for _, payload := range stats.Payloads {
+		if payload.Depth > stats.MaxDepth.Depth {
+			stats.MaxDepth = payload
+		}
+	}
+	slices.SortStableFunc(stats.Payloads, func(a, b Payload) int { return b.Fields - a.Fields })
+
+	return stats
+}
+
+// shape is the expanded size of a list of fields.
+type shape struct {
+	fields      int
+	depth       int
+	deepestPath string
+}
+
+type statsCalculator struct {
+	types map[string]*ast.TypeDecl
+	// shapes caches the shapes of the custom types
+	shapes map[string]shape
+}
+
+// shapeOf returns the shape of the fields following inline objects and
+// custom types, the analyzer guarantees there are no circular references.
+func (c *statsCalculator) shapeOf(children []*ast.FieldOrComment) shape {
+	result := shape{depth: 1}
+	for _, child := range children {
+		if child.Field == nil {
+			continue
+		}
+		field := child.Field
+		result.fields++
+		if result.deepestPath == "" {
+			result.deepestPath = field.Name
+		}
+
+		nested, ok := c.nestedShape(field)
+		if !ok {
+			continue
+		}
+		result.fields += nested.fields
+		if nested.depth+1 > result.depth {
+			result.depth = nested.depth + 1
+			result.deepestPath = field.Name
+			if field.Type.IsArray {
+				result.deepestPath += "[]"
+			}
+			result.deepestPath += "." + nested.deepestPath
+		}
+	}
+	return result
+}
+
+func (c *statsCalculator) nestedShape(field *ast.Field) (shape, bool) {
+	if field.Type.Base.Object != nil {
+		return c.shapeOf(field.Type.Base.Object.Children), true
+	}
+
+	named := field.Type.Base.Named
+	if named == nil || ast.IsPrimitiveType(*named) {
+		return shape{}, false
+	}
+	if cached, ok := c.shapes[*named]; ok {
+		return cached, true
+	}
+	typeDecl, ok := c.types[*named]
+	if !ok {
+		return shape{}, false
+	}
+	result := c.shapeOf(typeDecl.Children)
+	c.shapes[*named] = result
+	return result, true
+}
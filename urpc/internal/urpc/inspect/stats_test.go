@@ -0,0 +1,58 @@
+package inspect
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputeStats(t *testing.T) {
+	stats := ComputeStats(parseSchema(t, testSchema))
+
+	require.Equal(t, 3, stats.Types)
+	require.Equal(t, 1, stats.Procs)
+	require.Equal(t, 1, stats.Streams)
+	// 1 + 2 + 5 type fields, 2 proc fields and 1 stream field
+	require.Equal(t, 11, stats.Fields)
+	require.Equal(t, 1, stats.OptionalFields)
+	require.Equal(t, 2, stats.ArrayFields)
+	require.Equal(t, 1, stats.InlineObjects)
+	require.Equal(t, 0, stats.Deprecated)
+
+	require.Equal(t, Payload{
+		Name:        "proc GetUser output",
+		Fields:      13,
+		Depth:       4,
+		DeepestPath: "user.address.country.code",
+	}, stats.MaxDepth)
+
+	names := []string{}
+	for _, payload := range stats.Payloads {
+		names = append(names, payload.Name)
+	}
+	require.Equal(t, []string{
+		"proc GetUser output",
+		"type User",
+		"type Address",
+		"stream WatchCountries output",
+		"type Country",
+		"proc GetUser input",
+	}, names)
+	require.Equal(t, 12, stats.Payloads[1].Fields)
+}
+
+func TestComputeStatsEmpty(t *testing.T) {
+	stats := ComputeStats(parseSchema(t, `
+		version 1
+
+		deprecated type Empty {}
+
+		proc Ping {}
+	`))
+
+	require.Equal(t, 1, stats.Types)
+	require.Equal(t, 1, stats.Procs)
+	require.Equal(t, 1, stats.Deprecated)
+	require.Equal(t, Payload{Name: "type Empty", Depth: 1}, stats.MaxDepth)
+	require.Len(t, stats.Payloads, 1)
+}
@@ -1,6 +1,8 @@
 package lexer
 
 import (
+	"strings"
+
 	"github.com/uforg/uforpc/urpc/internal/urpc/token"
 )
 
@@ -28,7 +30,7 @@ func NewLexer(fileName, input string) *Lexer {
 	l.maxIndex = len(l.input) - 1
 
 	l.currentIndex = 0
-	if l.maxIndex <= 0 {
+	if l.maxIndex < 0 {
 		l.currentIndexIsEOF = true
 	} else {
 		l.currentIndexIsEOF = false
@@ -78,9 +80,9 @@ func (l *Lexer) peekChar(depth int) (rune, bool) {
 
 // readWhitespace reads whitespace characters from the current index to the next non-whitespace character.
 func (l *Lexer) readWhitespace() string {
-	var literal string
+	var literal strings.Builder
 	for isWhitespace(l.currentChar) {
-		literal += string(l.currentChar)
+		literal.WriteRune(l.currentChar)
 
 		nextChar, eofReached := l.peekChar(1)
 		if eofReached || !isWhitespace(nextChar) {
@@ -89,14 +91,14 @@ func (l *Lexer) readWhitespace() string {
 
 		l.readNextChar()
 	}
-	return literal
+	return literal.String()
 }
 
 // readIdentifier reads an identifier from the current index to the next non-letter/non-number character.
 func (l *Lexer) readIdentifier() string {
-	var ident string
+	var ident strings.Builder
 	for isLetter(l.currentChar) || isNumber(l.currentChar) {
-		ident += string(l.currentChar)
+		ident.WriteRune(l.currentChar)
 
 		nextChar, eofReached := l.peekChar(1)
 		if eofReached || (!isLetter(nextChar) && !isNumber(nextChar)) {
@@ -105,14 +107,14 @@ func (l *Lexer) readIdentifier() string {
 
 		l.readNextChar()
 	}
-	return ident
+	return ident.String()
 }
 
 // readNumber reads a number from the current index to the next non-digit character.
 func (l *Lexer) readNumber() string {
-	var num string
+	var num strings.Builder
 	for isNumber(l.currentChar) {
-		num += string(l.currentChar)
+		num.WriteRune(l.currentChar)
 
 		nextChar, eofReached := l.peekChar(1)
 		if eofReached || !isNumber(nextChar) {
@@ -121,7 +123,7 @@ func (l *Lexer) readNumber() string {
 
 		l.readNextChar()
 	}
-	return num
+	return num.String()
 }
 
 // readString reads a string from the current index to the next double quote.
@@ -140,7 +142,7 @@ func (l *Lexer) readString() (string, bool) {
 	// Skip the opening quote
 	l.readNextChar()
 
-	var str string
+	var str strings.Builder
 	for !l.currentIndexIsEOF && l.currentChar != '"' {
 		if l.currentChar == '\\' {
 			nextChar, eofReached := l.peekChar(1)
@@ -148,10 +150,10 @@ func (l *Lexer) readString() (string, bool) {
 				break
 			}
 			if nextChar == '"' {
-				str += `"`
+				str.WriteRune('"')
 			}
 			if nextChar == '\\' {
-				str += `\`
+				str.WriteRune('\\')
 			}
 
 			l.readNextChar() // Skip the backslash
@@ -159,15 +161,15 @@ func (l *Lexer) readString() (string, bool) {
 			continue
 		}
 
-		str += string(l.currentChar)
+		str.WriteRune(l.currentChar)
 		l.readNextChar()
 	}
 
 	if l.currentIndexIsEOF {
-		return str, true
+		return str.String(), true
 	}
 
-	return str, false
+	return str.String(), false
 }
 
 // readDocstring reads a docstring from the current index to the next triple quote.
@@ -193,7 +195,7 @@ func (l *Lexer) readDocstring() (string, bool) {
 	l.readNextChar()
 	l.readNextChar()
 
-	var docstring string
+	var docstring strings.Builder
 	for {
 		isEOF := func() bool {
 			if l.currentIndexIsEOF {
@@ -237,19 +239,19 @@ func (l *Lexer) readDocstring() (string, bool) {
 			break
 		}
 
-		docstring += string(l.currentChar)
+		docstring.WriteRune(l.currentChar)
 		l.readNextChar()
 	}
 
 	if l.currentIndexIsEOF {
-		return docstring, true
+		return docstring.String(), true
 	}
 
 	// Skip the 2 remaining closing quotes
 	l.readNextChar()
 	l.readNextChar()
 
-	return docstring, false
+	return docstring.String(), false
 }
 
 // readComment reads a comment from the current index to the next newline or EOF.
@@ -294,9 +296,9 @@ func (l *Lexer) readComment() (string, bool) {
 	// Read first character after the opening comment characters
 	l.readNextChar()
 
-	var comment string
+	var comment strings.Builder
 	for {
-		comment += string(l.currentChar)
+		comment.WriteRune(l.currentChar)
 
 		if isSingleLine {
 			nextChar, eofReached := l.peekChar(1)
@@ -321,7 +323,7 @@ func (l *Lexer) readComment() (string, bool) {
 		l.readNextChar()
 	}
 
-	return comment, isMultiline
+	return comment.String(), isMultiline
 }
 
 // NextToken returns the next token from the input.
@@ -44,6 +44,14 @@ func TestLexer(t *testing.T) {
 		require.Equal(t, tests, tokens)
 	})
 
+	t.Run("TestLexerSingleCharacter", func(t *testing.T) {
+		tokens := NewLexer("test.urpc", "}").ReadTokens()
+		require.Equal(t, []token.Token{
+			{Type: token.RBrace, Literal: "}", FileName: "test.urpc", LineStart: 1, ColumnStart: 1, LineEnd: 1, ColumnEnd: 1},
+			{Type: token.Eof, Literal: "", FileName: "test.urpc", LineStart: 1, ColumnStart: 2, LineEnd: 1, ColumnEnd: 2},
+		}, tokens)
+	})
+
 	t.Run("TestLexerNewLines", func(t *testing.T) {
 		input := ",:\n(){\n}\n[]@?\n"
 
@@ -0,0 +1,96 @@
+package linter
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/uforg/uforpc/urpc/internal/urpc/analyzer"
+)
+
+// Level is the configured level of a lint rule.
+type Level string
+
+// Level constants.
+const (
+	LevelOff     Level = "off"
+	LevelInfo    Level = "info"
+	LevelWarning Level = "warning"
+	LevelError   Level = "error"
+)
+
+// Levels is the list of all valid rule levels.
+var Levels = []Level{LevelOff, LevelInfo, LevelWarning, LevelError}
+
+// severity returns the analyzer severity for the level, the second
+// return value is false when the level is off.
+func (l Level) severity() (analyzer.Severity, bool) {
+	switch l {
+	case LevelInfo:
+		return analyzer.SeverityInfo, true
+	case LevelWarning:
+		return analyzer.SeverityWarning, true
+	case LevelError:
+		return analyzer.SeverityError, true
+	default:
+		return 0, false
+	}
+}
+
+// Config is the configuration for the schema linter.
+//
+// It is loaded from the [lint] table of the uforpc.toml file:
+//
+//	[lint]
+//	disabled = false
+//
+//	[lint.rules]
+//	field-camel-case = "error"
+//	missing-docstring = "off"
+type Config struct {
+	// Disabled turns off all lint rules.
+	Disabled bool `toml:"disabled"`
+	// Rules overrides the default level of individual rules by name.
+	Rules map[string]Level `toml:"rules"`
+}
+
+// Validate checks that all configured rules exist and have a valid level.
+func (c Config) Validate() error {
+	names := make([]string, 0, len(c.Rules))
+	for name := range c.Rules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if _, ok := getRule(name); !ok {
+			return fmt.Errorf("unknown lint rule %q", name)
+		}
+
+		level := c.Rules[name]
+		if !slices.Contains(Levels, level) {
+			levels := make([]string, len(Levels))
+			for i, l := range Levels {
+				levels[i] = string(l)
+			}
+			return fmt.Errorf(
+				"invalid level %q for lint rule %q, must be one of: %s",
+				level, name, strings.Join(levels, ", "),
+			)
+		}
+	}
+
+	return nil
+}
+
+// levelFor returns the effective level of the given rule.
+func (c Config) levelFor(r rule) Level {
+	if c.Disabled {
+		return LevelOff
+	}
+	if level, ok := c.Rules[r.Name]; ok {
+		return level
+	}
+	return r.DefaultLevel
+}
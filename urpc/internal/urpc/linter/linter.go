@@ -0,0 +1,244 @@
+// Package linter provides configurable style checks for URPC schemas.
+//
+// Unlike the analyzer, which reports hard errors that make a schema
+// invalid, the linter reports opinionated findings that can be toggled
+// individually and reported with different severities.
+//
+// Findings can be suppressed with a comment on the same line or on the
+// line before the reported node:
+//
+//	// urpc-lint-ignore
+//	// urpc-lint-ignore field-camel-case, unused-type
+package linter
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/uforg/uforpc/urpc/internal/urpc/analyzer"
+	"github.com/uforg/uforpc/urpc/internal/urpc/ast"
+)
+
+// IgnoreDirective is the comment prefix used to suppress lint findings.
+const IgnoreDirective = "urpc-lint-ignore"
+
+// Lint runs all enabled rules against an already analyzed schema.
+//
+// The schema should be free of analyzer errors, otherwise some rules
+// may report misleading findings.
+//
+// Returns the list of diagnostics sorted by position.
+func Lint(astSchema *ast.Schema, config Config) []analyzer.Diagnostic {
+	if astSchema == nil {
+		return nil
+	}
+
+	ctx := newLintContext(astSchema)
+	for _, r := range rules {
+		severity, enabled := config.levelFor(r).severity()
+		if !enabled {
+			continue
+		}
+
+		ctx.currentRule = r.Name
+		ctx.currentSeverity = severity
+		r.check(ctx)
+	}
+
+	sort.SliceStable(ctx.diagnostics, func(i, j int) bool {
+		a, b := ctx.diagnostics[i].Pos, ctx.diagnostics[j].Pos
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+
+	return ctx.diagnostics
+}
+
+// ownerKind is the kind of declaration that owns a field.
+type ownerKind int
+
+const (
+	ownerKindType ownerKind = iota
+	ownerKindProcInput
+	ownerKindProcOutput
+	ownerKindStreamInput
+	ownerKindStreamOutput
+)
+
+// label returns the name used for the owner kind in messages.
+func (k ownerKind) label() string {
+	switch k {
+	case ownerKindProcInput, ownerKindProcOutput:
+		return "procedure"
+	case ownerKindStreamInput, ownerKindStreamOutput:
+		return "stream"
+	default:
+		return "type"
+	}
+}
+
+// lintField is a field together with information about its owner.
+type lintField struct {
+	field           *ast.Field
+	ownerKind       ownerKind
+	ownerName       string
+	ownerDeprecated bool
+}
+
+// customTypeName returns the name of the referenced custom type if any.
+func (f lintField) customTypeName() (string, bool) {
+	named := f.field.Type.Base.Named
+	if named == nil || ast.IsPrimitiveType(*named) {
+		return "", false
+	}
+	return *named, true
+}
+
+// lintContext holds the precomputed data shared by all rules.
+type lintContext struct {
+	schema      *ast.Schema
+	fields      []lintField
+	ignores     map[int][]string
+	diagnostics []analyzer.Diagnostic
+
+	currentRule     string
+	currentSeverity analyzer.Severity
+}
+
+// newLintContext collects the fields and ignore directives of the schema.
+func newLintContext(astSchema *ast.Schema) *lintContext {
+	ctx := &lintContext{
+		schema:      astSchema,
+		fields:      []lintField{},
+		ignores:     map[int][]string{},
+		diagnostics: []analyzer.Diagnostic{},
+	}
+
+	for _, comment := range astSchema.GetComments() {
+		ctx.addComment(comment)
+	}
+
+	for _, typeDecl := range astSchema.GetTypes() {
+		ctx.addFields(typeDecl.Children, ownerKindType, typeDecl.Name, typeDecl.Deprecated != nil)
+	}
+
+	for _, proc := range astSchema.GetProcs() {
+		for _, child := range proc.Children {
+			ctx.addComment(child.Comment)
+			if child.Input != nil {
+				ctx.addFields(child.Input.Children, ownerKindProcInput, proc.Name, proc.Deprecated != nil)
+			}
+			if child.Output != nil {
+				ctx.addFields(child.Output.Children, ownerKindProcOutput, proc.Name, proc.Deprecated != nil)
+			}
+		}
+	}
+
+	for _, stream := range astSchema.GetStreams() {
+		for _, child := range stream.Children {
+			ctx.addComment(child.Comment)
+			if child.Input != nil {
+				ctx.addFields(child.Input.Children, ownerKindStreamInput, stream.Name, stream.Deprecated != nil)
+			}
+			if child.Output != nil {
+				ctx.addFields(child.Output.Children, ownerKindStreamOutput, stream.Name, stream.Deprecated != nil)
+			}
+		}
+	}
+
+	return ctx
+}
+
+// addFields recursively collects the fields and comments of a block.
+func (c *lintContext) addFields(children []*ast.FieldOrComment, kind ownerKind, name string, deprecated bool) {
+	for _, child := range children {
+		c.addComment(child.Comment)
+		if child.Field == nil {
+			continue
+		}
+
+		c.fields = append(c.fields, lintField{
+			field:           child.Field,
+			ownerKind:       kind,
+			ownerName:       name,
+			ownerDeprecated: deprecated,
+		})
+
+		if child.Field.Type.Base.Object != nil {
+			c.addFields(child.Field.Type.Base.Object.Children, kind, name, deprecated)
+		}
+	}
+}
+
+// addComment registers the comment if it is an ignore directive.
+func (c *lintContext) addComment(comment *ast.Comment) {
+	if comment == nil {
+		return
+	}
+
+	text := ""
+	if comment.Simple != nil {
+		text = *comment.Simple
+	}
+	if comment.Block != nil {
+		text = *comment.Block
+	}
+
+	ruleNames, ok := parseIgnoreDirective(text)
+	if !ok {
+		return
+	}
+
+	line := comment.Pos.Line
+	if len(ruleNames) == 0 {
+		ruleNames = []string{"*"}
+	}
+	c.ignores[line] = append(c.ignores[line], ruleNames...)
+}
+
+// isIgnored returns true if the current rule is suppressed at the given line.
+func (c *lintContext) isIgnored(line int) bool {
+	for _, l := range []int{line, line - 1} {
+		for _, ruleName := range c.ignores[l] {
+			if ruleName == "*" || ruleName == c.currentRule {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// report adds a diagnostic for the current rule unless it is ignored.
+func (c *lintContext) report(positions ast.Positions, message string) {
+	if c.isIgnored(positions.Pos.Line) {
+		return
+	}
+
+	c.diagnostics = append(c.diagnostics, analyzer.Diagnostic{
+		Positions: analyzer.Positions(positions),
+		Message:   message,
+		Severity:  c.currentSeverity,
+		Code:      c.currentRule,
+	})
+}
+
+// parseIgnoreDirective parses the text of a comment and returns the rules to
+// ignore. An empty list means that all rules are ignored.
+func parseIgnoreDirective(text string) ([]string, bool) {
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, IgnoreDirective) {
+		return nil, false
+	}
+
+	rest := strings.TrimPrefix(text, IgnoreDirective)
+	if rest != "" && rest[0] != ' ' && rest[0] != '\t' {
+		return nil, false
+	}
+
+	ruleNames := strings.FieldsFunc(rest, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	})
+	return ruleNames, true
+}
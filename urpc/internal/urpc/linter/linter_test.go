@@ -0,0 +1,222 @@
+package linter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/uforg/uforpc/urpc/internal/urpc/analyzer"
+	"github.com/uforg/uforpc/urpc/internal/urpc/ast"
+	"github.com/uforg/uforpc/urpc/internal/urpc/parser"
+)
+
+// parseSchema parses the given input string into an ast.Schema.
+// this is a test helper function.
+func parseSchema(t *testing.T, input string) *ast.Schema {
+	t.Helper()
+	schema, err := parser.ParserInstance.ParseString("test.urpc", input)
+	require.NoError(t, err)
+	return schema
+}
+
+// onlyRule returns a config that enables only the given rule as a warning.
+func onlyRule(name string) Config {
+	config := Config{Rules: map[string]Level{}}
+	for _, r := range rules {
+		config.Rules[r.Name] = LevelOff
+	}
+	config.Rules[name] = LevelWarning
+	return config
+}
+
+func TestLint_FieldCamelCase(t *testing.T) {
+	sch := parseSchema(t, `
+		type User {
+			UserId: string
+			name: string
+			address: {
+				ZipCode: string
+			}
+		}
+	`)
+
+	diags := Lint(sch, onlyRule("field-camel-case"))
+	require.Len(t, diags, 2)
+	require.Contains(t, diags[0].Message, `field "UserId" should be in camelCase (e.g. "userId")`)
+	require.Contains(t, diags[1].Message, `field "ZipCode"`)
+	require.Equal(t, "field-camel-case", diags[0].Code)
+	require.Equal(t, analyzer.SeverityWarning, diags[0].Severity)
+}
+
+func TestLint_MissingDocstring(t *testing.T) {
+	sch := parseSchema(t, `
+		""" Gets a user """
+		proc GetUser {}
+
+		proc DeleteUser {}
+
+		stream Events {}
+	`)
+
+	diags := Lint(sch, onlyRule("missing-docstring"))
+	require.Len(t, diags, 2)
+	require.Equal(t, `procedure "DeleteUser" has no docstring`, diags[0].Message)
+	require.Equal(t, `stream "Events" has no docstring`, diags[1].Message)
+}
+
+func TestLint_UnusedType(t *testing.T) {
+	sch := parseSchema(t, `
+		type Address {
+			street: string
+		}
+
+		type User {
+			address: Address
+		}
+
+		type Orphan {
+			value: string
+		}
+
+		proc GetUser {
+			output {
+				user: User
+			}
+		}
+	`)
+
+	diags := Lint(sch, onlyRule("unused-type"))
+	require.Len(t, diags, 1)
+	require.Equal(t, `type "Orphan" is declared but never used`, diags[0].Message)
+}
+
+func TestLint_DeprecatedReference(t *testing.T) {
+	sch := parseSchema(t, `
+		deprecated type OldUser {
+			id: string
+		}
+
+		proc GetUser {
+			output {
+				user: OldUser
+			}
+		}
+
+		deprecated proc GetOldUser {
+			output {
+				user: OldUser
+			}
+		}
+
+		stream UserChanges {
+			output {
+				nested: {
+					user: OldUser
+				}
+			}
+		}
+	`)
+
+	diags := Lint(sch, onlyRule("deprecated-reference"))
+	require.Len(t, diags, 2)
+	require.Equal(t, `procedure "GetUser" is not deprecated but references deprecated type "OldUser"`, diags[0].Message)
+	require.Equal(t, `stream "UserChanges" is not deprecated but references deprecated type "OldUser"`, diags[1].Message)
+}
+
+func TestLint_ArrayPluralName(t *testing.T) {
+	sch := parseSchema(t, `
+		type Post {
+			tags: string[]
+			comment: string[]
+		}
+	`)
+
+	diags := Lint(sch, onlyRule("array-plural-name"))
+	require.Len(t, diags, 1)
+	require.Equal(t, `array field "comment" should have a plural name`, diags[0].Message)
+}
+
+func TestLint_InputOutputReuse(t *testing.T) {
+	sch := parseSchema(t, `
+		type User {
+			id: string
+		}
+
+		type UserFilter {
+			id: string
+		}
+
+		proc CreateUser {
+			input {
+				user: User
+			}
+		}
+
+		proc ListUsers {
+			input {
+				filter: UserFilter
+			}
+			output {
+				users: User[]
+			}
+		}
+	`)
+
+	diags := Lint(sch, onlyRule("input-output-reuse"))
+	require.Len(t, diags, 1)
+	require.Contains(t, diags[0].Message, `type "User" is used both as input and output`)
+}
+
+func TestLint_IgnoreComments(t *testing.T) {
+	sch := parseSchema(t, `
+		type User {
+			// urpc-lint-ignore field-camel-case
+			UserId: string
+			Name: string // urpc-lint-ignore
+			// urpc-lint-ignore unused-type
+			Email: string
+		}
+	`)
+
+	diags := Lint(sch, onlyRule("field-camel-case"))
+	require.Len(t, diags, 1)
+	require.Contains(t, diags[0].Message, `field "Email"`)
+}
+
+func TestLint_Levels(t *testing.T) {
+	sch := parseSchema(t, `
+		proc GetUser {}
+	`)
+
+	t.Run("default level", func(t *testing.T) {
+		diags := Lint(sch, Config{})
+		require.Len(t, diags, 1)
+		require.Equal(t, analyzer.SeverityInfo, diags[0].Severity)
+	})
+
+	t.Run("overridden level", func(t *testing.T) {
+		diags := Lint(sch, Config{Rules: map[string]Level{"missing-docstring": LevelError}})
+		require.Len(t, diags, 1)
+		require.Equal(t, analyzer.SeverityError, diags[0].Severity)
+	})
+
+	t.Run("rule turned off", func(t *testing.T) {
+		diags := Lint(sch, Config{Rules: map[string]Level{"missing-docstring": LevelOff}})
+		require.Empty(t, diags)
+	})
+
+	t.Run("linter disabled", func(t *testing.T) {
+		diags := Lint(sch, Config{Disabled: true})
+		require.Empty(t, diags)
+	})
+}
+
+func TestConfig_Validate(t *testing.T) {
+	require.NoError(t, Config{}.Validate())
+	require.NoError(t, Config{Rules: map[string]Level{"unused-type": LevelError}}.Validate())
+
+	err := Config{Rules: map[string]Level{"unknown-rule": LevelError}}.Validate()
+	require.ErrorContains(t, err, `unknown lint rule "unknown-rule"`)
+
+	err = Config{Rules: map[string]Level{"unused-type": "fatal"}}.Validate()
+	require.ErrorContains(t, err, `invalid level "fatal" for lint rule "unused-type"`)
+}
@@ -0,0 +1,211 @@
+package linter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/uforg/uforpc/urpc/internal/urpc/ast"
+	"github.com/uforg/uforpc/urpc/internal/util/strutil"
+)
+
+// rule is a single lint rule that can be toggled from the config.
+type rule struct {
+	// Name is the identifier used in the config and ignore comments.
+	Name string
+	// Description is a short human readable description of the rule.
+	Description string
+	// DefaultLevel is the level used when the rule is not configured.
+	DefaultLevel Level
+	// check runs the rule and reports findings to the context.
+	check func(ctx *lintContext)
+}
+
+// RuleInfo describes a lint rule.
+type RuleInfo struct {
+	Name         string
+	Description  string
+	DefaultLevel Level
+}
+
+// rules is the list of all available lint rules.
+var rules = []rule{
+	{
+		Name:         "field-camel-case",
+		Description:  "Field names should be in camelCase.",
+		DefaultLevel: LevelWarning,
+		check:        checkFieldCamelCase,
+	},
+	{
+		Name:         "missing-docstring",
+		Description:  "Procedures and streams should have a docstring.",
+		DefaultLevel: LevelInfo,
+		check:        checkMissingDocstring,
+	},
+	{
+		Name:         "unused-type",
+		Description:  "Types should be referenced by at least one type, procedure or stream.",
+		DefaultLevel: LevelWarning,
+		check:        checkUnusedType,
+	},
+	{
+		Name:         "deprecated-reference",
+		Description:  "Procedures and streams that are not deprecated should not reference deprecated types.",
+		DefaultLevel: LevelWarning,
+		check:        checkDeprecatedReference,
+	},
+	{
+		Name:         "array-plural-name",
+		Description:  "Array fields should have plural names.",
+		DefaultLevel: LevelInfo,
+		check:        checkArrayPluralName,
+	},
+	{
+		Name:         "input-output-reuse",
+		Description:  "Types used as input should not be reused as output.",
+		DefaultLevel: LevelInfo,
+		check:        checkInputOutputReuse,
+	},
+}
+
+// Rules returns the information of all available lint rules.
+func Rules() []RuleInfo {
+	infos := make([]RuleInfo, len(rules))
+	for i, r := range rules {
+		infos[i] = RuleInfo{
+			Name:         r.Name,
+			Description:  r.Description,
+			DefaultLevel: r.DefaultLevel,
+		}
+	}
+	return infos
+}
+
+// getRule returns the rule with the given name.
+func getRule(name string) (rule, bool) {
+	for _, r := range rules {
+		if r.Name == name {
+			return r, true
+		}
+	}
+	return rule{}, false
+}
+
+// checkFieldCamelCase reports fields whose names are not camelCase.
+func checkFieldCamelCase(ctx *lintContext) {
+	for _, field := range ctx.fields {
+		if strutil.IsCamelCase(field.field.Name) {
+			continue
+		}
+		ctx.report(field.field.Positions, fmt.Sprintf(
+			"field \"%s\" should be in camelCase (e.g. \"%s\")",
+			field.field.Name, strutil.ToCamelCase(field.field.Name),
+		))
+	}
+}
+
+// checkMissingDocstring reports procedures and streams without docstring.
+func checkMissingDocstring(ctx *lintContext) {
+	isEmpty := func(docstring *ast.Docstring) bool {
+		return docstring == nil || strings.TrimSpace(docstring.Value) == ""
+	}
+
+	for _, proc := range ctx.schema.GetProcs() {
+		if isEmpty(proc.Docstring) {
+			ctx.report(proc.Positions, fmt.Sprintf("procedure \"%s\" has no docstring", proc.Name))
+		}
+	}
+
+	for _, stream := range ctx.schema.GetStreams() {
+		if isEmpty(stream.Docstring) {
+			ctx.report(stream.Positions, fmt.Sprintf("stream \"%s\" has no docstring", stream.Name))
+		}
+	}
+}
+
+// checkUnusedType reports types that are never referenced.
+func checkUnusedType(ctx *lintContext) {
+	used := map[string]bool{}
+	for _, field := range ctx.fields {
+		if name, ok := field.customTypeName(); ok && name != field.ownerName {
+			used[name] = true
+		}
+	}
+
+	for _, typeDecl := range ctx.schema.GetTypes() {
+		if !used[typeDecl.Name] {
+			ctx.report(typeDecl.Positions, fmt.Sprintf("type \"%s\" is declared but never used", typeDecl.Name))
+		}
+	}
+}
+
+// checkDeprecatedReference reports non deprecated procedures and streams that
+// reference deprecated types in their input or output.
+func checkDeprecatedReference(ctx *lintContext) {
+	types := ctx.schema.GetTypesMap()
+
+	for _, field := range ctx.fields {
+		if field.ownerKind == ownerKindType || field.ownerDeprecated {
+			continue
+		}
+
+		name, ok := field.customTypeName()
+		if !ok {
+			continue
+		}
+
+		typeDecl, ok := types[name]
+		if !ok || typeDecl.Deprecated == nil {
+			continue
+		}
+
+		ctx.report(field.field.Type.Positions, fmt.Sprintf(
+			"%s \"%s\" is not deprecated but references deprecated type \"%s\"",
+			field.ownerKind.label(), field.ownerName, name,
+		))
+	}
+}
+
+// checkArrayPluralName reports array fields whose name is not plural.
+func checkArrayPluralName(ctx *lintContext) {
+	for _, field := range ctx.fields {
+		if !field.field.Type.IsArray {
+			continue
+		}
+		if strings.HasSuffix(strings.ToLower(field.field.Name), "s") {
+			continue
+		}
+		ctx.report(field.field.Positions, fmt.Sprintf(
+			"array field \"%s\" should have a plural name", field.field.Name,
+		))
+	}
+}
+
+// checkInputOutputReuse reports types that are directly used both in an input
+// and in an output of procedures or streams.
+func checkInputOutputReuse(ctx *lintContext) {
+	usedInInput := map[string]bool{}
+	usedInOutput := map[string]bool{}
+
+	for _, field := range ctx.fields {
+		name, ok := field.customTypeName()
+		if !ok {
+			continue
+		}
+
+		switch field.ownerKind {
+		case ownerKindProcInput, ownerKindStreamInput:
+			usedInInput[name] = true
+		case ownerKindProcOutput, ownerKindStreamOutput:
+			usedInOutput[name] = true
+		}
+	}
+
+	for _, typeDecl := range ctx.schema.GetTypes() {
+		if usedInInput[typeDecl.Name] && usedInOutput[typeDecl.Name] {
+			ctx.report(typeDecl.Positions, fmt.Sprintf(
+				"type \"%s\" is used both as input and output, consider declaring separate types",
+				typeDecl.Name,
+			))
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/uforg/uforpc/urpc/internal/urpc/analyzer"
 	"github.com/uforg/uforpc/urpc/internal/urpc/ast"
+	"github.com/uforg/uforpc/urpc/internal/urpc/linter"
 )
 
 // DiagnosticSeverity defines the severity level of a diagnostic.
@@ -57,12 +58,25 @@ func ConvertAnalyzerDiagnosticToLSPDiagnostic(analyzerDiag analyzer.Diagnostic)
 			Start: convertASTPositionToLSPPosition(analyzerDiag.Pos),
 			End:   convertASTPositionToLSPPosition(analyzerDiag.EndPos),
 		},
-		Severity: DiagnosticSeverityError, // All analyzer diagnostics are treated as errors for now
+		Severity: convertAnalyzerSeverityToLSPSeverity(analyzerDiag.Severity),
+		Code:     analyzerDiag.Code,
 		Source:   "urpc",
 		Message:  analyzerDiag.Message,
 	}
 }
 
+// convertAnalyzerSeverityToLSPSeverity converts an analyzer severity to an LSP severity.
+func convertAnalyzerSeverityToLSPSeverity(severity analyzer.Severity) DiagnosticSeverity {
+	switch severity {
+	case analyzer.SeverityWarning:
+		return DiagnosticSeverityWarning
+	case analyzer.SeverityInfo:
+		return DiagnosticSeverityInformation
+	default:
+		return DiagnosticSeverityError
+	}
+}
+
 // convertASTPositionToLSPPosition converts an AST position to an LSP position.
 func convertASTPositionToLSPPosition(pos ast.Position) TextDocumentPosition {
 	// LSP positions are zero-based, but AST positions are one-based
@@ -107,7 +121,12 @@ func (l *LSP) analyzeAndPublishDiagnostics(uri string) {
 	}
 
 	// Run the analyzer
-	_, diagnostics, _ := l.analyzer.Analyze(uri)
+	astSchema, diagnostics, err := l.analyzer.Analyze(uri)
+
+	// Run the linter only on valid schemas to avoid misleading findings
+	if err == nil {
+		diagnostics = append(diagnostics, linter.Lint(astSchema, l.loadLintConfig(uri))...)
+	}
 
 	// Convert analyzer diagnostics to LSP diagnostics
 	lspDiagnostics := make([]Diagnostic, 0, len(diagnostics))
@@ -42,6 +42,27 @@ func TestDiagnostics(t *testing.T) {
 		assert.Equal(t, "urpc", lspDiag.Source)
 	})
 
+	t.Run("ConvertAnalyzerDiagnosticToLSPDiagnostic with lint severity", func(t *testing.T) {
+		analyzerDiag := analyzer.Diagnostic{
+			Positions: analyzer.Positions{
+				Pos:    ast.Position{Filename: "test.urpc", Line: 1, Column: 1},
+				EndPos: ast.Position{Filename: "test.urpc", Line: 1, Column: 5},
+			},
+			Message:  "Test lint message",
+			Severity: analyzer.SeverityWarning,
+			Code:     "field-camel-case",
+		}
+
+		lspDiag := ConvertAnalyzerDiagnosticToLSPDiagnostic(analyzerDiag)
+
+		assert.Equal(t, DiagnosticSeverityWarning, lspDiag.Severity)
+		assert.Equal(t, "field-camel-case", lspDiag.Code)
+
+		analyzerDiag.Severity = analyzer.SeverityInfo
+		lspDiag = ConvertAnalyzerDiagnosticToLSPDiagnostic(analyzerDiag)
+		assert.Equal(t, DiagnosticSeverityInformation, lspDiag.Severity)
+	})
+
 	// Test publishing diagnostics
 	t.Run("PublishDiagnostics", func(t *testing.T) {
 		// Clear the writer buffer
@@ -0,0 +1,115 @@
+package lsp
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"github.com/uforg/uforpc/urpc/internal/codegen"
+	"github.com/uforg/uforpc/urpc/internal/urpc/linter"
+	"github.com/uforg/uforpc/urpc/internal/util/filepathutil"
+)
+
+// lintConfigFileName is the name of the config file that holds the lint rules.
+const lintConfigFileName = "uforpc.toml"
+
+// lintConfigCache holds the lint configs already loaded, by config path.
+type lintConfigCache struct {
+	mu      sync.Mutex
+	entries map[string]lintConfigEntry
+}
+
+// lintConfigEntry is a loaded lint config together with the state of the
+// config files it was loaded from, the config file and the files it extends.
+type lintConfigEntry struct {
+	stamps []fileStamp
+	config linter.Config
+}
+
+// fileStamp identifies a version of a file by its modification time and
+// size, a missing file has the zero stamp.
+type fileStamp struct {
+	path    string
+	modTime int64
+	size    int64
+}
+
+// statFiles returns the current stamps of the given files.
+func statFiles(paths []string) []fileStamp {
+	stamps := make([]fileStamp, 0, len(paths))
+	for _, path := range paths {
+		stamp := fileStamp{path: path}
+		if info, err := os.Stat(path); err == nil {
+			stamp.modTime = info.ModTime().UnixNano()
+			stamp.size = info.Size()
+		}
+		stamps = append(stamps, stamp)
+	}
+	return stamps
+}
+
+// loadLintConfig looks for the nearest uforpc.toml file starting from the
+// directory of the given document and walking up to the root, and returns
+// its [lint] table.
+//
+// The config is loaded like in the other commands without a profile, so
+// extends and environment variables are applied, and it is cached until the
+// config file or any of the files it extends change.
+//
+// If no config file is found or it is invalid, the default config is used.
+func (l *LSP) loadLintConfig(uri string) linter.Config {
+	dir := filepath.Dir(filepathutil.FromURI(uri))
+
+	for {
+		configPath := filepath.Join(dir, lintConfigFileName)
+		if _, err := os.Stat(configPath); err == nil {
+			return l.lintConfigs.load(l, configPath)
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return linter.Config{}
+		}
+		dir = parent
+	}
+}
+
+// load returns the cached lint config of the given config file, loading it
+// again if any of its source files changed since it was cached.
+func (c *lintConfigCache) load(l *LSP, configPath string) linter.Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if entry, ok := c.entries[configPath]; ok && slices.Equal(entry.stamps, statFiles(sourcePaths(entry.stamps))) {
+		return entry.config
+	}
+
+	config, err := codegen.LoadConfig(configPath, "")
+	files := config.SourceFiles()
+	if len(files) == 0 {
+		files = []string{configPath}
+	}
+
+	entry := lintConfigEntry{stamps: statFiles(files)}
+	if err != nil {
+		l.logger.Warn("invalid lint config", "path", configPath, "error", err)
+	} else {
+		entry.config = config.Lint
+	}
+
+	if c.entries == nil {
+		c.entries = map[string]lintConfigEntry{}
+	}
+	c.entries[configPath] = entry
+	return entry.config
+}
+
+// sourcePaths returns the paths of the given stamps.
+func sourcePaths(stamps []fileStamp) []string {
+	paths := make([]string, 0, len(stamps))
+	for _, stamp := range stamps {
+		paths = append(paths, stamp.path)
+	}
+	return paths
+}
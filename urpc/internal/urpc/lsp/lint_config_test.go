@@ -0,0 +1,94 @@
+package lsp
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/uforg/uforpc/urpc/internal/urpc/linter"
+)
+
+func TestLoadLintConfig(t *testing.T) {
+	dir := t.TempDir()
+	basePath := filepath.Join(dir, "base.toml")
+	configPath := filepath.Join(dir, "api", lintConfigFileName)
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "api", "nested"), 0755))
+
+	// writeFile writes the file with a modification time in the future, so
+	// rewrites are detected on file systems with a coarse time resolution
+	modTime := time.Now()
+	writeFile := func(path string, content string) {
+		modTime = modTime.Add(time.Second)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		require.NoError(t, os.Chtimes(path, modTime, modTime))
+	}
+
+	writeFile(basePath, `
+version = 1
+schema = "./schema.urpc"
+
+[lint.rules]
+missing-docstring = "error"
+`)
+	writeFile(configPath, `
+extends = "../base.toml"
+
+[lint.rules]
+unused-type = "off"
+`)
+
+	l := New(&bytes.Buffer{}, &bytes.Buffer{})
+	uri := "file://" + filepath.Join(dir, "api", "nested", "schema.urpc")
+
+	t.Run("Extends are applied", func(t *testing.T) {
+		require.Equal(t, linter.Config{Rules: map[string]linter.Level{
+			"missing-docstring": linter.LevelError,
+			"unused-type":       linter.LevelOff,
+		}}, l.loadLintConfig(uri))
+		require.Len(t, l.lintConfigs.entries, 1)
+		require.Len(t, l.lintConfigs.entries[configPath].stamps, 2)
+	})
+
+	t.Run("Cached until a source file changes", func(t *testing.T) {
+		entry := l.lintConfigs.entries[configPath]
+		entry.config = linter.Config{Disabled: true}
+		l.lintConfigs.entries[configPath] = entry
+		require.Equal(t, linter.Config{Disabled: true}, l.loadLintConfig(uri))
+
+		writeFile(basePath, `
+version = 1
+schema = "./schema.urpc"
+
+[lint.rules]
+missing-docstring = "warning"
+`)
+		require.Equal(t, linter.Config{Rules: map[string]linter.Level{
+			"missing-docstring": linter.LevelWarning,
+			"unused-type":       linter.LevelOff,
+		}}, l.loadLintConfig(uri))
+	})
+
+	t.Run("Invalid config uses the defaults", func(t *testing.T) {
+		writeFile(configPath, `
+extends = "../base.toml"
+
+[lint.rules]
+unknown-rule = "off"
+`)
+		require.Equal(t, linter.Config{}, l.loadLintConfig(uri))
+
+		writeFile(configPath, `
+extends = "../base.toml"
+`)
+		require.Equal(t, linter.Config{Rules: map[string]linter.Level{
+			"missing-docstring": linter.LevelWarning,
+		}}, l.loadLintConfig(uri))
+	})
+
+	t.Run("No config file uses the defaults", func(t *testing.T) {
+		require.Equal(t, linter.Config{}, l.loadLintConfig("file://"+filepath.Join(t.TempDir(), "schema.urpc")))
+	})
+}
@@ -18,18 +18,19 @@ type LSP struct {
 	handlerMu            sync.Mutex
 	logger               *LSPLogger
 	docstore             *docstore.Docstore
-	analyzer             *analyzer.Analyzer
+	analyzer             *analyzer.CachedAnalyzer
 	analysisTimer        *time.Timer
 	analysisTimerMu      sync.Mutex
 	analysisInProgress   bool
 	analysisInProgressMu sync.Mutex
+	lintConfigs          lintConfigCache
 }
 
 // New creates a new LSP instance. It uses the given reader and writer to read and write
 // messages to the LSP server.
 func New(reader io.Reader, writer io.Writer) *LSP {
 	docstore := docstore.NewDocstore()
-	analyzerInstance, err := analyzer.NewAnalyzer(docstore)
+	analyzerInstance, err := analyzer.NewCachedAnalyzer(docstore)
 	if err != nil {
 		// If analyzer creation fails, we'll log it but continue without analyzer
 		logger := NewLSPLogger()
@@ -59,9 +59,10 @@ func (l *LSP) handleTextDocumentDefinition(rawMessage []byte) (any, error) {
 		return nil, fmt.Errorf("failed to get in memory text document: %w", err)
 	}
 
-	// Run the analyzer to get the combined schema
-	astSchema, _, err := l.analyzer.Analyze(filePath)
-	if err != nil {
+	// Run the analyzer to get the combined schema, it can be partial if the
+	// document has errors
+	astSchema, _, _ := l.analyzer.Analyze(filePath)
+	if astSchema == nil {
 		return ResponseMessageTextDocumentDefinition{
 			ResponseMessage: ResponseMessage{
 				Message: DefaultMessage,
@@ -146,7 +146,7 @@ proc BarProc {
 	require.NoError(t, err)
 
 	// Create a mock analyzer
-	mockAnalyzer, err := analyzer.NewAnalyzer(lsp.docstore)
+	mockAnalyzer, err := analyzer.NewCachedAnalyzer(lsp.docstore)
 	require.NoError(t, err)
 	lsp.analyzer = mockAnalyzer
 
@@ -73,9 +73,9 @@ func (l *LSP) handleTextDocumentDocumentSymbol(rawMessage []byte) (any, error) {
 
 	uri := request.Params.TextDocument.URI
 
-	// Run analyzer to get AST schema
-	astSchema, _, err := l.analyzer.Analyze(uri)
-	if err != nil {
+	// Run analyzer to get AST schema, it can be partial if the document has errors
+	astSchema, _, _ := l.analyzer.Analyze(uri)
+	if astSchema == nil {
 		// Return empty result but no error (so client still gets response)
 		resp := ResponseMessageTextDocumentDocumentSymbol{
 			ResponseMessage: ResponseMessage{Message: DefaultMessage, ID: request.ID},
@@ -0,0 +1,26 @@
+package parser
+
+import (
+	"fmt"
+
+	"github.com/uforg/uforpc/urpc/internal/urpc/ast"
+)
+
+// Error is a syntax error found while parsing a URPC schema.
+type Error interface {
+	error
+	// Message returns the error message without the position.
+	Message() string
+	// Position returns the position where the error occurred.
+	Position() ast.Position
+}
+
+// parseError is the implementation of the Error interface.
+type parseError struct {
+	msg string
+	pos ast.Position
+}
+
+func (e *parseError) Error() string          { return fmt.Sprintf("%s: %s", e.pos.String(), e.msg) }
+func (e *parseError) Message() string        { return e.msg }
+func (e *parseError) Position() ast.Position { return e.pos }
@@ -1,20 +1,605 @@
+// Package parser provides a hand-written recursive descent parser for URPC
+// schemas that produces the types defined in the ast package.
 package parser
 
 import (
-	"github.com/alecthomas/participle/v2"
+	"fmt"
+	"strconv"
+
 	"github.com/uforg/uforpc/urpc/internal/urpc/ast"
 	"github.com/uforg/uforpc/urpc/internal/urpc/lexer"
+	"github.com/uforg/uforpc/urpc/internal/urpc/token"
 )
 
-// Error is an alias for participle.Error
-type Error = participle.Error
-
-// Parser is an alias for participle.Parser with ast.Schema as the root node
-type Parser = participle.Parser[ast.Schema]
+// Parser parses URPC schemas.
+//
+// It works over the tokens produced by lexer.Lexer and populates the Pos
+// and EndPos fields of every node:
+//
+//   - Pos is the position of the first token of the node.
+//   - EndPos is the position of the token right after the last token of
+//     the node, including whitespace and newline tokens.
+type Parser struct{}
 
 // ParserInstance is a pre-built parser instance for URPC schemas.
-var ParserInstance = participle.MustBuild[ast.Schema](
-	participle.Lexer(&lexer.ParticipleLexer{}),
-	participle.Elide("Newline", "Whitespace"),
-	participle.UseLookahead(4),
-)
+var ParserInstance = &Parser{}
+
+// ParseString parses the given input and returns the schema AST.
+//
+// The returned error implements the Error interface.
+func (p *Parser) ParseString(filename string, input string) (*ast.Schema, error) {
+	ps := newParserState(filename, input)
+	schema, err := ps.parseSchema()
+	if err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+// ParseBytes parses the given input and returns the schema AST.
+//
+// The returned error implements the Error interface.
+func (p *Parser) ParseBytes(filename string, input []byte) (*ast.Schema, error) {
+	return p.ParseString(filename, string(input))
+}
+
+// parserState holds the state of a single parsing.
+type parserState struct {
+	filename string
+	tokens   []token.Token
+	// cursor is the index of the next raw token to be consumed, it can
+	// point to whitespace and newline tokens.
+	cursor int
+}
+
+// newParserState lexes the input and creates a new parser state.
+func newParserState(filename string, input string) *parserState {
+	return newParserStateAt(filename, input, 1, 1)
+}
+
+// newParserStateAt lexes the input that starts at the given line and column
+// of the file and creates a new parser state.
+func newParserStateAt(filename string, input string, line int, column int) *parserState {
+	lex := lexer.NewLexer(filename, input)
+	lex.CurrentLine = line
+	lex.CurrentColumn = column
+	return &parserState{
+		filename: filename,
+		tokens:   lex.ReadTokens(),
+		cursor:   0,
+	}
+}
+
+// isElided returns true for the tokens that are skipped by the parser.
+func isElided(tokType token.TokenType) bool {
+	return tokType == token.Whitespace || tokType == token.Newline
+}
+
+// position converts the start of a token to an AST position.
+func (ps *parserState) position(tok token.Token) ast.Position {
+	return ast.Position{
+		Filename: ps.filename,
+		Offset:   max(tok.ColumnStart-1, 0),
+		Line:     tok.LineStart,
+		Column:   tok.ColumnStart,
+	}
+}
+
+// peekIndex returns the index of the n-th (zero based) non elided token
+// starting from the cursor.
+func (ps *parserState) peekIndex(n int) int {
+	index := ps.cursor
+	for {
+		for index < len(ps.tokens)-1 && isElided(ps.tokens[index].Type) {
+			index++
+		}
+		if n == 0 || index >= len(ps.tokens)-1 {
+			return index
+		}
+		n--
+		index++
+	}
+}
+
+// peek returns the next non elided token without consuming it.
+func (ps *parserState) peek() token.Token {
+	return ps.tokens[ps.peekIndex(0)]
+}
+
+// peekN returns the n-th (zero based) non elided token without consuming it.
+func (ps *parserState) peekN(n int) token.Token {
+	return ps.tokens[ps.peekIndex(n)]
+}
+
+// next consumes and returns the next non elided token.
+func (ps *parserState) next() token.Token {
+	index := ps.peekIndex(0)
+	tok := ps.tokens[index]
+	if tok.Type != token.Eof {
+		ps.cursor = index + 1
+	}
+	return tok
+}
+
+// startPos returns the position where the next node starts.
+func (ps *parserState) startPos() ast.Position {
+	return ps.position(ps.peek())
+}
+
+// endPos returns the position where the last parsed node ends.
+func (ps *parserState) endPos() ast.Position {
+	return ps.position(ps.tokens[min(ps.cursor, len(ps.tokens)-1)])
+}
+
+// expect consumes the next token if it has the given type, otherwise it
+// returns an error with the given message.
+func (ps *parserState) expect(tokType token.TokenType, message string) (token.Token, error) {
+	tok := ps.peek()
+	if tok.Type != tokType {
+		return tok, ps.errorAt(tok, message)
+	}
+	return ps.next(), nil
+}
+
+// errorAt creates a syntax error at the given token.
+func (ps *parserState) errorAt(tok token.Token, message string) error {
+	return &parseError{
+		msg: fmt.Sprintf("%s, got %s", message, describeToken(tok)),
+		pos: ps.position(tok),
+	}
+}
+
+// describeToken returns a human readable description of a token.
+func describeToken(tok token.Token) string {
+	switch tok.Type {
+	case token.Eof:
+		return "end of file"
+	case token.Docstring:
+		return "docstring"
+	case token.Comment, token.CommentBlock:
+		return "comment"
+	case token.StringLiteral:
+		return fmt.Sprintf("string %q", tok.Literal)
+	case token.Illegal:
+		return fmt.Sprintf("illegal token %q", tok.Literal)
+	}
+	return fmt.Sprintf("%q", tok.Literal)
+}
+
+// isFollowedByBlankLine returns true if the token at the given index is
+// followed by two newlines, ignoring whitespace in between.
+func (ps *parserState) isFollowedByBlankLine(index int) bool {
+	newlines := 0
+	for i := index + 1; i < len(ps.tokens) && newlines < 2; i++ {
+		switch ps.tokens[i].Type {
+		case token.Whitespace:
+			continue
+		case token.Newline:
+			newlines++
+		default:
+			return false
+		}
+	}
+	return newlines == 2
+}
+
+// isDeclarationAhead returns true if the tokens after the next n non elided
+// tokens start a type, proc or stream declaration (optionally deprecated).
+func (ps *parserState) isDeclarationAhead(n int) bool {
+	tok := ps.peekN(n)
+	if tok.Type == token.Deprecated {
+		n++
+		if ps.peekN(n).Type == token.LParen {
+			n += 3
+		}
+		tok = ps.peekN(n)
+	}
+	switch tok.Type {
+	case token.Type, token.Proc, token.Stream:
+		return true
+	}
+	return false
+}
+
+// parseSchema parses the root of the schema.
+func (ps *parserState) parseSchema() (*ast.Schema, error) {
+	schema := &ast.Schema{}
+	schema.Pos = ps.startPos()
+
+	for ps.peek().Type != token.Eof {
+		child, err := ps.parseSchemaChild()
+		if err != nil {
+			return nil, err
+		}
+		schema.Children = append(schema.Children, child)
+	}
+
+	schema.EndPos = ps.endPos()
+	return schema, nil
+}
+
+// parseSchemaChild parses a top level node of the schema.
+func (ps *parserState) parseSchemaChild() (*ast.SchemaChild, error) {
+	child := &ast.SchemaChild{}
+	tok := ps.peek()
+
+	var positions ast.Positions
+	switch tok.Type {
+	case token.Version:
+		version, err := ps.parseVersion()
+		if err != nil {
+			return nil, err
+		}
+		child.Version = version
+		positions = version.Positions
+
+	case token.Comment, token.CommentBlock:
+		comment := ps.parseComment()
+		child.Comment = comment
+		positions = comment.Positions
+
+	case token.Docstring, token.Deprecated, token.Type, token.Proc, token.Stream:
+		if tok.Type == token.Docstring {
+			index := ps.peekIndex(0)
+			if ps.isFollowedByBlankLine(index) || !ps.isDeclarationAhead(1) {
+				docstring := ps.parseDocstring()
+				child.Docstring = docstring
+				positions = docstring.Positions
+				break
+			}
+		}
+
+		decl, err := ps.parseDeclaration()
+		if err != nil {
+			return nil, err
+		}
+		switch d := decl.(type) {
+		case *ast.TypeDecl:
+			child.Type = d
+			positions = d.Positions
+		case *ast.ProcDecl:
+			child.Proc = d
+			positions = d.Positions
+		case *ast.StreamDecl:
+			child.Stream = d
+			positions = d.Positions
+		}
+
+	default:
+		return nil, ps.errorAt(tok, "expected a declaration (version, type, proc, stream), comment or docstring")
+	}
+
+	child.Positions = positions
+	return child, nil
+}
+
+// parseVersion parses a version declaration.
+func (ps *parserState) parseVersion() (*ast.Version, error) {
+	version := &ast.Version{}
+	version.Pos = ps.startPos()
+	ps.next()
+
+	numTok, err := ps.expect(token.IntLiteral, "expected version number after 'version'")
+	if err != nil {
+		return nil, err
+	}
+
+	number, err := strconv.Atoi(numTok.Literal)
+	if err != nil {
+		return nil, ps.errorAt(numTok, "invalid version number")
+	}
+	version.Number = number
+
+	version.EndPos = ps.endPos()
+	return version, nil
+}
+
+// parseComment parses a simple or block comment.
+func (ps *parserState) parseComment() *ast.Comment {
+	comment := &ast.Comment{}
+	comment.Pos = ps.startPos()
+
+	tok := ps.next()
+	literal := tok.Literal
+	if tok.Type == token.CommentBlock {
+		comment.Block = &literal
+	} else {
+		comment.Simple = &literal
+	}
+
+	comment.EndPos = ps.endPos()
+	return comment
+}
+
+// parseDocstring parses a docstring.
+func (ps *parserState) parseDocstring() *ast.Docstring {
+	docstring := &ast.Docstring{}
+	docstring.Pos = ps.startPos()
+	docstring.Value = ps.next().Literal
+	docstring.EndPos = ps.endPos()
+	return docstring
+}
+
+// parseDeprecated parses a deprecated modifier with its optional message.
+func (ps *parserState) parseDeprecated() (*ast.Deprecated, error) {
+	deprecated := &ast.Deprecated{}
+	deprecated.Pos = ps.startPos()
+	ps.next()
+
+	if ps.peek().Type == token.LParen {
+		ps.next()
+
+		msgTok, err := ps.expect(token.StringLiteral, "expected deprecation message string after '('")
+		if err != nil {
+			return nil, err
+		}
+		message := msgTok.Literal
+		deprecated.Message = &message
+
+		if _, err := ps.expect(token.RParen, "expected ')' after deprecation message"); err != nil {
+			return nil, err
+		}
+	}
+
+	deprecated.EndPos = ps.endPos()
+	return deprecated, nil
+}
+
+// parseDeclaration parses a type, proc or stream declaration including its
+// docstring and deprecation.
+//
+// Returns an *ast.TypeDecl, *ast.ProcDecl or *ast.StreamDecl.
+func (ps *parserState) parseDeclaration() (any, error) {
+	startPos := ps.startPos()
+
+	var docstring *ast.Docstring
+	if ps.peek().Type == token.Docstring {
+		docstring = ps.parseDocstring()
+	}
+
+	var deprecated *ast.Deprecated
+	if ps.peek().Type == token.Deprecated {
+		var err error
+		deprecated, err = ps.parseDeprecated()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	keyword := ps.peek()
+	switch keyword.Type {
+	case token.Type:
+		ps.next()
+		name, err := ps.expect(token.Ident, "expected type name after 'type'")
+		if err != nil {
+			return nil, err
+		}
+		children, err := ps.parseFieldsBlock(fmt.Sprintf("type %q", name.Literal))
+		if err != nil {
+			return nil, err
+		}
+		return &ast.TypeDecl{
+			Positions:  ast.Positions{Pos: startPos, EndPos: ps.endPos()},
+			Docstring:  docstring,
+			Deprecated: deprecated,
+			Name:       name.Literal,
+			Children:   children,
+		}, nil
+
+	case token.Proc:
+		ps.next()
+		name, err := ps.expect(token.Ident, "expected procedure name after 'proc'")
+		if err != nil {
+			return nil, err
+		}
+		children, err := ps.parseProcOrStreamBlock(fmt.Sprintf("procedure %q", name.Literal))
+		if err != nil {
+			return nil, err
+		}
+		return &ast.ProcDecl{
+			Positions:  ast.Positions{Pos: startPos, EndPos: ps.endPos()},
+			Docstring:  docstring,
+			Deprecated: deprecated,
+			Name:       name.Literal,
+			Children:   children,
+		}, nil
+
+	case token.Stream:
+		ps.next()
+		name, err := ps.expect(token.Ident, "expected stream name after 'stream'")
+		if err != nil {
+			return nil, err
+		}
+		children, err := ps.parseProcOrStreamBlock(fmt.Sprintf("stream %q", name.Literal))
+		if err != nil {
+			return nil, err
+		}
+		return &ast.StreamDecl{
+			Positions:  ast.Positions{Pos: startPos, EndPos: ps.endPos()},
+			Docstring:  docstring,
+			Deprecated: deprecated,
+			Name:       name.Literal,
+			Children:   children,
+		}, nil
+	}
+
+	if deprecated != nil {
+		return nil, ps.errorAt(keyword, "expected 'type', 'proc' or 'stream' after 'deprecated'")
+	}
+	return nil, ps.errorAt(keyword, "expected 'type', 'proc' or 'stream' after docstring")
+}
+
+// parseProcOrStreamBlock parses the block of a proc or stream declaration.
+func (ps *parserState) parseProcOrStreamBlock(owner string) ([]*ast.ProcOrStreamDeclChild, error) {
+	if _, err := ps.expect(token.LBrace, fmt.Sprintf("expected '{' after %s", owner)); err != nil {
+		return nil, err
+	}
+
+	var children []*ast.ProcOrStreamDeclChild
+	for {
+		tok := ps.peek()
+		child := &ast.ProcOrStreamDeclChild{}
+
+		switch tok.Type {
+		case token.RBrace:
+			ps.next()
+			return children, nil
+
+		case token.Comment, token.CommentBlock:
+			child.Comment = ps.parseComment()
+			child.Positions = child.Comment.Positions
+
+		case token.Input:
+			input := &ast.ProcOrStreamDeclChildInput{}
+			input.Pos = ps.startPos()
+			ps.next()
+			fields, err := ps.parseFieldsBlock(fmt.Sprintf("input of %s", owner))
+			if err != nil {
+				return nil, err
+			}
+			input.Children = fields
+			input.EndPos = ps.endPos()
+			child.Input = input
+			child.Positions = input.Positions
+
+		case token.Output:
+			output := &ast.ProcOrStreamDeclChildOutput{}
+			output.Pos = ps.startPos()
+			ps.next()
+			fields, err := ps.parseFieldsBlock(fmt.Sprintf("output of %s", owner))
+			if err != nil {
+				return nil, err
+			}
+			output.Children = fields
+			output.EndPos = ps.endPos()
+			child.Output = output
+			child.Positions = output.Positions
+
+		default:
+			return nil, ps.errorAt(tok, fmt.Sprintf("expected 'input', 'output' or '}' in %s", owner))
+		}
+
+		children = append(children, child)
+	}
+}
+
+// parseFieldsBlock parses a block of fields and comments delimited by braces.
+func (ps *parserState) parseFieldsBlock(owner string) ([]*ast.FieldOrComment, error) {
+	if _, err := ps.expect(token.LBrace, fmt.Sprintf("expected '{' to open %s", owner)); err != nil {
+		return nil, err
+	}
+
+	var children []*ast.FieldOrComment
+	for {
+		tok := ps.peek()
+		child := &ast.FieldOrComment{}
+
+		switch tok.Type {
+		case token.RBrace:
+			ps.next()
+			return children, nil
+
+		case token.Comment, token.CommentBlock:
+			child.Comment = ps.parseComment()
+			child.Positions = child.Comment.Positions
+
+		case token.Docstring, token.Ident:
+			field, err := ps.parseField()
+			if err != nil {
+				return nil, err
+			}
+			child.Field = field
+			child.Positions = field.Positions
+
+		case token.Eof:
+			return nil, ps.errorAt(tok, fmt.Sprintf("expected '}' to close %s", owner))
+
+		default:
+			return nil, ps.errorAt(tok, fmt.Sprintf("expected field name or '}' in %s", owner))
+		}
+
+		children = append(children, child)
+	}
+}
+
+// parseField parses a field with its optional docstring.
+func (ps *parserState) parseField() (*ast.Field, error) {
+	field := &ast.Field{}
+	field.Pos = ps.startPos()
+
+	if tok := ps.peek(); tok.Type == token.Docstring {
+		if ps.isFollowedByBlankLine(ps.peekIndex(0)) {
+			return nil, ps.errorAt(tok, "field docstrings must not be followed by a blank line")
+		}
+		field.Docstring = ps.parseDocstring()
+	}
+
+	name, err := ps.expect(token.Ident, "expected field name")
+	if err != nil {
+		return nil, err
+	}
+	field.Name = name.Literal
+
+	if ps.peek().Type == token.Question {
+		ps.next()
+		field.Optional = true
+	}
+
+	if _, err := ps.expect(token.Colon, "expected ':' after field name"); err != nil {
+		return nil, err
+	}
+
+	fieldType, err := ps.parseFieldType()
+	if err != nil {
+		return nil, err
+	}
+	field.Type = fieldType
+
+	field.EndPos = ps.endPos()
+	return field, nil
+}
+
+// parseFieldType parses the type of a field.
+func (ps *parserState) parseFieldType() (ast.FieldType, error) {
+	fieldType := ast.FieldType{}
+	fieldType.Pos = ps.startPos()
+
+	base := &ast.FieldTypeBase{}
+	base.Pos = ps.startPos()
+
+	tok := ps.peek()
+	switch tok.Type {
+	case token.Ident, token.String, token.Int, token.Float, token.Bool, token.Datetime:
+		ps.next()
+		named := tok.Literal
+		base.Named = &named
+
+	case token.LBrace:
+		object := &ast.FieldTypeObject{}
+		object.Pos = ps.startPos()
+		children, err := ps.parseFieldsBlock("inline object")
+		if err != nil {
+			return fieldType, err
+		}
+		object.Children = children
+		object.EndPos = ps.endPos()
+		base.Object = object
+
+	default:
+		return fieldType, ps.errorAt(tok, "expected field type after ':'")
+	}
+
+	base.EndPos = ps.endPos()
+	fieldType.Base = base
+
+	if ps.peek().Type == token.LBracket {
+		ps.next()
+		if _, err := ps.expect(token.RBracket, "expected ']' after '['"); err != nil {
+			return fieldType, err
+		}
+		fieldType.IsArray = true
+	}
+
+	fieldType.EndPos = ps.endPos()
+	return fieldType, nil
+}
@@ -0,0 +1,203 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/alecthomas/participle/v2"
+	"github.com/stretchr/testify/require"
+	"github.com/uforg/uforpc/urpc/internal/urpc/ast"
+	"github.com/uforg/uforpc/urpc/internal/urpc/lexer"
+)
+
+// participleParserInstance is the participle parser built from the grammar
+// described in the ast struct tags. It was the original URPC parser and is
+// kept as the reference implementation for the hand-written parser.
+var participleParserInstance = participle.MustBuild[ast.Schema](
+	participle.Lexer(&lexer.ParticipleLexer{}),
+	participle.Elide("Newline", "Whitespace"),
+	participle.UseLookahead(4),
+)
+
+// referenceInputs returns inputs that exercise the edge cases of the grammar.
+func referenceInputs() map[string]string {
+	return map[string]string{
+		"empty":                       ``,
+		"only whitespace":             "  \n\t\n",
+		"version":                     "version 1\n",
+		"comments":                    "// a\n/* b */\nversion 1 // c\n",
+		"standalone docstring":        "\"\"\"doc\"\"\"\n\ntype A {}",
+		"blank line with spaces":      "\"\"\"doc\"\"\"\n   \ntype A {}",
+		"docstring before comment":    "\"\"\"doc\"\"\"\n// c\ntype A {}",
+		"docstring before version":    "\"\"\"doc\"\"\"\nversion 1",
+		"docstring at eof":            "type A {}\n\"\"\"doc\"\"\"",
+		"docstrings in a row":         "\"\"\"a\"\"\"\n\"\"\"b\"\"\"\ntype A {}",
+		"docstring same line":         "\"\"\"doc\"\"\"  deprecated type A {}",
+		"deprecated on its own line":  "deprecated\ntype A {}",
+		"deprecated with message":     "\"\"\"d\"\"\"\ndeprecated(\"msg\")\nproc P {}",
+		"deprecated with empty msg":   "deprecated(\"\") stream S {}",
+		"deprecated after blank line": "\"\"\"d\"\"\"\n\n\ndeprecated type A {}",
+		"empty blocks":                "proc P {\n  input {}\n  output {\n  }\n}\nstream S {}",
+		"duplicated blocks":           "proc P { input {} input {} }",
+		"fields": "type A {\n  \"\"\" doc \"\"\"\n  a?: string[]\n  b: {\n    c: B[] // end\n  }[]\n" +
+			"  /* block */\n  d:datetime\n}\n",
+		"no trailing newline": "type A { a: int }",
+		"crlf":                "type A {\r\n  a: int\r\n}\r\n",
+		"unicode":             "// ñandú\ntype A {\n  \"\"\" ñ \"\"\"\n  a: int\n}",
+	}
+}
+
+// referenceFiles returns the URPC files used as fixtures across the repo.
+func referenceFiles(t testing.TB) map[string]string {
+	t.Helper()
+
+	patterns := []string{
+		"../formatter/tests/*.urpc",
+		"../../transpile/tests/*.urpc",
+		"../../../cmd/urpc/*.urpc",
+	}
+
+	files := map[string]string{}
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		require.NoError(t, err)
+		for _, match := range matches {
+			content, err := os.ReadFile(match)
+			require.NoError(t, err)
+			files[match] = string(content)
+		}
+	}
+	require.NotEmpty(t, files)
+
+	return files
+}
+
+func TestParserMatchesParticiple(t *testing.T) {
+	inputs := referenceInputs()
+	for name, content := range referenceFiles(t) {
+		inputs[name] = content
+	}
+
+	for name, input := range inputs {
+		t.Run(name, func(t *testing.T) {
+			expected, expectedErr := participleParserInstance.ParseString("schema.urpc", input)
+			got, gotErr := ParserInstance.ParseString("schema.urpc", input)
+
+			if expectedErr != nil {
+				require.Error(t, gotErr)
+				return
+			}
+			require.NoError(t, gotErr)
+
+			expectedJSON, err := json.MarshalIndent(expected, "", "  ")
+			require.NoError(t, err)
+			gotJSON, err := json.MarshalIndent(got, "", "  ")
+			require.NoError(t, err)
+			require.Equal(t, string(expectedJSON), string(gotJSON))
+		})
+	}
+}
+
+func TestParserMatchesParticipleOnErrors(t *testing.T) {
+	inputs := map[string]string{
+		"two versions":              "version 1 version: 2",
+		"float version":             "version 1.0",
+		"missing colon":             "type A {\n  a string\n}",
+		"missing type":              "type A {\n  a: \n}",
+		"unclosed type":             "type A {\n  a: string\n",
+		"unclosed array":            "type A {\n  a: string[\n}",
+		"trailing brackets":         "type A {}[]",
+		"keyword as field name":     "type A {\n  type: string\n}",
+		"field docstring and blank": "type A {\n  \"\"\" d \"\"\"\n\n  a: string\n}",
+		"deprecated without decl":   "deprecated version 1",
+		"unknown proc child":        "proc P {\n  a: string\n}",
+		"illegal token":             "type A { a: string } $",
+	}
+
+	for name, input := range inputs {
+		t.Run(name, func(t *testing.T) {
+			_, expectedErr := participleParserInstance.ParseString("schema.urpc", input)
+			require.Error(t, expectedErr)
+			_, gotErr := ParserInstance.ParseString("schema.urpc", input)
+			require.Error(t, gotErr)
+		})
+	}
+}
+
+func TestParserErrorMessages(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"version 1 version: 2", `schema.urpc:1:18: expected version number after 'version', got ":"`},
+		{"type A {\n  a string\n}", `schema.urpc:2:5: expected ':' after field name, got "string"`},
+		{"type A {\n  a: \n}", `schema.urpc:3:1: expected field type after ':', got "}"`},
+		{"type A {\n  a: string\n", `schema.urpc:3:1: expected '}' to close type "A", got end of file`},
+		{"type A {\n  a: string[\n}", `schema.urpc:3:1: expected ']' after '[', got "}"`},
+		{"proc P {\n  a: string\n}", `schema.urpc:2:3: expected 'input', 'output' or '}' in procedure "P", got "a"`},
+		{"deprecated version 1", `schema.urpc:1:12: expected 'type', 'proc' or 'stream' after 'deprecated', got "version"`},
+		{"type {}", `schema.urpc:1:6: expected type name after 'type', got "{"`},
+		{"stream S input {}", `schema.urpc:1:10: expected '{' after stream "S", got "input"`},
+		{"type A {}[]", `schema.urpc:1:10: expected a declaration (version, type, proc, stream), comment or docstring, got "["`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := ParserInstance.ParseString("schema.urpc", tt.input)
+			require.EqualError(t, err, tt.expected)
+
+			var parserErr Error
+			require.ErrorAs(t, err, &parserErr)
+		})
+	}
+}
+
+// benchmarkSchema returns a large schema built from the repo fixtures.
+func benchmarkSchema(b *testing.B) string {
+	b.Helper()
+
+	files := referenceFiles(b)
+	names := slices.Sorted(maps.Keys(files))
+
+	var sb strings.Builder
+	for i, name := range names {
+		content := files[name]
+		if _, err := participleParserInstance.ParseString("schema.urpc", content); err != nil {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("// fixture %d\n", i))
+		sb.WriteString(content)
+		sb.WriteString("\n\n")
+	}
+	return sb.String()
+}
+
+func BenchmarkParser(b *testing.B) {
+	input := benchmarkSchema(b)
+	b.SetBytes(int64(len(input)))
+	b.ResetTimer()
+
+	for b.Loop() {
+		if _, err := ParserInstance.ParseString("schema.urpc", input); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParserParticiple(b *testing.B) {
+	input := benchmarkSchema(b)
+	b.SetBytes(int64(len(input)))
+	b.ResetTimer()
+
+	for b.Loop() {
+		if _, err := participleParserInstance.ParseString("schema.urpc", input); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package parser
+
+import (
+	"errors"
+
+	"github.com/uforg/uforpc/urpc/internal/urpc/ast"
+	"github.com/uforg/uforpc/urpc/internal/urpc/token"
+)
+
+// ParseWithRecovery parses the given input and recovers from syntax errors at
+// declaration boundaries (the type, proc, stream and version keywords and
+// the closing braces of top level declarations).
+//
+// The input is split with SplitSegments and every segment is lexed and
+// parsed in a single pass. When a top level node fails to parse, the parser
+// resynchronizes at the next declaration keyword of the segment, including
+// its docstring and deprecation, and continues from there, so the cost does
+// not grow with the number of errors.
+//
+// Returns:
+//   - The partial schema with all the declarations that could be parsed.
+//   - All the syntax errors found, in the order they were found.
+func ParseWithRecovery(filename, input string) (*ast.Schema, []Error) {
+	segments := SplitSegments(input)
+	parsed := make([]*ParsedSegment, 0, len(segments))
+	for _, segment := range segments {
+		parsed = append(parsed, ParseSegment(filename, segment))
+	}
+	return JoinSegments(parsed)
+}
+
+// asError converts an error returned while parsing to an Error.
+func (ps *parserState) asError(err error) Error {
+	var parserErr Error
+	if !errors.As(err, &parserErr) {
+		parserErr = &parseError{msg: err.Error(), pos: ps.position(ps.peek())}
+	}
+	return parserErr
+}
+
+// recoveryIndex returns the index of the token where the parsing continues
+// after a top level node that starts at startIndex failed at errIndex.
+//
+// It is the start of the next declaration after the error, including its
+// docstring and deprecation, or the end of the input if there is none. It
+// is always after startIndex so the parser makes progress.
+func (ps *parserState) recoveryIndex(startIndex int, errIndex int) int {
+	for i := max(errIndex, startIndex+1); i < len(ps.tokens); i++ {
+		if isDeclarationKeyword(ps.tokens[i].Type) {
+			return declarationStart(ps.tokens, i, startIndex+1)
+		}
+	}
+	return len(ps.tokens) - 1
+}
+
+// isDeclarationKeyword returns true if the token starts a top level declaration.
+func isDeclarationKeyword(tokType token.TokenType) bool {
+	switch tokType {
+	case token.Version, token.Type, token.Proc, token.Stream:
+		return true
+	}
+	return false
+}
+
+// isDeclarationPrefix returns true if the token can precede a declaration
+// keyword and is part of that declaration (docstrings and deprecation).
+func isDeclarationPrefix(tokType token.TokenType) bool {
+	switch tokType {
+	case token.Docstring, token.Deprecated, token.LParen, token.StringLiteral, token.RParen,
+		token.Whitespace, token.Newline:
+		return true
+	}
+	return false
+}
+
+// declarationStart extends the start of the declaration at the given keyword
+// index backwards to include its docstring and deprecation, never going before
+// the given lower bound.
+func declarationStart(tokens []token.Token, keywordIndex int, lowerBound int) int {
+	start := keywordIndex
+	for i := keywordIndex - 1; i >= lowerBound; i-- {
+		if !isDeclarationPrefix(tokens[i].Type) {
+			break
+		}
+		if tokens[i].Type != token.Whitespace && tokens[i].Type != token.Newline {
+			start = i
+		}
+	}
+	return start
+}
@@ -0,0 +1,137 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseWithRecovery(t *testing.T) {
+	t.Run("Valid schema returns no errors", func(t *testing.T) {
+		input := `
+			version 1
+
+			type User {
+				id: string
+			}
+		`
+		schema, errs := ParseWithRecovery("schema.urpc", input)
+		require.Empty(t, errs)
+		require.Len(t, schema.GetTypes(), 1)
+	})
+
+	t.Run("Recovers after broken declarations", func(t *testing.T) {
+		input := "version 1\n" +
+			"type A {\n" +
+			"  x: \n" +
+			"}\n" +
+			"type B {\n" +
+			"  y: string\n" +
+			"}\n" +
+			"proc C {\n" +
+			"  input { z: }\n" +
+			"}\n" +
+			"stream D {}\n"
+
+		schema, errs := ParseWithRecovery("schema.urpc", input)
+		require.Len(t, errs, 2)
+		require.Equal(t, 4, errs[0].Position().Line)
+		require.Equal(t, 9, errs[1].Position().Line)
+
+		require.Len(t, schema.GetVersions(), 1)
+		require.Len(t, schema.GetTypes(), 1)
+		require.Equal(t, "B", schema.GetTypes()[0].Name)
+		require.Equal(t, 5, schema.GetTypes()[0].Pos.Line)
+		require.Empty(t, schema.GetProcs())
+		require.Len(t, schema.GetStreams(), 1)
+	})
+
+	t.Run("Recovers from unclosed declaration", func(t *testing.T) {
+		input := "type A {\n" +
+			"  x: string\n" +
+			"\n" +
+			"type B {\n" +
+			"  y: string\n" +
+			"}\n"
+
+		schema, errs := ParseWithRecovery("schema.urpc", input)
+		require.Len(t, errs, 1)
+		require.Len(t, schema.GetTypes(), 1)
+		require.Equal(t, "B", schema.GetTypes()[0].Name)
+	})
+
+	t.Run("Recovers after extra closing brace", func(t *testing.T) {
+		input := "type A {\n" +
+			"  x: string\n" +
+			"}\n" +
+			"}\n" +
+			"type B {\n" +
+			"  y: string\n" +
+			"}\n"
+
+		schema, errs := ParseWithRecovery("schema.urpc", input)
+		require.Len(t, errs, 1)
+		require.Len(t, schema.GetTypes(), 2)
+	})
+
+	t.Run("Keeps docstring and deprecation of the next declaration", func(t *testing.T) {
+		input := "type A {\n" +
+			"  x string\n" +
+			"}\n" +
+			"\"\"\" B docs \"\"\"\n" +
+			"deprecated(\"old\") type B {\n" +
+			"  y: string\n" +
+			"}\n"
+
+		schema, errs := ParseWithRecovery("schema.urpc", input)
+		require.Len(t, errs, 1)
+		require.Len(t, schema.GetTypes(), 1)
+		require.NotNil(t, schema.GetTypes()[0].Docstring)
+		require.NotNil(t, schema.GetTypes()[0].Deprecated)
+	})
+
+	t.Run("Reports every broken declaration", func(t *testing.T) {
+		schema, errs := ParseWithRecovery("schema.urpc", brokenSchema(50))
+		require.Len(t, errs, 50)
+		require.Len(t, schema.GetTypes(), 50)
+		for i, err := range errs {
+			require.Equal(t, 8*i+9, err.Position().Line)
+		}
+	})
+
+	t.Run("Unrecoverable input returns an empty schema", func(t *testing.T) {
+		schema, errs := ParseWithRecovery("schema.urpc", "garbage")
+		require.NotEmpty(t, errs)
+		require.NotNil(t, schema)
+		require.Empty(t, schema.Children)
+	})
+}
+
+// brokenSchema generates a schema where every valid type is followed by a
+// type with a missing field type.
+func brokenSchema(count int) string {
+	var sb strings.Builder
+	sb.WriteString("version 1\n\n")
+	for i := range count {
+		fmt.Fprintf(&sb, "type Valid%d {\n  id: string\n}\n\n", i)
+		fmt.Fprintf(&sb, "type Broken%d {\n  id: \n}\n\n", i)
+	}
+	return sb.String()
+}
+
+func BenchmarkParseWithRecovery(b *testing.B) {
+	for _, count := range []int{10, 100, 1000} {
+		b.Run(fmt.Sprintf("BrokenDeclarations%d", count), func(b *testing.B) {
+			input := brokenSchema(count)
+			b.SetBytes(int64(len(input)))
+
+			for b.Loop() {
+				if _, errs := ParseWithRecovery("schema.urpc", input); len(errs) != count {
+					b.Fatalf("expected %d errors, got %d", count, len(errs))
+				}
+			}
+		})
+	}
+}
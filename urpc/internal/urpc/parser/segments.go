@@ -0,0 +1,224 @@
+package parser
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/uforg/uforpc/urpc/internal/urpc/ast"
+	"github.com/uforg/uforpc/urpc/internal/urpc/token"
+)
+
+// Segment is a part of a schema that is parsed independently of the rest,
+// see SplitSegments.
+type Segment struct {
+	// Input is the source of the segment.
+	Input string
+	// Line is the line where the segment starts in the schema.
+	Line int
+	// Column is the column where the segment starts in the schema.
+	Column int
+}
+
+// ParsedSegment is the result of parsing a segment.
+type ParsedSegment struct {
+	// Children are the top level nodes that could be parsed.
+	Children []*ast.SchemaChild
+	// Errors are the syntax errors found in the segment.
+	Errors []Error
+	// Pos is the position of the first token of the segment that is not
+	// whitespace, or the end of the segment if there is none.
+	Pos ast.Position
+	// EndPos is the position right after the last character of the segment.
+	EndPos ast.Position
+}
+
+// SplitSegments splits the input at top level declaration boundaries, so
+// every segment can be parsed on its own and an edit only affects the
+// segment that contains it:
+//
+//   - Right after the closing brace of every top level declaration.
+//   - Before a line that starts with a type, proc or stream keyword while
+//     a declaration is still open, so an unclosed declaration does not
+//     swallow the rest of the schema.
+//
+// Braces inside strings, docstrings and comments are ignored. Joining the
+// inputs of all the segments returns the original input.
+func SplitSegments(input string) []Segment {
+	var segments []Segment
+	segmentStart := 0
+	segmentLine, segmentColumn := 1, 1
+	line, column := 1, 1
+	depth := 0
+
+	split := func(offset int) {
+		if offset == segmentStart {
+			return
+		}
+		segments = append(segments, Segment{
+			Input:  input[segmentStart:offset],
+			Line:   segmentLine,
+			Column: segmentColumn,
+		})
+		segmentStart = offset
+		segmentLine, segmentColumn = line, column
+	}
+
+	// advance moves the current position n runes forward
+	offset := 0
+	advance := func(n int) {
+		for ; n > 0 && offset < len(input); n-- {
+			r, size := rune(input[offset]), 1
+			if r >= utf8.RuneSelf {
+				r, size = utf8.DecodeRuneInString(input[offset:])
+			}
+			offset += size
+			if r == '\n' {
+				line++
+				column = 1
+			} else {
+				column++
+			}
+		}
+	}
+
+	// skipUntil moves the current position past the given terminator, or to
+	// the end of the input if it is not found
+	skipUntil := func(terminator string) {
+		for offset < len(input) && !strings.HasPrefix(input[offset:], terminator) {
+			advance(1)
+		}
+		advance(utf8.RuneCountInString(terminator))
+	}
+
+	for offset < len(input) {
+		rest := input[offset:]
+
+		switch c := rest[0]; {
+		case column == 1 && depth > 0 && startsWithDeclarationKeyword(rest):
+			split(offset)
+			depth = 0
+			advance(1)
+
+		case c == '"' && strings.HasPrefix(rest, `"""`):
+			advance(3)
+			skipUntil(`"""`)
+
+		case c == '"':
+			// Same escapes as the lexer, any other backslash ends the string
+			advance(1)
+			for offset < len(input) && input[offset] != '"' {
+				if input[offset] == '\\' {
+					if offset+1 < len(input) && (input[offset+1] == '"' || input[offset+1] == '\\') {
+						advance(2)
+						continue
+					}
+					break
+				}
+				advance(1)
+			}
+			advance(1)
+
+		case c == '/' && strings.HasPrefix(rest, "//"):
+			advance(2)
+			for offset < len(input) && input[offset] != '\n' {
+				advance(1)
+			}
+
+		case c == '/' && strings.HasPrefix(rest, "/*"):
+			advance(2)
+			skipUntil("*/")
+
+		case c == '{':
+			depth++
+			advance(1)
+
+		case c == '}':
+			advance(1)
+			if depth > 0 {
+				depth--
+				if depth == 0 {
+					split(offset)
+				}
+			}
+
+		default:
+			advance(1)
+		}
+	}
+
+	split(offset)
+	if len(segments) == 0 {
+		segments = append(segments, Segment{Input: input, Line: 1, Column: 1})
+	}
+	return segments
+}
+
+// startsWithDeclarationKeyword returns true if the input starts with a
+// type, proc or stream keyword.
+func startsWithDeclarationKeyword(input string) bool {
+	if input[0] != 't' && input[0] != 'p' && input[0] != 's' {
+		return false
+	}
+	for _, keyword := range []string{"type", "proc", "stream"} {
+		if !strings.HasPrefix(input, keyword) {
+			continue
+		}
+		if len(input) == len(keyword) {
+			return true
+		}
+		next := input[len(keyword)]
+		isIdentChar := 'a' <= next && next <= 'z' || 'A' <= next && next <= 'Z' || '0' <= next && next <= '9'
+		return !isIdentChar
+	}
+	return false
+}
+
+// ParseSegment parses a segment recovering from syntax errors, see
+// ParseWithRecovery. The positions of the nodes and the errors are relative
+// to the whole schema.
+func ParseSegment(filename string, segment Segment) *ParsedSegment {
+	ps := newParserStateAt(filename, segment.Input, segment.Line, segment.Column)
+	parsed := &ParsedSegment{Pos: ps.startPos()}
+
+	for ps.peek().Type != token.Eof {
+		startIndex := ps.peekIndex(0)
+		child, err := ps.parseSchemaChild()
+		if err == nil {
+			parsed.Children = append(parsed.Children, child)
+			continue
+		}
+
+		parsed.Errors = append(parsed.Errors, ps.asError(err))
+		ps.cursor = ps.recoveryIndex(startIndex, ps.peekIndex(0))
+	}
+
+	parsed.EndPos = ps.endPos()
+	return parsed
+}
+
+// JoinSegments builds the schema from its parsed segments, in the same
+// order returned by SplitSegments.
+//
+// The children of the segments are shared with the returned schema.
+func JoinSegments(segments []*ParsedSegment) (*ast.Schema, []Error) {
+	schema := &ast.Schema{}
+	var errs []Error
+
+	hasStart := false
+	for _, segment := range segments {
+		if !hasStart && segment.Pos != segment.EndPos {
+			schema.Pos = segment.Pos
+			hasStart = true
+		}
+		schema.Children = append(schema.Children, segment.Children...)
+		errs = append(errs, segment.Errors...)
+	}
+
+	if len(segments) > 0 {
+		schema.EndPos = segments[len(segments)-1].EndPos
+		if !hasStart {
+			schema.Pos = schema.EndPos
+		}
+	}
+	return schema, errs
+}
@@ -0,0 +1,80 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitSegments(t *testing.T) {
+	t.Run("Splits after top level declarations", func(t *testing.T) {
+		input := "version 1\n" +
+			"type A {\n" +
+			"  b: {\n" +
+			"    c: string\n" +
+			"  }\n" +
+			"}\n" +
+			"// { not a brace\n" +
+			"/* } neither */\n" +
+			"deprecated(\"}\") proc P { input { x: string } }\n" +
+			"\"\"\" } \"\"\"\n" +
+			"stream S {}\n"
+
+		segments := SplitSegments(input)
+		require.Equal(t, []Segment{
+			{Input: "version 1\ntype A {\n  b: {\n    c: string\n  }\n}", Line: 1, Column: 1},
+			{Input: "\n// { not a brace\n/* } neither */\ndeprecated(\"}\") proc P { input { x: string } }", Line: 6, Column: 2},
+			{Input: "\n\"\"\" } \"\"\"\nstream S {}", Line: 9, Column: 47},
+			{Input: "\n", Line: 11, Column: 12},
+		}, segments)
+	})
+
+	t.Run("Splits before declarations after an unclosed one", func(t *testing.T) {
+		input := "type A {\n" +
+			"  x: string\n" +
+			"\n" +
+			"type B {\n" +
+			"  y: string\n" +
+			"}\n"
+
+		segments := SplitSegments(input)
+		require.Equal(t, []Segment{
+			{Input: "type A {\n  x: string\n\n", Line: 1, Column: 1},
+			{Input: "type B {\n  y: string\n}", Line: 4, Column: 1},
+			{Input: "\n", Line: 6, Column: 2},
+		}, segments)
+	})
+
+	t.Run("Joined segments are the input", func(t *testing.T) {
+		for name, input := range referenceFiles(t) {
+			var sb strings.Builder
+			for _, segment := range SplitSegments(input) {
+				sb.WriteString(segment.Input)
+			}
+			require.Equal(t, input, sb.String(), name)
+		}
+	})
+
+	t.Run("Empty input", func(t *testing.T) {
+		require.Equal(t, []Segment{{Input: "", Line: 1, Column: 1}}, SplitSegments(""))
+	})
+}
+
+func TestParseWithRecoveryMatchesParseString(t *testing.T) {
+	inputs := referenceInputs()
+	for name, content := range referenceFiles(t) {
+		inputs[name] = content
+	}
+
+	for name, input := range inputs {
+		expected, err := ParserInstance.ParseString("schema.urpc", input)
+		if err != nil {
+			continue
+		}
+
+		schema, errs := ParseWithRecovery("schema.urpc", input)
+		require.Empty(t, errs, name)
+		require.Equal(t, expected, schema, name)
+	}
+}
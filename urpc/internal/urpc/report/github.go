@@ -0,0 +1,72 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/uforg/uforpc/urpc/internal/urpc/analyzer"
+)
+
+// writeGitHub renders the diagnostics as GitHub Actions workflow commands,
+// so they are shown as annotations in the pull request files:
+//
+//	::error file=schema.urpc,line=4,col=6,endLine=4,endColumn=13,title=urpc::message
+func (r Report) writeGitHub(w io.Writer) error {
+	var sb strings.Builder
+	for _, diag := range r.Diagnostics {
+		title := "urpc"
+		if diag.Code != "" {
+			title = "urpc " + diag.Code
+		}
+
+		properties := []string{
+			"file=" + escapeGitHubProperty(filepath.ToSlash(diag.Pos.Filename)),
+			fmt.Sprintf("line=%d", diag.Pos.Line),
+			fmt.Sprintf("col=%d", diag.Pos.Column),
+		}
+		if diag.EndPos.Line >= diag.Pos.Line && diag.EndPos.Line > 0 {
+			properties = append(properties,
+				fmt.Sprintf("endLine=%d", diag.EndPos.Line),
+				fmt.Sprintf("endColumn=%d", diag.EndPos.Column),
+			)
+		}
+		properties = append(properties, "title="+escapeGitHubProperty(title))
+
+		fmt.Fprintf(&sb, "::%s %s::%s\n",
+			githubCommand(diag.Severity),
+			strings.Join(properties, ","),
+			escapeGitHubData(diag.Message),
+		)
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// githubCommand returns the workflow command of a severity.
+func githubCommand(severity analyzer.Severity) string {
+	switch severity {
+	case analyzer.SeverityWarning:
+		return "warning"
+	case analyzer.SeverityInfo:
+		return "notice"
+	default:
+		return "error"
+	}
+}
+
+// escapeGitHubData escapes the message of a workflow command.
+func escapeGitHubData(value string) string {
+	value = strings.ReplaceAll(value, "%", "%25")
+	value = strings.ReplaceAll(value, "\r", "%0D")
+	return strings.ReplaceAll(value, "\n", "%0A")
+}
+
+// escapeGitHubProperty escapes a property value of a workflow command.
+func escapeGitHubProperty(value string) string {
+	value = escapeGitHubData(value)
+	value = strings.ReplaceAll(value, ":", "%3A")
+	return strings.ReplaceAll(value, ",", "%2C")
+}
@@ -0,0 +1,82 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/uforg/uforpc/urpc/internal/urpc/analyzer"
+)
+
+// writeHuman renders the diagnostics as text with source snippets:
+//
+//	schema.urpc:4:6: error: type "Missing" referenced at type "A" is not declared
+//	   4 |   b: Missing
+//	     |      ^^^^^^^
+func (r Report) writeHuman(w io.Writer) error {
+	var sb strings.Builder
+	for _, diag := range r.Diagnostics {
+		sb.WriteString(diag.Pos.String())
+		sb.WriteString(": ")
+		sb.WriteString(diag.Severity.String())
+		sb.WriteString(": ")
+		sb.WriteString(diag.Message)
+		if diag.Code != "" {
+			sb.WriteString(" (")
+			sb.WriteString(diag.Code)
+			sb.WriteString(")")
+		}
+		sb.WriteString("\n")
+		sb.WriteString(r.snippet(diag))
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// snippet returns the source line of the diagnostic start position with
+// carets under the reported range, or an empty string if the source is not
+// available. Ranges spanning multiple lines are underlined until the end of
+// the first line.
+func (r Report) snippet(diag analyzer.Diagnostic) string {
+	source, ok := r.Sources[diag.Pos.Filename]
+	if !ok || diag.Pos.Line < 1 {
+		return ""
+	}
+
+	lines := strings.Split(source, "\n")
+	if diag.Pos.Line > len(lines) {
+		return ""
+	}
+	line := []rune(strings.TrimRight(lines[diag.Pos.Line-1], "\r"))
+
+	// Columns are 1-based and counted in runes
+	start := max(diag.Pos.Column-1, 0)
+	start = min(start, len(line))
+	end := start + 1
+	if diag.EndPos.Line == diag.Pos.Line && diag.EndPos.Column > diag.Pos.Column {
+		end = diag.EndPos.Column - 1
+	} else if diag.EndPos.Line > diag.Pos.Line {
+		end = len(line)
+	}
+	end = max(min(end, len(line)), start+1)
+
+	// Tabs are kept so the carets stay aligned with the source line
+	var padding strings.Builder
+	for _, char := range line[:start] {
+		if char == '\t' {
+			padding.WriteRune('\t')
+		} else {
+			padding.WriteRune(' ')
+		}
+	}
+
+	lineNumber := strconv.Itoa(diag.Pos.Line)
+	gutter := strings.Repeat(" ", len(lineNumber))
+	return fmt.Sprintf(
+		" %s | %s\n %s | %s%s\n",
+		lineNumber, string(line),
+		gutter, padding.String(), strings.Repeat("^", end-start),
+	)
+}
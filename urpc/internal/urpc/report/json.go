@@ -0,0 +1,49 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/uforg/uforpc/urpc/internal/urpc/ast"
+)
+
+// jsonDiagnostic is the JSON representation of a diagnostic.
+type jsonDiagnostic struct {
+	File     string       `json:"file"`
+	Severity string       `json:"severity"`
+	Code     string       `json:"code,omitempty"`
+	Message  string       `json:"message"`
+	Start    jsonPosition `json:"start"`
+	End      jsonPosition `json:"end"`
+}
+
+// jsonPosition is the JSON representation of a position, line and column
+// are 1-based and the column is counted in characters.
+type jsonPosition struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+func newJSONPosition(pos ast.Position) jsonPosition {
+	return jsonPosition{Line: pos.Line, Column: pos.Column}
+}
+
+// writeJSON renders the diagnostics as an indented JSON array, an empty
+// report renders an empty array.
+func (r Report) writeJSON(w io.Writer) error {
+	diagnostics := make([]jsonDiagnostic, 0, len(r.Diagnostics))
+	for _, diag := range r.Diagnostics {
+		diagnostics = append(diagnostics, jsonDiagnostic{
+			File:     diag.Pos.Filename,
+			Severity: diag.Severity.String(),
+			Code:     diag.Code,
+			Message:  diag.Message,
+			Start:    newJSONPosition(diag.Pos),
+			End:      newJSONPosition(diag.EndPos),
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(diagnostics)
+}
@@ -0,0 +1,94 @@
+// Package report renders analyzer diagnostics in formats meant for humans
+// and for other tools.
+//
+// The supported formats are:
+//
+//   - human: one line per diagnostic followed by the source snippet with
+//     carets under the reported range.
+//   - json: a JSON array with one object per diagnostic.
+//   - sarif: a SARIF 2.1.0 log, suitable for code scanning uploads.
+//   - github: GitHub Actions workflow commands (::error file=...::message).
+package report
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/uforg/uforpc/urpc/internal/urpc/analyzer"
+)
+
+// Format is an output format of a report.
+type Format string
+
+// Format constants.
+const (
+	FormatHuman  Format = "human"
+	FormatJSON   Format = "json"
+	FormatSARIF  Format = "sarif"
+	FormatGitHub Format = "github"
+)
+
+// Formats is the list of all supported formats.
+var Formats = []Format{FormatHuman, FormatJSON, FormatSARIF, FormatGitHub}
+
+// ParseFormat returns the format with the given name.
+func ParseFormat(name string) (Format, error) {
+	format := Format(name)
+	if !slices.Contains(Formats, format) {
+		return "", fmt.Errorf("unknown format %q, must be one of %s", name, formatsList())
+	}
+	return format, nil
+}
+
+// formatsList returns the supported formats separated by commas.
+func formatsList() string {
+	names := make([]string, len(Formats))
+	for i, format := range Formats {
+		names[i] = string(format)
+	}
+	return strings.Join(names, ", ")
+}
+
+// Report is a set of diagnostics ready to be rendered.
+type Report struct {
+	// Diagnostics are rendered in the given order. The file names of their
+	// positions are printed as is, so they should already be relative to
+	// the working directory when needed.
+	Diagnostics []analyzer.Diagnostic
+	// Sources maps the file names of the diagnostics to their content, it
+	// is used to print source snippets. Missing files print no snippet.
+	Sources map[string]string
+}
+
+// Counts returns the number of diagnostics of each severity.
+func (r Report) Counts() (errors int, warnings int, infos int) {
+	for _, diag := range r.Diagnostics {
+		switch diag.Severity {
+		case analyzer.SeverityError:
+			errors++
+		case analyzer.SeverityWarning:
+			warnings++
+		case analyzer.SeverityInfo:
+			infos++
+		}
+	}
+	return errors, warnings, infos
+}
+
+// Write renders the report in the given format.
+func (r Report) Write(w io.Writer, format Format) error {
+	switch format {
+	case FormatHuman:
+		return r.writeHuman(w)
+	case FormatJSON:
+		return r.writeJSON(w)
+	case FormatSARIF:
+		return r.writeSARIF(w)
+	case FormatGitHub:
+		return r.writeGitHub(w)
+	default:
+		return fmt.Errorf("unknown format %q, must be one of %s", format, formatsList())
+	}
+}
@@ -0,0 +1,181 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/uforg/uforpc/urpc/internal/urpc/analyzer"
+	"github.com/uforg/uforpc/urpc/internal/urpc/ast"
+)
+
+func pos(line, column int) ast.Position {
+	return ast.Position{Filename: "rpc/schema.urpc", Line: line, Column: column}
+}
+
+func testReport() Report {
+	return Report{
+		Diagnostics: []analyzer.Diagnostic{
+			{
+				Positions: analyzer.Positions{Pos: pos(4, 6), EndPos: pos(4, 13)},
+				Message:   `type "Missing" referenced at type "A" is not declared`,
+			},
+			{
+				Positions: analyzer.Positions{Pos: pos(5, 3), EndPos: pos(5, 4)},
+				Message:   "field name should be camelCase, got: C",
+				Severity:  analyzer.SeverityWarning,
+				Code:      "field-camel-case",
+			},
+		},
+		Sources: map[string]string{
+			"rpc/schema.urpc": "version 1\n\ntype A {\n  b: Missing\n  C: string\n}\n",
+		},
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for _, format := range Formats {
+		parsed, err := ParseFormat(string(format))
+		require.NoError(t, err)
+		require.Equal(t, format, parsed)
+	}
+
+	_, err := ParseFormat("xml")
+	require.ErrorContains(t, err, `unknown format "xml"`)
+}
+
+func TestCounts(t *testing.T) {
+	errors, warnings, infos := testReport().Counts()
+	require.Equal(t, 1, errors)
+	require.Equal(t, 1, warnings)
+	require.Equal(t, 0, infos)
+}
+
+func TestWriteHuman(t *testing.T) {
+	t.Run("With sources", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, testReport().Write(&buf, FormatHuman))
+
+		expected := "" +
+			"rpc/schema.urpc:4:6: error: type \"Missing\" referenced at type \"A\" is not declared\n" +
+			" 4 |   b: Missing\n" +
+			"   |      ^^^^^^^\n" +
+			"rpc/schema.urpc:5:3: warning: field name should be camelCase, got: C (field-camel-case)\n" +
+			" 5 |   C: string\n" +
+			"   |   ^\n"
+		require.Equal(t, expected, buf.String())
+	})
+
+	t.Run("Without sources", func(t *testing.T) {
+		r := testReport()
+		r.Sources = nil
+
+		var buf bytes.Buffer
+		require.NoError(t, r.Write(&buf, FormatHuman))
+
+		expected := "" +
+			"rpc/schema.urpc:4:6: error: type \"Missing\" referenced at type \"A\" is not declared\n" +
+			"rpc/schema.urpc:5:3: warning: field name should be camelCase, got: C (field-camel-case)\n"
+		require.Equal(t, expected, buf.String())
+	})
+
+	t.Run("Tabs and multiline ranges", func(t *testing.T) {
+		r := Report{
+			Diagnostics: []analyzer.Diagnostic{{
+				Positions: analyzer.Positions{Pos: pos(2, 3), EndPos: pos(4, 2)},
+				Message:   "bad type",
+			}},
+			Sources: map[string]string{"rpc/schema.urpc": "version 1\n\ttype A {\n\t}\n"},
+		}
+
+		var buf bytes.Buffer
+		require.NoError(t, r.Write(&buf, FormatHuman))
+		require.Equal(t, "rpc/schema.urpc:2:3: error: bad type\n 2 | \ttype A {\n   | \t ^^^^^^^\n", buf.String())
+	})
+}
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, testReport().Write(&buf, FormatJSON))
+
+	var got []map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+	require.Len(t, got, 2)
+	require.Equal(t, "rpc/schema.urpc", got[0]["file"])
+	require.Equal(t, "error", got[0]["severity"])
+	require.NotContains(t, got[0], "code")
+	require.Equal(t, map[string]any{"line": 4.0, "column": 6.0}, got[0]["start"])
+	require.Equal(t, map[string]any{"line": 4.0, "column": 13.0}, got[0]["end"])
+	require.Equal(t, "warning", got[1]["severity"])
+	require.Equal(t, "field-camel-case", got[1]["code"])
+
+	t.Run("Empty report", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, Report{}.Write(&buf, FormatJSON))
+		require.Equal(t, "[]\n", buf.String())
+	})
+}
+
+func TestWriteSARIF(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, testReport().Write(&buf, FormatSARIF))
+
+	var got sarifLog
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+	require.Equal(t, "2.1.0", got.Version)
+	require.Equal(t, sarifSchema, got.Schema)
+	require.Len(t, got.Runs, 1)
+
+	run := got.Runs[0]
+	require.Equal(t, "urpc", run.Tool.Driver.Name)
+	require.Equal(t, []sarifRule{{ID: "invalid-schema"}, {ID: "field-camel-case"}}, run.Tool.Driver.Rules)
+	require.Equal(t, []sarifResult{
+		{
+			RuleID:    "invalid-schema",
+			RuleIndex: 0,
+			Level:     "error",
+			Message:   sarifMessage{Text: `type "Missing" referenced at type "A" is not declared`},
+			Locations: []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: "rpc/schema.urpc", URIBaseID: "%SRCROOT%"},
+				Region:           sarifRegion{StartLine: 4, StartColumn: 6, EndLine: 4, EndColumn: 13},
+			}}},
+		},
+		{
+			RuleID:    "field-camel-case",
+			RuleIndex: 1,
+			Level:     "warning",
+			Message:   sarifMessage{Text: "field name should be camelCase, got: C"},
+			Locations: []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: "rpc/schema.urpc", URIBaseID: "%SRCROOT%"},
+				Region:           sarifRegion{StartLine: 5, StartColumn: 3, EndLine: 5, EndColumn: 4},
+			}}},
+		},
+	}, run.Results)
+
+	t.Run("Absolute paths", func(t *testing.T) {
+		require.Equal(t, sarifArtifactLocation{URI: "file:///rpc/my%20schema.urpc"}, sarifArtifact("/rpc/my schema.urpc"))
+	})
+
+	t.Run("Empty report has empty results", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, Report{}.Write(&buf, FormatSARIF))
+		require.Contains(t, buf.String(), `"results": []`)
+		require.Contains(t, buf.String(), `"rules": []`)
+	})
+}
+
+func TestWriteGitHub(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, testReport().Write(&buf, FormatGitHub))
+
+	expected := "" +
+		"::error file=rpc/schema.urpc,line=4,col=6,endLine=4,endColumn=13,title=urpc::type \"Missing\" referenced at type \"A\" is not declared\n" +
+		"::warning file=rpc/schema.urpc,line=5,col=3,endLine=5,endColumn=4,title=urpc field-camel-case::field name should be camelCase, got: C\n"
+	require.Equal(t, expected, buf.String())
+
+	t.Run("Escaping", func(t *testing.T) {
+		require.Equal(t, "100%25%0Adone", escapeGitHubData("100%\ndone"))
+		require.Equal(t, "a%3Ab%2Cc", escapeGitHubProperty("a:b,c"))
+	})
+}
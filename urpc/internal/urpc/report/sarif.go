@@ -0,0 +1,167 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+	"net/url"
+	"path/filepath"
+
+	"github.com/uforg/uforpc/urpc/internal/urpc/analyzer"
+	"github.com/uforg/uforpc/urpc/internal/version"
+)
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+	// sarifSourceRoot is the base id of the relative artifact locations, the
+	// code scanning tools resolve it to the root of the repository.
+	sarifSourceRoot = "%SRCROOT%"
+	// sarifDefaultRuleID is the rule of the diagnostics without a code,
+	// those are the analyzer errors that make a schema invalid.
+	sarifDefaultRuleID = "invalid-schema"
+)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID string `json:"id"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+// sarifRegion uses 1-based lines and columns, the end column is exclusive
+// like in the analyzer positions.
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn"`
+	EndLine     int `json:"endLine,omitempty"`
+	EndColumn   int `json:"endColumn,omitempty"`
+}
+
+// writeSARIF renders the diagnostics as a SARIF 2.1.0 log with a single run.
+func (r Report) writeSARIF(w io.Writer) error {
+	rules := []sarifRule{}
+	ruleIndexes := map[string]int{}
+	results := make([]sarifResult, 0, len(r.Diagnostics))
+
+	for _, diag := range r.Diagnostics {
+		ruleID := diag.Code
+		if ruleID == "" {
+			ruleID = sarifDefaultRuleID
+		}
+		ruleIndex, ok := ruleIndexes[ruleID]
+		if !ok {
+			ruleIndex = len(rules)
+			ruleIndexes[ruleID] = ruleIndex
+			rules = append(rules, sarifRule{ID: ruleID})
+		}
+
+		region := sarifRegion{
+			StartLine:   max(diag.Pos.Line, 1),
+			StartColumn: max(diag.Pos.Column, 1),
+		}
+		if diag.EndPos.Line > diag.Pos.Line || (diag.EndPos.Line == diag.Pos.Line && diag.EndPos.Column > diag.Pos.Column) {
+			region.EndLine = diag.EndPos.Line
+			region.EndColumn = diag.EndPos.Column
+		}
+
+		results = append(results, sarifResult{
+			RuleID:    ruleID,
+			RuleIndex: ruleIndex,
+			Level:     sarifLevel(diag.Severity),
+			Message:   sarifMessage{Text: diag.Message},
+			Locations: []sarifLocation{{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifact(diag.Pos.Filename),
+					Region:           region,
+				},
+			}},
+		})
+	}
+
+	log := sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           "urpc",
+				Version:        version.Version,
+				InformationURI: "https://github.com/uforg/uforpc",
+				Rules:          rules,
+			}},
+			Results: results,
+		}},
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(log)
+}
+
+// sarifLevel returns the SARIF level of a severity.
+func sarifLevel(severity analyzer.Severity) string {
+	switch severity {
+	case analyzer.SeverityWarning:
+		return "warning"
+	case analyzer.SeverityInfo:
+		return "note"
+	default:
+		return "error"
+	}
+}
+
+// sarifArtifact returns the location of a file, relative paths are
+// resolved against the source root and absolute paths use a file URI.
+func sarifArtifact(filename string) sarifArtifactLocation {
+	path := filepath.ToSlash(filename)
+	if filepath.IsAbs(filename) {
+		fileURL := url.URL{Scheme: "file", Path: path}
+		return sarifArtifactLocation{URI: fileURL.String()}
+	}
+	fileURL := url.URL{Path: path}
+	return sarifArtifactLocation{URI: fileURL.String(), URIBaseID: sarifSourceRoot}
+}
@@ -0,0 +1,150 @@
+package filepathutil
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Match reports whether name matches the shell pattern.
+//
+// It supports the same syntax as filepath.Match and additionally the "**"
+// path segment, that matches zero or more directories. Both the pattern
+// and the name are split using forward slashes after converting them with
+// filepath.ToSlash.
+//
+// E.g. "rpc/**/*.urpc" matches "rpc/a.urpc" and "rpc/v1/users/b.urpc".
+func Match(pattern string, name string) (bool, error) {
+	patternSegments := splitSegments(pattern)
+	nameSegments := splitSegments(name)
+
+	// Validate the pattern upfront so errors are reported even when the
+	// matching stops before reaching the malformed segment
+	for _, segment := range patternSegments {
+		if _, err := filepath.Match(segment, ""); err != nil {
+			return false, err
+		}
+	}
+
+	return matchSegments(patternSegments, nameSegments), nil
+}
+
+// Glob returns the sorted paths of all the files matching the pattern, or
+// nil if there is no matching file. Directories are never returned.
+//
+// It supports the same syntax as Match, so "./rpc/**/*.urpc" returns all
+// the .urpc files inside the ./rpc directory and its subdirectories.
+func Glob(pattern string) ([]string, error) {
+	if _, err := Match(pattern, ""); err != nil {
+		return nil, err
+	}
+
+	var matches []string
+	if !strings.Contains(pattern, "**") {
+		globMatches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, match := range globMatches {
+			if info, err := os.Stat(match); err == nil && !info.IsDir() {
+				matches = append(matches, match)
+			}
+		}
+		return matches, nil
+	}
+
+	base, rest := splitGlobBase(pattern)
+	restSegments := splitSegments(rest)
+
+	walkRoot := base
+	if walkRoot == "" {
+		walkRoot = "."
+	}
+
+	err := filepath.WalkDir(walkRoot, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) && path == walkRoot {
+				return filepath.SkipAll
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(walkRoot, path)
+		if err != nil {
+			return err
+		}
+		if matchSegments(restSegments, splitSegments(rel)) {
+			matches = append(matches, filepath.Join(base, rel))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slices.Sort(matches)
+	return matches, nil
+}
+
+// splitGlobBase splits a pattern into the leading directory without any
+// glob meta characters and the rest of the pattern.
+func splitGlobBase(pattern string) (string, string) {
+	segments := strings.Split(filepath.ToSlash(pattern), "/")
+
+	baseSegments := []string{}
+	for i, segment := range segments {
+		if strings.ContainsAny(segment, `*?[\`) {
+			return filepath.FromSlash(strings.Join(baseSegments, "/")), strings.Join(segments[i:], "/")
+		}
+		baseSegments = append(baseSegments, segment)
+	}
+
+	return filepath.FromSlash(strings.Join(baseSegments, "/")), ""
+}
+
+// splitSegments splits a path into its segments, ignoring empty and "."
+// segments.
+func splitSegments(path string) []string {
+	segments := []string{}
+	for segment := range strings.SplitSeq(filepath.ToSlash(path), "/") {
+		if segment == "" || segment == "." {
+			continue
+		}
+		segments = append(segments, segment)
+	}
+	return segments
+}
+
+// matchSegments matches the path segments against the pattern segments.
+func matchSegments(pattern []string, name []string) bool {
+	if len(pattern) == 0 {
+		return len(name) == 0
+	}
+
+	if pattern[0] == "**" {
+		for i := 0; i <= len(name); i++ {
+			if matchSegments(pattern[1:], name[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(name) == 0 {
+		return false
+	}
+
+	matched, err := filepath.Match(pattern[0], name[0])
+	if err != nil || !matched {
+		return false
+	}
+
+	return matchSegments(pattern[1:], name[1:])
+}
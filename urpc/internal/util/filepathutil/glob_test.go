@@ -0,0 +1,99 @@
+package filepathutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatch(t *testing.T) {
+	testCases := []struct {
+		pattern  string
+		name     string
+		expected bool
+	}{
+		{"*.urpc", "a.urpc", true},
+		{"*.urpc", "rpc/a.urpc", false},
+		{"rpc/*.urpc", "rpc/a.urpc", true},
+		{"rpc/**/*.urpc", "rpc/a.urpc", true},
+		{"rpc/**/*.urpc", "rpc/v1/users/a.urpc", true},
+		{"rpc/**/*.urpc", "other/a.urpc", false},
+		{"**/*.urpc", "a.urpc", true},
+		{"**/*.urpc", "a/b/c.urpc", true},
+		{"**", "a/b/c.urpc", true},
+		{"rpc/**", "rpc", true},
+		{"./rpc/**/*.urpc", "rpc/a.urpc", true},
+		{"**/generated/**", "rpc/generated/a.urpc", true},
+		{"rpc/?.urpc", "rpc/ab.urpc", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.pattern+" "+tc.name, func(t *testing.T) {
+			matched, err := Match(tc.pattern, tc.name)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, matched)
+		})
+	}
+
+	t.Run("Malformed pattern", func(t *testing.T) {
+		_, err := Match("rpc/[a/*.urpc", "other/a.urpc")
+		require.ErrorIs(t, err, filepath.ErrBadPattern)
+	})
+}
+
+func TestGlob(t *testing.T) {
+	dir := t.TempDir()
+	files := []string{
+		"a.urpc",
+		"b.txt",
+		"rpc/c.urpc",
+		"rpc/v1/d.urpc",
+		"rpc/v1/e.txt",
+	}
+	for _, file := range files {
+		path := filepath.Join(dir, file)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte{}, 0644))
+	}
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "dir.urpc"), 0755))
+
+	join := func(paths ...string) []string {
+		for i, path := range paths {
+			paths[i] = filepath.Join(dir, path)
+		}
+		return paths
+	}
+
+	t.Run("Without double star", func(t *testing.T) {
+		matches, err := Glob(filepath.Join(dir, "*.urpc"))
+		require.NoError(t, err)
+		require.Equal(t, join("a.urpc"), matches)
+	})
+
+	t.Run("With double star", func(t *testing.T) {
+		matches, err := Glob(filepath.Join(dir, "**", "*.urpc"))
+		require.NoError(t, err)
+		require.Equal(t, join("a.urpc", "rpc/c.urpc", "rpc/v1/d.urpc"), matches)
+	})
+
+	t.Run("With double star in the middle", func(t *testing.T) {
+		matches, err := Glob(filepath.Join(dir, "rpc", "**", "*.txt"))
+		require.NoError(t, err)
+		require.Equal(t, join("rpc/v1/e.txt"), matches)
+	})
+
+	t.Run("Relative pattern", func(t *testing.T) {
+		t.Chdir(dir)
+		matches, err := Glob("./rpc/**/*.urpc")
+		require.NoError(t, err)
+		require.Equal(t, []string{"rpc/c.urpc", "rpc/v1/d.urpc"}, matches)
+	})
+
+	t.Run("Missing base directory", func(t *testing.T) {
+		matches, err := Glob(filepath.Join(dir, "missing", "**", "*.urpc"))
+		require.NoError(t, err)
+		require.Empty(t, matches)
+	})
+}
@@ -0,0 +1,65 @@
+// Package fileutil provides helpers to work with files on disk.
+package fileutil
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// WriteFileIfChanged writes the content to the file only if the file does
+// not exist or its current content is different, so the modification time
+// of unchanged files is preserved. Parent directories are created if needed.
+//
+// The file is written atomically: the content is written to a temporary
+// file in the same directory that is then renamed, so readers never see a
+// partially written file.
+//
+// Returns true if the file was written.
+func WriteFileIfChanged(path string, content []byte, perm os.FileMode) (bool, error) {
+	current, err := os.ReadFile(path)
+	if err == nil && bytes.Equal(current, content) {
+		return false, nil
+	}
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return false, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return false, fmt.Errorf("failed to create directory for %s: %w", path, err)
+	}
+
+	if err := writeFileAtomic(path, content, perm); err != nil {
+		return false, fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	return true, nil
+}
+
+// writeFileAtomic writes the content to a temporary file next to path and
+// renames it to path.
+func writeFileAtomic(path string, content []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+
+	_, err = tmp.Write(content)
+	if err == nil {
+		err = tmp.Chmod(perm)
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, path)
+	}
+	if err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
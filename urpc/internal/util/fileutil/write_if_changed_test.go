@@ -0,0 +1,42 @@
+package fileutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteFileIfChanged(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "file.txt")
+
+	written, err := WriteFileIfChanged(path, []byte("hello"), 0644)
+	require.NoError(t, err)
+	require.True(t, written)
+
+	old := time.Now().Add(-time.Hour)
+	require.NoError(t, os.Chtimes(path, old, old))
+
+	written, err = WriteFileIfChanged(path, []byte("hello"), 0644)
+	require.NoError(t, err)
+	require.False(t, written)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.WithinDuration(t, old, info.ModTime(), time.Second)
+
+	written, err = WriteFileIfChanged(path, []byte("bye"), 0644)
+	require.NoError(t, err)
+	require.True(t, written)
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "bye", string(content))
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	require.Len(t, entries, 1, "temporary files must not be left behind")
+	require.Equal(t, os.FileMode(0644), info.Mode().Perm())
+}
@@ -0,0 +1,14 @@
+package strutil
+
+// ToKebabCase converts a string to kebab-case, it will interpret all
+// space like characters, underscores, dashes and the words of camelCase
+// and PascalCase strings as word boundaries.
+//
+// Example:
+//
+//	"hello world" -> "hello-world"
+//	"HelloWorld"  -> "hello-world"
+//	"helloWORLD"  -> "hello-world"
+func ToKebabCase(str string) string {
+	return joinPascalWords(str, '-')
+}
@@ -0,0 +1,28 @@
+package strutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToKebabCase(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"hello world", "hello-world"},
+		{"HelloWorld", "hello-world"},
+		{"helloWorld", "hello-world"},
+		{"hello_world", "hello-world"},
+		{"helloWORLD", "hello-world"},
+		{"GetUserById", "get-user-by-id"},
+		{"singleword", "singleword"},
+		{"", ""},
+	}
+
+	for _, test := range tests {
+		result := ToKebabCase(test.input)
+		assert.Equal(t, test.expected, result, "Input: %s", test.input)
+	}
+}
@@ -0,0 +1,35 @@
+package strutil
+
+import (
+	"strings"
+	"unicode"
+)
+
+// ToSnakeCase converts a string to snake_case, it will interpret all
+// space like characters, underscores, dashes and the words of camelCase
+// and PascalCase strings as word boundaries.
+//
+// Example:
+//
+//	"hello world" -> "hello_world"
+//	"HelloWorld"  -> "hello_world"
+//	"helloWORLD"  -> "hello_world"
+func ToSnakeCase(str string) string {
+	return joinPascalWords(str, '_')
+}
+
+// joinPascalWords splits the PascalCase version of the string in words and
+// joins them in lowercase with the separator.
+func joinPascalWords(str string, separator rune) string {
+	result := strings.Builder{}
+	for i, char := range ToPascalCase(str) {
+		if unicode.IsUpper(char) {
+			if i > 0 {
+				result.WriteRune(separator)
+			}
+			char = unicode.ToLower(char)
+		}
+		result.WriteRune(char)
+	}
+	return result.String()
+}
@@ -0,0 +1,33 @@
+package strutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToSnakeCase(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"hello world", "hello_world"},
+		{"HelloWorld", "hello_world"},
+		{"helloWorld", "hello_world"},
+		{"hello_world", "hello_world"},
+		{"hello-world", "hello_world"},
+		{"HELLO WORLD", "hello_world"},
+		{"helloWORLD", "hello_world"},
+		{"GetUserById", "get_user_by_id"},
+		{"hello123 world", "hello123_world"},
+		{"123hello world", "123hello_world"},
+		{"multiple__spaces", "multiple_spaces"},
+		{"singleword", "singleword"},
+		{"", ""},
+	}
+
+	for _, test := range tests {
+		result := ToSnakeCase(test.input)
+		assert.Equal(t, test.expected, result, "Input: %s", test.input)
+	}
+}
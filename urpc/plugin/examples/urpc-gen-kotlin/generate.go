@@ -0,0 +1,176 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/uforg/uforpc/urpc/plugin"
+)
+
+// options are the options of the plugin entry in uforpc.toml.
+type options struct {
+	// Package is the Kotlin package of the generated file.
+	Package string `json:"package"`
+	// FileName is the name of the generated file, defaults to Models.kt.
+	FileName string `json:"file_name"`
+}
+
+// kotlinKeywords are the hard keywords that must be escaped with backticks
+// when used as property names.
+var kotlinKeywords = []string{
+	"as", "break", "class", "continue", "do", "else", "false", "for", "fun",
+	"if", "in", "interface", "is", "null", "object", "package", "return",
+	"super", "this", "throw", "true", "try", "typealias", "typeof", "val",
+	"var", "when", "while",
+}
+
+func generate(req plugin.Request) (plugin.Response, error) {
+	opts := options{FileName: "Models.kt"}
+	if err := req.DecodeOptions(&opts); err != nil {
+		return plugin.Response{}, err
+	}
+	if opts.Package == "" {
+		return plugin.Response{}, fmt.Errorf(`the "package" option is required`)
+	}
+
+	res := plugin.Response{}
+	g := &generator{}
+	g.line(0, "// Code generated by urpc-gen-kotlin. DO NOT EDIT.")
+	g.line(0, "")
+	g.line(0, "package %s", opts.Package)
+	g.line(0, "")
+	g.line(0, "import kotlinx.serialization.Serializable")
+
+	for _, typeNode := range req.Schema.GetTypeNodes() {
+		g.line(0, "")
+		g.dataClass(0, typeNode.Name, typeNode.Doc, typeNode.Deprecated, typeNode.Fields)
+	}
+	for _, procNode := range req.Schema.GetProcNodes() {
+		g.line(0, "")
+		g.dataClass(0, procNode.Name+"Input", procNode.Doc, procNode.Deprecated, procNode.Input)
+		g.line(0, "")
+		g.dataClass(0, procNode.Name+"Output", nil, procNode.Deprecated, procNode.Output)
+	}
+	for _, streamNode := range req.Schema.GetStreamNodes() {
+		g.line(0, "")
+		g.dataClass(0, streamNode.Name+"Input", streamNode.Doc, streamNode.Deprecated, streamNode.Input)
+		g.line(0, "")
+		g.dataClass(0, streamNode.Name+"Output", nil, streamNode.Deprecated, streamNode.Output)
+	}
+
+	if len(req.Schema.GetTypeNodes())+len(req.Schema.GetProcNodes())+len(req.Schema.GetStreamNodes()) == 0 {
+		res.Warnf("the schema has no types, procedures or streams")
+	}
+
+	res.AddFile(opts.FileName, g.String())
+	return res, nil
+}
+
+type generator struct {
+	strings.Builder
+}
+
+func (g *generator) line(indent int, format string, args ...any) {
+	if format == "" {
+		g.WriteString("\n")
+		return
+	}
+	g.WriteString(strings.Repeat("    ", indent))
+	fmt.Fprintf(g, format, args...)
+	g.WriteString("\n")
+}
+
+func (g *generator) doc(indent int, doc *string) {
+	if doc == nil || strings.TrimSpace(*doc) == "" {
+		return
+	}
+	g.line(indent, "/**")
+	for _, docLine := range strings.Split(strings.TrimSpace(*doc), "\n") {
+		g.line(indent, " * %s", strings.ReplaceAll(docLine, "*/", "* /"))
+	}
+	g.line(indent, " */")
+}
+
+// dataClass writes a data class with a nested data class for every inline
+// object field.
+func (g *generator) dataClass(indent int, name string, doc *string, deprecated *string, fields []plugin.FieldDefinition) {
+	g.doc(indent, doc)
+	if deprecated != nil {
+		message := *deprecated
+		if message == "" {
+			message = "Deprecated"
+		}
+		g.line(indent, "@Deprecated(%q)", message)
+	}
+	g.line(indent, "@Serializable")
+
+	if len(fields) == 0 {
+		// Data classes require at least one property
+		g.line(indent, "class %s", name)
+		return
+	}
+
+	g.line(indent, "data class %s(", name)
+	for _, field := range fields {
+		g.doc(indent+1, field.Doc)
+		fieldType := kotlinType(field)
+		if field.Optional {
+			g.line(indent+1, "val %s: %s? = null,", propertyName(field.Name), fieldType)
+		} else {
+			g.line(indent+1, "val %s: %s,", propertyName(field.Name), fieldType)
+		}
+	}
+
+	inlineFields := slices.DeleteFunc(slices.Clone(fields), func(field plugin.FieldDefinition) bool { return !field.IsInline() })
+	if len(inlineFields) == 0 {
+		g.line(indent, ")")
+		return
+	}
+
+	g.line(indent, ") {")
+	for i, field := range inlineFields {
+		if i > 0 {
+			g.line(0, "")
+		}
+		g.dataClass(indent+1, inlineClassName(field.Name), nil, nil, field.TypeInline.Fields)
+	}
+	g.line(indent, "}")
+}
+
+func kotlinType(field plugin.FieldDefinition) string {
+	typeName := ""
+	switch {
+	case field.IsInline():
+		typeName = inlineClassName(field.Name)
+	case field.IsNamed():
+		switch *field.TypeName {
+		case "string", "datetime":
+			typeName = "String"
+		case "int":
+			typeName = "Long"
+		case "float":
+			typeName = "Double"
+		case "bool":
+			typeName = "Boolean"
+		default:
+			typeName = *field.TypeName
+		}
+	}
+
+	if field.IsArray {
+		return "List<" + typeName + ">"
+	}
+	return typeName
+}
+
+func inlineClassName(fieldName string) string {
+	return strings.ToUpper(fieldName[:1]) + fieldName[1:]
+}
+
+func propertyName(name string) string {
+	if slices.Contains(kotlinKeywords, name) {
+		return "`" + name + "`"
+	}
+	return name
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/uforg/uforpc/urpc/plugin"
+)
+
+func run(t *testing.T, request string) plugin.Response {
+	t.Helper()
+	var stdout bytes.Buffer
+	require.NoError(t, plugin.Serve(strings.NewReader(request), &stdout, generate))
+
+	res := plugin.Response{}
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &res))
+	return res
+}
+
+func TestGenerate(t *testing.T) {
+	res := run(t, `{
+  "protocolVersion": 1,
+  "options": {"package": "com.example.api"},
+  "outputDir": "/out",
+  "schema": {
+    "version": 1,
+    "nodes": [
+      {"kind": "doc", "content": "Standalone docs are ignored"},
+      {
+        "kind": "type", "name": "User", "doc": "A user of the app.",
+        "fields": [
+          {"name": "id", "typeName": "string", "isArray": false, "optional": false},
+          {"name": "scores", "typeName": "float", "isArray": true, "optional": false},
+          {"name": "object", "typeName": "bool", "isArray": false, "optional": true, "doc": "Escaped keyword."},
+          {"name": "address", "typeInline": {"fields": [
+            {"name": "zip", "typeName": "int", "isArray": false, "optional": false}
+          ]}, "isArray": false, "optional": true}
+        ]
+      },
+      {
+        "kind": "proc", "name": "GetUser", "deprecated": "Use FindUser",
+        "input": [{"name": "id", "typeName": "string", "isArray": false, "optional": false}],
+        "output": [{"name": "user", "typeName": "User", "isArray": false, "optional": false}]
+      },
+      {
+        "kind": "stream", "name": "Ticks",
+        "input": [],
+        "output": [{"name": "at", "typeName": "datetime", "isArray": false, "optional": false}]
+      }
+    ]
+  }
+}`)
+
+	require.Empty(t, res.Diagnostics)
+	require.Len(t, res.Files, 1)
+	require.Equal(t, "Models.kt", res.Files[0].Path)
+	require.Equal(t, `// Code generated by urpc-gen-kotlin. DO NOT EDIT.
+
+package com.example.api
+
+import kotlinx.serialization.Serializable
+
+/**
+ * A user of the app.
+ */
+@Serializable
+data class User(
+    val id: String,
+    val scores: List<Double>,
+    /**
+     * Escaped keyword.
+     */
+    val `+"`object`"+`: Boolean? = null,
+    val address: Address? = null,
+) {
+    @Serializable
+    data class Address(
+        val zip: Long,
+    )
+}
+
+@Deprecated("Use FindUser")
+@Serializable
+data class GetUserInput(
+    val id: String,
+)
+
+@Deprecated("Use FindUser")
+@Serializable
+data class GetUserOutput(
+    val user: User,
+)
+
+@Serializable
+class TicksInput
+
+@Serializable
+data class TicksOutput(
+    val at: String,
+)
+`, res.Files[0].Content)
+}
+
+func TestGenerateOptions(t *testing.T) {
+	request := `{"protocolVersion": 1, "options": %s, "outputDir": "/out", "schema": {"version": 1, "nodes": []}}`
+
+	res := run(t, strings.Replace(request, "%s", `{}`, 1))
+	require.Equal(t, []plugin.Diagnostic{{Severity: plugin.SeverityError, Message: `the "package" option is required`}}, res.Diagnostics)
+
+	res = run(t, strings.Replace(request, "%s", `{"package": "api", "file_name": "Api.kt"}`, 1))
+	require.Equal(t, "Api.kt", res.Files[0].Path)
+	require.Equal(t, []plugin.Diagnostic{{Severity: plugin.SeverityWarning, Message: "the schema has no types, procedures or streams"}}, res.Diagnostics)
+}
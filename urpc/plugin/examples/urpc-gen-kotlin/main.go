@@ -0,0 +1,17 @@
+// Command urpc-gen-kotlin is an example UFO RPC plugin that generates
+// Kotlin data classes for the types, inputs and outputs of a schema using
+// kotlinx.serialization.
+//
+// Install it with `go install` and declare it in uforpc.toml:
+//
+//	[[plugin]]
+//	command = "urpc-gen-kotlin"
+//	output_dir = "./ufogen/kotlin"
+//	options = { package = "com.example.api" }
+package main
+
+import "github.com/uforg/uforpc/urpc/plugin"
+
+func main() {
+	plugin.Main(generate)
+}
@@ -0,0 +1,180 @@
+// Package plugin is the SDK to write external UFO RPC code generators.
+//
+// A plugin is an executable declared in uforpc.toml:
+//
+//	[[plugin]]
+//	command = "urpc-gen-kotlin"
+//	output_dir = "./ufogen/kotlin"
+//	options = { package = "com.example.api" }
+//
+// When running `urpc generate` the plugin receives a JSON encoded Request in
+// its stdin and must write a JSON encoded Response to its stdout. The files
+// of the response are written by urpc inside the output directory, and the
+// diagnostics with severity "error" make the generation fail. Anything the
+// plugin writes to stderr is shown to the user.
+//
+// A minimal plugin:
+//
+//	func main() {
+//		plugin.Main(func(req plugin.Request) (plugin.Response, error) {
+//			res := plugin.Response{}
+//			for _, typeNode := range req.Schema.GetTypeNodes() {
+//				res.AddFile(typeNode.Name+".txt", typeNode.Name)
+//			}
+//			return res, nil
+//		})
+//	}
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/uforg/uforpc/urpc/internal/schema"
+)
+
+// ProtocolVersion is the version of the protocol between urpc and the
+// plugins, it changes only when a backwards incompatible change is made.
+const ProtocolVersion = 1
+
+// The schema intermediate representation received by the plugins.
+type (
+	Schema               = schema.Schema
+	Node                 = schema.Node
+	NodeDoc              = schema.NodeDoc
+	NodeType             = schema.NodeType
+	NodeProc             = schema.NodeProc
+	NodeStream           = schema.NodeStream
+	FieldDefinition      = schema.FieldDefinition
+	InlineTypeDefinition = schema.InlineTypeDefinition
+)
+
+// Request is the input of a plugin.
+type Request struct {
+	// ProtocolVersion is the version of the protocol used by urpc.
+	ProtocolVersion int `json:"protocolVersion"`
+	// Schema is the analyzed schema in its JSON representation.
+	Schema Schema `json:"schema"`
+	// Options are the options of the plugin entry in uforpc.toml.
+	Options map[string]any `json:"options"`
+	// OutputDir is the absolute path of the directory where the files of
+	// the response are written. Plugins should not write files directly.
+	OutputDir string `json:"outputDir"`
+}
+
+// DecodeOptions decodes the options into v using their JSON
+// representation, v is usually a pointer to a struct with json tags.
+func (r Request) DecodeOptions(v any) error {
+	optionsBytes, err := json.Marshal(r.Options)
+	if err != nil {
+		return fmt.Errorf("failed to encode options: %w", err)
+	}
+	if err := json.Unmarshal(optionsBytes, v); err != nil {
+		return fmt.Errorf("invalid options: %w", err)
+	}
+	return nil
+}
+
+// Severities of the diagnostics.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityInfo    = "info"
+)
+
+// Diagnostic is a message of the plugin for the user.
+type Diagnostic struct {
+	// Severity is one of "error", "warning" or "info".
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+// File is a generated file.
+type File struct {
+	// Path is the slash separated path relative to the output directory.
+	Path    string `json:"path"`
+	Content string `json:"content"`
+}
+
+// Response is the output of a plugin.
+type Response struct {
+	Files       []File       `json:"files"`
+	Diagnostics []Diagnostic `json:"diagnostics"`
+}
+
+// AddFile adds a generated file to the response.
+func (r *Response) AddFile(path string, content string) {
+	r.Files = append(r.Files, File{Path: path, Content: content})
+}
+
+// Errorf adds a diagnostic with severity "error" to the response.
+func (r *Response) Errorf(format string, args ...any) {
+	r.Diagnostics = append(r.Diagnostics, Diagnostic{Severity: SeverityError, Message: fmt.Sprintf(format, args...)})
+}
+
+// Warnf adds a diagnostic with severity "warning" to the response.
+func (r *Response) Warnf(format string, args ...any) {
+	r.Diagnostics = append(r.Diagnostics, Diagnostic{Severity: SeverityWarning, Message: fmt.Sprintf(format, args...)})
+}
+
+// Infof adds a diagnostic with severity "info" to the response.
+func (r *Response) Infof(format string, args ...any) {
+	r.Diagnostics = append(r.Diagnostics, Diagnostic{Severity: SeverityInfo, Message: fmt.Sprintf(format, args...)})
+}
+
+// HasErrors returns true if any diagnostic has severity "error".
+func (r Response) HasErrors() bool {
+	for _, diagnostic := range r.Diagnostics {
+		if diagnostic.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// Generator generates the files of a request. A returned error is sent to
+// urpc as a diagnostic with severity "error".
+type Generator func(req Request) (Response, error)
+
+// Serve reads a request from r, runs the generator and writes the response
+// to w. An error is returned only if the request cannot be read or the
+// response cannot be written.
+func Serve(r io.Reader, w io.Writer, generate Generator) error {
+	req := Request{}
+	if err := json.NewDecoder(r).Decode(&req); err != nil {
+		return fmt.Errorf("failed to decode request: %w", err)
+	}
+
+	res := Response{}
+	if req.ProtocolVersion != ProtocolVersion {
+		res.Errorf("unsupported protocol version %d, the plugin supports version %d", req.ProtocolVersion, ProtocolVersion)
+	} else {
+		generated, err := generate(req)
+		res = generated
+		if err != nil {
+			res.Errorf("%s", err)
+		}
+	}
+
+	if res.Files == nil {
+		res.Files = []File{}
+	}
+	if res.Diagnostics == nil {
+		res.Diagnostics = []Diagnostic{}
+	}
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		return fmt.Errorf("failed to encode response: %w", err)
+	}
+	return nil
+}
+
+// Main serves the request of stdin to stdout, it must be called from the
+// main function of the plugin.
+func Main(generate Generator) {
+	if err := Serve(os.Stdin, os.Stdout, generate); err != nil {
+		fmt.Fprintf(os.Stderr, "plugin: %s\n", err)
+		os.Exit(1)
+	}
+}
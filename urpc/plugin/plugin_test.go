@@ -0,0 +1,93 @@
+package plugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testRequest = `{
+  "protocolVersion": 1,
+  "schema": {
+    "version": 1,
+    "nodes": [
+      {"kind": "type", "name": "User", "fields": [{"name": "id", "typeName": "string", "isArray": false, "optional": false}]},
+      {"kind": "proc", "name": "GetUser", "input": [], "output": []}
+    ]
+  },
+  "options": {"prefix": "gen_", "count": 2},
+  "outputDir": "/project/out"
+}`
+
+func serve(t *testing.T, request string, generate Generator) Response {
+	t.Helper()
+	var stdout bytes.Buffer
+	require.NoError(t, Serve(strings.NewReader(request), &stdout, generate))
+
+	res := Response{}
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &res))
+	return res
+}
+
+func TestServe(t *testing.T) {
+	res := serve(t, testRequest, func(req Request) (Response, error) {
+		require.Equal(t, "/project/out", req.OutputDir)
+		require.Len(t, req.Schema.GetTypeNodes(), 1)
+		require.Len(t, req.Schema.GetProcNodes(), 1)
+
+		opts := struct {
+			Prefix string `json:"prefix"`
+			Count  int    `json:"count"`
+		}{}
+		require.NoError(t, req.DecodeOptions(&opts))
+
+		res := Response{}
+		for _, typeNode := range req.Schema.GetTypeNodes() {
+			res.AddFile(opts.Prefix+typeNode.Name+".txt", typeNode.Fields[0].Name)
+		}
+		res.Warnf("count is %d", opts.Count)
+		res.Infof("done")
+		return res, nil
+	})
+
+	require.Equal(t, Response{
+		Files: []File{{Path: "gen_User.txt", Content: "id"}},
+		Diagnostics: []Diagnostic{
+			{Severity: SeverityWarning, Message: "count is 2"},
+			{Severity: SeverityInfo, Message: "done"},
+		},
+	}, res)
+	require.False(t, res.HasErrors())
+}
+
+func TestServeErrors(t *testing.T) {
+	res := serve(t, testRequest, func(req Request) (Response, error) {
+		return Response{}, errors.New("something failed")
+	})
+	require.Equal(t, Response{
+		Files:       []File{},
+		Diagnostics: []Diagnostic{{Severity: SeverityError, Message: "something failed"}},
+	}, res)
+	require.True(t, res.HasErrors())
+
+	res = serve(t, strings.Replace(testRequest, `"protocolVersion": 1`, `"protocolVersion": 99`, 1), func(req Request) (Response, error) {
+		require.Fail(t, "the generator must not run")
+		return Response{}, nil
+	})
+	require.Equal(t, "unsupported protocol version 99, the plugin supports version 1", res.Diagnostics[0].Message)
+
+	err := Serve(strings.NewReader("not json"), &bytes.Buffer{}, nil)
+	require.ErrorContains(t, err, "failed to decode request")
+}
+
+func TestDecodeOptions(t *testing.T) {
+	opts := struct {
+		Count int `json:"count"`
+	}{}
+	err := Request{Options: map[string]any{"count": "two"}}.DecodeOptions(&opts)
+	require.ErrorContains(t, err, "invalid options")
+}